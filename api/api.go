@@ -24,10 +24,15 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang/protobuf/jsonpb"
@@ -40,25 +45,73 @@ import (
 // TimeoutDuration represents the API response timeout duration in miliseconds.
 const TimeoutDuration = 30 * time.Second
 
+// RunIDHeader is the HTTP header carrying the validation run ID on every request.
+const RunIDHeader = "X-Validator-Run-Id"
+
+// retryBackoff is the delay before the first retry of a failed submit, growing linearly with each attempt.
+var retryBackoff = time.Second
+
 var reader = ioutil.ReadFile
 
+var writer = ioutil.WriteFile
+
+var sleep = time.Sleep
+
+var systemCertPool = x509.SystemCertPool
+
 // HTTPConnection is a convenience struct for holding connection-related objects.
 type HTTPConnection struct {
 	client      *http.Client
 	config      *tls.Config
+	systemRoots bool
+	proxy       *url.URL
 	credentials string
 	marshaler   *jsonpb.Marshaler
 	baseURL     string
+	runID       string
+	// mu guards certificates, compression, keepAlive, redirects, artifacts, artifact usage, passing, locators,
+	// transactions, statuses and queries, which are used by concurrent requests in load tests.
+	mu           sync.Mutex
+	certificates *CertificateReport
+	compression  map[string]*EndpointCompression
+	keepAlive    keepAlive
+	redirects    []Redirect
+	presence     utils.PresenceTally
+	locators     map[string]string
+	transactions map[string]string
+	statuses     map[string]int
+	queries      map[string]url.Values
+	maxLogBytes  int
+	artifactDir  string
+	artifacts    int
+	// artifactQuota is the limit of SetArtifactQuota, and artifactBytes the size of artifactDir once artifactSized.
+	artifactQuota int64
+	artifactBytes int64
+	artifactSized bool
+	passingRate   float64
+	passing       int
+	strictJSON    bool
+	keyOrder      bool
+	saveFailures  bool
+	requireHTTPS  bool
+	headers       http.Header
+	hostHeader    string
+	timeHeader    string
+	fakedTime     time.Time
+	sessions      *sessionCache
+	sessionFile   string
+	bridge        *xmlBridge
 }
 
 // InitHTTPConnection creates and returns a new HTTPConnection object with a given server address and username/password.
-func InitHTTPConnection(serverAddr, credentialsFile, caFile, fullServerName string) (*HTTPConnection, error) {
+// The runID is sent in the RunIDHeader of every request so partners can correlate their server logs with a run.
+func InitHTTPConnection(serverAddr, credentialsFile, caFile, fullServerName, runID string) (*HTTPConnection, error) {
 	// Set up username/password.
 	credentials, err := setupCredentials(credentialsFile)
 	if err != nil {
 		return nil, err
 	}
-	config, err := setupCertConfig(caFile, fullServerName)
+	config, err := setupCertConfig(caFile, fullServerName, false)
 	if err != nil {
 		return nil, err
 	}
@@ -66,7 +119,7 @@ func InitHTTPConnection(serverAddr, credentialsFile, caFile, fullServerName stri
 	if config != nil {
 		protocol = "https"
 	}
-	return &HTTPConnection{
+	h := &HTTPConnection{
 		client: &http.Client{
 			Timeout:   TimeoutDuration,
 			Transport: &http.Transport{TLSClientConfig: config},
@@ -75,10 +128,171 @@ func InitHTTPConnection(serverAddr, credentialsFile, caFile, fullServerName stri
 		credentials: credentials,
 		marshaler:   &jsonpb.Marshaler{OrigName: true},
 		baseURL:     protocol + "://" + serverAddr,
-	}, nil
+		runID:       runID,
+	}
+	h.client.CheckRedirect = h.checkRedirect
+	if config != nil {
+		h.resumeSessions(config)
+	}
+	return h, nil
+}
+
+// RunID returns the validation run ID attached to requests sent over this connection.
+func (h *HTTPConnection) RunID() string {
+	return h.runID
+}
+
+// CertificateReport returns the certificate chain presented by the server on the first HTTPS response, or nil when
+// no TLS connection has been made.
+func (h *HTTPConnection) CertificateReport() *CertificateReport {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.certificates
+}
+
+// FieldPresence returns the share of the room rates of the availability responses received over this connection
+// setting each optional field, or nil when no room rate was received.
+func (h *HTTPConnection) FieldPresence() *utils.PresenceReport {
+	return h.presence.Report()
+}
+
+// recordPresence records the optional fields set in the room rates of resp, an availability response.
+func (h *HTTPConnection) recordPresence(resp *pb.BookingAvailabilityResponse) {
+	h.presence.AddResponse()
+	plans := utils.RatePlansByCode(resp.GetRatePlans())
+	for _, r := range resp.GetRoomRates() {
+		h.presence.AddRoomRate(r, plans)
+	}
+}
+
+// SetResponseLogLimit truncates logged response bodies to maxBytes. The full body of a truncated response is written
+// to a file in a per-run directory under artifactDir, and its path is logged instead. A maxBytes of 0 disables
+// truncation.
+func (h *HTTPConnection) SetResponseLogLimit(maxBytes int, artifactDir string) {
+	h.maxLogBytes = maxBytes
+	h.artifactDir = artifactDir
+}
+
+// SetHeader sends an additional HTTP header with every request, e.g. Accept-Language. An empty value removes it.
+func (h *HTTPConnection) SetHeader(name, value string) {
+	if h.headers == nil {
+		h.headers = http.Header{}
+	}
+	if value == "" {
+		h.headers.Del(name)
+		return
+	}
+	h.headers.Set(name, value)
+}
+
+// Header returns the value of an additional header set by SetHeader, or "" if none is set.
+func (h *HTTPConnection) Header(name string) string {
+	return h.headers.Get(name)
+}
+
+// SetHostHeader sends host as the Host header of every request instead of the server address, for partners behind
+// load balancers routing on Host. The TLS server name is unaffected, it is set with fullServerName. An empty host
+// restores the server address.
+func (h *HTTPConnection) SetHostHeader(host string) {
+	h.hostHeader = host
 }
 
-func (h HTTPConnection) getURL(endpoint string) string {
+// SetStrictJSON makes responses starting with a byte order mark or XSSI prefix fail to parse, rather than being
+// stripped with a Warning finding.
+func (h *HTTPConnection) SetStrictJSON(strict bool) {
+	h.strictJSON = strict
+}
+
+// SetCheckKeyOrder reports the first key of each json object in responses that is out of the order of the schema as
+// a Warning finding. Repeated keys are reported regardless.
+func (h *HTTPConnection) SetCheckKeyOrder(check bool) {
+	h.keyOrder = check
+}
+
+// SetProxy routes requests through the proxy at proxyURL, which may use the http, https, socks5 or socks5h scheme.
+// With socks5h the proxy resolves the server name, with socks5 the validator does. Proxy credentials are taken from
+// the user info of proxyURL or, if credentialsFile is set, from a file with a single line of the form
+// 'username:password'. An empty proxyURL connects directly.
+func (h *HTTPConnection) SetProxy(proxyURL, credentialsFile string) error {
+	if proxyURL == "" {
+		return nil
+	}
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return fmt.Errorf("invalid proxy url: %v", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5", "socks5h":
+	default:
+		return fmt.Errorf("unsupported proxy scheme %q, expected http, https, socks5 or socks5h", u.Scheme)
+	}
+	if credentialsFile != "" {
+		data, err := reader(credentialsFile)
+		if err != nil {
+			return fmt.Errorf("failed to read proxy credentials: %v", err)
+		}
+		parts := strings.SplitN(strings.TrimSpace(string(data)), ":", 2)
+		if len(parts) != 2 {
+			return errors.New("proxy credentials file should have exactly one line of the form 'username:password'")
+		}
+		u.User = url.UserPassword(parts[0], parts[1])
+	}
+	transport, ok := h.client.Transport.(*http.Transport)
+	if !ok {
+		return errors.New("connection does not support proxies")
+	}
+	if u.Scheme == "socks5" {
+		// net/http leaves resolving the server name to any SOCKS proxy, as for socks5h.
+		dial, err := socksDialer(u)
+		if err != nil {
+			return err
+		}
+		transport.DialContext = dial
+	} else {
+		transport.Proxy = http.ProxyURL(u)
+	}
+	h.proxy = u
+	return nil
+}
+
+// UseSystemRoots switches the connection to https, trusting the system root certificates in addition to the roots
+// in caFile, if any. Partners with publicly trusted certificates need no caFile at all.
+func (h *HTTPConnection) UseSystemRoots(caFile, fullServerName string) error {
+	config, err := setupCertConfig(caFile, fullServerName, true)
+	if err != nil {
+		return err
+	}
+	return h.useTLSConfig(config, true)
+}
+
+// TrustRoots switches the connection to https, trusting the PEM encoded root certificates in pem, and the system
+// roots as well if systemRoots is set, for callers given the roots themselves rather than the path of a file.
+func (h *HTTPConnection) TrustRoots(pem []byte, fullServerName string, systemRoots bool) error {
+	config, err := certConfig(pem, fullServerName, systemRoots)
+	if err != nil {
+		return err
+	}
+	if config == nil {
+		return errors.New("no root certificates given")
+	}
+	return h.useTLSConfig(config, systemRoots)
+}
+
+// useTLSConfig switches the connection to https with config, which trusts the system roots if systemRoots is set.
+func (h *HTTPConnection) useTLSConfig(config *tls.Config, systemRoots bool) error {
+	transport, ok := h.client.Transport.(*http.Transport)
+	if !ok {
+		return errors.New("connection does not support custom root certificates")
+	}
+	h.resumeSessions(config)
+	transport.TLSClientConfig = config
+	h.config = config
+	h.systemRoots = systemRoots
+	h.baseURL = "https://" + strings.TrimPrefix(strings.TrimPrefix(h.baseURL, "http://"), "https://")
+	return nil
+}
+
+func (h *HTTPConnection) getURL(endpoint string) string {
 	if endpoint != "" {
 		return fmt.Sprintf("%v%v", h.baseURL, endpoint)
 	}
@@ -97,17 +311,45 @@ func setupCredentials(credentialsFile string) (string, error) {
 	return credentials, nil
 }
 
-func setupCertConfig(caFile, fullServerName string) (*tls.Config, error) {
-	if caFile == "" {
-		return nil, nil
+// setupCertConfig returns the TLS configuration trusting the roots in caFile, added to the system roots if
+// systemRoots is set, or nil to connect using http when neither is requested.
+func setupCertConfig(caFile, fullServerName string, systemRoots bool) (*tls.Config, error) {
+	var pem []byte
+	if caFile != "" {
+		b, err := reader(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read root certificates file: %v", err)
+		}
+		if len(b) == 0 {
+			return nil, errors.New("failed to parse root certificates, please check your roots file (ca_file flag) and try again")
+		}
+		pem = b
 	}
-	b, err := reader(caFile)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read root certificates file: %v", err)
+	config, err := certConfig(pem, fullServerName, systemRoots)
+	if err == errRootsUnparsable {
+		return nil, errors.New("failed to parse root certificates, please check your roots file (ca_file flag) and try again")
+	}
+	return config, err
+}
+
+// errRootsUnparsable is returned by certConfig for PEM data without a certificate.
+var errRootsUnparsable = errors.New("failed to parse root certificates, no PEM encoded certificate found")
+
+// certConfig returns the TLS configuration trusting the PEM encoded roots in pem, added to the system roots if
+// systemRoots is set, or nil when neither is requested.
+func certConfig(pem []byte, fullServerName string, systemRoots bool) (*tls.Config, error) {
+	if len(pem) == 0 && !systemRoots {
+		return nil, nil
 	}
 	cp := x509.NewCertPool()
-	if !cp.AppendCertsFromPEM(b) {
-		return nil, errors.New("failed to parse root certificates, please check your roots file (ca_file flag) and try again")
+	if systemRoots {
+		var err error
+		if cp, err = systemCertPool(); err != nil {
+			return nil, fmt.Errorf("failed to load system root certificates: %v", err)
+		}
+	}
+	if len(pem) > 0 && !cp.AppendCertsFromPEM(pem) {
+		return nil, errRootsUnparsable
 	}
 	return &tls.Config{
 		RootCAs:    cp,
@@ -115,76 +357,469 @@ func setupCertConfig(caFile, fullServerName string) (*tls.Config, error) {
 	}, nil
 }
 
+// credentialHeaders are the request headers whose values are redacted from the log, as they carry credentials.
+var credentialHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "X-Api-Key"}
+
+// redactHeaders returns a copy of header with the values of credentialHeaders replaced.
+func redactHeaders(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, name := range credentialHeaders {
+		if values := redacted.Values(name); len(values) > 0 {
+			redacted[http.CanonicalHeaderKey(name)] = []string{"<redacted>"}
+		}
+	}
+	return redacted
+}
+
 func logHTTPRequest(rpcName string, httpReq *http.Request) {
-	log.Printf("RPC %s Request. Sent(unix): %s, Url: %s, Method: %s, Header: %s, Body: %v\n", rpcName, time.Now().UTC().Format(time.RFC850), httpReq.URL, httpReq.Method, httpReq.Header, httpReq.Body)
+	log.Printf("RPC %s Request. Sent(unix): %s, Url: %s, Method: %s, Header: %s, Body: %v\n", rpcName, time.Now().UTC().Format(time.RFC850), httpReq.URL, httpReq.Method, redactHeaders(httpReq.Header), httpReq.Body)
 }
 
 func logHTTPResponse(rpcName, bodyString string) {
 	log.Printf("RPC %s Response. Received(unix): %s, Response %s\n", rpcName, time.Now().UTC().Format(time.RFC850), bodyString)
 }
 
+// recordCertificates keeps the certificate chain of the first TLS response.
+func (h *HTTPConnection) recordCertificates(state *tls.ConnectionState) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.certificates == nil {
+		h.certificates = newCertificateReport(state, time.Now())
+		logCertificateReport(h.certificates)
+	}
+}
+
 // sendRequest sets up and sends the relevant HTTP request to the server and returns the HTTP response.
 func sendRequest(endpoint, req string, conn *HTTPConnection) (string, error) {
-	httpReq, err := http.NewRequest("POST", conn.getURL(endpoint), bytes.NewBuffer([]byte(req)))
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", conn.credentials)
-	logHTTPRequest(endpoint, httpReq)
-	httpResp, err := conn.client.Do(httpReq)
+	_, body, err := sendRequestStatus(endpoint, req, conn)
+	return body, err
+}
+
+// sendRequestStatus behaves like sendRequest, and also returns the HTTP status of the response.
+func sendRequestStatus(endpoint, req string, conn *HTTPConnection) (int, string, error) {
+	httpResp, err := openRequest(endpoint, req, conn)
 	if err != nil {
-		return "", fmt.Errorf("Invalid response. %s yielded error: %v", endpoint, err)
+		return 0, "", err
 	}
 	defer httpResp.Body.Close()
 	bodyBytes, err := ioutil.ReadAll(httpResp.Body)
 	if err != nil {
-		return "", fmt.Errorf("Could not read http response body: %v", err)
+		var te *TimeoutError
+		if errors.As(err, &te) {
+			return httpResp.StatusCode, "", te
+		}
+		if errors.Is(err, ErrParse) {
+			return httpResp.StatusCode, "", err
+		}
+		return httpResp.StatusCode, "", fmt.Errorf("%w: could not read http response body: %v", ErrTransport, err)
 	}
 	bodyString := string(bodyBytes)
-	logHTTPResponse(endpoint, bodyString)
-	return bodyString, nil
+	conn.logResponse(endpoint, bodyString)
+	return httpResp.StatusCode, bodyString, nil
 }
 
-// BookingAvailability requests the rooms and metadata, that are available for a specified request context
-func BookingAvailability(reqPB *pb.BookingAvailabilityRequest, conn *HTTPConnection, endpoint string) error {
+// newRequest builds the HTTP request sending the json encoded req to endpoint, with the headers of conn.
+func (h *HTTPConnection) newRequest(endpoint, req string) (*http.Request, error) {
+	path, err := expandEndpoint(endpoint, req)
+	if err != nil {
+		return nil, err
+	}
+	path = h.withQuery(endpoint, path)
+	httpReq, err := http.NewRequest("POST", h.getURL(path), bytes.NewBuffer([]byte(h.wrapRequest(req))))
+	if err != nil {
+		return nil, fmt.Errorf("%s: invalid request: %v", endpoint, err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if h.bridge != nil {
+		httpReq.Header.Set("Content-Type", XMLContentType)
+	}
+	httpReq.Header.Set("Authorization", h.credentials)
+	if h.runID != "" {
+		httpReq.Header.Set(RunIDHeader, h.runID)
+	}
+	for name, values := range h.headers {
+		httpReq.Header[name] = values
+	}
+	if h.hostHeader != "" {
+		httpReq.Host = h.hostHeader
+	}
+	return httpReq, nil
+}
+
+// openRequest sends the HTTP request and returns the response once its status has been checked. The caller must
+// close the response body.
+func openRequest(endpoint, req string, conn *HTTPConnection) (*http.Response, error) {
+	httpResp, _, err := openTimedRequest(endpoint, req, conn)
+	return httpResp, err
+}
+
+// openTimedRequest behaves like openRequest, and also returns the timer recording the phases of the request.
+func openTimedRequest(endpoint, req string, conn *HTTPConnection) (*http.Response, *requestTimer, error) {
+	if err := conn.checkPlaintextCredentials(); err != nil {
+		return nil, nil, err
+	}
+	httpReq, err := conn.newRequest(endpoint, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	timer := &requestTimer{deadline: conn.client.Timeout}
+	httpReq = httpReq.WithContext(conn.traceConnections(timer.trace(httpReq.Context())))
+	compressed := offerCompression(httpReq)
+	logHTTPRequest(endpoint, httpReq)
+	httpResp, err := conn.client.Do(httpReq)
+	if err != nil {
+		if te := timer.classify(endpoint, err); te != nil {
+			return nil, timer, te
+		}
+		if errors.Is(err, ErrRedirect) {
+			return nil, timer, fmt.Errorf("%s yielded error: %w", endpoint, err)
+		}
+		return nil, timer, fmt.Errorf("%w: %s yielded error: %v", ErrTransport, endpoint, err)
+	}
+	if httpResp.Close {
+		conn.recordClose()
+	}
+	if compressed {
+		conn.decompressResponse(endpoint, httpResp)
+	}
+	if httpResp.TLS != nil {
+		conn.recordCertificates(httpResp.TLS)
+	}
+	if conn.ExpectedStatus(endpoint) != httpResp.StatusCode {
+		if err := timer.gatewayTimeout(endpoint, httpResp); err != nil {
+			httpResp.Body.Close()
+			return nil, timer, err
+		}
+	}
+	if err := conn.checkStatus(endpoint, httpResp); err != nil {
+		httpResp.Body.Close()
+		return nil, timer, err
+	}
+	httpResp.Body = conn.unwrapResponse(endpoint, &timedBody{httpResp.Body, timer, endpoint})
+	return httpResp, timer, nil
+}
+
+// logResponse logs body, truncated to the connection's limit, and saves the full body as an artifact if it was cut.
+func (h *HTTPConnection) logResponse(rpcName, body string) {
+	if h.maxLogBytes <= 0 || len(body) <= h.maxLogBytes {
+		logHTTPResponse(rpcName, body)
+		return
+	}
+	path, err := h.writeArtifact(rpcName, body)
+	if err != nil {
+		log.Printf("Could not save full response of %s: %v\n", rpcName, err)
+		path = "not saved"
+	}
+	logHTTPResponse(rpcName, fmt.Sprintf("%s... [truncated %d of %d bytes, full response: %s]", body[:h.maxLogBytes], len(body)-h.maxLogBytes, len(body), path))
+}
+
+// writeArtifact saves body to <artifactDir>/<runID>/<n>_<rpcName>.json and returns the path of the file, unless the
+// connection's artifact quota is reached, see SetArtifactQuota.
+func (h *HTTPConnection) writeArtifact(rpcName, body string) (string, error) {
+	return h.writeArtifactWithin(rpcName, body, h.artifactQuota)
+}
+
+// writeArtifactWithin behaves like writeArtifact but returns ErrArtifactQuota if body would grow artifactDir beyond
+// limit bytes. A limit of 0 saves every artifact.
+func (h *HTTPConnection) writeArtifactWithin(rpcName, body string, limit int64) (string, error) {
+	runDir := h.runID
+	if runDir == "" {
+		runDir = "run"
+	}
+	dir := filepath.Join(h.artifactDir, runDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	h.mu.Lock()
+	if limit > 0 {
+		if !h.artifactSized {
+			h.artifactBytes, h.artifactSized = dirSize(h.artifactDir), true
+		}
+		if h.artifactBytes+int64(len(body)) > limit {
+			h.mu.Unlock()
+			return "", fmt.Errorf("%w: %s would grow %s beyond %d bytes", ErrArtifactQuota, rpcName, h.artifactDir, limit)
+		}
+	}
+	h.artifactBytes += int64(len(body))
+	h.artifacts++
+	n := h.artifacts
+	h.mu.Unlock()
+	name := strings.Trim(strings.Replace(rpcName, "/", "_", -1), "_")
+	if name == "" {
+		name = "response"
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%d_%s.json", n, name))
+	if err := writer(path, []byte(body), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// SendJSON sends the json encoded body to endpoint unchanged and returns the response body with any anti-hijacking
+// prefix removed. Unlike the other Send functions it can carry fields outside of the v1 schema.
+func SendJSON(body string, conn *HTTPConnection, endpoint string) (string, error) {
+	httpResp, err := sendRequest(endpoint, body, conn)
+	if err != nil {
+		return "", fmt.Errorf("%s: HTTP response yielded error: %w", endpoint, err)
+	}
+	httpResp, _ = stripJSONPrefix(httpResp)
+	return httpResp, nil
+}
+
+// SendJSONStatus behaves like SendJSON, and also returns the HTTP status of the response, for scenarios expecting a
+// partner to reject a request with a status rather than an error type.
+func SendJSONStatus(body string, conn *HTTPConnection, endpoint string) (int, string, error) {
+	status, httpResp, err := sendRequestStatus(endpoint, body, conn)
+	if err != nil {
+		return status, "", fmt.Errorf("%s: HTTP response yielded error: %w", endpoint, err)
+	}
+	httpResp, _ = stripJSONPrefix(httpResp)
+	return status, httpResp, nil
+}
+
+// SendBookingAvailability sends reqPB to the availability endpoint and returns the parsed, unvalidated response.
+func SendBookingAvailability(reqPB *pb.BookingAvailabilityRequest, conn *HTTPConnection, endpoint string) (*pb.BookingAvailabilityResponse, error) {
+	respPB, _, _, err := sendBookingAvailability(reqPB, conn, endpoint)
+	return respPB, err
+}
+
+// sendBookingAvailability returns the parsed response along with its body, which is set even if it failed to parse.
+func sendBookingAvailability(reqPB *pb.BookingAvailabilityRequest, conn *HTTPConnection, endpoint string) (*pb.BookingAvailabilityResponse, string, []utils.Finding, error) {
 	req, err := conn.marshaler.MarshalToString(reqPB)
 	if err != nil {
-		return fmt.Errorf("Could not convert pb3 to json: %v, Error: %v", reqPB, err)
+		return nil, "", nil, fmt.Errorf("Could not convert pb3 to json: %v, Error: %v", reqPB, err)
 	}
 
 	httpResp, err := sendRequest(endpoint, req, conn)
 	if err != nil {
-		return fmt.Errorf("HTTP response yielded error: %v", err)
+		return nil, "", sendFindings(err), fmt.Errorf("HTTP response yielded error: %w", err)
 	}
 	var respPB pb.BookingAvailabilityResponse
-	if err := jsonpb.UnmarshalString(httpResp, &respPB); err != nil {
-		return fmt.Errorf("Could not parse HTTP response to pb3: %v", err)
+	findings, err := parseResponse(httpResp, conn, &respPB)
+	if err != nil {
+		return nil, httpResp, findings, fmt.Errorf("%w: %v", ErrParse, err)
 	}
+	conn.recordPresence(&respPB)
+	return &respPB, httpResp, findings, nil
+}
 
-	if err := utils.ValidateBookingAvailabilityResponse(reqPB, &respPB); err != nil {
-		return fmt.Errorf("Validation error: %v", err)
+// BookingAvailability requests the rooms and metadata, that are available for a specified request context
+func BookingAvailability(reqPB *pb.BookingAvailabilityRequest, conn *HTTPConnection, endpoint string) ([]utils.Finding, error) {
+	respPB, body, findings, err := sendBookingAvailability(reqPB, conn, endpoint)
+	if err != nil {
+		return conn.saveFailure(endpoint, reqPB, nil, body, findings, err)
 	}
+	findings = append(findings, conn.transactionFindings(reqPB, respPB)...)
+	findings = append(findings, utils.LintAvailabilityError(respPB.GetError())...)
+	findings = append(findings, utils.CheckFieldLengths("", respPB)...)
 
-	return nil
+	validation, err := utils.ValidateBookingAvailabilityResponse(reqPB, respPB)
+	findings = append(findings, validation...)
+	if err != nil {
+		return conn.saveFailure(endpoint, reqPB, respPB, body, findings, fmt.Errorf("%w: %v", ErrValidation, err))
+	}
+
+	conn.SamplePassing(endpoint, reqPB, respPB)
+	return findings, nil
 }
 
-// BookingSubmit requests the rooms and metadata, that are available for a specified request context
-func BookingSubmit(reqPB *pb.BookingSubmitRequest, conn *HTTPConnection, endpoint string) error {
+// BookingAvailabilityFromReader behaves like BookingAvailability for a json or pb3 encoded request read from r, e.g.
+// strings.NewReader of a raw json string.
+func BookingAvailabilityFromReader(r io.Reader, conn *HTTPConnection, endpoint string) ([]utils.Finding, error) {
+	var reqPB pb.BookingAvailabilityRequest
+	if err := utils.LoadRequestFromReader(r, &reqPB); err != nil {
+		return nil, err
+	}
+	return BookingAvailability(&reqPB, conn, endpoint)
+}
+
+// SendBookingSubmit sends reqPB to the submit endpoint and returns the parsed, unvalidated response.
+func SendBookingSubmit(reqPB *pb.BookingSubmitRequest, conn *HTTPConnection, endpoint string) (*pb.BookingSubmitResponse, error) {
 	req, err := conn.marshaler.MarshalToString(reqPB)
 	if err != nil {
-		return fmt.Errorf("Could not convert pb3 to json: %v, Error: %v", reqPB, err)
+		return nil, fmt.Errorf("Could not convert pb3 to json: %v, Error: %v", reqPB, err)
 	}
 
 	httpResp, err := sendRequest(endpoint, req, conn)
 	if err != nil {
-		return fmt.Errorf("%s: HTTP response yielded error: %v", endpoint, err)
+		return nil, fmt.Errorf("%s: HTTP response yielded error: %w", endpoint, err)
 	}
 	var respPB pb.BookingSubmitResponse
-	if err := jsonpb.UnmarshalString(httpResp, &respPB); err != nil {
-		return fmt.Errorf("%s: Could not parse HTTP response to pb3: %v", endpoint, err)
+	if _, err := parseResponse(httpResp, conn, &respPB); err != nil {
+		return nil, fmt.Errorf("%s: %w: %v", endpoint, ErrParse, err)
 	}
+	return &respPB, nil
+}
 
-	if err := utils.ValidateBookingSubmitResponse(reqPB, &respPB); err != nil {
-		return fmt.Errorf("Validation error: %v", err)
+// BookingSubmit requests the rooms and metadata, that are available for a specified request context
+func BookingSubmit(reqPB *pb.BookingSubmitRequest, conn *HTTPConnection, endpoint string) ([]utils.Finding, error) {
+	return BookingSubmitWithRetries(reqPB, conn, endpoint, 0)
+}
+
+// BookingSubmitFromReader behaves like BookingSubmit for a json or pb3 encoded request read from r.
+func BookingSubmitFromReader(r io.Reader, conn *HTTPConnection, endpoint string) ([]utils.Finding, error) {
+	var reqPB pb.BookingSubmitRequest
+	if err := utils.LoadRequestFromReader(r, &reqPB); err != nil {
+		return nil, err
 	}
+	return BookingSubmit(&reqPB, conn, endpoint)
+}
 
-	return nil
+// BookingSubmitWithRetries behaves like BookingSubmit but resends reqPB up to retries times after transport failures.
+// Every attempt carries the same transaction_id, which partners use to deduplicate bookings. When a retry was needed,
+// the request is replayed once more after it succeeds and the partner must return the same reservation or a
+// DUPLICATE_BOOKING error; a second reservation is reported as a Critical finding, and a replay failing otherwise as
+// a Warning finding.
+func BookingSubmitWithRetries(reqPB *pb.BookingSubmitRequest, conn *HTTPConnection, endpoint string, retries int) ([]utils.Finding, error) {
+	req, err := conn.marshaler.MarshalToString(reqPB)
+	if err != nil {
+		return nil, fmt.Errorf("Could not convert pb3 to json: %v, Error: %v", reqPB, err)
+	}
+
+	var httpResp string
+	attempt := 0
+	for ; ; attempt++ {
+		httpResp, err = sendRequest(endpoint, req, conn)
+		if err == nil || !errors.Is(err, ErrTransport) || attempt >= retries {
+			break
+		}
+		log.Printf("Retrying %s with transaction_id %s after transport failure (%d/%d): %v\n", endpoint, reqPB.GetTransactionId(), attempt+1, retries, err)
+		sleep(retryBackoff * time.Duration(attempt+1))
+	}
+	if err != nil {
+		return sendFindings(err), fmt.Errorf("%s: HTTP response yielded error: %w", endpoint, err)
+	}
+	var respPB pb.BookingSubmitResponse
+	findings, err := parseResponse(httpResp, conn, &respPB)
+	if err != nil {
+		return conn.saveFailure(endpoint, reqPB, nil, httpResp, findings, fmt.Errorf("%s: %w: %v", endpoint, ErrParse, err))
+	}
+	findings = append(findings, utils.LintSubmitError(respPB.GetError())...)
+	findings = append(findings, utils.CheckFieldLengths("", &respPB)...)
+	findings = append(findings, utils.LintPayment(reqPB, utils.Now())...)
+
+	validation, err := utils.ValidateBookingSubmitResponse(reqPB, &respPB)
+	findings = append(findings, validation...)
+	if err != nil {
+		return conn.saveFailure(endpoint, reqPB, &respPB, httpResp, findings, fmt.Errorf("%w: %v", ErrValidation, err))
+	}
+	masking, err := utils.ValidateCardMasking(reqPB, httpResp)
+	findings = append(findings, masking...)
+	if err != nil {
+		return conn.saveFailure(endpoint, reqPB, &respPB, httpResp, findings, fmt.Errorf("%w: %v", ErrValidation, err))
+	}
+
+	if respPB.GetStatus() == pb.BookingSubmitResponse_SUCCESS {
+		id := respPB.GetReservation().GetLocator().GetId()
+		if prev := conn.recordLocator(reqPB.GetTransactionId(), id); prev != "" {
+			f := utils.Finding{
+				Severity: utils.Critical,
+				Field:    "reservation > locator > id",
+				Message:  fmt.Sprintf("locator %s was already returned for transaction_id %s", id, prev),
+				Code:     utils.CodeLocatorReused,
+			}
+			log.Println(f)
+			return conn.saveFailure(endpoint, reqPB, &respPB, httpResp, append(findings, f), fmt.Errorf("%w: reservation locator %s is not unique", ErrValidation, id))
+		}
+	}
+
+	if attempt > 0 {
+		f, err := checkDeduplication(reqPB, &respPB, conn, endpoint)
+		if f != nil {
+			findings = append(findings, *f)
+		}
+		if err != nil {
+			return conn.saveFailure(endpoint, reqPB, &respPB, httpResp, findings, fmt.Errorf("%w: %v", ErrValidation, err))
+		}
+	}
+
+	conn.SamplePassing(endpoint, reqPB, &respPB)
+	return findings, nil
+}
+
+// recordLocator remembers that locator was returned for transactionID. If it was previously returned for another
+// transaction_id, that transaction_id is returned.
+func (h *HTTPConnection) recordLocator(transactionID, locator string) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.locators == nil {
+		h.locators = map[string]string{}
+	}
+	if prev, ok := h.locators[locator]; ok && prev != transactionID {
+		return prev
+	}
+	h.locators[locator] = transactionID
+	return ""
+}
+
+// recordTransaction remembers that respID was returned as the transaction_id of the response to the request with
+// transaction_id reqID. If respID was previously returned for a request with another transaction_id, that
+// transaction_id is returned.
+func (h *HTTPConnection) recordTransaction(reqID, respID string) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.transactions == nil {
+		h.transactions = map[string]string{}
+	}
+	if prev, ok := h.transactions[respID]; ok && prev != reqID {
+		return prev
+	}
+	h.transactions[respID] = reqID
+	return ""
+}
+
+// transactionFindings returns an Error finding if the transaction_id of resp was already returned during the run in
+// response to a request with another transaction_id, which means the partner does not echo it.
+func (h *HTTPConnection) transactionFindings(req *pb.BookingAvailabilityRequest, resp *pb.BookingAvailabilityResponse) []utils.Finding {
+	if resp.GetTransactionId() == "" {
+		return nil
+	}
+	prev := h.recordTransaction(req.GetTransactionId(), resp.GetTransactionId())
+	if prev == "" {
+		return nil
+	}
+	f := utils.Finding{
+		Severity: utils.Error,
+		Field:    "transaction_id",
+		Message:  fmt.Sprintf("transaction_id %s was returned for requests with transaction_id %s and %s", resp.GetTransactionId(), prev, req.GetTransactionId()),
+		Code:     utils.CodeTransactionReused,
+	}
+	log.Println(f)
+	return []utils.Finding{f}
+}
+
+// checkDeduplication replays reqPB, which previously produced resp after a retry, and ensures the partner did not
+// create a second reservation for the same transaction_id.
+func checkDeduplication(reqPB *pb.BookingSubmitRequest, resp *pb.BookingSubmitResponse, conn *HTTPConnection, endpoint string) (*utils.Finding, error) {
+	log.Printf("Replaying %s with transaction_id %s to verify deduplication\n", endpoint, reqPB.GetTransactionId())
+	replay, err := SendBookingSubmit(reqPB, conn, endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("replayed submit with transaction_id %s yielded error: %w", reqPB.GetTransactionId(), err)
+	}
+	if replay.GetStatus() == pb.BookingSubmitResponse_FAILURE && replay.GetError().GetType() == pb.SubmitError_DUPLICATE_BOOKING {
+		return nil, nil
+	}
+	want, got := resp.GetReservation().GetLocator().GetId(), replay.GetReservation().GetLocator().GetId()
+	if replay.GetStatus() == pb.BookingSubmitResponse_FAILURE && got == "" {
+		// Nothing was booked by the replay, but neither was the booking recognized as a duplicate.
+		f := &utils.Finding{
+			Severity: utils.Warning,
+			Field:    "error > type",
+			Message:  fmt.Sprintf("replayed submit with transaction_id %s failed with %v rather than returning reservation %s or DUPLICATE_BOOKING, so deduplication could not be verified", reqPB.GetTransactionId(), replay.GetError().GetType(), want),
+			Code:     utils.CodeReplayFailed,
+		}
+		log.Println(f)
+		return f, nil
+	}
+	if got == want {
+		return nil, nil
+	}
+	f := &utils.Finding{
+		Severity: utils.Critical,
+		Field:    "reservation > locator > id",
+		Message:  fmt.Sprintf("duplicate booking: transaction_id %s was booked as both %s and %s", reqPB.GetTransactionId(), want, got),
+		Code:     utils.CodeDuplicateBooking,
+	}
+	log.Println(f)
+	return f, fmt.Errorf("partner did not deduplicate transaction_id %s", reqPB.GetTransactionId())
 }
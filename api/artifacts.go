@@ -0,0 +1,119 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+// ErrArtifactQuota means an artifact was not saved because the artifact directory reached its quota.
+var ErrArtifactQuota = errors.New("artifact quota reached")
+
+// SetArtifactQuota limits the size of the artifactDir set by SetResponseLogLimit, including the artifacts of
+// previous runs, to maxBytes, so long soak tests do not fill the disk. Artifacts that would exceed it are not
+// saved and their absence is logged. Sampled passing payloads, see SetPassingSampleRate, only use the first half of
+// the quota, leaving the rest to failures. A maxBytes of 0 removes the limit.
+func (h *HTTPConnection) SetArtifactQuota(maxBytes int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.artifactQuota = maxBytes
+}
+
+// SetPassingSampleRate saves the anonymized request and response of a share rate, between 0 and 1, of the
+// availability and submit flows passing validation, alongside the payloads of failures saved by SetSaveFailures.
+// Flows are sampled evenly, e.g. every tenth flow for a rate of 0.1. A rate of 0 saves no passing payloads.
+func (h *HTTPConnection) SetPassingSampleRate(rate float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.passingRate = rate
+}
+
+// dirSize returns the total size of the files under dir, or 0 if it does not exist.
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.Walk(dir, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// savePayloads saves the anonymized reqPB and respPB of endpoint as artifacts within limit bytes, see
+// writeArtifactWithin, and returns a "<kind> saved to <path>" description of each. Both are anonymized with the same
+// utils.Anonymizer, keeping echoed fields consistent; if respPB is nil, body is anonymized as text instead.
+func (h *HTTPConnection) savePayloads(endpoint string, reqPB, respPB proto.Message, body string, limit int64) []string {
+	a := utils.NewAnonymizer()
+	var saved []string
+	save := func(kind string, m proto.Message, raw string) {
+		payload := raw
+		if m != nil {
+			m = proto.Clone(m)
+			if aerr := a.Anonymize(m); aerr != nil {
+				log.Printf("Could not anonymize %s of %s: %v\n", kind, endpoint, aerr)
+				return
+			}
+			s, merr := h.marshaler.MarshalToString(m)
+			if merr != nil {
+				log.Printf("Could not encode %s of %s: %v\n", kind, endpoint, merr)
+				return
+			}
+			payload = s
+		}
+		path, werr := h.writeArtifactWithin(endpoint+"_"+kind, payload, limit)
+		if werr != nil {
+			log.Printf("Could not save %s of %s: %v\n", kind, endpoint, werr)
+			return
+		}
+		saved = append(saved, fmt.Sprintf("%s saved to %s", kind, path))
+	}
+	save("request", reqPB, "")
+	if respPB != nil {
+		save("response", respPB, "")
+	} else {
+		save("response", nil, a.AnonymizeText(body))
+	}
+	return saved
+}
+
+// SamplePassing saves the payloads of a flow that passed validation if it is sampled, see SetPassingSampleRate.
+// BookingAvailability and BookingSubmit call it for every passing flow; scenarios sending their own requests may call
+// it for theirs.
+func (h *HTTPConnection) SamplePassing(endpoint string, reqPB, respPB proto.Message) {
+	h.mu.Lock()
+	rate := h.passingRate
+	h.passing++
+	n := h.passing
+	limit := h.artifactQuota / 2
+	h.mu.Unlock()
+	if rate <= 0 || int(float64(n)*rate) == int(float64(n-1)*rate) {
+		return
+	}
+	if saved := h.savePayloads(endpoint, reqPB, respPB, "", limit); len(saved) > 0 {
+		log.Printf("Sampled passing payloads of %s: %s\n", endpoint, strings.Join(saved, ", "))
+	}
+}
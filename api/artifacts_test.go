@@ -0,0 +1,64 @@
+package api
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+func TestSetPassingSampleRate(t *testing.T) {
+	data, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	conn, server := NewFakeHTTPClient(t, data.Resp)
+	defer server.Close()
+	conn.runID = "run"
+	conn.SetResponseLogLimit(0, dir)
+	conn.SetPassingSampleRate(0.5)
+
+	for i := 0; i < 4; i++ {
+		if _, err := BookingAvailability(data.ReqPb, conn, "/v1/BookingAvailability"); err != nil {
+			t.Fatalf("BookingAvailability() returned error %v", err)
+		}
+	}
+	saved, err := filepath.Glob(filepath.Join(dir, "run", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != 4 {
+		t.Fatalf("4 passing flows sampled at 0.5 saved %v, want 2 requests and 2 responses", saved)
+	}
+	for _, path := range saved {
+		if !strings.HasSuffix(path, "_v1_BookingAvailability_request.json") && !strings.HasSuffix(path, "_v1_BookingAvailability_response.json") {
+			t.Errorf("sampling saved unexpected artifact %s", path)
+		}
+	}
+}
+
+func TestSetArtifactQuota(t *testing.T) {
+	dir := t.TempDir()
+	if err := ioutil.WriteFile(filepath.Join(dir, "previous.json"), []byte(strings.Repeat("x", 60)), 0644); err != nil {
+		t.Fatal(err)
+	}
+	conn := &HTTPConnection{runID: "run", artifactDir: dir}
+	conn.SetArtifactQuota(100)
+
+	if _, err := conn.writeArtifact("first", strings.Repeat("y", 30)); err != nil {
+		t.Fatalf("writeArtifact() within the quota returned error %v", err)
+	}
+	if _, err := conn.writeArtifact("second", strings.Repeat("y", 30)); !errors.Is(err, ErrArtifactQuota) {
+		t.Errorf("writeArtifact() beyond the quota, counting the previous run, returned error %v, want ErrArtifactQuota", err)
+	}
+	if _, err := conn.writeArtifactWithin("sample", "y", conn.artifactQuota/2); !errors.Is(err, ErrArtifactQuota) {
+		t.Errorf("writeArtifactWithin() beyond half the quota returned error %v, want ErrArtifactQuota", err)
+	}
+	if _, err := conn.writeArtifact("third", strings.Repeat("y", 10)); err != nil {
+		t.Errorf("writeArtifact() filling the quota returned error %v", err)
+	}
+}
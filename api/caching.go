@@ -0,0 +1,165 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// cachingField is the field of the findings of CheckCaching.
+const cachingField = "caching"
+
+// cachedResponse is a response received by CheckCaching.
+type cachedResponse struct {
+	status int
+	header http.Header
+	resp   *pb.BookingAvailabilityResponse
+}
+
+// sendWithHeaders sends reqPB to endpoint with the additional headers, and parses the response if its status is 200.
+func sendWithHeaders(reqPB *pb.BookingAvailabilityRequest, conn *HTTPConnection, endpoint string, headers map[string]string) (*cachedResponse, error) {
+	req, err := conn.marshaler.MarshalToString(reqPB)
+	if err != nil {
+		return nil, fmt.Errorf("Could not convert pb3 to json: %v, Error: %v", reqPB, err)
+	}
+	for name, value := range headers {
+		defer conn.SetHeader(name, conn.Header(name))
+		conn.SetHeader(name, value)
+	}
+	httpResp, err := openRequest(endpoint, req, conn)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP response yielded error: %w", err)
+	}
+	defer httpResp.Body.Close()
+	body, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: could not read http response body: %v", ErrTransport, err)
+	}
+	conn.logResponse(endpoint, string(body))
+	r := &cachedResponse{status: httpResp.StatusCode, header: httpResp.Header}
+	if r.status == http.StatusOK {
+		var respPB pb.BookingAvailabilityResponse
+		if _, err := parseResponse(string(body), conn, &respPB); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrParse, err)
+		}
+		r.resp = &respPB
+	}
+	return r, nil
+}
+
+// cacheable returns the headers of resp that allow a shared cache to store it, or "" if there are none. Responses
+// to POST requests are only cached when they say so explicitly.
+func cacheable(h http.Header) string {
+	cc := strings.ToLower(h.Get("Cache-Control"))
+	if strings.Contains(cc, "no-store") || strings.Contains(cc, "no-cache") || strings.Contains(cc, "private") {
+		return ""
+	}
+	for _, directive := range strings.Split(cc, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		if name == "public" {
+			return "Cache-Control: " + h.Get("Cache-Control")
+		}
+		if n, err := strconv.Atoi(value); (name == "max-age" || name == "s-maxage") && err == nil && n > 0 {
+			return "Cache-Control: " + h.Get("Cache-Control")
+		}
+	}
+	if cc == "" {
+		if expires, err := http.ParseTime(h.Get("Expires")); err == nil && expires.After(time.Now()) {
+			return "Expires: " + h.Get("Expires")
+		}
+	}
+	return ""
+}
+
+// CheckCaching verifies the availability endpoint answers every search in full, whatever the caching headers of the
+// request, as a cached answer breaks the shop flow. It sends reqPB, then sends it again under another transaction_id
+// with If-None-Match and If-Modified-Since matching the first response. A 304 Not Modified, a 412 Precondition Failed
+// or any other status than 200 to the conditional request is an Error finding, and so is a response echoing the
+// transaction_id of the other request or carrying an Age header, which was served from a cache. A response that
+// allows shared caches to store it is a Warning. An error is returned if the endpoint honored the caching headers or
+// served a cached response.
+func CheckCaching(reqPB *pb.BookingAvailabilityRequest, conn *HTTPConnection, endpoint string) ([]utils.Finding, error) {
+	var findings []utils.Finding
+	var problems []string
+	report := func(s utils.Severity, code, msg string) {
+		f := utils.Finding{Severity: s, Field: cachingField, Message: msg, Code: code}
+		log.Println(f)
+		findings = append(findings, f)
+		if s != utils.Warning {
+			problems = append(problems, msg)
+		}
+	}
+	checkCached := func(req *pb.BookingAvailabilityRequest, r *cachedResponse) {
+		if age := r.header.Get("Age"); age != "" {
+			report(utils.Error, utils.CodeCachedResponse, fmt.Sprintf("the response to transaction_id %s has an Age header of %s, it was served from a cache", req.GetTransactionId(), age))
+		}
+		if r.resp != nil && r.resp.GetTransactionId() != req.GetTransactionId() {
+			report(utils.Error, utils.CodeCachedResponse, fmt.Sprintf("the response to transaction_id %s echoes transaction_id %s, it is the cached response of another request", req.GetTransactionId(), r.resp.GetTransactionId()))
+		}
+	}
+
+	first := proto.Clone(reqPB).(*pb.BookingAvailabilityRequest)
+	first.TransactionId = reqPB.GetTransactionId() + "-cache-1"
+	r, err := sendWithHeaders(first, conn, endpoint, nil)
+	if err != nil {
+		return sendFindings(err), err
+	}
+	if r.status != http.StatusOK {
+		return nil, fmt.Errorf("%s returned %d %s, want 200 OK", endpoint, r.status, http.StatusText(r.status))
+	}
+	if h := cacheable(r.header); h != "" {
+		report(utils.Warning, utils.CodeCacheableResponse, fmt.Sprintf("the availability response allows shared caches to store it with %s; send Cache-Control: no-store", h))
+	}
+	checkCached(first, r)
+
+	// Conditions that match the first response, or any response if it has no validators.
+	etag, modified := r.header.Get("ETag"), r.header.Get("Last-Modified")
+	if etag == "" {
+		etag = "*"
+	}
+	if modified == "" {
+		modified = time.Now().UTC().Format(http.TimeFormat)
+	}
+	second := proto.Clone(reqPB).(*pb.BookingAvailabilityRequest)
+	second.TransactionId = reqPB.GetTransactionId() + "-cache-2"
+	r, err = sendWithHeaders(second, conn, endpoint, map[string]string{"If-None-Match": etag, "If-Modified-Since": modified})
+	if err != nil {
+		return append(findings, sendFindings(err)...), err
+	}
+	if r.status != http.StatusOK {
+		report(utils.Error, utils.CodeConditionalHonored, fmt.Sprintf("answered %d %s to a POST with If-None-Match: %s and If-Modified-Since: %s; availability requests must always be answered in full", r.status, http.StatusText(r.status), etag, modified))
+	} else {
+		checkCached(second, r)
+	}
+
+	if len(problems) > 0 {
+		return findings, fmt.Errorf("%s honors caching headers or serves cached responses: %s", endpoint, strings.Join(problems, "; "))
+	}
+	return findings, nil
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestCheckCaching(t *testing.T) {
+	data, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// echo answers every request with the sample response for the request's transaction_id.
+	echo := func(w http.ResponseWriter, r *http.Request, transactionID string) {
+		var req pb.BookingAvailabilityRequest
+		if err := jsonpb.Unmarshal(r.Body, &req); err != nil {
+			t.Fatal(err)
+		}
+		resp := proto.Clone(data.RespPb).(*pb.BookingAvailabilityResponse)
+		resp.TransactionId = req.GetTransactionId()
+		if transactionID != "" {
+			resp.TransactionId = transactionID
+		}
+		resp.StartDate, resp.EndDate, resp.Party, resp.HotelId = req.GetStartDate(), req.GetEndDate(), req.GetParty(), req.GetHotelId()
+		if err := (&jsonpb.Marshaler{OrigName: true}).Marshal(w, resp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		name      string
+		handler   http.HandlerFunc
+		wantErr   bool
+		wantCodes []string
+	}{
+		{
+			name: "answered in full",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("ETag", `"v1"`)
+				w.Header().Set("Cache-Control", "no-store")
+				echo(w, r, "")
+			},
+		},
+		{
+			name: "not modified",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("ETag", `"v1"`)
+				if r.Header.Get("If-None-Match") == `"v1"` {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				echo(w, r, "")
+			},
+			wantErr:   true,
+			wantCodes: []string{utils.CodeConditionalHonored},
+		},
+		{
+			name: "cached body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Cache-Control", "public, max-age=300")
+				if r.Header.Get("If-None-Match") != "" {
+					w.Header().Set("Age", "1")
+				}
+				echo(w, r, data.ReqPb.GetTransactionId()+"-cache-1")
+			},
+			wantErr:   true,
+			wantCodes: []string{utils.CodeCacheableResponse, utils.CodeCachedResponse, utils.CodeCachedResponse},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+			conn := &HTTPConnection{client: server.Client(), marshaler: &jsonpb.Marshaler{OrigName: true}, baseURL: server.URL}
+
+			findings, err := CheckCaching(data.ReqPb, conn, "/v1/BookingAvailability")
+			if (err != nil) != tc.wantErr {
+				t.Errorf("CheckCaching() returned error %v, want error %v", err, tc.wantErr)
+			}
+			var codes []string
+			for _, f := range findings {
+				codes = append(codes, f.Code)
+			}
+			if diff := cmp.Diff(codes, tc.wantCodes); diff != "" {
+				t.Errorf("CheckCaching() returned finding codes that differ (-got +want):\n%s", diff)
+			}
+			if got := conn.headers.Get("If-None-Match"); got != "" {
+				t.Errorf("CheckCaching() left the If-None-Match header %q set", got)
+			}
+		})
+	}
+}
+
+func TestCacheable(t *testing.T) {
+	cases := []struct {
+		header http.Header
+		want   bool
+	}{
+		{header: http.Header{}},
+		{header: http.Header{"Cache-Control": {"no-store"}}},
+		{header: http.Header{"Cache-Control": {"max-age=0"}}},
+		{header: http.Header{"Cache-Control": {"private, max-age=60"}}},
+		{header: http.Header{"Cache-Control": {"public"}}, want: true},
+		{header: http.Header{"Cache-Control": {"s-maxage=60"}}, want: true},
+		{header: http.Header{"Expires": {"Thu, 01 Jan 2099 00:00:00 GMT"}}, want: true},
+		{header: http.Header{"Expires": {"Thu, 01 Jan 1970 00:00:00 GMT"}}},
+	}
+	for _, tc := range cases {
+		if got := cacheable(tc.header) != ""; got != tc.want {
+			t.Errorf("cacheable(%v) = %q, want cacheable %v", tc.header, cacheable(tc.header), tc.want)
+		}
+	}
+}
+
+func TestSendWithHeadersRestoresHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("If-Modified-Since"); got != "Tue, 08 Jan 2030 15:04:05 GMT" {
+			t.Errorf("request sent If-Modified-Since %q, want the conditional one", got)
+		}
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+	conn := &HTTPConnection{client: server.Client(), marshaler: &jsonpb.Marshaler{OrigName: true}, baseURL: server.URL}
+	conn.SetHeader("If-Modified-Since", "Mon, 07 Jan 2030 15:04:05 GMT")
+
+	if _, err := sendWithHeaders(&pb.BookingAvailabilityRequest{}, conn, "/v1/BookingAvailability", map[string]string{"If-Modified-Since": "Tue, 08 Jan 2030 15:04:05 GMT"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := conn.Header("If-Modified-Since"); got != "Mon, 07 Jan 2030 15:04:05 GMT" {
+		t.Errorf("sendWithHeaders() left If-Modified-Since %q, want the previous value", got)
+	}
+}
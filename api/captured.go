@@ -0,0 +1,169 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// echoedAvailabilityRequest returns the request whose fields resp echoes. Captured responses come without their
+// request, so the echo checks of validation pass and only the structure of resp is validated.
+func echoedAvailabilityRequest(resp *pb.BookingAvailabilityResponse) *pb.BookingAvailabilityRequest {
+	return &pb.BookingAvailabilityRequest{
+		ApiVersion:    resp.GetApiVersion(),
+		TransactionId: resp.GetTransactionId(),
+		HotelId:       resp.GetHotelId(),
+		StartDate:     resp.GetStartDate(),
+		EndDate:       resp.GetEndDate(),
+		Party:         resp.GetParty(),
+	}
+}
+
+// echoedSubmitRequest returns the request whose fields resp echoes, see echoedAvailabilityRequest.
+func echoedSubmitRequest(resp *pb.BookingSubmitResponse) *pb.BookingSubmitRequest {
+	r := resp.GetReservation()
+	return &pb.BookingSubmitRequest{
+		ApiVersion:    resp.GetApiVersion(),
+		TransactionId: resp.GetTransactionId(),
+		HotelId:       r.GetHotelId(),
+		StartDate:     r.GetStartDate(),
+		EndDate:       r.GetEndDate(),
+		Customer:      r.GetCustomer(),
+		Traveler:      r.GetTraveler(),
+		RoomRate:      r.GetRoomRate(),
+	}
+}
+
+// validateCapturedAvailability validates the captured body of an availability response.
+func validateCapturedAvailability(body string, conn *HTTPConnection) ([]utils.Finding, error) {
+	var respPB pb.BookingAvailabilityResponse
+	findings, err := parseResponse(body, conn, &respPB)
+	if err != nil {
+		return findings, fmt.Errorf("%w: %v", ErrParse, err)
+	}
+	findings = append(findings, utils.LintAvailabilityError(respPB.GetError())...)
+	findings = append(findings, utils.CheckFieldLengths("", &respPB)...)
+	validation, err := utils.ValidateBookingAvailabilityResponse(echoedAvailabilityRequest(&respPB), &respPB)
+	findings = append(findings, validation...)
+	if err != nil {
+		return findings, fmt.Errorf("%w: %v", ErrValidation, err)
+	}
+	return findings, nil
+}
+
+// validateCapturedSubmit validates the captured body of a submit response. A locator returned for two
+// transaction_ids within the capture, as recorded by conn, is a Critical finding, as it is during a run.
+func validateCapturedSubmit(body string, conn *HTTPConnection) ([]utils.Finding, error) {
+	var respPB pb.BookingSubmitResponse
+	findings, err := parseResponse(body, conn, &respPB)
+	if err != nil {
+		return findings, fmt.Errorf("%w: %v", ErrParse, err)
+	}
+	findings = append(findings, utils.LintSubmitError(respPB.GetError())...)
+	findings = append(findings, utils.CheckFieldLengths("", &respPB)...)
+	validation, err := utils.ValidateBookingSubmitResponse(echoedSubmitRequest(&respPB), &respPB)
+	findings = append(findings, validation...)
+	if err != nil {
+		return findings, fmt.Errorf("%w: %v", ErrValidation, err)
+	}
+	if respPB.GetStatus() == pb.BookingSubmitResponse_SUCCESS {
+		id := respPB.GetReservation().GetLocator().GetId()
+		if prev := conn.recordLocator(respPB.GetTransactionId(), id); prev != "" {
+			f := utils.Finding{
+				Severity: utils.Critical,
+				Field:    "reservation > locator > id",
+				Message:  fmt.Sprintf("locator %s was already returned for transaction_id %s", id, prev),
+				Code:     utils.CodeLocatorReused,
+			}
+			log.Println(f)
+			return append(findings, f), fmt.Errorf("%w: reservation locator %s is not unique", ErrValidation, id)
+		}
+	}
+	return findings, nil
+}
+
+// CapturedValidator validates captured responses of one kind, see Validate. Reservation locators must be unique
+// across every input it validates, e.g. all the files of a capture.
+type CapturedValidator struct {
+	validate func(string, *HTTPConnection) ([]utils.Finding, error)
+	conn     *HTTPConnection
+}
+
+// NewCapturedValidator returns a validator of captured responses of the kind "availability" or "submit". The strict
+// and keyOrder options behave like SetStrictJSON and SetCheckKeyOrder.
+func NewCapturedValidator(kind string, strict, keyOrder bool) (*CapturedValidator, error) {
+	v := &CapturedValidator{conn: &HTTPConnection{strictJSON: strict, keyOrder: keyOrder}}
+	switch kind {
+	case "availability":
+		v.validate = validateCapturedAvailability
+	case "submit":
+		v.validate = validateCapturedSubmit
+	default:
+		return nil, fmt.Errorf("unknown type %q, expected availability or submit", kind)
+	}
+	return v, nil
+}
+
+// ValidateCapturedResponses validates the responses read from r with a new CapturedValidator, see Validate.
+func ValidateCapturedResponses(r io.Reader, kind string, tally *utils.RuleTally, strict, keyOrder bool) error {
+	v, err := NewCapturedValidator(kind, strict, keyOrder)
+	if err != nil {
+		return err
+	}
+	return v.Validate(r, tally)
+}
+
+// Validate validates the response bodies read from r, one json object per line, e.g. captured from partner access
+// logs. The responses have no request, so the fields they echo are not compared, but every structural check of a run
+// applies. Blank lines are skipped. The findings and error of every response are recorded in tally, whose per rule
+// counts are the defect rates of the capture, and an error is returned if any response failed validation.
+func (v *CapturedValidator) Validate(r io.Reader, tally *utils.RuleTally) error {
+	// Responses with many room rates easily exceed the line limit of a bufio.Scanner.
+	br := bufio.NewReader(r)
+	line, responses, failed := 0, 0, 0
+	for {
+		body, readErr := br.ReadString('\n')
+		if readErr != nil && readErr != io.EOF {
+			return fmt.Errorf("failed to read captured responses: %v", readErr)
+		}
+		line++
+		if strings.TrimSpace(body) != "" {
+			responses++
+			findings, err := v.validate(strings.TrimRight(body, "\r\n"), v.conn)
+			tally.Add(findings, err)
+			if err != nil {
+				failed++
+				log.Printf("Captured response on line %d failed: %v\n", line, err)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d captured responses failed validation", failed, responses)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+// compactLine returns body as a single line of json.
+func compactLine(t *testing.T, body string) string {
+	var b bytes.Buffer
+	if err := json.Compact(&b, []byte(body)); err != nil {
+		t.Fatal(err)
+	}
+	return b.String() + "\n"
+}
+
+func TestValidateCapturedAvailability(t *testing.T) {
+	data, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	good := compactLine(t, data.Resp)
+	// Responses are validated without a request, a different stay is not an echo mismatch.
+	otherStay := strings.Replace(good, data.RespPb.GetStartDate(), "2030-01-01", 1)
+	missing := strings.Replace(good, `"hotel_id":"`+data.RespPb.GetHotelId()+`",`, "", 1)
+	if otherStay == good || missing == good {
+		t.Fatal("test responses were not altered")
+	}
+
+	tally := &utils.RuleTally{}
+	err = ValidateCapturedResponses(strings.NewReader(good+"\n"+otherStay+missing+missing), "availability", tally, false, false)
+	if want := "2 of 4 captured responses failed validation"; err == nil || err.Error() != want {
+		t.Errorf("ValidateCapturedResponses() returned error %v, want %q", err, want)
+	}
+	if got := tally.Requests(); got != 4 {
+		t.Errorf("ValidateCapturedResponses() recorded %d responses, want 4", got)
+	}
+	want := []utils.RuleCount{{Rule: "hotel_id", Code: utils.CodeRequiredMissing, Severity: utils.Error, Count: 2}}
+	if diff := cmp.Diff(tally.Rules(), want); diff != "" {
+		t.Errorf("ValidateCapturedResponses() recorded rules that differ (-got +want):\n%s", diff)
+	}
+}
+
+func TestValidateCapturedSubmit(t *testing.T) {
+	data, err := utils.BookingSubmitData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	good := compactLine(t, data.Resp)
+	reused := strings.Replace(good, `"transaction_id":"`+data.RespPb.GetTransactionId()+`"`, `"transaction_id":"other"`, 1)
+	if reused == good {
+		t.Fatal("test response was not altered")
+	}
+
+	tally := &utils.RuleTally{}
+	err = ValidateCapturedResponses(strings.NewReader(good+good+reused), "submit", tally, false, false)
+	if want := "1 of 3 captured responses failed validation"; err == nil || err.Error() != want {
+		t.Errorf("ValidateCapturedResponses() returned error %v, want %q", err, want)
+	}
+	want := []utils.RuleCount{{Rule: "reservation > locator > id", Code: utils.CodeLocatorReused, Severity: utils.Critical, Count: 1}}
+	if diff := cmp.Diff(tally.Rules(), want); diff != "" {
+		t.Errorf("ValidateCapturedResponses() recorded rules that differ (-got +want):\n%s", diff)
+	}
+}
+
+func TestCapturedValidatorLocatorsAcrossInputs(t *testing.T) {
+	data, err := utils.BookingSubmitData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	good := compactLine(t, data.Resp)
+	reused := strings.Replace(good, `"transaction_id":"`+data.RespPb.GetTransactionId()+`"`, `"transaction_id":"other"`, 1)
+
+	v, err := NewCapturedValidator("submit", false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tally := &utils.RuleTally{}
+	if err := v.Validate(strings.NewReader(good), tally); err != nil {
+		t.Fatalf("Validate() of the first input returned error %v, want nil", err)
+	}
+	if err := v.Validate(strings.NewReader(reused), tally); err == nil {
+		t.Error("Validate() of a locator reused from the first input returned nil error, want an error")
+	}
+	want := []utils.RuleCount{{Rule: "reservation > locator > id", Code: utils.CodeLocatorReused, Severity: utils.Critical, Count: 1}}
+	if diff := cmp.Diff(tally.Rules(), want); diff != "" {
+		t.Errorf("Validate() recorded rules that differ (-got +want):\n%s", diff)
+	}
+}
+
+func TestValidateCapturedUnknownType(t *testing.T) {
+	if err := ValidateCapturedResponses(strings.NewReader("{}\n"), "cancel", &utils.RuleTally{}, false, false); err == nil {
+		t.Error("ValidateCapturedResponses() with type cancel returned no error, want an error")
+	}
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+)
+
+// CertificateExpiryWarningDays is the number of days before expiry at which the partner's leaf certificate is flagged.
+const CertificateExpiryWarningDays = 30
+
+// CertificateInfo describes a single certificate presented by the partner during the TLS handshake.
+type CertificateInfo struct {
+	Subject  string    `json:"subject"`
+	Issuer   string    `json:"issuer"`
+	SANs     []string  `json:"sans"`
+	NotAfter time.Time `json:"not_after"`
+}
+
+// CertificateReport summarizes the certificate chain presented by the partner.
+type CertificateReport struct {
+	Chain           []CertificateInfo `json:"chain"`
+	DaysUntilExpiry int               `json:"days_until_expiry"`
+	Warnings        []string          `json:"warnings,omitempty"`
+}
+
+func newCertificateReport(state *tls.ConnectionState, now time.Time) *CertificateReport {
+	r := &CertificateReport{}
+	for _, c := range state.PeerCertificates {
+		r.Chain = append(r.Chain, CertificateInfo{
+			Subject:  c.Subject.String(),
+			Issuer:   c.Issuer.String(),
+			SANs:     subjectAltNames(c),
+			NotAfter: c.NotAfter,
+		})
+	}
+	if len(state.PeerCertificates) == 0 {
+		r.Warnings = append(r.Warnings, "server presented no certificates")
+		return r
+	}
+
+	leaf := state.PeerCertificates[0]
+	r.DaysUntilExpiry = int(leaf.NotAfter.Sub(now).Hours() / 24)
+	if r.DaysUntilExpiry < CertificateExpiryWarningDays {
+		r.Warnings = append(r.Warnings, fmt.Sprintf("leaf certificate expires in %d day(s) on %s", r.DaysUntilExpiry, leaf.NotAfter.UTC().Format(time.RFC3339)))
+	}
+	if len(state.PeerCertificates) == 1 && !isSelfSigned(leaf) {
+		r.Warnings = append(r.Warnings, fmt.Sprintf("certificate chain omits intermediates, leaf is issued by %q but no issuer certificate was presented", leaf.Issuer.String()))
+	}
+	return r
+}
+
+func subjectAltNames(c *x509.Certificate) []string {
+	sans := append([]string{}, c.DNSNames...)
+	for _, ip := range c.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	sans = append(sans, c.EmailAddresses...)
+	for _, u := range c.URIs {
+		sans = append(sans, u.String())
+	}
+	return sans
+}
+
+func isSelfSigned(c *x509.Certificate) bool {
+	return bytes.Equal(c.RawIssuer, c.RawSubject) && c.CheckSignatureFrom(c) == nil
+}
+
+func logCertificateReport(r *CertificateReport) {
+	for i, c := range r.Chain {
+		log.Printf("TLS certificate [%d] Subject: %s, Issuer: %s, SANs: [%s], Expires: %s\n", i, c.Subject, c.Issuer, strings.Join(c.SANs, ", "), c.NotAfter.UTC().Format(time.RFC3339))
+	}
+	log.Printf("TLS leaf certificate expires in %d day(s)\n", r.DaysUntilExpiry)
+	for _, w := range r.Warnings {
+		log.Printf("Warning: TLS %s\n", w)
+	}
+}
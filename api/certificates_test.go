@@ -0,0 +1,76 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func newTestCertificate(t *testing.T, cn string, notAfter time.Time, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		DNSNames:              []string{cn},
+		NotBefore:             notAfter.AddDate(-1, 0, 0),
+		NotAfter:              notAfter,
+		IsCA:                  parent == nil,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	if parent == nil {
+		parent, parentKey = tmpl, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestCertificateReport(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	ca, caKey := newTestCertificate(t, "ca.example.com", now.AddDate(5, 0, 0), nil, nil)
+	leaf, _ := newTestCertificate(t, "partner.example.com", now.AddDate(0, 0, 90), ca, caKey)
+	expiring, _ := newTestCertificate(t, "partner.example.com", now.AddDate(0, 0, 10), ca, caKey)
+
+	cases := []struct {
+		name         string
+		chain        []*x509.Certificate
+		wantDays     int
+		wantWarnings int
+	}{
+		{name: "complete chain", chain: []*x509.Certificate{leaf, ca}, wantDays: 90, wantWarnings: 0},
+		{name: "self-signed leaf", chain: []*x509.Certificate{ca}, wantDays: 1827, wantWarnings: 0},
+		{name: "missing intermediate", chain: []*x509.Certificate{leaf}, wantDays: 90, wantWarnings: 1},
+		{name: "expiring soon", chain: []*x509.Certificate{expiring, ca}, wantDays: 10, wantWarnings: 1},
+		{name: "expiring and missing intermediate", chain: []*x509.Certificate{expiring}, wantDays: 10, wantWarnings: 2},
+	}
+	for _, tc := range cases {
+		got := newCertificateReport(&tls.ConnectionState{PeerCertificates: tc.chain}, now)
+		if got.DaysUntilExpiry != tc.wantDays {
+			t.Errorf("%s: DaysUntilExpiry got [%d] want [%d]", tc.name, got.DaysUntilExpiry, tc.wantDays)
+		}
+		if len(got.Warnings) != tc.wantWarnings {
+			t.Errorf("%s: got warnings %v, want %d warning(s)", tc.name, got.Warnings, tc.wantWarnings)
+		}
+		if diff := cmp.Diff(got.Chain[0].SANs, tc.chain[0].DNSNames); diff != "" {
+			t.Errorf("%s: SANs did not match (-got +want)\n%s", tc.name, diff)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// CompressionMinBytes is the decompressed size above which responses are expected to be compressed.
+const CompressionMinBytes = 10 << 10
+
+// EndpointCompression summarizes the compression of the responses of one endpoint. Bytes and WireBytes are the
+// decompressed and transferred sizes of the gzip encoded responses, whose ratio is Ratio.
+type EndpointCompression struct {
+	Endpoint string `json:"endpoint"`
+	// Responses is the number of responses read, Compressed the number of those that were gzip encoded and Large the
+	// number of uncompressed responses of more than CompressionMinBytes.
+	Responses  int     `json:"responses"`
+	Compressed int     `json:"compressed"`
+	Large      int     `json:"large_uncompressed"`
+	Bytes      int64   `json:"bytes"`
+	WireBytes  int64   `json:"wire_bytes"`
+	Ratio      float64 `json:"ratio,omitempty"`
+}
+
+// CompressionReport summarizes the compression of the responses of every endpoint. Every request offers gzip, so
+// large uncompressed responses are reported as warnings.
+type CompressionReport struct {
+	Endpoints []EndpointCompression `json:"endpoints"`
+	Warnings  []string              `json:"warnings,omitempty"`
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// compressedBody decodes a gzip encoded response body and records the sizes of the response when it is closed.
+type compressedBody struct {
+	io.ReadCloser
+	wire     *countingReader
+	gz       *gzip.Reader
+	n        int64
+	endpoint string
+	conn     *HTTPConnection
+}
+
+func (b *compressedBody) Read(p []byte) (int, error) {
+	if b.wire == nil {
+		return b.count(b.ReadCloser.Read(p))
+	}
+	if b.gz == nil {
+		gz, err := gzip.NewReader(b.wire)
+		if err != nil {
+			return 0, err
+		}
+		b.gz = gz
+	}
+	return b.count(b.gz.Read(p))
+}
+
+func (b *compressedBody) count(n int, err error) (int, error) {
+	b.n += int64(n)
+	return n, err
+}
+
+func (b *compressedBody) Close() error {
+	var wire int64 = -1
+	if b.wire != nil {
+		wire = b.wire.n
+	}
+	b.conn.recordCompression(b.endpoint, b.n, wire)
+	return b.ReadCloser.Close()
+}
+
+// offerCompression offers gzip in httpReq, unless an Accept-Encoding header was configured. Setting the header
+// disables the transparent decompression of net/http, so the transferred size of responses can be measured.
+func offerCompression(httpReq *http.Request) bool {
+	if httpReq.Header.Get("Accept-Encoding") != "" {
+		return false
+	}
+	httpReq.Header.Set("Accept-Encoding", "gzip")
+	return true
+}
+
+// decompressResponse replaces the body of httpResp, sent to a request through offerCompression, with one measuring
+// and, if it is gzip encoded, decoding it.
+func (h *HTTPConnection) decompressResponse(endpoint string, httpResp *http.Response) {
+	b := &compressedBody{ReadCloser: httpResp.Body, endpoint: endpoint, conn: h}
+	if strings.EqualFold(httpResp.Header.Get("Content-Encoding"), "gzip") {
+		b.wire = &countingReader{r: httpResp.Body}
+		httpResp.Header.Del("Content-Encoding")
+		httpResp.Header.Del("Content-Length")
+		httpResp.ContentLength = -1
+		httpResp.Uncompressed = true
+	}
+	httpResp.Body = b
+}
+
+// recordCompression records a response of n decompressed bytes to endpoint, of which wire bytes were transferred, or
+// -1 if the response was not compressed.
+func (h *HTTPConnection) recordCompression(endpoint string, n, wire int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.compression == nil {
+		h.compression = map[string]*EndpointCompression{}
+	}
+	c, ok := h.compression[endpoint]
+	if !ok {
+		c = &EndpointCompression{Endpoint: endpoint}
+		h.compression[endpoint] = c
+	}
+	c.Responses++
+	switch {
+	case wire >= 0:
+		c.Compressed++
+		c.Bytes += n
+		c.WireBytes += wire
+	case n > CompressionMinBytes:
+		c.Large++
+	}
+}
+
+// CompressionReport returns the compression of the responses read over this connection, or nil when no response
+// was read.
+func (h *HTTPConnection) CompressionReport() *CompressionReport {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.compression) == 0 {
+		return nil
+	}
+	r := &CompressionReport{}
+	for _, c := range h.compression {
+		e := *c
+		if e.WireBytes > 0 {
+			e.Ratio = float64(e.Bytes) / float64(e.WireBytes)
+		}
+		r.Endpoints = append(r.Endpoints, e)
+	}
+	sort.Slice(r.Endpoints, func(i, j int) bool { return r.Endpoints[i].Endpoint < r.Endpoints[j].Endpoint })
+	for _, e := range r.Endpoints {
+		if e.Large > 0 {
+			r.Warnings = append(r.Warnings, fmt.Sprintf("%d response(s) of %s over %d KiB were not compressed although the request offered gzip", e.Large, e.Endpoint, CompressionMinBytes>>10))
+		}
+	}
+	return r
+}
+
+// LogCompressionReport logs the compression ratio of every endpoint and the warnings of r.
+func LogCompressionReport(r *CompressionReport) {
+	if r == nil {
+		return
+	}
+	for _, e := range r.Endpoints {
+		if e.Compressed > 0 {
+			log.Printf("Compression of %s: %d of %d response(s) gzip encoded, %d bytes sent as %d, ratio %.1f\n", e.Endpoint, e.Compressed, e.Responses, e.Bytes, e.WireBytes, e.Ratio)
+		} else {
+			log.Printf("Compression of %s: none of %d response(s) compressed\n", e.Endpoint, e.Responses)
+		}
+	}
+	for _, w := range r.Warnings {
+		log.Printf("Warning: compression %s\n", w)
+	}
+}
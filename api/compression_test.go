@@ -0,0 +1,62 @@
+package api
+
+import (
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCompressionReport(t *testing.T) {
+	large := `{"padding":"` + strings.Repeat("a", CompressionMinBytes) + `"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Accept-Encoding"); got != "gzip" {
+			t.Errorf("Accept-Encoding = %q, want gzip", got)
+		}
+		switch r.URL.Path {
+		case "/gzip":
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			gz.Write([]byte(large))
+			gz.Close()
+		case "/plain":
+			w.Write([]byte(large))
+		default:
+			w.Write([]byte("{}"))
+		}
+	}))
+	defer server.Close()
+	conn, err := InitHTTPConnection(strings.TrimPrefix(server.URL, "http://"), "", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if conn.CompressionReport() != nil {
+		t.Error("CompressionReport() before any response is not nil")
+	}
+	for _, endpoint := range []string{"/gzip", "/gzip", "/plain", "/small"} {
+		body, err := sendRequest(endpoint, "{}", conn)
+		if err != nil {
+			t.Fatalf("sendRequest(%s) failed: %v", endpoint, err)
+		}
+		if endpoint != "/small" && body != large {
+			t.Errorf("sendRequest(%s) returned %d bytes, want the %d decompressed bytes", endpoint, len(body), len(large))
+		}
+	}
+
+	got := conn.CompressionReport()
+	if len(got.Endpoints) != 3 || got.Endpoints[0].Ratio < 10 {
+		t.Fatalf("CompressionReport() = %+v, want three endpoints with a gzip ratio over 10", got)
+	}
+	gzipped := got.Endpoints[0]
+	if gzipped.Endpoint != "/gzip" || gzipped.Responses != 2 || gzipped.Compressed != 2 || gzipped.Bytes != int64(2*len(large)) {
+		t.Errorf("CompressionReport() of /gzip = %+v, want two compressed responses of %d bytes", gzipped, len(large))
+	}
+	want := []string{"1 response(s) of /plain over 10 KiB were not compressed although the request offered gzip"}
+	if diff := cmp.Diff(got.Warnings, want); diff != "" {
+		t.Errorf("CompressionReport() warnings did not match (-got +want)\n%s", diff)
+	}
+}
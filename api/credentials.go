@@ -0,0 +1,141 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// invalidCredentials are the username:password sent by CheckCredentials, which no partner should accept.
+const invalidCredentials = "hotel-booking-api-validator:invalid-credentials"
+
+// withCredentials runs f with credentials in place of those of the connection.
+func (h *HTTPConnection) withCredentials(credentials string, f func()) {
+	saved := h.credentials
+	h.credentials = credentials
+	defer func() { h.credentials = saved }()
+	f()
+}
+
+// CheckCredentials verifies the partner is ready for a rotation of its credentials: reqPB must be answered when sent
+// with the credentials of the connection and, if secondaryFile is set, with those of secondaryFile too, and rejected
+// with 401 Unauthorized when sent with invalid credentials. Valid credentials that are rejected are Error findings.
+// Invalid credentials answered with a valid response are a Critical finding, answered with another status, e.g. 200
+// with an error body, an Error finding, and rejected with 403 Forbidden a Warning finding. Invalid credentials are
+// not probed if the connection has no credentials.
+func CheckCredentials(reqPB *pb.BookingAvailabilityRequest, conn *HTTPConnection, endpoint, secondaryFile string) ([]utils.Finding, error) {
+	var findings []utils.Finding
+	report := func(s utils.Severity, field, code, msg string) {
+		f := utils.Finding{Severity: s, Field: field, Message: msg, Code: code}
+		log.Println(f)
+		findings = append(findings, f)
+	}
+
+	valid := []struct{ name, credentials string }{{"primary", conn.credentials}}
+	if secondaryFile != "" {
+		secondary, err := setupCredentials(secondaryFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read secondary credentials: %v", err)
+		}
+		valid = append(valid, struct{ name, credentials string }{"secondary", secondary})
+	}
+	for _, v := range valid {
+		var err error
+		conn.withCredentials(v.credentials, func() { _, err = SendBookingAvailability(reqPB, conn, endpoint) })
+		switch {
+		case errors.Is(err, ErrAuth):
+			report(utils.Error, "credentials > "+v.name, utils.CodeCredentialsRejected, fmt.Sprintf("valid credentials were rejected: %v", err))
+		case err != nil:
+			return findings, fmt.Errorf("request with the %s credentials failed: %w", v.name, err)
+		default:
+			log.Printf("Request with the %s credentials succeeded", v.name)
+		}
+	}
+
+	if conn.credentials == "" {
+		log.Printf("No credentials configured, invalid credentials not probed")
+	} else if err := probeInvalidCredentials(reqPB, conn, endpoint, report); err != nil {
+		return findings, err
+	}
+
+	var failed []string
+	for _, f := range findings {
+		if f.Severity != utils.Warning {
+			failed = append(failed, f.Field)
+		}
+	}
+	if len(failed) > 0 {
+		return findings, fmt.Errorf("credentials check failed: %s", strings.Join(failed, ", "))
+	}
+	return findings, nil
+}
+
+// probeInvalidCredentials sends reqPB with invalidCredentials and reports how the partner answered.
+func probeInvalidCredentials(reqPB *pb.BookingAvailabilityRequest, conn *HTTPConnection, endpoint string, report func(s utils.Severity, field, code, msg string)) error {
+	if err := conn.checkPlaintextCredentials(); err != nil {
+		return err
+	}
+	req, err := conn.marshaler.MarshalToString(reqPB)
+	if err != nil {
+		return fmt.Errorf("Could not convert pb3 to json: %v, Error: %v", reqPB, err)
+	}
+	var httpReq *http.Request
+	conn.withCredentials("Basic "+base64.StdEncoding.EncodeToString([]byte(invalidCredentials)), func() {
+		httpReq, err = conn.newRequest(endpoint, req)
+	})
+	if err != nil {
+		return err
+	}
+	logHTTPRequest(endpoint, httpReq)
+	resp, err := conn.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("%w: %s yielded error: %v", ErrTransport, endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	const field = "credentials > invalid"
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		log.Printf("Invalid credentials rejected with %s as expected", resp.Status)
+	case resp.StatusCode == http.StatusForbidden:
+		report(utils.Warning, field, utils.CodeCredentialsForbidden, fmt.Sprintf("invalid credentials were rejected with %s, want 401 Unauthorized", resp.Status))
+	case resp.StatusCode == http.StatusOK:
+		var respPB pb.BookingAvailabilityResponse
+		body, err := ioutil.ReadAll(conn.unwrapResponse(endpoint, resp.Body))
+		if err != nil && !errors.Is(err, ErrParse) {
+			return fmt.Errorf("%w: could not read http response body: %v", ErrTransport, err)
+		}
+		if _, err := parseResponse(string(body), conn, &respPB); err == nil && respPB.GetError() == nil {
+			report(utils.Critical, field, utils.CodeCredentialsAccepted, "invalid credentials were accepted and answered with an availability response")
+			break
+		}
+		report(utils.Error, field, utils.CodeCredentialsStatus, fmt.Sprintf("invalid credentials were answered with %s and an error body, want 401 Unauthorized", resp.Status))
+	default:
+		report(utils.Error, field, utils.CodeCredentialsStatus, fmt.Sprintf("invalid credentials were answered with %s, want 401 Unauthorized", resp.Status))
+	}
+	return nil
+}
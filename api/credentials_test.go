@@ -0,0 +1,93 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+func TestCheckCredentials(t *testing.T) {
+	data, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const secondary = "/path/to/secondary"
+	reader = FakeFileReader{secondary: []byte("partner:next")}.ReadFile
+	cases := []struct {
+		name      string
+		invalid   func(w http.ResponseWriter)
+		accept    string
+		wantErr   bool
+		wantCodes []string
+	}{
+		{
+			name:    "rotation ready",
+			invalid: func(w http.ResponseWriter) { http.Error(w, "unauthorized", http.StatusUnauthorized) },
+			accept:  "partner:next",
+		},
+		{
+			name:      "secondary rejected",
+			invalid:   func(w http.ResponseWriter) { http.Error(w, "unauthorized", http.StatusUnauthorized) },
+			wantErr:   true,
+			wantCodes: []string{utils.CodeCredentialsRejected},
+		},
+		{
+			name:      "forbidden",
+			invalid:   func(w http.ResponseWriter) { http.Error(w, "forbidden", http.StatusForbidden) },
+			accept:    "partner:next",
+			wantCodes: []string{utils.CodeCredentialsForbidden},
+		},
+		{
+			name:      "error body",
+			invalid:   func(w http.ResponseWriter) { w.Write([]byte(`{"error": {"type": "UNKNOWN_ERROR"}}`)) },
+			accept:    "partner:next",
+			wantErr:   true,
+			wantCodes: []string{utils.CodeCredentialsStatus},
+		},
+		{
+			name:      "accepted",
+			invalid:   func(w http.ResponseWriter) { w.Write([]byte(data.Resp)) },
+			accept:    "partner:next",
+			wantErr:   true,
+			wantCodes: []string{utils.CodeCredentialsAccepted},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				user, password, _ := r.BasicAuth()
+				switch user + ":" + password {
+				case "partner:current", tc.accept:
+					w.Write([]byte(data.Resp))
+				case invalidCredentials:
+					tc.invalid(w)
+				default:
+					http.Error(w, "unauthorized", http.StatusUnauthorized)
+				}
+			}))
+			defer server.Close()
+			conn := &HTTPConnection{client: server.Client(), marshaler: &jsonpb.Marshaler{OrigName: true}, baseURL: server.URL}
+			conn.credentials = "Basic cGFydG5lcjpjdXJyZW50" // partner:current
+
+			findings, err := CheckCredentials(data.ReqPb, conn, "/v1/BookingAvailability", secondary)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("CheckCredentials() returned error %v, want error %v", err, tc.wantErr)
+			}
+			var codes []string
+			for _, f := range findings {
+				codes = append(codes, f.Code)
+			}
+			if diff := cmp.Diff(codes, tc.wantCodes); diff != "" {
+				t.Errorf("CheckCredentials() returned finding codes that differ (-got +want):\n%s", diff)
+			}
+			if conn.credentials != "Basic cGFydG5lcjpjdXJyZW50" {
+				t.Errorf("CheckCredentials() left credentials %q, want them restored", conn.credentials)
+			}
+		})
+	}
+}
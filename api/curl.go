@@ -0,0 +1,92 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// shellQuote quotes s as a single word for POSIX shells.
+func shellQuote(s string) string {
+	return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+}
+
+// CurlCommand returns a curl command sending reqPB to endpoint as the validator would over conn: the same URL,
+// headers and json body, wrapped in the envelope of SetXMLBridge if set, with the same timeout and proxy. caFile is
+// the root certificates file conn was set up with, if any, trusted with --ca-native in addition to the system roots
+// if conn trusts those as well. A server name set with the connection is mapped to the server address with
+// --connect-to, and a Host header differing from the URL is sent explicitly. The command contains the credentials
+// of conn and of its proxy.
+func CurlCommand(reqPB proto.Message, conn *HTTPConnection, endpoint, caFile string) (string, error) {
+	body, err := conn.marshaler.MarshalToString(reqPB)
+	if err != nil {
+		return "", fmt.Errorf("Could not convert pb3 to json: %v, Error: %v", reqPB, err)
+	}
+	httpReq, err := conn.newRequest(endpoint, body)
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{"curl", "--request", httpReq.Method}
+	if conn.client.Timeout > 0 {
+		args = append(args, "--max-time", fmt.Sprint(conn.client.Timeout.Seconds()))
+	}
+	if caFile != "" {
+		if conn.systemRoots {
+			// --cacert replaces the default roots of curl, --ca-native adds those of the system back.
+			args = append(args, "--ca-native")
+		}
+		args = append(args, "--cacert", shellQuote(caFile))
+	}
+	if conn.proxy != nil {
+		args = append(args, "--proxy", shellQuote(conn.proxy.String()))
+	}
+	u := *httpReq.URL
+	host := httpReq.Host
+	if conn.config != nil && conn.config.ServerName != "" && conn.config.ServerName != u.Hostname() {
+		// Go verifies the certificate against the server name but connects to, and sends the Host of, the
+		// server address; curl takes the name from the URL.
+		port := u.Port()
+		if port == "" {
+			port = "443"
+		}
+		args = append(args, "--connect-to", shellQuote(fmt.Sprintf("%s:%s:%s", conn.config.ServerName, port, net.JoinHostPort(u.Hostname(), port))))
+		httpReq.Header.Set("Host", host)
+		u.Host = net.JoinHostPort(conn.config.ServerName, port)
+	} else if host != u.Host {
+		httpReq.Header.Set("Host", host)
+	}
+	names := make([]string, 0, len(httpReq.Header))
+	for name := range httpReq.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range httpReq.Header[name] {
+			if value != "" {
+				args = append(args, "--header", shellQuote(name+": "+value))
+			}
+		}
+	}
+	args = append(args, "--data-binary", shellQuote(conn.wrapRequest(body)), shellQuote(u.String()))
+	return strings.Join(args, " "), nil
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/net/proxy"
+)
+
+// serverAddress returns the host and port requests are sent to.
+func (h *HTTPConnection) serverAddress() (string, string, error) {
+	u, err := url.Parse(h.baseURL)
+	if err != nil {
+		return "", "", err
+	}
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return u.Hostname(), port, nil
+}
+
+// ResolveServer returns the IP addresses of the server, from its A and AAAA records.
+func (h *HTTPConnection) ResolveServer(ctx context.Context) ([]string, error) {
+	host, _, err := h.serverAddress()
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %v", host, err)
+	}
+	ips := make([]string, len(addrs))
+	for i, a := range addrs {
+		ips[i] = a.String()
+	}
+	return ips, nil
+}
+
+// socksDialer returns a DialContext function connecting through the SOCKS5 proxy at u to the address the server
+// name resolves to, rather than sending the name for the proxy to resolve.
+func socksDialer(u *url.URL) (func(ctx context.Context, network, addr string) (net.Conn, error), error) {
+	d, err := proxy.FromURL(u, proxy.Direct)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy url: %v", err)
+	}
+	socks := d.(proxy.ContextDialer)
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		return socks.DialContext(ctx, network, net.JoinHostPort(ips[0].String(), port))
+	}, nil
+}
+
+// SetDialAddress connects to the server at ip, one of its addresses, rather than whichever address the resolver
+// returns, e.g. to test each backend behind a load balancer. Requests keep the server name in their Host header and
+// TLS handshake. Open connections are closed so the next request dials ip. An empty ip removes the override. It is
+// not supported with a proxy, which dials the server itself.
+func (h *HTTPConnection) SetDialAddress(ip string) error {
+	transport, ok := h.client.Transport.(*http.Transport)
+	if !ok {
+		return errors.New("connection does not support dial overrides")
+	}
+	if h.proxy != nil {
+		return errors.New("dial overrides are not supported through a proxy")
+	}
+	transport.CloseIdleConnections()
+	if ip == "" {
+		transport.DialContext = nil
+		return nil
+	}
+	if net.ParseIP(ip) == nil {
+		return fmt.Errorf("invalid IP address %q", ip)
+	}
+	host, port, err := h.serverAddress()
+	if err != nil {
+		return err
+	}
+	server := net.JoinHostPort(host, port)
+	target := net.JoinHostPort(ip, port)
+	var d net.Dialer
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if addr == server {
+			addr = target
+		}
+		return d.DialContext(ctx, network, addr)
+	}
+	return nil
+}
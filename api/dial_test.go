@@ -0,0 +1,152 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+)
+
+func TestSetDialAddress(t *testing.T) {
+	var host string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host = r.Host
+	}))
+	defer server.Close()
+	_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn := &HTTPConnection{
+		client:    &http.Client{Transport: &http.Transport{}},
+		marshaler: &jsonpb.Marshaler{OrigName: true},
+		baseURL:   "http://partner.invalid:" + port,
+	}
+	if err := conn.SetDialAddress("127.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sendRequest("/v1/BookingAvailability", `{}`, conn); err != nil {
+		t.Fatalf("sendRequest() with a dial address returned error %v", err)
+	}
+	if want := "partner.invalid:" + port; host != want {
+		t.Errorf("sendRequest() with a dial address sent Host %s, want %s", host, want)
+	}
+
+	if err := conn.SetDialAddress(""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sendRequest("/v1/BookingAvailability", `{}`, conn); err == nil {
+		t.Error("sendRequest() without a dial address reached partner.invalid, want error")
+	}
+	if err := conn.SetDialAddress("not-an-ip"); err == nil {
+		t.Error("SetDialAddress(not-an-ip) returned nil, want error")
+	}
+	if err := conn.SetProxy("http://proxy:3128", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.SetDialAddress("127.0.0.1"); err == nil {
+		t.Error("SetDialAddress() through a proxy returned nil, want error")
+	}
+}
+
+func TestResolveServer(t *testing.T) {
+	conn := &HTTPConnection{baseURL: "https://127.0.0.1:8443"}
+	ips, err := conn.ResolveServer(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ips) != 1 || ips[0] != "127.0.0.1" {
+		t.Errorf("ResolveServer() = %v, want [127.0.0.1]", ips)
+	}
+}
+
+// socksServer serves the no authentication SOCKS5 CONNECT command, forwarding each connection to target, and
+// sends the address type of every request, 1 for IPv4 and 3 for a domain name, on the returned channel.
+func socksServer(t *testing.T, target string) (net.Listener, <-chan byte) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	types := make(chan byte, 10)
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				b := make([]byte, 262)
+				// Greeting: version, number of methods, methods.
+				if _, err := io.ReadFull(c, b[:2]); err != nil {
+					return
+				}
+				if _, err := io.ReadFull(c, b[:b[1]]); err != nil {
+					return
+				}
+				c.Write([]byte{5, 0})
+				// Request: version, command, reserved, address type, address, port.
+				if _, err := io.ReadFull(c, b[:4]); err != nil {
+					return
+				}
+				types <- b[3]
+				n := 4
+				if b[3] == 3 {
+					if _, err := io.ReadFull(c, b[:1]); err != nil {
+						return
+					}
+					n = int(b[0])
+				}
+				if _, err := io.ReadFull(c, b[:n+2]); err != nil {
+					return
+				}
+				backend, err := net.Dial("tcp", target)
+				if err != nil {
+					return
+				}
+				defer backend.Close()
+				c.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+				go io.Copy(backend, c)
+				io.Copy(c, backend)
+			}()
+		}
+	}()
+	return l, types
+}
+
+func TestSetProxySOCKS5Resolution(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	_, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, types := socksServer(t, server.Listener.Addr().String())
+	defer l.Close()
+
+	for _, tc := range []struct {
+		scheme   string
+		wantType byte
+	}{
+		{"socks5", 1},
+		{"socks5h", 3},
+	} {
+		conn, err := InitHTTPConnection("localhost:"+port, "", "", "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := conn.SetProxy(tc.scheme+"://"+l.Addr().String(), ""); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := sendRequest("/", "{}", conn); err != nil {
+			t.Fatalf("sendRequest() through %s returned error %v", tc.scheme, err)
+		}
+		if got := <-types; got != tc.wantType {
+			t.Errorf("sendRequest() through %s sent the proxy address type %d, want %d", tc.scheme, got, tc.wantType)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// endpointVariable matches a template variable of an endpoint, such as {hotel_id} or {party.adults}.
+var endpointVariable = regexp.MustCompile(`\{([a-z0-9_.]+)\}`)
+
+// expandEndpoint resolves the template variables of endpoint, e.g. /v1/hotels/{hotel_id}/availability, from the
+// fields of the json encoded request body. Variables name fields by their proto name, with dots separating nested
+// messages, and must refer to a string or number. Values are escaped as a single path segment.
+func expandEndpoint(endpoint, body string) (string, error) {
+	if !strings.Contains(endpoint, "{") {
+		return endpoint, nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &fields); err != nil {
+		return "", fmt.Errorf("endpoint %s: unable to read template variables from request: %v", endpoint, err)
+	}
+	var err error
+	expanded := endpointVariable.ReplaceAllStringFunc(endpoint, func(v string) string {
+		name := strings.Trim(v, "{}")
+		value, ok := lookupField(fields, strings.Split(name, "."))
+		if !ok && err == nil {
+			err = fmt.Errorf("endpoint %s: request has no string or number field %s", endpoint, name)
+		}
+		return url.PathEscape(value)
+	})
+	if err != nil {
+		return "", err
+	}
+	if strings.ContainsAny(expanded, "{}") {
+		return "", fmt.Errorf("endpoint %s: invalid template variable, want {field} or {message.field}", endpoint)
+	}
+	return expanded, nil
+}
+
+// lookupField returns the string or number at path in fields, a decoded json object.
+func lookupField(fields map[string]interface{}, path []string) (string, bool) {
+	switch v := fields[path[0]].(type) {
+	case map[string]interface{}:
+		if len(path) > 1 {
+			return lookupField(v, path[1:])
+		}
+	case string:
+		return v, len(path) == 1
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), len(path) == 1
+	}
+	return "", false
+}
+
+// SetQueryParameters appends params to the URL of every request to endpoint, e.g. a partner id or channel that a
+// gateway routes on. Parameters set for the endpoint "" are sent to all endpoints, unless the endpoint has its own
+// value for the same name. Empty params remove those of endpoint.
+func (h *HTTPConnection) SetQueryParameters(endpoint string, params url.Values) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(params) == 0 {
+		delete(h.queries, endpoint)
+		return
+	}
+	if h.queries == nil {
+		h.queries = map[string]url.Values{}
+	}
+	h.queries[endpoint] = params
+}
+
+// withQuery returns path, the expanded endpoint, with the query parameters set for endpoint appended, encoded and
+// sorted by name. A query already in endpoint is kept as is.
+func (h *HTTPConnection) withQuery(endpoint, path string) string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	params := url.Values{}
+	for name, values := range h.queries[""] {
+		params[name] = values
+	}
+	for name, values := range h.queries[endpoint] {
+		params[name] = values
+	}
+	if len(params) == 0 {
+		return path
+	}
+	switch {
+	case strings.HasSuffix(path, "?") || strings.HasSuffix(path, "&"):
+		return path + params.Encode()
+	case strings.Contains(path, "?"):
+		return path + "&" + params.Encode()
+	}
+	return path + "?" + params.Encode()
+}
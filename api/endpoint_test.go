@@ -0,0 +1,83 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+)
+
+func TestExpandEndpoint(t *testing.T) {
+	body := `{"api_version": 1, "hotel_id": "h 1/2", "party": {"adults": 2}, "chain_id": 1234567}`
+	cases := []struct {
+		endpoint string
+		want     string
+		wantErr  bool
+	}{
+		{endpoint: "/v1/BookingAvailability", want: "/v1/BookingAvailability"},
+		{endpoint: "/v1/hotels/{hotel_id}/availability", want: "/v1/hotels/h%201%2F2/availability"},
+		{endpoint: "/v{api_version}/hotels/{hotel_id}/rooms/{party.adults}", want: "/v1/hotels/h%201%2F2/rooms/2"},
+		{endpoint: "/v1/chains/{chain_id}", want: "/v1/chains/1234567"},
+		{endpoint: "/v1/hotels/{room_rate.code}", wantErr: true},
+		{endpoint: "/v1/hotels/{party}", wantErr: true},
+		{endpoint: "/v1/hotels/{Hotel-ID}", wantErr: true},
+	}
+	for _, tc := range cases {
+		got, err := expandEndpoint(tc.endpoint, body)
+		if (err != nil) != tc.wantErr || got != tc.want {
+			t.Errorf("expandEndpoint(%q) = %q, %v, want %q and error %v", tc.endpoint, got, err, tc.want, tc.wantErr)
+		}
+	}
+}
+
+func TestSendRequestEndpointTemplate(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.EscapedPath()
+	}))
+	defer server.Close()
+	conn := &HTTPConnection{client: server.Client(), marshaler: &jsonpb.Marshaler{OrigName: true}, baseURL: server.URL}
+	if _, err := sendRequest("/v1/hotels/{hotel_id}/availability", `{"hotel_id": "123"}`, conn); err != nil {
+		t.Fatal(err)
+	}
+	if got != "/v1/hotels/123/availability" {
+		t.Errorf("sendRequest() requested %s, want /v1/hotels/123/availability", got)
+	}
+}
+
+func TestSendRequestQueryParameters(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.RequestURI()
+	}))
+	defer server.Close()
+	conn := &HTTPConnection{client: server.Client(), marshaler: &jsonpb.Marshaler{OrigName: true}, baseURL: server.URL}
+	conn.SetQueryParameters("", url.Values{"channel": {"mobile"}, "partner_id": {"p&1"}})
+	conn.SetQueryParameters("/v1/BookingSubmit", url.Values{"partner_id": {"p 2"}, "debug": {"1"}})
+	cases := []struct {
+		endpoint string
+		want     string
+	}{
+		{endpoint: "/v1/BookingAvailability", want: "/v1/BookingAvailability?channel=mobile&partner_id=p%261"},
+		{endpoint: "/v1/BookingSubmit", want: "/v1/BookingSubmit?channel=mobile&debug=1&partner_id=p+2"},
+		{endpoint: "/v1/BookingAvailability?key=k", want: "/v1/BookingAvailability?key=k&channel=mobile&partner_id=p%261"},
+	}
+	for _, tc := range cases {
+		if _, err := sendRequest(tc.endpoint, `{}`, conn); err != nil {
+			t.Fatal(err)
+		}
+		if got != tc.want {
+			t.Errorf("sendRequest(%s) requested %s, want %s", tc.endpoint, got, tc.want)
+		}
+	}
+	conn.SetQueryParameters("", nil)
+	conn.SetQueryParameters("/v1/BookingSubmit", nil)
+	if _, err := sendRequest("/v1/BookingSubmit", `{}`, conn); err != nil {
+		t.Fatal(err)
+	}
+	if got != "/v1/BookingSubmit" {
+		t.Errorf("sendRequest() requested %s after removing the parameters, want /v1/BookingSubmit", got)
+	}
+}
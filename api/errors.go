@@ -0,0 +1,44 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import "errors"
+
+// Errors returned by BookingAvailability, BookingSubmit and their variants wrap one of these, so callers can classify
+// a failure with errors.Is.
+var (
+	// ErrTransport means the request could not be sent or the response could not be read.
+	ErrTransport = errors.New("transport error")
+	// ErrTimeout means the request timed out, or the server answered with 504 Gateway Timeout. The error is a
+	// *TimeoutError telling which.
+	ErrTimeout = errors.New("timeout")
+	// ErrAuth means the server rejected the request's credentials with 401 Unauthorized or 403 Forbidden.
+	ErrAuth = errors.New("authentication error")
+	// ErrThrottled means the server shed the request with 429 Too Many Requests or 503 Service Unavailable.
+	ErrThrottled = errors.New("throttled")
+	// ErrStatus means the server answered with a status other than the one set by SetExpectedStatus.
+	ErrStatus = errors.New("unexpected HTTP status")
+	// ErrInsecure means the request was not sent because it would have exposed credentials over plaintext http.
+	ErrInsecure = errors.New("insecure connection")
+	// ErrRedirect means the server redirected a request with credentials to another host or to plaintext http, and
+	// the redirect was not followed.
+	ErrRedirect = errors.New("redirect not followed")
+	// ErrParse means the response body was not a valid json encoded message.
+	ErrParse = errors.New("Could not parse HTTP response to pb3")
+	// ErrValidation means the response was parsed but did not pass validation.
+	ErrValidation = errors.New("Validation error")
+)
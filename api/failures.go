@@ -0,0 +1,72 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+// ArtifactsField is the field of the Warning finding referencing the payloads saved by SetSaveFailures. It does not
+// describe an issue of the response and is not scored.
+const ArtifactsField = "artifacts"
+
+// SetSaveFailures saves the request and response of every flow whose response fails to parse or validate as
+// artifacts, in the per-run directory under the artifactDir set by SetResponseLogLimit, and references their paths
+// in a Warning finding so the failure can be reproduced. Both payloads are anonymized with the same
+// utils.Anonymizer, keeping echoed fields consistent; a response that could not be parsed is anonymized as text,
+// see utils.Anonymizer.AnonymizeText.
+func (h *HTTPConnection) SetSaveFailures(save bool) {
+	h.saveFailures = save
+}
+
+// SavedPayloads returns the path of each payload referenced by the ArtifactsField finding in findings, keyed by
+// kind, e.g. request.
+func SavedPayloads(findings []utils.Finding) map[string]string {
+	paths := map[string]string{}
+	for _, f := range findings {
+		if f.Field != ArtifactsField {
+			continue
+		}
+		for _, saved := range strings.Split(f.Message, ", ") {
+			if parts := strings.SplitN(saved, " saved to ", 2); len(parts) == 2 {
+				paths[parts[0]] = parts[1]
+			}
+		}
+	}
+	return paths
+}
+
+// saveFailure returns findings and err, adding a finding referencing the saved payloads if the failure concerns the
+// response body and SetSaveFailures is on. respPB is nil if body could not be parsed.
+func (h *HTTPConnection) saveFailure(endpoint string, reqPB, respPB proto.Message, body string, findings []utils.Finding, err error) ([]utils.Finding, error) {
+	if !h.saveFailures || body == "" || !(errors.Is(err, ErrParse) || errors.Is(err, ErrValidation)) {
+		return findings, err
+	}
+	saved := h.savePayloads(endpoint, reqPB, respPB, body, h.artifactQuota)
+	if len(saved) == 0 {
+		return findings, err
+	}
+	f := utils.Finding{Severity: utils.Warning, Field: ArtifactsField, Message: strings.Join(saved, ", "), Code: utils.CodeSavedPayloads}
+	log.Println(f)
+	return append(findings, f), err
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestSetSaveFailures(t *testing.T) {
+	data, err := utils.BookingSubmitData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The response echoes another hotel_id, which fails validation.
+	resp := strings.Replace(data.Resp, `"123"`, `"456"`, -1)
+	if resp == data.Resp {
+		t.Fatal("test response was not altered")
+	}
+	dir := t.TempDir()
+	conn, server := NewFakeHTTPClient(t, resp)
+	defer server.Close()
+	conn.runID = "2f1c7c9e-4b8e-4c2a-9d55-0a7e4d3b6f10"
+	conn.SetResponseLogLimit(0, dir)
+
+	findings, err := BookingSubmit(data.ReqPb, conn, "/v1/BookingSubmit")
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("BookingSubmit() returned error %v, want ErrValidation", err)
+	}
+	for _, f := range findings {
+		if f.Field == ArtifactsField {
+			t.Errorf("BookingSubmit() without SetSaveFailures returned finding %v", f)
+		}
+	}
+
+	conn.SetSaveFailures(true)
+	findings, err = BookingSubmit(data.ReqPb, conn, "/v1/BookingSubmit")
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("BookingSubmit() returned error %v, want ErrValidation", err)
+	}
+	last := findings[len(findings)-1]
+	reqPath := filepath.Join(dir, conn.runID, "1_v1_BookingSubmit_request.json")
+	respPath := filepath.Join(dir, conn.runID, "2_v1_BookingSubmit_response.json")
+	if want := "request saved to " + reqPath + ", response saved to " + respPath; last.Field != ArtifactsField || last.Message != want {
+		t.Fatalf("BookingSubmit() returned last finding %v, want %s", last, want)
+	}
+	if got, want := SavedPayloads(findings), map[string]string{"request": reqPath, "response": respPath}; !reflect.DeepEqual(got, want) {
+		t.Errorf("SavedPayloads() = %v, want %v", got, want)
+	}
+
+	b, err := ioutil.ReadFile(reqPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var req pb.BookingSubmitRequest
+	if err := jsonpb.UnmarshalString(string(b), &req); err != nil {
+		t.Fatalf("saved request is not a BookingSubmitRequest: %v", err)
+	}
+	if req.GetHotelId() != "HOTEL1" || req.GetCustomer().GetEmail() == data.ReqPb.GetCustomer().GetEmail() {
+		t.Errorf("saved request was not anonymized: %v", &req)
+	}
+	b, err = ioutil.ReadFile(respPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"HOTEL2"`) {
+		t.Errorf("saved response %s does not anonymize the mismatched hotel_id consistently with the request", b)
+	}
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// plaintextPort is the port probed by CheckHTTPSRedirect for a plaintext http listener.
+var plaintextPort = "80"
+
+// isLocal reports whether the connection's server is the local machine, where plaintext http is acceptable.
+func (h *HTTPConnection) isLocal() bool {
+	u, err := url.Parse(h.baseURL)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// SetRequireHTTPS makes the connection refuse to send credentials over plaintext http to any server but the local
+// machine. It returns ErrInsecure if the connection is already configured to do so.
+func (h *HTTPConnection) SetRequireHTTPS(require bool) error {
+	h.requireHTTPS = require
+	if require && strings.HasPrefix(h.baseURL, "http://") && h.credentials == "" && !h.isLocal() {
+		log.Printf("Warning: connecting to %s over plaintext http, production servers must use https", h.baseURL)
+	}
+	return h.checkPlaintextCredentials()
+}
+
+// checkPlaintextCredentials returns ErrInsecure if a request would send credentials in plaintext in violation of
+// SetRequireHTTPS.
+func (h *HTTPConnection) checkPlaintextCredentials() error {
+	if h.requireHTTPS && h.credentials != "" && strings.HasPrefix(h.baseURL, "http://") && !h.isLocal() {
+		return fmt.Errorf("%w: refusing to send credentials to %s over plaintext http, set ca_file or use_system_roots", ErrInsecure, h.baseURL)
+	}
+	return nil
+}
+
+// httpsField is the field of the findings about plaintext http.
+const httpsField = "https"
+
+// httpsTarget returns the https URL of endpoint. If the connection does not use https, it returns nil along with an
+// Error finding and an ErrInsecure error.
+func httpsTarget(conn *HTTPConnection, endpoint string) (*url.URL, []utils.Finding, error) {
+	target, err := url.Parse(conn.getURL(endpoint))
+	if err != nil {
+		return nil, nil, err
+	}
+	if target.Scheme != "https" {
+		f := utils.Finding{Severity: utils.Error, Field: httpsField, Message: fmt.Sprintf("%s is served over plaintext http", target), Code: utils.CodePlaintextHTTP}
+		log.Println(f)
+		return nil, []utils.Finding{f}, fmt.Errorf("%w: %s is not served over https", ErrInsecure, endpoint)
+	}
+	return target, nil, nil
+}
+
+// plaintextClient returns a client for the plaintext http port of the connection's server, which does not follow
+// redirects.
+func plaintextClient(conn *HTTPConnection) *http.Client {
+	return &http.Client{
+		Timeout:   TimeoutDuration,
+		Transport: conn.client.Transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// CheckHTTPSRedirect sends an empty request, without credentials, to the plaintext http port of the server's
+// endpoint and ensures it is redirected to https on the same host, or refused. A plaintext answer, or a redirect to
+// another scheme or host, is an Error finding; a 301, 302 or 303 redirect, which turns the POST into a GET, is a
+// Warning.
+func CheckHTTPSRedirect(conn *HTTPConnection, endpoint string) ([]utils.Finding, error) {
+	target, findings, err := httpsTarget(conn, endpoint)
+	if target == nil {
+		return findings, err
+	}
+
+	plain := *target
+	plain.Scheme = "http"
+	plain.Host = net.JoinHostPort(target.Hostname(), plaintextPort)
+	resp, err := plaintextClient(conn).Post(plain.String(), "application/json", strings.NewReader("{}"))
+	if err != nil {
+		log.Printf("No plaintext http listener at %s: %v", plain.Host, err)
+		return nil, nil
+	}
+	resp.Body.Close()
+
+	report := func(s utils.Severity, code, msg string) {
+		f := utils.Finding{Severity: s, Field: httpsField, Message: msg, Code: code}
+		log.Println(f)
+		findings = append(findings, f)
+	}
+	switch resp.StatusCode {
+	case http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther:
+		report(utils.Warning, utils.CodeRedirectMethod, fmt.Sprintf("%s redirects with %s, which turns POST requests into GET; use 307 or 308", plain.String(), resp.Status))
+	default:
+		report(utils.Error, utils.CodePlaintextAnswered, fmt.Sprintf("%s answered %s over plaintext http instead of redirecting to https", plain.String(), resp.Status))
+		return findings, fmt.Errorf("%w: %s accepts plaintext http", ErrInsecure, plain.Host)
+	}
+	location, err := resp.Location()
+	if err != nil || location.Scheme != "https" || location.Hostname() != target.Hostname() {
+		report(utils.Error, utils.CodeRedirectTarget, fmt.Sprintf("%s redirects to %q, want https://%s", plain.String(), resp.Header.Get("Location"), target.Hostname()))
+		return findings, fmt.Errorf("%w: %s does not redirect to https", ErrInsecure, plain.Host)
+	}
+	return findings, nil
+}
+
+// ComparePlaintextAvailability sends reqPB to endpoint over https, and the same request without credentials to the
+// plaintext http port of the server. The check passes if nothing listens there or if it redirects, see
+// CheckHTTPSRedirect for the redirect itself. Any other answer is an Error finding, and an availability response
+// served over plaintext http a Critical one, since it exposes the production endpoint to anyone on the network. When
+// both schemes answer, their responses are compared ignoring the volatile fields, and a difference is an Error
+// finding: the plaintext listener is a separate, possibly stale, deployment.
+func ComparePlaintextAvailability(reqPB *pb.BookingAvailabilityRequest, conn *HTTPConnection, endpoint string) ([]utils.Finding, error) {
+	target, findings, err := httpsTarget(conn, endpoint)
+	if target == nil {
+		return findings, err
+	}
+	req, err := conn.marshaler.MarshalToString(reqPB)
+	if err != nil {
+		return nil, fmt.Errorf("Could not convert pb3 to json: %v, Error: %v", reqPB, err)
+	}
+	httpReq, err := conn.newRequest(endpoint, req)
+	if err != nil {
+		return nil, err
+	}
+	// The credentials are never sent in plaintext, a partner answering without them is the worst case.
+	httpReq.Header.Del("Authorization")
+	httpReq.URL.Scheme = "http"
+	httpReq.URL.Host = net.JoinHostPort(target.Hostname(), plaintextPort)
+	if conn.hostHeader == "" {
+		httpReq.Host = httpReq.URL.Host
+	}
+	plain := httpReq.URL.String()
+
+	resp, err := plaintextClient(conn).Do(httpReq)
+	if err != nil {
+		log.Printf("No plaintext http listener at %s: %v", httpReq.URL.Host, err)
+		return nil, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 {
+		log.Printf("%s redirects with %s, no plaintext response to compare", plain, resp.Status)
+		return nil, nil
+	}
+	report := func(s utils.Severity, code, msg string) {
+		f := utils.Finding{Severity: s, Field: httpsField, Message: msg, Code: code}
+		log.Println(f)
+		findings = append(findings, f)
+	}
+	body, err := ioutil.ReadAll(conn.unwrapResponse(endpoint, resp.Body))
+	var plainResp pb.BookingAvailabilityResponse
+	if err == nil && resp.StatusCode == http.StatusOK {
+		_, err = parseResponse(string(body), conn, &plainResp)
+	}
+	if err != nil || resp.StatusCode != http.StatusOK {
+		report(utils.Error, utils.CodePlaintextAnswered, fmt.Sprintf("%s answered %s over plaintext http instead of redirecting to https", plain, resp.Status))
+		return findings, fmt.Errorf("%w: %s accepts plaintext http", ErrInsecure, httpReq.URL.Host)
+	}
+	report(utils.Critical, utils.CodePlaintextAvailability, fmt.Sprintf("%s served an availability response over plaintext http without credentials", plain))
+
+	httpsResp, err := SendBookingAvailability(reqPB, conn, endpoint)
+	if err != nil {
+		return findings, fmt.Errorf("%w: %s serves availability over plaintext http, and the https request failed: %v", ErrInsecure, httpReq.URL.Host, err)
+	}
+	diff, err := utils.DiffResponses(httpsResp, &plainResp, nil)
+	if err != nil {
+		return findings, err
+	}
+	if diff != "" {
+		report(utils.Error, utils.CodePlaintextDiverges, fmt.Sprintf("the plaintext http response differs from the https response (-https +http):\n%s", diff))
+	}
+	return findings, fmt.Errorf("%w: %s serves availability over plaintext http", ErrInsecure, httpReq.URL.Host)
+}
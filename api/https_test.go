@@ -0,0 +1,190 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+func TestSetRequireHTTPS(t *testing.T) {
+	cases := []struct {
+		baseURL     string
+		credentials string
+		wantErr     error
+	}{
+		{baseURL: "http://partner.example.com:80", credentials: "Basic dXNlcjpwYXNz", wantErr: ErrInsecure},
+		{baseURL: "https://partner.example.com:443", credentials: "Basic dXNlcjpwYXNz"},
+		{baseURL: "http://localhost:8080", credentials: "Basic dXNlcjpwYXNz"},
+		{baseURL: "http://127.0.0.1:8080", credentials: "Basic dXNlcjpwYXNz"},
+		{baseURL: "http://partner.example.com:80"},
+	}
+	for _, tc := range cases {
+		conn := &HTTPConnection{baseURL: tc.baseURL, credentials: tc.credentials}
+		if err := conn.SetRequireHTTPS(true); !errors.Is(err, tc.wantErr) {
+			t.Errorf("SetRequireHTTPS() for %s returned %v, want %v", tc.baseURL, err, tc.wantErr)
+		}
+		if tc.wantErr == nil {
+			continue
+		}
+		if _, err := sendRequest("", "{}", conn); !errors.Is(err, tc.wantErr) {
+			t.Errorf("sendRequest() to %s returned %v, want %v", tc.baseURL, err, tc.wantErr)
+		}
+	}
+}
+
+func TestCheckHTTPSRedirect(t *testing.T) {
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer tlsServer.Close()
+	conn := &HTTPConnection{client: tlsServer.Client(), baseURL: tlsServer.URL}
+	defer func(p string) { plaintextPort = p }(plaintextPort)
+
+	cases := []struct {
+		name         string
+		handler      http.HandlerFunc
+		wantErr      bool
+		wantFindings []utils.Severity
+	}{
+		{
+			name: "307 to https",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, tlsServer.URL+r.URL.Path, http.StatusTemporaryRedirect)
+			},
+		},
+		{
+			name: "301 to https",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, tlsServer.URL+r.URL.Path, http.StatusMovedPermanently)
+			},
+			wantFindings: []utils.Severity{utils.Warning},
+		},
+		{
+			name: "redirect to http",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, "http://127.0.0.1/", http.StatusPermanentRedirect)
+			},
+			wantErr:      true,
+			wantFindings: []utils.Severity{utils.Error},
+		},
+		{
+			name:         "plaintext answer",
+			handler:      func(w http.ResponseWriter, r *http.Request) {},
+			wantErr:      true,
+			wantFindings: []utils.Severity{utils.Error},
+		},
+	}
+	for _, tc := range cases {
+		plain := httptest.NewServer(tc.handler)
+		_, port, err := net.SplitHostPort(plain.Listener.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		plaintextPort = port
+		findings, err := CheckHTTPSRedirect(conn, "/v1/BookingAvailability")
+		plain.Close()
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: CheckHTTPSRedirect() returned error %v, want error %v", tc.name, err, tc.wantErr)
+		}
+		var got []utils.Severity
+		for _, f := range findings {
+			got = append(got, f.Severity)
+		}
+		if len(got) != len(tc.wantFindings) || (len(got) > 0 && got[0] != tc.wantFindings[0]) {
+			t.Errorf("%s: CheckHTTPSRedirect() returned findings %v, want severities %v", tc.name, findings, tc.wantFindings)
+		}
+	}
+
+	// Nothing listening on the plaintext port is secure.
+	plaintextPort = "1"
+	if findings, err := CheckHTTPSRedirect(conn, ""); err != nil || findings != nil {
+		t.Errorf("CheckHTTPSRedirect() without a plaintext listener returned %v, %v, want none", findings, err)
+	}
+}
+
+func TestComparePlaintextAvailability(t *testing.T) {
+	data, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, data.Resp)
+	}))
+	defer tlsServer.Close()
+	conn := &HTTPConnection{client: tlsServer.Client(), marshaler: &jsonpb.Marshaler{OrigName: true}, baseURL: tlsServer.URL, credentials: "Basic dXNlcjpwYXNz"}
+	defer func(p string) { plaintextPort = p }(plaintextPort)
+	stale := strings.Replace(data.Resp, `"hotel_id": "`+data.RespPb.GetHotelId()+`"`, `"hotel_id": "stale"`, 1)
+	if stale == data.Resp {
+		t.Fatal("test response was not altered")
+	}
+
+	cases := []struct {
+		name      string
+		handler   http.HandlerFunc
+		wantErr   bool
+		wantCodes []string
+	}{
+		{
+			name: "redirect",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Redirect(w, r, tlsServer.URL+r.URL.Path, http.StatusPermanentRedirect)
+			},
+		},
+		{
+			name: "unauthorized",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			},
+			wantErr:   true,
+			wantCodes: []string{utils.CodePlaintextAnswered},
+		},
+		{
+			name: "same response",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, data.Resp)
+			},
+			wantErr:   true,
+			wantCodes: []string{utils.CodePlaintextAvailability},
+		},
+		{
+			name: "divergent response",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintln(w, stale)
+			},
+			wantErr:   true,
+			wantCodes: []string{utils.CodePlaintextAvailability, utils.CodePlaintextDiverges},
+		},
+	}
+	for _, tc := range cases {
+		plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "" {
+				t.Errorf("%s: credentials were sent over plaintext http", tc.name)
+			}
+			tc.handler(w, r)
+		}))
+		_, port, err := net.SplitHostPort(plain.Listener.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		plaintextPort = port
+		findings, err := ComparePlaintextAvailability(data.ReqPb, conn, "/v1/BookingAvailability")
+		plain.Close()
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: ComparePlaintextAvailability() returned error %v, want error %v", tc.name, err, tc.wantErr)
+		}
+		var got []string
+		for _, f := range findings {
+			got = append(got, f.Code)
+		}
+		if diff := cmp.Diff(got, tc.wantCodes); diff != "" {
+			t.Errorf("%s: ComparePlaintextAvailability() returned codes that differ (-got +want):\n%s", tc.name, diff)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http/httptrace"
+)
+
+// KeepAliveReport summarizes the reuse of connections across the requests sent to the partner. A partner that closes
+// every connection makes each request pay for a new TCP connection, and TLS handshake, a latency production traffic
+// pays too.
+type KeepAliveReport struct {
+	// Requests is the number of requests sent, Reused the number of those sent over a connection opened by a previous
+	// request and Closed the number of responses with which the partner closed the connection, e.g. with a
+	// Connection: close header.
+	Requests int      `json:"requests"`
+	Reused   int      `json:"reused"`
+	Closed   int      `json:"closed,omitempty"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// keepAlive counts the connections used by the requests of a connection.
+type keepAlive struct {
+	requests, reused, closed int
+}
+
+// traceConnections returns a context derived from ctx which counts whether the request reuses a connection.
+func (h *HTTPConnection) traceConnections(ctx context.Context) context.Context {
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+			h.keepAlive.requests++
+			if info.Reused {
+				h.keepAlive.reused++
+			}
+		},
+	})
+}
+
+// recordClose counts a response with which the partner closed the connection.
+func (h *HTTPConnection) recordClose() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.keepAlive.closed++
+}
+
+// KeepAliveReport returns the reuse of connections across the requests sent over this connection, or nil when none
+// was sent. Every request opening a new connection is reported as a warning, unless a single request was sent.
+func (h *HTTPConnection) KeepAliveReport() *KeepAliveReport {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	k := h.keepAlive
+	if k.requests == 0 {
+		return nil
+	}
+	r := &KeepAliveReport{Requests: k.requests, Reused: k.reused, Closed: k.closed}
+	if r.Requests > 1 && r.Reused == 0 {
+		if r.Closed > 0 {
+			r.Warnings = append(r.Warnings, fmt.Sprintf("each of %d requests opened a new connection, the server closed the connection after %d response(s)", r.Requests, r.Closed))
+		} else {
+			r.Warnings = append(r.Warnings, fmt.Sprintf("each of %d requests opened a new connection, the server closed idle connections before the next request", r.Requests))
+		}
+	}
+	return r
+}
+
+// LogKeepAliveReport logs how many of the requests of r reused a connection, and its warnings.
+func LogKeepAliveReport(r *KeepAliveReport) {
+	if r == nil {
+		return
+	}
+	log.Printf("Connection keep-alive: %d of %d request(s) reused a connection, %d connection(s) closed by the server\n", r.Reused, r.Requests, r.Closed)
+	for _, w := range r.Warnings {
+		log.Printf("Warning: keep-alive %s\n", w)
+	}
+}
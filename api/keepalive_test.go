@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestKeepAliveReport(t *testing.T) {
+	cases := []struct {
+		name        string
+		close       bool
+		wantReused  int
+		wantClosed  int
+		wantWarning string
+	}{
+		{name: "kept alive", wantReused: 2},
+		{name: "closed", close: true, wantClosed: 3, wantWarning: "each of 3 requests opened a new connection, the server closed the connection after 3 response(s)"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tc.close {
+					w.Header().Set("Connection", "close")
+				}
+				w.Write([]byte("{}"))
+			}))
+			defer server.Close()
+			conn := &HTTPConnection{client: &http.Client{Transport: &http.Transport{}}, baseURL: server.URL}
+			if conn.KeepAliveReport() != nil {
+				t.Error("KeepAliveReport() before any request is not nil")
+			}
+			for i := 0; i < 3; i++ {
+				if _, err := sendRequest("", "{}", conn); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got := conn.KeepAliveReport()
+			if got.Requests != 3 || got.Reused != tc.wantReused || got.Closed != tc.wantClosed {
+				t.Errorf("KeepAliveReport() = %+v, want %d of 3 requests reused and %d closed", got, tc.wantReused, tc.wantClosed)
+			}
+			if warnings := strings.Join(got.Warnings, "; "); warnings != tc.wantWarning {
+				t.Errorf("KeepAliveReport() warnings = %q, want %q", warnings, tc.wantWarning)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+// jsonPrefixes are the non-json prefixes some partner stacks emit before the response body, with a description
+// used in findings. They are matched in order, so a byte order mark followed by an XSSI guard reports both.
+var jsonPrefixes = []struct {
+	prefix, name string
+}{
+	{"\ufeff", "UTF-8 byte order mark"},
+	{")]}'", "XSSI prefix )]}'"},
+	{"while(1);", "XSSI prefix while(1);"},
+	{"for(;;);", "XSSI prefix for(;;);"},
+}
+
+// stripJSONPrefix removes any known prefix from body and returns a Warning finding for each one found.
+func stripJSONPrefix(body string) (string, []utils.Finding) {
+	var findings []utils.Finding
+	for _, p := range jsonPrefixes {
+		if strings.HasPrefix(body, p.prefix) {
+			body = strings.TrimLeft(body[len(p.prefix):], " \t\r\n")
+			findings = append(findings, utils.Finding{
+				Severity: utils.Warning,
+				Field:    "response body",
+				Message:  fmt.Sprintf("starts with a %s; it was stripped but should be removed", p.name),
+				Code:     utils.CodeJSONPrefix,
+			})
+		}
+	}
+	return body, findings
+}
+
+// parseResponse unmarshals the json response body into respPB. Known prefixes are stripped with a Warning finding,
+// unless the connection is in strict mode, in which case they fail the parse. Numbers encoded as strings or in
+// exponent notation are reported by field, see utils.CheckNumberEncoding, and so are repeated keys, see
+// utils.CheckJSONKeys.
+func parseResponse(body string, conn *HTTPConnection, respPB proto.Message) ([]utils.Finding, error) {
+	body, findings := stripJSONPrefix(body)
+	for _, f := range findings {
+		if conn.strictJSON {
+			return nil, fmt.Errorf("response body %s", f.Message)
+		}
+		log.Println(f)
+	}
+	if trimmed := strings.TrimSpace(body); !strings.HasPrefix(trimmed, "{") {
+		if len(trimmed) > 20 {
+			trimmed = trimmed[:20] + "..."
+		}
+		return findings, fmt.Errorf("response body is not a json object, it starts with %q", trimmed)
+	}
+	findings = append(findings, utils.CheckJSONKeys("", []byte(body), respPB, conn.keyOrder)...)
+	numbers, err := utils.CheckNumberEncoding("", []byte(body), respPB)
+	findings = append(findings, numbers...)
+	if err != nil {
+		return findings, err
+	}
+	if err := jsonpb.UnmarshalString(body, respPB); err != nil {
+		return findings, err
+	}
+	return findings, nil
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+func TestBookingAvailabilityJSONPrefix(t *testing.T) {
+	data, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name         string
+		prefix       string
+		strict       bool
+		wantFindings int
+		wantErr      bool
+	}{
+		{name: "none", prefix: ""},
+		{name: "bom", prefix: "\ufeff", wantFindings: 1},
+		{name: "xssi", prefix: ")]}'\n", wantFindings: 1},
+		{name: "bom and xssi", prefix: "\ufeff)]}'\n", wantFindings: 2},
+		{name: "strict", prefix: ")]}'\n", strict: true, wantErr: true},
+		{name: "unknown prefix", prefix: "<html>", wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			conn, server := NewFakeHTTPClient(t, tc.prefix+data.Resp)
+			defer server.Close()
+			conn.SetStrictJSON(tc.strict)
+			findings, err := BookingAvailability(data.ReqPb, conn, "")
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("BookingAvailability() returned error [%v], want error: %v", err, tc.wantErr)
+			}
+			if err == nil && len(findings) != tc.wantFindings {
+				t.Errorf("BookingAvailability() returned findings %v, want %d", findings, tc.wantFindings)
+			}
+			for _, f := range findings {
+				if f.Severity != utils.Warning {
+					t.Errorf("BookingAvailability() returned finding %v, want Warning", f)
+				}
+			}
+		})
+	}
+}
+
+func TestBookingAvailabilityNumberEncoding(t *testing.T) {
+	data, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name    string
+		resp    string
+		wantErr string
+	}{
+		{name: "quoted api_version", resp: strings.Replace(data.Resp, `"api_version": 1`, `"api_version": "1"`, 1)},
+		{name: "exponent adults", resp: strings.Replace(data.Resp, `"adults": 2`, `"adults": 2e0`, 1), wantErr: "Could not parse HTTP response to pb3: invalid number encoding in field(s): party > adults"},
+	} {
+		if tc.resp == data.Resp {
+			t.Fatalf("%s: test response was not altered", tc.name)
+		}
+		conn, server := NewFakeHTTPClient(t, tc.resp)
+		findings, err := BookingAvailability(data.ReqPb, conn, "")
+		server.Close()
+		if tc.wantErr == "" && err != nil || tc.wantErr != "" && (err == nil || !strings.HasSuffix(err.Error(), tc.wantErr)) {
+			t.Errorf("%s: BookingAvailability() returned error %v, want %q", tc.name, err, tc.wantErr)
+		}
+		if len(findings) != 1 {
+			t.Errorf("%s: BookingAvailability() returned findings %v, want one for the number encoding", tc.name, findings)
+		}
+	}
+}
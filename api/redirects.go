@@ -0,0 +1,128 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+// maxRedirects is the number of redirects followed for a request, as by the default policy of http.Client.
+const maxRedirects = 10
+
+// Redirect is a redirect answered by the partner, counted once per distinct source, target and status. CrossOrigin is
+// set if the target is on another host, or on plaintext http while the source is on https.
+type Redirect struct {
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Status      int    `json:"status"`
+	CrossOrigin bool   `json:"cross_origin,omitempty"`
+	Followed    bool   `json:"followed"`
+	Count       int    `json:"count"`
+}
+
+// RedirectReport lists the redirects answered by the partner during a run. Requests to the partner's endpoints are
+// expected to be answered directly: a redirect to another host, or from https to plaintext http, which would carry
+// the Authorization header is not followed, and is reported as a Warning finding along with any redirect followed
+// to another host.
+type RedirectReport struct {
+	Redirects []Redirect `json:"redirects"`
+}
+
+// checkRedirect is the CheckRedirect policy of the connection's client. It follows redirects on the same host and
+// scheme, and redirects elsewhere of requests without credentials, but returns ErrRedirect rather than send the
+// credentials of the first request to another host or over plaintext http.
+func (h *HTTPConnection) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	first := via[0]
+	cross := req.URL.Host != first.URL.Host || (first.URL.Scheme == "https" && req.URL.Scheme != "https")
+	status := 0
+	if req.Response != nil {
+		status = req.Response.StatusCode
+	}
+	d := Redirect{From: via[len(via)-1].URL.String(), To: req.URL.String(), Status: status, CrossOrigin: cross}
+	d.Followed = !cross || first.Header.Get("Authorization") == ""
+	h.recordRedirect(d)
+	if !d.Followed {
+		return fmt.Errorf("%w: %s redirected to %s, not followed to keep the credentials from another host or plaintext http", ErrRedirect, via[len(via)-1].URL, req.URL)
+	}
+	return nil
+}
+
+// recordRedirect counts the redirect d.
+func (h *HTTPConnection) recordRedirect(d Redirect) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, r := range h.redirects {
+		if r.From == d.From && r.To == d.To && r.Status == d.Status {
+			h.redirects[i].Count++
+			return
+		}
+	}
+	d.Count = 1
+	h.redirects = append(h.redirects, d)
+}
+
+// RedirectReport returns the redirects answered by the partner over this connection, or nil when none was.
+func (h *HTTPConnection) RedirectReport() *RedirectReport {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.redirects) == 0 {
+		return nil
+	}
+	return &RedirectReport{Redirects: append([]Redirect(nil), h.redirects...)}
+}
+
+// Findings returns a Warning finding for each redirect of r that was not followed or led to another host or scheme,
+// or nil if r is nil.
+func (r *RedirectReport) Findings() []utils.Finding {
+	if r == nil {
+		return nil
+	}
+	var findings []utils.Finding
+	for _, d := range r.Redirects {
+		switch {
+		case !d.Followed:
+			findings = append(findings, utils.Finding{Severity: utils.Warning, Field: "redirects > " + d.From, Message: fmt.Sprintf("redirects with %d to %s, which was not followed so as not to send it the credentials", d.Status, d.To), Code: utils.CodeRedirectCredentials})
+		case d.CrossOrigin:
+			findings = append(findings, utils.Finding{Severity: utils.Warning, Field: "redirects > " + d.From, Message: fmt.Sprintf("redirects with %d to %s on another host or scheme", d.Status, d.To), Code: utils.CodeRedirectCrossOrigin})
+		}
+	}
+	return findings
+}
+
+// LogRedirectReport logs the redirects of r and its findings.
+func LogRedirectReport(r *RedirectReport) {
+	if r == nil {
+		return
+	}
+	for _, d := range r.Redirects {
+		followed := "followed"
+		if !d.Followed {
+			followed = "not followed"
+		}
+		log.Printf("Redirect: %s -> %s (%d, %s, %d time(s))\n", d.From, d.To, d.Status, followed, d.Count)
+	}
+	for _, f := range r.Findings() {
+		log.Println(f)
+	}
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+func TestCheckRedirect(t *testing.T) {
+	var reached bool
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reached = true
+		w.Write([]byte("{}"))
+	}))
+	defer other.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/same":
+			http.Redirect(w, r, "/final", http.StatusTemporaryRedirect)
+		case "/other":
+			http.Redirect(w, r, other.URL+"/final", http.StatusTemporaryRedirect)
+		default:
+			w.Write([]byte("{}"))
+		}
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		name         string
+		endpoint     string
+		credentials  string
+		wantErr      error
+		wantReached  bool
+		wantFollowed bool
+		wantCode     string
+	}{
+		{name: "same host", endpoint: "/same", credentials: "Basic cGFydG5lcjpjdXJyZW50", wantFollowed: true},
+		{name: "other host with credentials", endpoint: "/other", credentials: "Basic cGFydG5lcjpjdXJyZW50", wantErr: ErrRedirect, wantCode: utils.CodeRedirectCredentials},
+		{name: "other host without credentials", endpoint: "/other", wantReached: true, wantFollowed: true, wantCode: utils.CodeRedirectCrossOrigin},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			reached = false
+			conn := &HTTPConnection{client: &http.Client{}, credentials: tc.credentials, baseURL: server.URL}
+			conn.client.CheckRedirect = conn.checkRedirect
+			_, err := sendRequest(tc.endpoint, "{}", conn)
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("sendRequest(%q) returned error %v, want %v", tc.endpoint, err, tc.wantErr)
+			}
+			if reached != tc.wantReached {
+				t.Errorf("sendRequest(%q) reached the other host: %t, want %t", tc.endpoint, reached, tc.wantReached)
+			}
+			got := conn.RedirectReport()
+			if got == nil || len(got.Redirects) != 1 {
+				t.Fatalf("RedirectReport() = %+v, want a single redirect", got)
+			}
+			if got.Redirects[0].Followed != tc.wantFollowed || got.Redirects[0].Status != http.StatusTemporaryRedirect {
+				t.Errorf("RedirectReport() redirect = %+v, want followed %t with status 307", got.Redirects[0], tc.wantFollowed)
+			}
+			var codes []string
+			for _, f := range got.Findings() {
+				codes = append(codes, f.Code)
+			}
+			if want := []string{tc.wantCode}; tc.wantCode == "" && len(codes) != 0 || tc.wantCode != "" && !reflect.DeepEqual(codes, want) {
+				t.Errorf("RedirectReport().Findings() codes = %q, want %q", codes, tc.wantCode)
+			}
+		})
+	}
+}
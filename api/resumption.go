@@ -0,0 +1,196 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"sync"
+)
+
+// ResumptionReport summarizes the TLS handshakes made with the partner. A partner that issues no session tickets, or
+// never accepts them, makes every new connection pay for a full handshake, which skews the latency of load tests.
+type ResumptionReport struct {
+	// Handshakes is the number of TLS handshakes completed, Resumed the number of those that resumed a session and
+	// TicketsIssued the number of session tickets the partner sent.
+	Handshakes    int      `json:"handshakes"`
+	Resumed       int      `json:"resumed"`
+	TicketsIssued int      `json:"tickets_issued"`
+	Warnings      []string `json:"warnings,omitempty"`
+}
+
+// sessionCache keeps the most recent session ticket of every server, and counts the tickets and handshakes.
+type sessionCache struct {
+	mu         sync.Mutex
+	sessions   map[string]*tls.ClientSessionState
+	issued     int
+	handshakes int
+	resumed    int
+}
+
+func (c *sessionCache) Get(key string) (*tls.ClientSessionState, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cs, ok := c.sessions[key]
+	return cs, ok
+}
+
+func (c *sessionCache) Put(key string, cs *tls.ClientSessionState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cs == nil {
+		delete(c.sessions, key)
+		return
+	}
+	c.sessions[key] = cs
+	c.issued++
+}
+
+// verifyConnection counts every completed handshake, including resumed ones.
+func (c *sessionCache) verifyConnection(state tls.ConnectionState) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handshakes++
+	if state.DidResume {
+		c.resumed++
+	}
+	return nil
+}
+
+// persistedSession is a session ticket saved by SaveTLSSessions.
+type persistedSession struct {
+	Ticket []byte `json:"ticket"`
+	State  []byte `json:"state"`
+}
+
+// tlsSessions returns the session cache of the connection, creating it on first use.
+func (h *HTTPConnection) tlsSessions() *sessionCache {
+	if h.sessions == nil {
+		h.sessions = &sessionCache{sessions: map[string]*tls.ClientSessionState{}}
+	}
+	return h.sessions
+}
+
+// resumeSessions makes the handshakes configured by config resume the sessions of the connection.
+func (h *HTTPConnection) resumeSessions(config *tls.Config) {
+	config.ClientSessionCache = h.tlsSessions()
+	config.VerifyConnection = h.tlsSessions().verifyConnection
+}
+
+// SetTLSSessionFile resumes the sessions saved in path by a previous run, so the first connection does not need a
+// full handshake either, and makes SaveTLSSessions write the tickets of this run back to it. A missing file is not
+// an error.
+func (h *HTTPConnection) SetTLSSessionFile(path string) error {
+	h.sessionFile = path
+	if path == "" {
+		return nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read TLS sessions: %v", err)
+	}
+	var saved map[string]persistedSession
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return fmt.Errorf("failed to parse TLS sessions in %s: %v", path, err)
+	}
+	c := h.tlsSessions()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, s := range saved {
+		state, err := tls.ParseSessionState(s.State)
+		if err != nil {
+			log.Printf("Warning: ignoring saved TLS session of %s: %v\n", key, err)
+			continue
+		}
+		cs, err := tls.NewResumptionState(s.Ticket, state)
+		if err != nil {
+			log.Printf("Warning: ignoring saved TLS session of %s: %v\n", key, err)
+			continue
+		}
+		c.sessions[key] = cs
+	}
+	return nil
+}
+
+// SaveTLSSessions writes the latest session ticket of every server to the file set with SetTLSSessionFile, if any.
+func (h *HTTPConnection) SaveTLSSessions() error {
+	if h.sessionFile == "" || h.sessions == nil {
+		return nil
+	}
+	h.sessions.mu.Lock()
+	saved := map[string]persistedSession{}
+	for key, cs := range h.sessions.sessions {
+		ticket, state, err := cs.ResumptionState()
+		if err != nil || state == nil {
+			continue
+		}
+		b, err := state.Bytes()
+		if err != nil {
+			continue
+		}
+		saved[key] = persistedSession{Ticket: ticket, State: b}
+	}
+	h.sessions.mu.Unlock()
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+	// The tickets resume sessions with the partner, keep them private like the credentials file.
+	if err := ioutil.WriteFile(h.sessionFile, data, 0600); err != nil {
+		return fmt.Errorf("failed to save TLS sessions: %v", err)
+	}
+	return nil
+}
+
+// ResumptionReport returns the TLS handshakes made over this connection, or nil when none was made.
+func (h *HTTPConnection) ResumptionReport() *ResumptionReport {
+	if h.sessions == nil {
+		return nil
+	}
+	h.sessions.mu.Lock()
+	defer h.sessions.mu.Unlock()
+	if h.sessions.handshakes == 0 {
+		return nil
+	}
+	r := &ResumptionReport{Handshakes: h.sessions.handshakes, Resumed: h.sessions.resumed, TicketsIssued: h.sessions.issued}
+	if r.Handshakes > 1 && r.Resumed == 0 {
+		if r.TicketsIssued == 0 {
+			r.Warnings = append(r.Warnings, fmt.Sprintf("server issued no session tickets, all %d handshakes were full handshakes", r.Handshakes))
+		} else {
+			r.Warnings = append(r.Warnings, fmt.Sprintf("none of %d handshakes resumed one of the %d session tickets issued", r.Handshakes, r.TicketsIssued))
+		}
+	}
+	return r
+}
+
+// LogResumptionReport logs how many of the TLS handshakes of r resumed a session, and its warnings.
+func LogResumptionReport(r *ResumptionReport) {
+	if r == nil {
+		return
+	}
+	log.Printf("TLS session resumption: %d of %d handshake(s) resumed, %d session ticket(s) issued\n", r.Resumed, r.Handshakes, r.TicketsIssued)
+	for _, w := range r.Warnings {
+		log.Printf("Warning: TLS resumption %s\n", w)
+	}
+}
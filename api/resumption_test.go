@@ -0,0 +1,80 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestResumptionReport(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	}))
+	defer server.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	newConn := func() (*HTTPConnection, *http.Transport) {
+		transport := &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}
+		conn := &HTTPConnection{client: &http.Client{Transport: transport}, baseURL: server.URL}
+		conn.resumeSessions(transport.TLSClientConfig)
+		return conn, transport
+	}
+	file := filepath.Join(t.TempDir(), "sessions.json")
+
+	conn, transport := newConn()
+	if err := conn.SetTLSSessionFile(file); err != nil {
+		t.Fatalf("SetTLSSessionFile() of a missing file failed: %v", err)
+	}
+	if conn.ResumptionReport() != nil {
+		t.Error("ResumptionReport() before any handshake is not nil")
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := sendRequest("", "{}", conn); err != nil {
+			t.Fatal(err)
+		}
+		transport.CloseIdleConnections()
+	}
+	got := conn.ResumptionReport()
+	if got.Handshakes != 3 || got.Resumed != 2 || got.TicketsIssued == 0 || len(got.Warnings) != 0 {
+		t.Errorf("ResumptionReport() = %+v, want 2 of 3 handshakes resumed without warnings", got)
+	}
+	if err := conn.SaveTLSSessions(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A new run resumes the session saved by the previous one on its first handshake.
+	conn, _ = newConn()
+	if err := conn.SetTLSSessionFile(file); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sendRequest("", "{}", conn); err != nil {
+		t.Fatal(err)
+	}
+	if got := conn.ResumptionReport(); got.Handshakes != 1 || got.Resumed != 1 {
+		t.Errorf("ResumptionReport() of a run resuming saved sessions = %+v, want its handshake resumed", got)
+	}
+}
+
+func TestResumptionReportWarnings(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = &tls.Config{SessionTicketsDisabled: true}
+	server.StartTLS()
+	defer server.Close()
+	transport := server.Client().Transport.(*http.Transport)
+	conn := &HTTPConnection{client: server.Client(), baseURL: server.URL}
+	conn.resumeSessions(transport.TLSClientConfig)
+	for i := 0; i < 2; i++ {
+		if _, err := sendRequest("", "{}", conn); err != nil {
+			t.Fatal(err)
+		}
+		transport.CloseIdleConnections()
+	}
+	got := conn.ResumptionReport()
+	want := "server issued no session tickets, all 2 handshakes were full handshakes"
+	if got.Resumed != 0 || len(got.Warnings) != 1 || got.Warnings[0] != want {
+		t.Errorf("ResumptionReport() = %+v, want warning %q", got, want)
+	}
+}
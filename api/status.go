@@ -0,0 +1,76 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+// SetExpectedStatus makes requests to endpoint fail with ErrStatus unless the server answers with status, e.g. 409
+// for a negative test. The body of a response with the expected status is parsed and validated as usual. A status of
+// 0 removes the expectation, accepting any status other than those classified as ErrAuth or ErrThrottled.
+func (h *HTTPConnection) SetExpectedStatus(endpoint string, status int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if status == 0 {
+		delete(h.statuses, endpoint)
+		return
+	}
+	if h.statuses == nil {
+		h.statuses = map[string]int{}
+	}
+	h.statuses[endpoint] = status
+}
+
+// ExpectedStatus returns the status set for endpoint by SetExpectedStatus, or 0 if there is none.
+func (h *HTTPConnection) ExpectedStatus(endpoint string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.statuses[endpoint]
+}
+
+// checkStatus classifies the status of a response from endpoint. A status other than the expected one is reported
+// as ErrAuth or ErrThrottled where applicable, and otherwise as ErrStatus if an expectation was set.
+func (h *HTTPConnection) checkStatus(endpoint string, resp *http.Response) error {
+	want := h.ExpectedStatus(endpoint)
+	if resp.StatusCode == want {
+		return nil
+	}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("%w: %s returned %s", ErrAuth, endpoint, resp.Status)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		return fmt.Errorf("%w: %s returned %s", ErrThrottled, endpoint, resp.Status)
+	}
+	if want != 0 {
+		return fmt.Errorf("%w: %s returned %s, want %d %s", ErrStatus, endpoint, resp.Status, want, http.StatusText(want))
+	}
+	return nil
+}
+
+// statusFindings returns an Error finding carrying the actual status if err is an ErrStatus.
+func statusFindings(err error) []utils.Finding {
+	if !errors.Is(err, ErrStatus) {
+		return nil
+	}
+	f := utils.Finding{Severity: utils.Error, Field: "http_status", Message: err.Error(), Code: utils.CodeHTTPStatus}
+	return []utils.Finding{f}
+}
@@ -0,0 +1,51 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+func TestSetExpectedStatus(t *testing.T) {
+	availability, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(availability.Resp))
+	}))
+	defer server.Close()
+	conn := &HTTPConnection{client: server.Client(), marshaler: &jsonpb.Marshaler{OrigName: true}, baseURL: server.URL}
+	const endpoint = "/v1/BookingAvailability"
+
+	// Without an expectation any status is accepted.
+	if _, err := BookingAvailability(availability.ReqPb, conn, endpoint); err != nil {
+		t.Errorf("BookingAvailability() without expected status returned %v, want nil", err)
+	}
+
+	conn.SetExpectedStatus(endpoint, http.StatusConflict)
+	if _, err := BookingAvailability(availability.ReqPb, conn, endpoint); err != nil {
+		t.Errorf("BookingAvailability() with expected status 409 returned %v, want nil", err)
+	}
+
+	conn.SetExpectedStatus(endpoint, http.StatusOK)
+	findings, err := BookingAvailability(availability.ReqPb, conn, endpoint)
+	if !errors.Is(err, ErrStatus) {
+		t.Errorf("BookingAvailability() with expected status 200 returned %v, want ErrStatus", err)
+	}
+	want := "/v1/BookingAvailability returned 409 Conflict, want 200 OK"
+	if len(findings) != 1 || findings[0].Field != "http_status" || findings[0].Message != "unexpected HTTP status: "+want {
+		t.Errorf("BookingAvailability() returned findings %v, want a single http_status finding: %s", findings, want)
+	}
+
+	conn.SetExpectedStatus(endpoint, 0)
+	if got := conn.ExpectedStatus(endpoint); got != 0 {
+		t.Errorf("ExpectedStatus() after reset returned %d, want 0", got)
+	}
+}
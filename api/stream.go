@@ -0,0 +1,189 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/golang/protobuf/jsonpb"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// maxPrefixBytes is the length of the start of a streamed body searched for jsonPrefixes.
+const maxPrefixBytes = 64
+
+// BookingAvailabilityStream behaves like BookingAvailability but decodes the response body as it is received and
+// validates its room_rates one at a time, so neither the body nor the complete response message is held in memory.
+// Use it for availability responses of several megabytes, e.g. during load tests. The response body is not logged.
+func BookingAvailabilityStream(reqPB *pb.BookingAvailabilityRequest, conn *HTTPConnection, endpoint string) ([]utils.Finding, error) {
+	req, err := conn.marshaler.MarshalToString(reqPB)
+	if err != nil {
+		return nil, fmt.Errorf("Could not convert pb3 to json: %v, Error: %v", reqPB, err)
+	}
+
+	httpResp, err := openRequest(endpoint, req, conn)
+	if err != nil {
+		return sendFindings(err), fmt.Errorf("HTTP response yielded error: %w", err)
+	}
+	defer httpResp.Body.Close()
+
+	v := utils.NewAvailabilityValidator(reqPB, reqPB.GetApiVersion())
+	var respPB pb.BookingAvailabilityResponse
+	findings, err := decodeAvailabilityStream(httpResp.Body, conn, v, &respPB)
+	if err != nil {
+		return findings, fmt.Errorf("%w: %v", ErrParse, err)
+	}
+	log.Printf("Streamed %d room_rates from %s\n", v.RoomRates(), endpoint)
+	conn.presence.AddResponse()
+	findings = append(findings, conn.transactionFindings(reqPB, &respPB)...)
+	findings = append(findings, utils.LintAvailabilityError(respPB.GetError())...)
+	findings = append(findings, utils.CheckFieldLengths("", &respPB)...)
+
+	validation, err := v.Validate(&respPB)
+	findings = append(findings, validation...)
+	if err != nil {
+		return findings, fmt.Errorf("%w: %v", ErrValidation, err)
+	}
+	return findings, nil
+}
+
+// decodeAvailabilityStream reads a json encoded BookingAvailabilityResponse from r, adding each of its room rates
+// to v and unmarshaling the other fields into respPB. Known prefixes are handled as by parseResponse.
+func decodeAvailabilityStream(r io.Reader, conn *HTTPConnection, v *utils.AvailabilityValidator, respPB *pb.BookingAvailabilityResponse) ([]utils.Finding, error) {
+	br := bufio.NewReader(r)
+	start, _ := br.Peek(maxPrefixBytes)
+	rest, findings := stripJSONPrefix(string(start))
+	for _, f := range findings {
+		if conn.strictJSON {
+			return nil, fmt.Errorf("response body %s", f.Message)
+		}
+		log.Println(f)
+	}
+	br.Discard(len(start) - len(rest))
+
+	dec := json.NewDecoder(br)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return findings, fmt.Errorf("response body is not a json object")
+	}
+	header := map[string]json.RawMessage{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return findings, err
+		}
+		key, _ := tok.(string)
+		if key != "room_rates" && key != "roomRates" {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return findings, err
+			}
+			if _, ok := header[key]; ok {
+				f := utils.Finding{Severity: utils.Warning, Field: key, Message: fmt.Sprintf("key %s is repeated, the last value is used but other json parsers may use the first", key), Code: utils.CodeJSONRepeatedKey}
+				log.Println(f)
+				findings = append(findings, f)
+			}
+			header[key] = raw
+			if key == "api_version" || key == "apiVersion" {
+				var apiVersion int32
+				if json.Unmarshal(raw, &apiVersion) == nil {
+					v.SetAPIVersion(apiVersion)
+				}
+			}
+			continue
+		}
+		if tok, err = dec.Token(); err != nil {
+			return findings, err
+		}
+		if tok == nil {
+			continue
+		}
+		if tok != json.Delim('[') {
+			return findings, fmt.Errorf("room_rates is not an array")
+		}
+		plans := streamedRatePlans(header)
+		for dec.More() {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return findings, err
+			}
+			var rate pb.RoomRate
+			prefix := fmt.Sprintf("room_rates[%d]", v.RoomRates())
+			findings = append(findings, utils.CheckJSONKeys(prefix, raw, &rate, conn.keyOrder)...)
+			numbers, err := utils.CheckNumberEncoding(prefix, raw, &rate)
+			findings = append(findings, numbers...)
+			if err != nil {
+				return findings, err
+			}
+			if err := jsonpb.Unmarshal(bytes.NewReader(raw), &rate); err != nil {
+				return findings, fmt.Errorf("room_rates[%d]: %v", v.RoomRates(), err)
+			}
+			findings = append(findings, utils.CheckFieldLengths(prefix, &rate)...)
+			v.AddRoomRate(&rate)
+			conn.presence.AddRoomRate(&rate, plans)
+		}
+		if _, err := dec.Token(); err != nil {
+			return findings, err
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return findings, err
+	}
+
+	b, err := json.Marshal(header)
+	if err != nil {
+		return findings, err
+	}
+	numbers, err := utils.CheckNumberEncoding("", b, respPB)
+	findings = append(findings, numbers...)
+	if err != nil {
+		return findings, err
+	}
+	if err := jsonpb.Unmarshal(bytes.NewReader(b), respPB); err != nil {
+		return findings, err
+	}
+	return findings, nil
+}
+
+// streamedRatePlans returns the rate plans in header, the fields of a streamed response preceding its room_rates, as
+// indexed by utils.RatePlansByCode. Rate plans that cannot be parsed are left out; the error is reported once the
+// whole header is parsed.
+func streamedRatePlans(header map[string]json.RawMessage) map[string]*pb.RatePlan {
+	raw, ok := header["rate_plans"]
+	if !ok {
+		raw = header["ratePlans"]
+	}
+	var items []json.RawMessage
+	if json.Unmarshal(raw, &items) != nil {
+		return nil
+	}
+	var plans []*pb.RatePlan
+	for _, item := range items {
+		var p pb.RatePlan
+		if (&jsonpb.Unmarshaler{AllowUnknownFields: true}).Unmarshal(bytes.NewReader(item), &p) == nil {
+			plans = append(plans, &p)
+		}
+	}
+	return utils.RatePlansByCode(plans)
+}
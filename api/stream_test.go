@@ -0,0 +1,71 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestBookingAvailabilityStream(t *testing.T) {
+	availability, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := jsonpb.Marshaler{OrigName: true}
+	large := proto.Clone(availability.RespPb).(*pb.BookingAvailabilityResponse)
+	for i := 0; i < 1000; i++ {
+		r := proto.Clone(availability.RespPb.RoomRates[i%len(availability.RespPb.RoomRates)]).(*pb.RoomRate)
+		r.Code = fmt.Sprintf("STREAMED%d", i)
+		large.RoomRates = append(large.RoomRates, r)
+	}
+	undefined := proto.Clone(large).(*pb.BookingAvailabilityResponse)
+	undefined.RoomRates[500].RatePlanCode = "UNDEFINED"
+
+	for _, tc := range []struct {
+		name string
+		resp *pb.BookingAvailabilityResponse
+		body func(string) string
+	}{
+		{name: "large", resp: large},
+		{name: "undefined rate plan", resp: undefined},
+		{name: "xssi prefix", resp: large, body: func(b string) string { return ")]}'\n" + b }},
+		{name: "room_rates last", resp: large, body: func(b string) string {
+			// Move hotel_details and policies before room_rates to ensure field order does not matter.
+			i := strings.Index(b, `,"room_rates":`)
+			j := strings.Index(b, `,"hotel_details":`)
+			return b[:i] + b[j:len(b)-1] + b[i:j] + "}"
+		}},
+	} {
+		body, err := m.MarshalToString(tc.resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tc.body != nil {
+			body = tc.body(body)
+		}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(body))
+		}))
+		conn := &HTTPConnection{client: server.Client(), marshaler: &m, baseURL: server.URL}
+
+		wantFindings, wantErr := BookingAvailability(availability.ReqPb, conn, "")
+		gotFindings, gotErr := BookingAvailabilityStream(availability.ReqPb, conn, "")
+		server.Close()
+		if fmt.Sprint(gotErr) != fmt.Sprint(wantErr) {
+			t.Errorf("%s: BookingAvailabilityStream() returned error %v, want %v as returned by BookingAvailability", tc.name, gotErr, wantErr)
+		}
+		if diff := cmp.Diff(gotFindings, wantFindings); diff != "" {
+			t.Errorf("%s: BookingAvailabilityStream() findings did not match BookingAvailability (-got +want)\n%s", tc.name, diff)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+// Kinds of TimeoutError, reported in the field of their findings, e.g. "timeout > connect". They tell apart latency
+// on the network path from latency of the partner server.
+const (
+	// TimeoutConnect means the host name was not resolved or the TCP connection not established in time.
+	TimeoutConnect = "connect"
+	// TimeoutTLSHandshake means the TCP connection was established but the TLS handshake did not complete in time.
+	TimeoutTLSHandshake = "tls_handshake"
+	// TimeoutClientDeadline means the request was sent but the complete response was not received before the client
+	// deadline, TimeoutDuration unless the client was replaced.
+	TimeoutClientDeadline = "client_deadline"
+	// TimeoutGateway means the partner answered with 504 Gateway Timeout, i.e. its own upstream timed out.
+	TimeoutGateway = "gateway"
+)
+
+// TimeoutError is a request that timed out, along with the time spent in each phase completed before the timeout.
+// It wraps ErrTimeout, and ErrTransport unless the partner answered with a 504.
+type TimeoutError struct {
+	Endpoint string
+	Kind     string
+	// Elapsed is the time from starting the request to the timeout or the 504 status.
+	Elapsed time.Duration
+	// DNS, Connect and TLSHandshake are the durations of the phases of opening the connection, zero if they did not
+	// complete or the connection was reused. RequestSent and FirstByte are measured from the start of the request,
+	// zero if they did not happen.
+	DNS          time.Duration
+	Connect      time.Duration
+	TLSHandshake time.Duration
+	RequestSent  time.Duration
+	FirstByte    time.Duration
+	// Deadline is the client deadline of the request.
+	Deadline time.Duration
+	// Status is the status of a TimeoutGateway response.
+	Status string
+	err    error
+}
+
+func (e *TimeoutError) Error() string {
+	var what string
+	switch e.Kind {
+	case TimeoutConnect:
+		what = "TCP connect timed out"
+	case TimeoutTLSHandshake:
+		what = "TLS handshake timed out"
+	case TimeoutClientDeadline:
+		what = fmt.Sprintf("response not received within the client deadline of %v", e.Deadline)
+	case TimeoutGateway:
+		what = fmt.Sprintf("partner returned %s", e.Status)
+	}
+	msg := fmt.Sprintf("%s: %s after %v (%s)", e.Endpoint, what, e.Elapsed.Round(time.Millisecond), e.timings())
+	if e.err != nil {
+		msg += ": " + e.err.Error()
+	}
+	return msg
+}
+
+// timings describes the phases of e that completed.
+func (e *TimeoutError) timings() string {
+	var t []string
+	for _, p := range []struct {
+		name string
+		d    time.Duration
+	}{{"dns", e.DNS}, {"connect", e.Connect}, {"tls handshake", e.TLSHandshake}, {"request sent at", e.RequestSent}, {"first byte at", e.FirstByte}} {
+		if p.d > 0 {
+			t = append(t, fmt.Sprintf("%s %v", p.name, p.d.Round(time.Millisecond)))
+		}
+	}
+	if len(t) == 0 {
+		return "no phase completed"
+	}
+	return strings.Join(t, ", ")
+}
+
+func (e *TimeoutError) Unwrap() []error {
+	if e.Kind == TimeoutGateway {
+		return []error{ErrTimeout}
+	}
+	return []error{ErrTimeout, ErrTransport}
+}
+
+// requestTimer records when each phase of a request happened. Trace callbacks may run on other goroutines.
+type requestTimer struct {
+	deadline                time.Duration
+	mu                      sync.Mutex
+	start                   time.Time
+	dnsStart, dnsDone       time.Time
+	connectStart, connected time.Time
+	tlsStart, tlsDone       time.Time
+	wroteRequest, firstByte time.Time
+}
+
+// trace returns a context derived from ctx which records the phases of a request into t.
+func (t *requestTimer) trace(ctx context.Context) context.Context {
+	t.start = time.Now()
+	set := func(p *time.Time) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if p.IsZero() {
+			*p = time.Now()
+		}
+	}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart:     func(httptrace.DNSStartInfo) { set(&t.dnsStart) },
+		DNSDone:      func(httptrace.DNSDoneInfo) { set(&t.dnsDone) },
+		ConnectStart: func(string, string) { set(&t.connectStart) },
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				set(&t.connected)
+			}
+		},
+		TLSHandshakeStart: func() { set(&t.tlsStart) },
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			if err == nil {
+				set(&t.tlsDone)
+			}
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { set(&t.wroteRequest) },
+		GotFirstResponseByte: func() { set(&t.firstByte) },
+	})
+}
+
+// timeoutError returns the TimeoutError of endpoint, with the phases recorded so far, classified as kind.
+func (t *requestTimer) timeoutError(endpoint, kind string, err error) *TimeoutError {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	since := func(from, to time.Time) time.Duration {
+		if from.IsZero() || to.IsZero() {
+			return 0
+		}
+		return to.Sub(from)
+	}
+	return &TimeoutError{
+		Endpoint:     endpoint,
+		Kind:         kind,
+		Elapsed:      time.Since(t.start),
+		Deadline:     t.deadline,
+		DNS:          since(t.dnsStart, t.dnsDone),
+		Connect:      since(t.connectStart, t.connected),
+		TLSHandshake: since(t.tlsStart, t.tlsDone),
+		RequestSent:  since(t.start, t.wroteRequest),
+		FirstByte:    since(t.start, t.firstByte),
+		err:          err,
+	}
+}
+
+// classify returns the TimeoutError of err, returned by a request to endpoint, or nil if err is not a timeout.
+func (t *requestTimer) classify(endpoint string, err error) error {
+	var ne net.Error
+	if !errors.As(err, &ne) || !ne.Timeout() {
+		if !errors.Is(err, context.DeadlineExceeded) {
+			return nil
+		}
+	}
+	t.mu.Lock()
+	kind := TimeoutClientDeadline
+	switch {
+	case !t.tlsStart.IsZero() && t.tlsDone.IsZero():
+		kind = TimeoutTLSHandshake
+	case t.wroteRequest.IsZero() && t.tlsDone.IsZero() && t.connected.IsZero():
+		kind = TimeoutConnect
+	}
+	t.mu.Unlock()
+	return t.timeoutError(endpoint, kind, err)
+}
+
+// gatewayTimeout returns the TimeoutError of a 504 response from endpoint, or nil for any other status.
+func (t *requestTimer) gatewayTimeout(endpoint string, resp *http.Response) error {
+	if resp.StatusCode != http.StatusGatewayTimeout {
+		return nil
+	}
+	e := t.timeoutError(endpoint, TimeoutGateway, nil)
+	e.Status = resp.Status
+	return e
+}
+
+// timedBody classifies timeouts while reading a response body.
+type timedBody struct {
+	io.ReadCloser
+	timer    *requestTimer
+	endpoint string
+}
+
+func (b *timedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && err != io.EOF {
+		if te := b.timer.classify(b.endpoint, err); te != nil {
+			return n, te
+		}
+	}
+	return n, err
+}
+
+// code returns the finding code of e: a client deadline is reported apart whether the first byte of the response was
+// received or not.
+func (e *TimeoutError) code() string {
+	switch e.Kind {
+	case TimeoutConnect:
+		return utils.CodeTimeoutConnect
+	case TimeoutTLSHandshake:
+		return utils.CodeTimeoutTLS
+	case TimeoutGateway:
+		return utils.CodeTimeoutGateway
+	}
+	if e.FirstByte == 0 {
+		return utils.CodeTimeoutFirstByte
+	}
+	return utils.CodeTimeoutTotal
+}
+
+// timeoutFindings returns an Error finding with the timings of err if it is a TimeoutError.
+func timeoutFindings(err error) []utils.Finding {
+	var te *TimeoutError
+	if !errors.As(err, &te) {
+		return nil
+	}
+	return []utils.Finding{{Severity: utils.Error, Field: "timeout > " + te.Kind, Message: te.Error(), Code: te.code()}}
+}
+
+// sendFindings returns the findings of a request that failed with err.
+func sendFindings(err error) []utils.Finding {
+	return append(statusFindings(err), timeoutFindings(err)...)
+}
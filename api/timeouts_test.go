@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+func TestTimeoutClassification(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer slow.Close()
+	slowBody := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{"))
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer slowBody.Close()
+	gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "upstream timed out", http.StatusGatewayTimeout)
+	}))
+	defer gateway.Close()
+	// A listener that accepts connections but never answers the TLS handshake.
+	silent, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer silent.Close()
+	go func() {
+		for {
+			c, err := silent.Accept()
+			if err != nil {
+				return
+			}
+			defer c.Close()
+		}
+	}()
+
+	blackhole := &http.Transport{DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}}
+	cases := []struct {
+		name          string
+		baseURL       string
+		transport     http.RoundTripper
+		wantKind      string
+		wantCode      string
+		wantTransport bool
+	}{
+		{name: "connect", baseURL: "http://partner.example.com", transport: blackhole, wantKind: TimeoutConnect, wantCode: utils.CodeTimeoutConnect, wantTransport: true},
+		{name: "tls handshake", baseURL: "https://" + silent.Addr().String(), transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}, wantKind: TimeoutTLSHandshake, wantCode: utils.CodeTimeoutTLS, wantTransport: true},
+		{name: "client deadline", baseURL: slow.URL, transport: http.DefaultTransport, wantKind: TimeoutClientDeadline, wantCode: utils.CodeTimeoutFirstByte, wantTransport: true},
+		{name: "client deadline after first byte", baseURL: slowBody.URL, transport: http.DefaultTransport, wantKind: TimeoutClientDeadline, wantCode: utils.CodeTimeoutTotal, wantTransport: true},
+		{name: "gateway", baseURL: gateway.URL, transport: http.DefaultTransport, wantKind: TimeoutGateway, wantCode: utils.CodeTimeoutGateway},
+	}
+	for _, tc := range cases {
+		conn := &HTTPConnection{
+			client:    &http.Client{Timeout: 100 * time.Millisecond, Transport: tc.transport},
+			marshaler: &jsonpb.Marshaler{OrigName: true},
+			baseURL:   tc.baseURL,
+		}
+		_, err := sendRequest("/v1/BookingAvailability", "{}", conn)
+		var te *TimeoutError
+		if !errors.As(err, &te) || !errors.Is(err, ErrTimeout) {
+			t.Errorf("%s: sendRequest() returned %v, want a TimeoutError", tc.name, err)
+			continue
+		}
+		if te.Kind != tc.wantKind || errors.Is(err, ErrTransport) != tc.wantTransport {
+			t.Errorf("%s: sendRequest() returned %s timeout, transport error %v, want %s and %v", tc.name, te.Kind, errors.Is(err, ErrTransport), tc.wantKind, tc.wantTransport)
+		}
+		findings := sendFindings(err)
+		if len(findings) != 1 || findings[0].Field != "timeout > "+tc.wantKind || findings[0].Code != tc.wantCode || !strings.Contains(findings[0].Message, "after") {
+			t.Errorf("%s: sendFindings() = %v, want a %s timeout > %s finding with timings", tc.name, findings, tc.wantCode, tc.wantKind)
+		}
+	}
+
+	// A 504 set as the expected status is accepted.
+	conn := &HTTPConnection{client: gateway.Client(), marshaler: &jsonpb.Marshaler{OrigName: true}, baseURL: gateway.URL}
+	conn.SetExpectedStatus("/v1/BookingAvailability", http.StatusGatewayTimeout)
+	if _, err := sendRequest("/v1/BookingAvailability", "{}", conn); err != nil {
+		t.Errorf("sendRequest() with expected status 504 returned %v, want nil", err)
+	}
+}
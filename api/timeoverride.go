@@ -0,0 +1,53 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+// SetTimeOverrideHeader sets the header, e.g. X-Sandbox-Time, in which OverrideTime sends the faked current time to
+// partner sandboxes supporting one, for testing time-dependent policies such as cancellation deadlines.
+func (h *HTTPConnection) SetTimeOverrideHeader(name string) {
+	h.timeHeader = name
+}
+
+// OverrideTime sends t as an RFC 3339 timestamp in the time override header of every following request, and fakes
+// the current time of validation to t, so that cancellation deadlines, expired cards and past dates are relative to
+// the time of the sandbox. The zero time removes the header and restores the real time. It fails if no time override
+// header is set.
+func (h *HTTPConnection) OverrideTime(t time.Time) error {
+	if h.timeHeader == "" {
+		return errors.New("no time override header set")
+	}
+	if t.IsZero() {
+		h.SetHeader(h.timeHeader, "")
+	} else {
+		h.SetHeader(h.timeHeader, t.Format(time.RFC3339))
+	}
+	h.fakedTime = t
+	utils.SetNow(t)
+	return nil
+}
+
+// OverriddenTime returns the time set by OverrideTime, or the zero time if the real time is used.
+func (h *HTTPConnection) OverriddenTime() time.Time {
+	return h.fakedTime
+}
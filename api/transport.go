@@ -0,0 +1,154 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+// TransportProbes is the number of requests CheckTransport sends to each endpoint. The first request pays for the
+// connection, and the others show whether it is reused.
+const TransportProbes = 3
+
+// SlowTransportLatency is the median time to the first response byte above which an endpoint is reported as slow.
+var SlowTransportLatency = 2 * time.Second
+
+// TransportProbe is the outcome of a single request sent by CheckTransport.
+type TransportProbe struct {
+	Endpoint string `json:"endpoint"`
+	// Status is the HTTP status of the response, empty if none was received.
+	Status string `json:"status,omitempty"`
+	// Connect and TLSHandshake are zero if the connection was reused, see TimeoutError.
+	DNS          time.Duration `json:"dns"`
+	Connect      time.Duration `json:"connect"`
+	TLSHandshake time.Duration `json:"tls_handshake"`
+	FirstByte    time.Duration `json:"first_byte"`
+	Error        string        `json:"error,omitempty"`
+}
+
+// probeBody returns the json request sent to endpoint by CheckTransport: an empty object, with a placeholder for
+// every template variable of the endpoint so its path can be expanded.
+func probeBody(endpoint string) string {
+	fields := map[string]interface{}{}
+	for _, m := range endpointVariable.FindAllStringSubmatch(endpoint, -1) {
+		path := strings.Split(m[1], ".")
+		parent := fields
+		for _, name := range path[:len(path)-1] {
+			child, ok := parent[name].(map[string]interface{})
+			if !ok {
+				child = map[string]interface{}{}
+				parent[name] = child
+			}
+			parent = child
+		}
+		parent[path[len(path)-1]] = "probe"
+	}
+	body, _ := json.Marshal(fields)
+	return string(body)
+}
+
+// sendProbe sends the probe request of endpoint and records the phases of the request, whatever its outcome.
+func sendProbe(conn *HTTPConnection, endpoint string) (TransportProbe, error) {
+	resp, timer, err := openTimedRequest(endpoint, probeBody(endpoint), conn)
+	if resp != nil {
+		io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	p := TransportProbe{Endpoint: endpoint}
+	if timer != nil {
+		phases := timer.timeoutError(endpoint, "", nil)
+		p.DNS, p.Connect, p.TLSHandshake, p.FirstByte = phases.DNS, phases.Connect, phases.TLSHandshake, phases.FirstByte
+	}
+	if resp != nil {
+		p.Status = resp.Status
+	}
+	if err != nil {
+		p.Error = err.Error()
+	}
+	return p, err
+}
+
+// CheckTransport sends TransportProbes requests without a payload to each of endpoints, verifying the partner is
+// reachable, completes the TLS handshake and accepts the credentials, before any request file exists. The partner
+// is expected to reject the empty request; any status other than an authentication or throttling status passes. A
+// request that fails to connect, or whose credentials are rejected, is an Error finding, and throttling or a median
+// time to the first byte above SlowTransportLatency is a Warning. An error is returned if any request failed.
+func CheckTransport(conn *HTTPConnection, endpoints []string) ([]TransportProbe, []utils.Finding, error) {
+	var probes []TransportProbe
+	var findings []utils.Finding
+	var failed []string
+	for _, endpoint := range endpoints {
+		var latencies []time.Duration
+		var endpointErr error
+		for i := 0; i < TransportProbes; i++ {
+			p, err := sendProbe(conn, endpoint)
+			probes = append(probes, p)
+			log.Printf("Transport probe %d of %s: status %q, dns %v, connect %v, tls handshake %v, first byte at %v", i+1, endpoint, p.Status, p.DNS, p.Connect, p.TLSHandshake, p.FirstByte)
+			if p.FirstByte > 0 {
+				latencies = append(latencies, p.FirstByte)
+			}
+			if err == nil {
+				continue
+			}
+			var f []utils.Finding
+			switch {
+			case errors.Is(err, ErrAuth):
+				f = []utils.Finding{{Severity: utils.Error, Field: "transport > " + endpoint, Message: fmt.Sprintf("credentials were rejected: %v", err), Code: utils.CodeTransportAuth}}
+			case errors.Is(err, ErrThrottled):
+				f = []utils.Finding{{Severity: utils.Warning, Field: "transport > " + endpoint, Message: fmt.Sprintf("the probe was throttled: %v", err), Code: utils.CodeTransportThrottled}}
+			default:
+				if f = sendFindings(err); len(f) == 0 {
+					f = []utils.Finding{{Severity: utils.Error, Field: "transport > " + endpoint, Message: err.Error(), Code: utils.CodeTransportFailed}}
+				}
+			}
+			for _, finding := range f {
+				log.Println(finding)
+			}
+			findings = append(findings, f...)
+			if !errors.Is(err, ErrThrottled) {
+				endpointErr = err
+			}
+		}
+		if endpointErr != nil {
+			failed = append(failed, endpoint)
+			continue
+		}
+		if len(latencies) == 0 {
+			continue
+		}
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		if median := latencies[len(latencies)/2]; median > SlowTransportLatency {
+			f := utils.Finding{Severity: utils.Warning, Field: "transport > " + endpoint, Message: fmt.Sprintf("median time to the first byte of %v is above %v", median.Round(time.Millisecond), SlowTransportLatency), Code: utils.CodeTransportSlow}
+			log.Println(f)
+			findings = append(findings, f)
+		}
+	}
+	if len(failed) > 0 {
+		return probes, findings, fmt.Errorf("transport probes failed for %s", strings.Join(failed, ", "))
+	}
+	return probes, findings, nil
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+func TestProbeBody(t *testing.T) {
+	cases := []struct {
+		endpoint string
+		want     string
+	}{
+		{endpoint: "/v1/BookingAvailability", want: "{}"},
+		{endpoint: "/v1/hotels/{hotel_id}/rooms/{party.adults}", want: `{"hotel_id":"probe","party":{"adults":"probe"}}`},
+	}
+	for _, tc := range cases {
+		if got := probeBody(tc.endpoint); got != tc.want {
+			t.Errorf("probeBody(%q) = %s, want %s", tc.endpoint, got, tc.want)
+		}
+		if _, err := expandEndpoint(tc.endpoint, probeBody(tc.endpoint)); err != nil {
+			t.Errorf("expandEndpoint(%q) of the probe body returned error: %v", tc.endpoint, err)
+		}
+	}
+}
+
+func TestCheckTransport(t *testing.T) {
+	cases := []struct {
+		name      string
+		status    int
+		wantCodes []string
+		wantErr   bool
+	}{
+		{name: "empty request rejected", status: http.StatusBadRequest},
+		{name: "credentials rejected", status: http.StatusUnauthorized, wantCodes: []string{utils.CodeTransportAuth, utils.CodeTransportAuth, utils.CodeTransportAuth}, wantErr: true},
+		{name: "throttled", status: http.StatusTooManyRequests, wantCodes: []string{utils.CodeTransportThrottled, utils.CodeTransportThrottled, utils.CodeTransportThrottled}},
+		{name: "status expectation", status: http.StatusInternalServerError, wantCodes: []string{utils.CodeHTTPStatus, utils.CodeHTTPStatus, utils.CodeHTTPStatus}, wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+			conn := &HTTPConnection{client: server.Client(), baseURL: server.URL}
+			if tc.status == http.StatusInternalServerError {
+				conn.SetExpectedStatus("/v1/BookingAvailability", http.StatusBadRequest)
+			}
+
+			probes, findings, err := CheckTransport(conn, []string{"/v1/BookingAvailability"})
+			if (err != nil) != tc.wantErr {
+				t.Errorf("CheckTransport() returned error %v, want error %v", err, tc.wantErr)
+			}
+			var codes []string
+			for _, f := range findings {
+				codes = append(codes, f.Code)
+			}
+			if diff := cmp.Diff(codes, tc.wantCodes); diff != "" {
+				t.Errorf("CheckTransport() returned finding codes that differ (-got +want):\n%s", diff)
+			}
+			if len(probes) != TransportProbes {
+				t.Fatalf("CheckTransport() returned %d probes, want %d", len(probes), TransportProbes)
+			}
+			if probes[0].TLSHandshake == 0 || probes[0].FirstByte == 0 {
+				t.Errorf("CheckTransport() first probe %+v has no tls handshake or first byte timing", probes[0])
+			}
+		})
+	}
+}
+
+func TestCheckTransportUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	conn := &HTTPConnection{client: server.Client(), baseURL: server.URL}
+	server.Close()
+
+	_, findings, err := CheckTransport(conn, []string{"/v1/BookingSubmit"})
+	if err == nil {
+		t.Error("CheckTransport() of a closed server returned no error, want an error")
+	}
+	if len(findings) != TransportProbes || findings[0].Code != utils.CodeTransportFailed {
+		t.Errorf("CheckTransport() of a closed server returned findings %v, want %d %s findings", findings, TransportProbes, utils.CodeTransportFailed)
+	}
+}
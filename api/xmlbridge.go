@@ -0,0 +1,138 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// XMLPayloadPlaceholder marks where the json request is inserted in the envelope of SetXMLBridge.
+const XMLPayloadPlaceholder = "{{payload}}"
+
+// XMLContentType is the Content-Type of requests sent through the XML bridge.
+const XMLContentType = "text/xml; charset=utf-8"
+
+// xmlBridge wraps json requests in an XML envelope and extracts the json response from the text of an element.
+type xmlBridge struct {
+	envelope string
+	element  string
+}
+
+// SetXMLBridge is experimental. It sends every request wrapped in envelope, an XML document such as a SOAP envelope,
+// in place of its XMLPayloadPlaceholder, with the json escaped as XML text, and reads the json response from the text
+// or CDATA of the first element of the response named element, ignoring its namespace. Everything else, from
+// endpoint templates to validation, is unchanged, so gateways of partners migrating from XML can be validated as
+// is. An empty envelope removes the bridge.
+func (h *HTTPConnection) SetXMLBridge(envelope, element string) error {
+	if envelope == "" {
+		h.bridge = nil
+		return nil
+	}
+	if strings.Count(envelope, XMLPayloadPlaceholder) != 1 {
+		return fmt.Errorf("xml envelope must contain %s exactly once", XMLPayloadPlaceholder)
+	}
+	if element == "" {
+		return fmt.Errorf("xml envelope needs the name of the response element holding the json")
+	}
+	h.bridge = &xmlBridge{envelope: envelope, element: element}
+	return nil
+}
+
+// wrapRequest returns the body sent for the json encoded req: req itself, or its XML envelope if a bridge is set.
+func (h *HTTPConnection) wrapRequest(req string) string {
+	if h.bridge == nil {
+		return req
+	}
+	var escaped bytes.Buffer
+	xml.EscapeText(&escaped, []byte(req))
+	return strings.Replace(h.bridge.envelope, XMLPayloadPlaceholder, escaped.String(), 1)
+}
+
+// unwrapResponse returns body, or a body reading the json extracted from its XML envelope if a bridge is set.
+func (h *HTTPConnection) unwrapResponse(endpoint string, body io.ReadCloser) io.ReadCloser {
+	if h.bridge == nil {
+		return body
+	}
+	return &bridgedBody{ReadCloser: body, bridge: h.bridge, endpoint: endpoint}
+}
+
+// bridgedBody reads the whole XML response on the first Read and then serves the json it contains.
+type bridgedBody struct {
+	io.ReadCloser
+	bridge   *xmlBridge
+	endpoint string
+	json     io.Reader
+}
+
+func (b *bridgedBody) Read(p []byte) (int, error) {
+	if b.json == nil {
+		envelope, err := ioutil.ReadAll(b.ReadCloser)
+		if err != nil {
+			return 0, err
+		}
+		payload, err := b.bridge.extract(envelope)
+		if err != nil {
+			return 0, fmt.Errorf("%w: %s response: %v", ErrParse, b.endpoint, err)
+		}
+		b.json = strings.NewReader(payload)
+	}
+	return b.json.Read(p)
+}
+
+// soapFault is the fault of a SOAP 1.1 or 1.2 envelope.
+type soapFault struct {
+	String string `xml:"faultstring"`
+	Reason string `xml:"Reason>Text"`
+}
+
+// extract returns the text of the first element of envelope named b.element. A SOAP fault in its place is returned as
+// an error.
+func (b *xmlBridge) extract(envelope []byte) (string, error) {
+	d := xml.NewDecoder(bytes.NewReader(envelope))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return "", fmt.Errorf("no <%s> element in the xml envelope", b.element)
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid xml envelope: %v", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case b.element:
+			var text string
+			if err := d.DecodeElement(&text, &start); err != nil {
+				return "", fmt.Errorf("invalid <%s> element: %v", b.element, err)
+			}
+			return strings.TrimSpace(text), nil
+		case "Fault":
+			var f soapFault
+			if err := d.DecodeElement(&f, &start); err != nil {
+				return "", fmt.Errorf("invalid SOAP fault: %v", err)
+			}
+			return "", fmt.Errorf("SOAP fault: %s", strings.TrimSpace(f.String+f.Reason))
+		}
+	}
+}
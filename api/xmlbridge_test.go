@@ -0,0 +1,83 @@
+package api
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+const testEnvelope = `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><Request><json>{{payload}}</json></Request></soap:Body></soap:Envelope>`
+
+func TestSetXMLBridge(t *testing.T) {
+	data, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name     string
+		response string
+		wantErr  error
+	}{
+		{
+			name:     "cdata",
+			response: `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><Response><ns:json xmlns:ns="urn:partner"><![CDATA[%s]]></ns:json></Response></soap:Body></soap:Envelope>`,
+		},
+		{
+			name:     "missing element",
+			response: `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><Response/></soap:Body></soap:Envelope><!-- %s -->`,
+			wantErr:  ErrParse,
+		},
+		{
+			name:     "fault",
+			response: `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault><faultstring>gateway down</faultstring></soap:Fault></soap:Body></soap:Envelope><!-- %s -->`,
+			wantErr:  ErrParse,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Content-Type"); got != XMLContentType {
+					t.Errorf("request Content-Type = %q, want %q", got, XMLContentType)
+				}
+				var envelope struct {
+					JSON string `xml:"Body>Request>json"`
+				}
+				if err := xml.NewDecoder(r.Body).Decode(&envelope); err != nil {
+					t.Fatalf("request is not an xml envelope: %v", err)
+				}
+				var req pb.BookingAvailabilityRequest
+				if err := jsonpb.UnmarshalString(envelope.JSON, &req); err != nil || req.GetHotelId() != data.ReqPb.GetHotelId() {
+					t.Errorf("request envelope holds %q, want the json request", envelope.JSON)
+				}
+				fmt.Fprintf(w, tc.response, data.Resp)
+			}))
+			defer server.Close()
+			conn := &HTTPConnection{client: server.Client(), marshaler: &jsonpb.Marshaler{OrigName: true}, baseURL: server.URL}
+			if err := conn.SetXMLBridge(testEnvelope, "json"); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := BookingAvailability(data.ReqPb, conn, "/v1/BookingAvailability")
+			if tc.wantErr == nil && err != nil {
+				t.Errorf("BookingAvailability() through the bridge returned error %v", err)
+			}
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Errorf("BookingAvailability() through the bridge returned error %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+
+	conn := &HTTPConnection{}
+	if err := conn.SetXMLBridge("<Envelope/>", "json"); err == nil {
+		t.Error("SetXMLBridge() accepted an envelope without a placeholder")
+	}
+}
@@ -0,0 +1,222 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package builders constructs BookingService requests fluently, as an alternative to editing json fixtures:
+//
+//	req := builders.NewAvailabilityRequest().WithHotel("h1").WithStay(in, out).WithParty(2, 1).Build()
+package builders
+
+import (
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// DefaultChildAge is the age of the children added by WithParty.
+const DefaultChildAge = 8
+
+// party returns an Occupancy of adults and children aged DefaultChildAge.
+func party(adults, children int) *pb.Occupancy {
+	o := &pb.Occupancy{Adults: int32(adults)}
+	for i := 0; i < children; i++ {
+		o.Children = append(o.Children, DefaultChildAge)
+	}
+	return o
+}
+
+// transactionID returns id, or a new random one if it is empty, drawn from the seed of utils.SetSeed if set.
+func transactionID(id string) string {
+	if id != "" {
+		return id
+	}
+	// NewUUID only fails if the system has no source of randomness, leaving the transaction_id to be set explicitly.
+	id, _ = utils.NewUUID()
+	return id
+}
+
+// AvailabilityRequestBuilder builds a BookingAvailabilityRequest. The zero value is not usable; start from
+// NewAvailabilityRequest.
+type AvailabilityRequestBuilder struct {
+	req *pb.BookingAvailabilityRequest
+}
+
+// NewAvailabilityRequest returns a builder of a request for 2 adults from the US, in English and USD, on desktop.
+// Every other field, including the hotel and stay dates, must be set by the caller.
+func NewAvailabilityRequest() *AvailabilityRequestBuilder {
+	return &AvailabilityRequestBuilder{&pb.BookingAvailabilityRequest{
+		ApiVersion:  utils.DefaultAPIVersion,
+		Party:       party(2, 0),
+		Language:    "en",
+		Currency:    "USD",
+		UserCountry: "US",
+		DeviceType:  pb.BookingAvailabilityRequest_DESKTOP,
+	}}
+}
+
+// WithTransactionID sets the transaction_id. By default a random one is generated by Build.
+func (b *AvailabilityRequestBuilder) WithTransactionID(id string) *AvailabilityRequestBuilder {
+	b.req.TransactionId = id
+	return b
+}
+
+// WithAPIVersion sets the api_version, which also selects the date format used by WithStay.
+func (b *AvailabilityRequestBuilder) WithAPIVersion(v int32) *AvailabilityRequestBuilder {
+	b.req.ApiVersion = v
+	return b
+}
+
+// WithHotel sets the partner hotel_id.
+func (b *AvailabilityRequestBuilder) WithHotel(id string) *AvailabilityRequestBuilder {
+	b.req.HotelId = id
+	return b
+}
+
+// WithStay sets start_date and end_date to the check-in and check-out days.
+func (b *AvailabilityRequestBuilder) WithStay(checkIn, checkOut time.Time) *AvailabilityRequestBuilder {
+	layout := utils.FormatsFor(b.req.GetApiVersion()).DateLayout
+	b.req.StartDate, b.req.EndDate = checkIn.Format(layout), checkOut.Format(layout)
+	return b
+}
+
+// WithParty sets the party to adults and children aged DefaultChildAge.
+func (b *AvailabilityRequestBuilder) WithParty(adults, children int) *AvailabilityRequestBuilder {
+	b.req.Party = party(adults, children)
+	return b
+}
+
+// WithChildAges replaces the children of the party with children of the given ages.
+func (b *AvailabilityRequestBuilder) WithChildAges(ages ...int32) *AvailabilityRequestBuilder {
+	b.req.Party.Children = append([]int32(nil), ages...)
+	return b
+}
+
+// WithLocale sets the language, currency and user_country of the request.
+func (b *AvailabilityRequestBuilder) WithLocale(language, currency, userCountry string) *AvailabilityRequestBuilder {
+	b.req.Language, b.req.Currency, b.req.UserCountry = language, currency, userCountry
+	return b
+}
+
+// Build returns the request. The builder can be reused: later changes do not affect requests already built.
+func (b *AvailabilityRequestBuilder) Build() *pb.BookingAvailabilityRequest {
+	req := proto.Clone(b.req).(*pb.BookingAvailabilityRequest)
+	req.TransactionId = transactionID(req.GetTransactionId())
+	return req
+}
+
+// SubmitRequestBuilder builds a BookingSubmitRequest. The zero value is not usable; start from NewSubmitRequest or
+// FromAvailability.
+type SubmitRequestBuilder struct {
+	req *pb.BookingSubmitRequest
+}
+
+// NewSubmitRequest returns a builder of a booking for 2 adults by a placeholder customer from the US, in English,
+// paid by a Visa card with redacted number and cvc. The hotel, stay dates and room rate must be set by the caller.
+func NewSubmitRequest() *SubmitRequestBuilder {
+	return &SubmitRequestBuilder{&pb.BookingSubmitRequest{
+		ApiVersion: utils.DefaultAPIVersion,
+		IpAddress:  "192.0.2.1",
+		Language:   "en",
+		Customer: &pb.Customer{
+			FirstName:   "John",
+			LastName:    "Doe",
+			PhoneNumber: "+1-555-0100",
+			Email:       "john.doe@example.com",
+			Country:     "US",
+		},
+		Traveler: &pb.Traveler{FirstName: "John", LastName: "Doe", Occupancy: party(2, 0)},
+		Payment: &pb.BookingSubmitRequest_Payment{
+			Type: pb.GuaranteeType_PAYMENT_CARD,
+			PaymentCardParameters: &pb.BookingSubmitRequest_Payment_PaymentCardParameters{
+				CardType:        pb.CardType_VI,
+				CardNumber:      "---PAN---",
+				CardholderName:  "John Doe",
+				ExpirationMonth: "12",
+				ExpirationYear:  "2030",
+				Cvc:             "---CVN---",
+			},
+		},
+	}}
+}
+
+// FromAvailability returns a builder like NewSubmitRequest that books the hotel, stay and party of req, in its
+// language if it has one.
+func FromAvailability(req *pb.BookingAvailabilityRequest) *SubmitRequestBuilder {
+	b := NewSubmitRequest()
+	b.req.ApiVersion = req.GetApiVersion()
+	b.req.HotelId = req.GetHotelId()
+	b.req.StartDate, b.req.EndDate = req.GetStartDate(), req.GetEndDate()
+	if req.GetLanguage() != "" {
+		b.req.Language = req.GetLanguage()
+	}
+	if req.GetParty() != nil {
+		b.req.Traveler.Occupancy = proto.Clone(req.GetParty()).(*pb.Occupancy)
+	}
+	return b
+}
+
+// WithTransactionID sets the transaction_id. By default a random one is generated by Build.
+func (b *SubmitRequestBuilder) WithTransactionID(id string) *SubmitRequestBuilder {
+	b.req.TransactionId = id
+	return b
+}
+
+// WithHotel sets the partner hotel_id.
+func (b *SubmitRequestBuilder) WithHotel(id string) *SubmitRequestBuilder {
+	b.req.HotelId = id
+	return b
+}
+
+// WithStay sets start_date and end_date to the check-in and check-out days.
+func (b *SubmitRequestBuilder) WithStay(checkIn, checkOut time.Time) *SubmitRequestBuilder {
+	layout := utils.FormatsFor(b.req.GetApiVersion()).DateLayout
+	b.req.StartDate, b.req.EndDate = checkIn.Format(layout), checkOut.Format(layout)
+	return b
+}
+
+// WithParty sets the traveler's occupancy to adults and children aged DefaultChildAge.
+func (b *SubmitRequestBuilder) WithParty(adults, children int) *SubmitRequestBuilder {
+	b.req.Traveler.Occupancy = party(adults, children)
+	return b
+}
+
+// WithCustomer sets the name and email of the customer placing the booking.
+func (b *SubmitRequestBuilder) WithCustomer(firstName, lastName, email string) *SubmitRequestBuilder {
+	b.req.Customer.FirstName, b.req.Customer.LastName, b.req.Customer.Email = firstName, lastName, email
+	return b
+}
+
+// WithTraveler sets the name of the primary traveler.
+func (b *SubmitRequestBuilder) WithTraveler(firstName, lastName string) *SubmitRequestBuilder {
+	b.req.Traveler.FirstName, b.req.Traveler.LastName = firstName, lastName
+	return b
+}
+
+// WithRoomRate books a copy of rate, typically one of the room_rates of a BookingAvailabilityResponse.
+func (b *SubmitRequestBuilder) WithRoomRate(rate *pb.RoomRate) *SubmitRequestBuilder {
+	b.req.RoomRate = proto.Clone(rate).(*pb.RoomRate)
+	return b
+}
+
+// Build returns the request. The builder can be reused: later changes do not affect requests already built.
+func (b *SubmitRequestBuilder) Build() *pb.BookingSubmitRequest {
+	req := proto.Clone(b.req).(*pb.BookingSubmitRequest)
+	req.TransactionId = transactionID(req.GetTransactionId())
+	return req
+}
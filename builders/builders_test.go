@@ -0,0 +1,64 @@
+package builders
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestAvailabilityRequest(t *testing.T) {
+	data, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := NewAvailabilityRequest().
+		WithTransactionID(data.ReqPb.GetTransactionId()).
+		WithHotel("123").
+		WithStay(time.Date(2019, 4, 3, 0, 0, 0, 0, time.UTC), time.Date(2019, 4, 5, 0, 0, 0, 0, time.UTC)).
+		WithParty(2, 1).
+		WithChildAges(7)
+	got := b.Build()
+
+	want := proto.Clone(data.ReqPb).(*pb.BookingAvailabilityRequest)
+	want.Tracking = nil
+	if diff := cmp.Diff(got, want, cmp.Comparer(proto.Equal)); diff != "" {
+		t.Errorf("Build() did not match the sample request (-got +want)\n%s", diff)
+	}
+
+	b.WithParty(1, 0)
+	if got.GetParty().GetAdults() != 2 {
+		t.Error("Build() returned a request changed by later calls to the builder")
+	}
+}
+
+func TestSubmitRequest(t *testing.T) {
+	availability, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := FromAvailability(availability.ReqPb).WithRoomRate(availability.RespPb.GetRoomRates()[0]).Build()
+	if got.GetTransactionId() == "" {
+		t.Error("Build() returned an empty transaction_id, want a random one")
+	}
+	if got.GetHotelId() != "123" || got.GetStartDate() != "2019-04-03" || got.GetEndDate() != "2019-04-05" {
+		t.Errorf("FromAvailability() did not copy hotel and stay: %v", got)
+	}
+	if diff := cmp.Diff(got.GetTraveler().GetOccupancy(), availability.ReqPb.GetParty(), cmp.Comparer(proto.Equal)); diff != "" {
+		t.Errorf("FromAvailability() did not copy the party (-got +want)\n%s", diff)
+	}
+	if got.GetRoomRate().GetCode() != availability.RespPb.GetRoomRates()[0].GetCode() {
+		t.Errorf("WithRoomRate() booked %q, want %q", got.GetRoomRate().GetCode(), availability.RespPb.GetRoomRates()[0].GetCode())
+	}
+
+	req := proto.Clone(availability.ReqPb).(*pb.BookingAvailabilityRequest)
+	req.Language = ""
+	if got := FromAvailability(req).Build().GetLanguage(); got != "en" {
+		t.Errorf("FromAvailability() of a request without language booked in %q, want en", got)
+	}
+}
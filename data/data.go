@@ -0,0 +1,26 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package data embeds the sample requests and responses in the binary, so that the self-test and the mock partner
+// find them wherever the validator is installed.
+package data
+
+import "embed"
+
+// Samples holds the sample BookingAvailability and BookingSubmit requests and responses, by file name.
+//
+//go:embed BookingAvailabilityRequest.json BookingAvailabilityResponse.json BookingSubmitRequest.json BookingSubmitResponse.json
+var Samples embed.FS
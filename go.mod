@@ -0,0 +1,20 @@
+module github.com/google/hotel-booking-api-validator
+
+go 1.22.0
+
+require (
+	github.com/golang/protobuf v1.5.4
+	github.com/google/cel-go v0.26.1
+	github.com/google/go-cmp v0.6.0
+	golang.org/x/net v0.30.0
+	google.golang.org/protobuf v1.36.0
+)
+
+require (
+	cel.dev/expr v0.24.0 // indirect
+	github.com/antlr4-go/antlr/v4 v4.13.0 // indirect
+	github.com/stoewer/go-strcase v1.2.0 // indirect
+	golang.org/x/exp v0.0.0-20230515195305-f3d0a9c9a5cc // indirect
+	google.golang.org/genproto/googleapis/api v0.0.0-20240826202546-f6391c0de4c7 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240826202546-f6391c0de4c7 // indirect
+)
@@ -0,0 +1,158 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package mock implements a partner BookingService that echoes sample responses, with optional fault injection.
+package mock
+
+import (
+	"log"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// Faults configures the misbehaviour injected into responses. Rates are probabilities between 0 and 1, rolled
+// independently for every request in the order listed.
+type Faults struct {
+	// Latency delays every response.
+	Latency time.Duration
+	// ErrorRate is the share of requests answered with 500 Internal Server Error.
+	ErrorRate float64
+	// TruncateRate is the share of responses cut off halfway through the body.
+	TruncateRate float64
+	// InvalidJSONRate is the share of responses with a body that is not valid json.
+	InvalidJSONRate float64
+	// Seed seeds the choice of the responses faults are injected into, so that the same requests get the same faults
+	// again. A zero seed is derived from the clock.
+	Seed int64
+}
+
+// Server is a mock partner serving BookingAvailability and BookingSubmit. Responses are copies of the templates with
+// the echo fields of each request filled in, so well-formed requests pass validation unless a fault is injected.
+type Server struct {
+	availability *pb.BookingAvailabilityResponse
+	submit       *pb.BookingSubmitResponse
+	faults       Faults
+	marshaler    *jsonpb.Marshaler
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// New returns a Server answering with copies of availability and submit, injecting faults.
+func New(availability *pb.BookingAvailabilityResponse, submit *pb.BookingSubmitResponse, faults Faults) *Server {
+	seed := faults.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Server{
+		availability: availability,
+		submit:       submit,
+		faults:       faults,
+		marshaler:    &jsonpb.Marshaler{OrigName: true},
+		rand:         rand.New(rand.NewSource(seed)),
+	}
+}
+
+// roll reports whether an event with probability p happens.
+func (s *Server) roll(p float64) bool {
+	if p <= 0 {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rand.Float64() < p
+}
+
+func (s *Server) availabilityResponse(req *pb.BookingAvailabilityRequest) proto.Message {
+	resp := proto.Clone(s.availability).(*pb.BookingAvailabilityResponse)
+	resp.TransactionId = req.GetTransactionId()
+	resp.HotelId = req.GetHotelId()
+	resp.StartDate = req.GetStartDate()
+	resp.EndDate = req.GetEndDate()
+	resp.Party = req.GetParty()
+	return resp
+}
+
+func (s *Server) submitResponse(req *pb.BookingSubmitRequest) proto.Message {
+	resp := proto.Clone(s.submit).(*pb.BookingSubmitResponse)
+	resp.TransactionId = req.GetTransactionId()
+	if resp.Reservation == nil {
+		resp.Reservation = &pb.BookingSubmitResponse_Reservation{}
+	}
+	// The locator is derived from the transaction_id so replayed bookings are deduplicated.
+	resp.Reservation.Locator = &pb.BookingSubmitResponse_Reservation_Locator{Id: "MOCK-" + req.GetTransactionId()}
+	resp.Reservation.HotelId = req.GetHotelId()
+	resp.Reservation.StartDate = req.GetStartDate()
+	resp.Reservation.EndDate = req.GetEndDate()
+	resp.Reservation.Customer = req.GetCustomer()
+	resp.Reservation.Traveler = req.GetTraveler()
+	resp.Reservation.RoomRate = req.GetRoomRate()
+	return resp
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var build func() proto.Message
+	switch r.URL.Path {
+	case "/v1/BookingAvailability":
+		var req pb.BookingAvailabilityRequest
+		if err := jsonpb.Unmarshal(r.Body, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		build = func() proto.Message { return s.availabilityResponse(&req) }
+	case "/v1/BookingSubmit":
+		var req pb.BookingSubmitRequest
+		if err := jsonpb.Unmarshal(r.Body, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		build = func() proto.Message { return s.submitResponse(&req) }
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	if s.faults.Latency > 0 {
+		time.Sleep(s.faults.Latency)
+	}
+	if s.roll(s.faults.ErrorRate) {
+		log.Printf("Injecting 500 into %s", r.URL.Path)
+		http.Error(w, "injected fault", http.StatusInternalServerError)
+		return
+	}
+	body, err := s.marshaler.MarshalToString(build())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if s.roll(s.faults.TruncateRate) {
+		log.Printf("Injecting truncated body into %s", r.URL.Path)
+		body = body[:len(body)/2]
+	}
+	if s.roll(s.faults.InvalidJSONRate) {
+		log.Printf("Injecting invalid json into %s", r.URL.Path)
+		body = "{\"api_version\": 1,, " + body[1:]
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(body))
+}
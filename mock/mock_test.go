@@ -0,0 +1,47 @@
+package mock
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+func TestServer(t *testing.T) {
+	availability, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	submit, err := utils.BookingSubmitData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name    string
+		faults  Faults
+		wantErr error
+	}{
+		{name: "no faults"},
+		{name: "500", faults: Faults{ErrorRate: 1}, wantErr: api.ErrParse},
+		{name: "truncated", faults: Faults{TruncateRate: 1}, wantErr: api.ErrParse},
+		{name: "invalid json", faults: Faults{InvalidJSONRate: 1}, wantErr: api.ErrParse},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(New(availability.RespPb, submit.RespPb, tc.faults))
+			defer server.Close()
+			conn, err := api.InitHTTPConnection(strings.TrimPrefix(server.URL, "http://"), "", "", "", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := api.BookingAvailability(availability.ReqPb, conn, "/v1/BookingAvailability"); !errors.Is(err, tc.wantErr) {
+				t.Errorf("BookingAvailability() returned error [%v], want [%v]", err, tc.wantErr)
+			}
+			if _, err := api.BookingSubmit(submit.ReqPb, conn, "/v1/BookingSubmit"); !errors.Is(err, tc.wantErr) {
+				t.Errorf("BookingSubmit() returned error [%v], want [%v]", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+// ghaData and ghaProperty escape the message and property values of GitHub Actions workflow commands.
+var (
+	ghaData     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	ghaProperty = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+)
+
+func writeAnnotation(w io.Writer, level, file, title, message string) error {
+	props := "title=" + ghaProperty.Replace(title)
+	if file != "" {
+		props = "file=" + ghaProperty.Replace(file) + "," + props
+	}
+	_, err := fmt.Fprintf(w, "::%s %s::%s\n", level, props, ghaData.Replace(message))
+	return err
+}
+
+// WriteGitHubAnnotations writes every finding and failed flow of r to w as GitHub Actions workflow commands, so they
+// show up as annotations on the workflow run. files maps flow names to the request file that flow was run with, if
+// any, which the annotations are attached to.
+func (r *Report) WriteGitHubAnnotations(w io.Writer, files map[string]string) error {
+	for _, res := range r.Results {
+		reported := false
+		for _, f := range res.Findings {
+			level := "error"
+			if f.Severity == utils.Warning {
+				level = "warning"
+			} else {
+				reported = true
+			}
+			title := fmt.Sprintf("%s: %s", res.Flow, f.Field)
+			if f.Code != "" {
+				title = fmt.Sprintf("%s %s: %s", res.Flow, f.Code, f.Field)
+			}
+			if err := writeAnnotation(w, level, files[res.Flow], title, f.Message); err != nil {
+				return err
+			}
+		}
+		if !res.Success && !reported {
+			if err := writeAnnotation(w, "error", files[res.Flow], res.Flow+" failed", res.Error); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+func TestWriteGitHubAnnotations(t *testing.T) {
+	r := New("run-1")
+	r.Add("BookingAvailability", "", []utils.Finding{{Severity: utils.Warning, Field: "rate_plans[0]", Message: "short window"}}, nil)
+	r.Add("BookingSubmit", "", []utils.Finding{{Severity: utils.Error, Field: "hotel_id", Message: "did not match (-got +want)\n-1\n+2", Code: utils.CodeEchoMismatch}}, errors.New("Validation error: echo field(s) did not match request: hotel_id"))
+	r.Add("Sweep", "", nil, errors.New("2 of 160 sweep combination(s) failed"))
+
+	var buf bytes.Buffer
+	files := map[string]string{"BookingAvailability": "data/avail.json", "BookingSubmit": "data/submit.json"}
+	if err := r.WriteGitHubAnnotations(&buf, files); err != nil {
+		t.Fatal(err)
+	}
+	want := "::warning file=data/avail.json,title=BookingAvailability%3A rate_plans[0]::short window\n" +
+		"::error file=data/submit.json,title=BookingSubmit ECHO_001%3A hotel_id::did not match (-got +want)%0A-1%0A+2\n" +
+		"::error title=Sweep failed::2 of 160 sweep combination(s) failed\n"
+	if diff := cmp.Diff(buf.String(), want); diff != "" {
+		t.Errorf("WriteGitHubAnnotations() did not match (-got +want)\n%s", diff)
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/google/hotel-booking-api-validator/api"
+)
+
+// Bundle is everything of a run packaged by WriteBundle in addition to the report itself.
+type Bundle struct {
+	// Log is the log output of the run.
+	Log []byte
+	// Requests are the contents of the request files the flows of the run were made with, by file name. Callers
+	// should anonymize them, as bundles are meant to be shared.
+	Requests map[string][]byte
+	// ArtifactDir is the directory of per-run artifacts, whose <ArtifactDir>/<run_id> subdirectory is included.
+	ArtifactDir string
+	// Flags are the command line flags set for the run, with any secret already redacted.
+	Flags map[string]string
+}
+
+// bundleEnvironment describes where a bundled run was made.
+type bundleEnvironment struct {
+	Validator Build             `json:"validator"`
+	GoVersion string            `json:"go_version"`
+	OS        string            `json:"os"`
+	Arch      string            `json:"arch"`
+	Created   time.Time         `json:"created"`
+	Flags     map[string]string `json:"flags,omitempty"`
+}
+
+// bundleTLS is the TLS information of a bundled run.
+type bundleTLS struct {
+	Certificates *api.CertificateReport `json:"certificates,omitempty"`
+	Resumption   *api.ResumptionReport  `json:"resumption,omitempty"`
+}
+
+// WriteBundle writes a zip archive of the run to w, for partners to attach to support escalations: report.json and
+// report.md, the log of the run as run.log, the certificates and session resumption of the server as tls.json,
+// environment.json describing the validator, platform and flags of the run, the request files under requests/ and
+// the saved request and response payloads under artifacts/.
+func (r *Report) WriteBundle(w io.Writer, b Bundle) error {
+	z := zip.NewWriter(w)
+	add := func(name string, write func(io.Writer) error) error {
+		f, err := z.Create(name)
+		if err != nil {
+			return err
+		}
+		if err := write(f); err != nil {
+			return fmt.Errorf("could not bundle %s: %v", name, err)
+		}
+		return nil
+	}
+	addJSON := func(name string, v interface{}) error {
+		return add(name, func(w io.Writer) error {
+			e := json.NewEncoder(w)
+			e.SetIndent("", "  ")
+			return e.Encode(v)
+		})
+	}
+	addFile := func(name, fp string) error {
+		return add(name, func(w io.Writer) error {
+			content, err := ioutil.ReadFile(fp)
+			if err != nil {
+				return err
+			}
+			_, err = w.Write(content)
+			return err
+		})
+	}
+
+	if err := add("report.json", r.WriteJSON); err != nil {
+		return err
+	}
+	if err := add("report.md", func(w io.Writer) error { return r.WriteMarkdown(w, nil) }); err != nil {
+		return err
+	}
+	if err := add("run.log", func(w io.Writer) error { _, err := w.Write(b.Log); return err }); err != nil {
+		return err
+	}
+	if r.TLS != nil || r.Resumption != nil {
+		if err := addJSON("tls.json", bundleTLS{Certificates: r.TLS, Resumption: r.Resumption}); err != nil {
+			return err
+		}
+	}
+	env := bundleEnvironment{
+		Validator: r.Validator,
+		GoVersion: runtime.Version(),
+		OS:        runtime.GOOS,
+		Arch:      runtime.GOARCH,
+		Created:   time.Now().UTC(),
+		Flags:     b.Flags,
+	}
+	if err := addJSON("environment.json", env); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(b.Requests))
+	for name := range b.Requests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		content := b.Requests[name]
+		if err := add(path.Join("requests", name), func(w io.Writer) error { _, err := w.Write(content); return err }); err != nil {
+			return err
+		}
+	}
+
+	if b.ArtifactDir != "" {
+		dir := filepath.Join(b.ArtifactDir, r.RunID)
+		err := filepath.Walk(dir, func(fp string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() {
+				return err
+			}
+			rel, err := filepath.Rel(dir, fp)
+			if err != nil {
+				return err
+			}
+			return addFile(path.Join("artifacts", filepath.ToSlash(rel)), fp)
+		})
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return z.Close()
+}
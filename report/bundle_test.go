@@ -0,0 +1,68 @@
+package report
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/google/hotel-booking-api-validator/api"
+)
+
+func TestWriteBundle(t *testing.T) {
+	dir := t.TempDir()
+	r := New("2f1c7c9e-4b8e-4c2a-9d55-0a7e4d3b6f10")
+	r.TLS = &api.CertificateReport{}
+	r.Add("BookingAvailability", "/v1/BookingAvailability", nil, nil)
+	saved := filepath.Join(dir, "artifacts", r.RunID, "BookingAvailability-response.json")
+	for fp, content := range map[string]string{saved: "{}"} {
+		if err := os.MkdirAll(filepath.Dir(fp), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(fp, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	b := Bundle{Log: []byte("run log\n"), Requests: map[string][]byte{"availability.json": []byte(`{"hotel_id": "123"}`)}, ArtifactDir: filepath.Join(dir, "artifacts"), Flags: map[string]string{"server_addr": "localhost:8080"}}
+	if err := r.WriteBundle(&buf, b); err != nil {
+		t.Fatal(err)
+	}
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{}
+	var names []string
+	for _, f := range z.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name], _ = ioutil.ReadAll(rc)
+		rc.Close()
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+	want := []string{"artifacts/BookingAvailability-response.json", "environment.json", "report.json", "report.md", "requests/availability.json", "run.log", "tls.json"}
+	if diff := cmp.Diff(names, want); diff != "" {
+		t.Errorf("WriteBundle() files differ (-got +want):\n%s", diff)
+	}
+	if got := string(files["run.log"]); got != "run log\n" {
+		t.Errorf("WriteBundle() run.log = %q, want %q", got, "run log\n")
+	}
+	var env bundleEnvironment
+	if err := json.Unmarshal(files["environment.json"], &env); err != nil {
+		t.Fatal(err)
+	}
+	if env.Flags["server_addr"] != "localhost:8080" || env.GoVersion == "" {
+		t.Errorf("WriteBundle() environment.json = %+v, want the flags and Go version of the run", env)
+	}
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MaxSignedURLExpiry is the longest validity of a Cloud Storage V4 signed URL.
+const MaxSignedURLExpiry = 7 * 24 * time.Hour
+
+// gcsEndpoint is the Cloud Storage XML API endpoint, replaced in tests.
+var gcsEndpoint = "https://storage.googleapis.com"
+
+// GCSUploader uploads run artifacts to a Google Cloud Storage bucket and returns V4 signed URLs to read them, so
+// reports of validators run in ephemeral CI containers can still be shared. Uploads are signed with the key of a
+// service account as well, so no other Google Cloud client is needed.
+type GCSUploader struct {
+	bucket string
+	prefix string
+	email  string
+	key    *rsa.PrivateKey
+	expiry time.Duration
+	client *http.Client
+	now    func() time.Time
+}
+
+// serviceAccountKey holds the fields of a json service account key used for signing.
+type serviceAccountKey struct {
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+}
+
+// NewGCSUploader returns a GCSUploader for bucketURL, of the form gs://bucket or gs://bucket/prefix, signing with
+// the json service account key in keyFile. Signed URLs are valid for expiry, at most MaxSignedURLExpiry.
+func NewGCSUploader(bucketURL, keyFile string, expiry time.Duration) (*GCSUploader, error) {
+	u, err := url.Parse(bucketURL)
+	if err != nil || u.Scheme != "gs" || u.Host == "" {
+		return nil, fmt.Errorf("invalid bucket %q, want gs://bucket or gs://bucket/prefix", bucketURL)
+	}
+	if expiry <= 0 || expiry > MaxSignedURLExpiry {
+		return nil, fmt.Errorf("signed url expiry %v must be positive and at most %v", expiry, MaxSignedURLExpiry)
+	}
+	b, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read service account key: %v", err)
+	}
+	var k serviceAccountKey
+	if err := json.Unmarshal(b, &k); err != nil {
+		return nil, fmt.Errorf("unable to parse service account key %s: %v", keyFile, err)
+	}
+	block, _ := pem.Decode([]byte(k.PrivateKey))
+	if k.ClientEmail == "" || block == nil {
+		return nil, fmt.Errorf("service account key %s has no client_email or private_key", keyFile)
+	}
+	key, err := parseRSAKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse private_key of %s: %v", keyFile, err)
+	}
+	return &GCSUploader{
+		bucket: u.Host,
+		prefix: strings.Trim(u.Path, "/"),
+		email:  k.ClientEmail,
+		key:    key,
+		expiry: expiry,
+		client: &http.Client{Timeout: NotifyTimeout},
+		now:    time.Now,
+	}, nil
+}
+
+func parseRSAKey(der []byte) (*rsa.PrivateKey, error) {
+	if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not an RSA key")
+	}
+	return rsaKey, nil
+}
+
+// Upload stores content as the object name under the prefix of the bucket, and returns a signed URL to read it.
+func (u *GCSUploader) Upload(name string, content []byte, contentType string) (string, error) {
+	object := path.Join(u.prefix, name)
+	put, err := u.signedURL(http.MethodPut, object)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest(http.MethodPut, put, bytes.NewReader(content))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload gs://%s/%s: %v", u.bucket, object, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return "", fmt.Errorf("upload of gs://%s/%s returned %s: %s", u.bucket, object, resp.Status, bytes.TrimSpace(body))
+	}
+	return u.signedURL(http.MethodGet, object)
+}
+
+// UploadDir uploads every file in dir, without descending into subdirectories, under the object prefix name. It
+// returns the signed URLs of the uploaded files by file name. A missing dir has no files to upload.
+func (u *GCSUploader) UploadDir(dir, name string) (map[string]string, error) {
+	files, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	urls := map[string]string{}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		if err != nil {
+			return urls, err
+		}
+		signed, err := u.Upload(path.Join(name, f.Name()), content, http.DetectContentType(content))
+		if err != nil {
+			return urls, err
+		}
+		urls[f.Name()] = signed
+	}
+	return urls, nil
+}
+
+// signedURL returns a V4 signed URL, signing only the host header, allowing method on object until the expiry of u.
+// See https://cloud.google.com/storage/docs/access-control/signing-urls-manually.
+func (u *GCSUploader) signedURL(method, object string) (string, error) {
+	endpoint, err := url.Parse(gcsEndpoint)
+	if err != nil {
+		return "", err
+	}
+	now := u.now().UTC()
+	date := now.Format("20060102")
+	timestamp := now.Format("20060102T150405Z")
+	scope := date + "/auto/storage/goog4_request"
+	query := url.Values{
+		"X-Goog-Algorithm":     {"GOOG4-RSA-SHA256"},
+		"X-Goog-Credential":    {u.email + "/" + scope},
+		"X-Goog-Date":          {timestamp},
+		"X-Goog-Expires":       {fmt.Sprint(int(u.expiry.Seconds()))},
+		"X-Goog-SignedHeaders": {"host"},
+	}
+	uri := "/" + u.bucket + "/" + escapeObject(object)
+	canonicalQuery := canonicalQuery(query)
+	canonicalRequest := strings.Join([]string{method, uri, canonicalQuery, "host:" + endpoint.Host + "\n", "host", "UNSIGNED-PAYLOAD"}, "\n")
+	digest := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{"GOOG4-RSA-SHA256", timestamp, scope, hex.EncodeToString(digest[:])}, "\n")
+	hashed := sha256.Sum256([]byte(stringToSign))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, u.key, crypto.SHA256, hashed[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign url: %v", err)
+	}
+	return fmt.Sprintf("%s://%s%s?%s&X-Goog-Signature=%s", endpoint.Scheme, endpoint.Host, uri, canonicalQuery, hex.EncodeToString(signature)), nil
+}
+
+// escapeObject percent-encodes an object name for a canonical URI, keeping the slashes of its path.
+func escapeObject(object string) string {
+	parts := strings.Split(object, "/")
+	for i, p := range parts {
+		parts[i] = escape(p)
+	}
+	return strings.Join(parts, "/")
+}
+
+// escape percent-encodes every byte of s other than the unreserved characters of RFC 3986.
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-._~", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func canonicalQuery(v url.Values) string {
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var pairs []string
+	for _, k := range keys {
+		pairs = append(pairs, escape(k)+"="+escape(v.Get(k)))
+	}
+	return strings.Join(pairs, "&")
+}
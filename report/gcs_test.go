@@ -0,0 +1,127 @@
+package report
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestUploader(t *testing.T, bucketURL string) (*GCSUploader, *rsa.PrivateKey) {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(serviceAccountKey{
+		ClientEmail: "validator@project.iam.gserviceaccount.com",
+		PrivateKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "key.json")
+	if err := ioutil.WriteFile(keyFile, b, 0600); err != nil {
+		t.Fatal(err)
+	}
+	u, err := NewGCSUploader(bucketURL, keyFile, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.now = func() time.Time { return time.Date(2019, 4, 3, 15, 0, 0, 0, time.UTC) }
+	return u, key
+}
+
+// verifySignature checks the V4 signature of r, which only signs the host header.
+func verifySignature(t *testing.T, r *http.Request, key *rsa.PublicKey) {
+	query := r.URL.Query()
+	signature, err := hex.DecodeString(query.Get("X-Goog-Signature"))
+	if err != nil {
+		t.Fatalf("invalid X-Goog-Signature: %v", err)
+	}
+	rawQuery := strings.Split(r.URL.RawQuery, "&X-Goog-Signature=")[0]
+	canonicalRequest := r.Method + "\n" + r.URL.EscapedPath() + "\n" + rawQuery + "\nhost:" + r.Host + "\n\nhost\nUNSIGNED-PAYLOAD"
+	digest := sha256.Sum256([]byte(canonicalRequest))
+	scope := strings.TrimPrefix(query.Get("X-Goog-Credential"), "validator@project.iam.gserviceaccount.com/")
+	stringToSign := "GOOG4-RSA-SHA256\n" + query.Get("X-Goog-Date") + "\n" + scope + "\n" + hex.EncodeToString(digest[:])
+	hashed := sha256.Sum256([]byte(stringToSign))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, hashed[:], signature); err != nil {
+		t.Errorf("%s %s has an invalid signature: %v", r.Method, r.URL, err)
+	}
+	if scope != "20190403/auto/storage/goog4_request" || query.Get("X-Goog-Expires") != "3600" {
+		t.Errorf("%s %s has scope %q and expiry %q, want 20190403/auto/storage/goog4_request and 3600", r.Method, r.URL, scope, query.Get("X-Goog-Expires"))
+	}
+}
+
+func TestGCSUpload(t *testing.T) {
+	u, key := newTestUploader(t, "gs://ci-artifacts/validator")
+	uploaded := map[string]string{}
+	gcs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verifySignature(t, r, &key.PublicKey)
+		if r.Method != http.MethodPut {
+			t.Errorf("got %s %s, want PUT", r.Method, r.URL.Path)
+		}
+		b, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		uploaded[r.URL.Path] = string(b)
+	}))
+	defer gcs.Close()
+	defer func(endpoint string) { gcsEndpoint = endpoint }(gcsEndpoint)
+	gcsEndpoint = gcs.URL
+
+	signed, err := u.Upload("run-1/report one.json", []byte(`{"run_id": "run-1"}`), "application/json")
+	if err != nil {
+		t.Fatalf("Upload() returned error: %v", err)
+	}
+	if got := uploaded["/ci-artifacts/validator/run-1/report one.json"]; got != `{"run_id": "run-1"}` {
+		t.Errorf("Upload() stored %v, want the report under /ci-artifacts/validator/run-1/report one.json", uploaded)
+	}
+	// The signed url must be valid for reading the object.
+	get, err := url.Parse(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if get.EscapedPath() != "/ci-artifacts/validator/run-1/report%20one.json" {
+		t.Errorf("Upload() returned %s, want a url of the uploaded object", signed)
+	}
+	verifySignature(t, &http.Request{Method: http.MethodGet, URL: get, Host: get.Host}, &key.PublicKey)
+}
+
+func TestGCSUploadError(t *testing.T) {
+	u, _ := newTestUploader(t, "gs://ci-artifacts")
+	gcs := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+	}))
+	defer gcs.Close()
+	defer func(endpoint string) { gcsEndpoint = endpoint }(gcsEndpoint)
+	gcsEndpoint = gcs.URL
+
+	if _, err := u.Upload("report.json", nil, "application/json"); err == nil || !strings.Contains(err.Error(), "AccessDenied") {
+		t.Errorf("Upload() to a denied bucket returned %v, want an error with the response", err)
+	}
+}
+
+func TestNewGCSUploaderInvalidBucket(t *testing.T) {
+	for _, bucket := range []string{"ci-artifacts", "s3://ci-artifacts", "gs://"} {
+		if _, err := NewGCSUploader(bucket, "key.json", time.Hour); err == nil {
+			t.Errorf("NewGCSUploader(%q) returned nil, want error", bucket)
+		}
+	}
+}
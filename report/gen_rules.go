@@ -0,0 +1,41 @@
+//go:build ignore
+
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// gen_rules writes RULES.md, the reference of every check of the validator, from utils.Rules.
+package main
+
+import (
+	"log"
+	"os"
+
+	"github.com/google/hotel-booking-api-validator/report"
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+func main() {
+	f, err := os.Create("../RULES.md")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := report.WriteRulesMarkdown(f, utils.Rules); err != nil {
+		log.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		log.Fatal(err)
+	}
+}
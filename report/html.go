@@ -0,0 +1,123 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+// diffLine is a line of an echo field mismatch. Changed is the part of a removed or added line that differs from the
+// line it replaces, between the unchanged Before and After.
+type diffLine struct {
+	// Kind is "del" for a line of the response, "ins" for a line of the request and "" for a line of both.
+	Kind                           string
+	Marker, Before, Changed, After string
+}
+
+// commonAffixes returns the lengths in runes of the longest common prefix and suffix of a and b that do not overlap.
+func commonAffixes(a, b []rune) (int, int) {
+	p := 0
+	for p < len(a) && p < len(b) && a[p] == b[p] {
+		p++
+	}
+	s := 0
+	for s < len(a)-p && s < len(b)-p && a[len(a)-1-s] == b[len(b)-1-s] {
+		s++
+	}
+	return p, s
+}
+
+// diffLines splits the message of an echo field mismatch, see utils.MismatchPrefix, into lines. Each removed line is
+// paired with the added line at the same offset of the run that follows it, and the part in which they differ is
+// highlighted. It returns nil for other messages.
+func diffLines(message string) []diffLine {
+	if !strings.HasPrefix(message, utils.MismatchPrefix) {
+		return nil
+	}
+	var lines, del, ins []diffLine
+	flush := func() {
+		for i := 0; i < len(del) && i < len(ins); i++ {
+			d, n := &del[i], &ins[i]
+			dr, nr := []rune(d.Changed), []rune(n.Changed)
+			p, s := commonAffixes(dr, nr)
+			d.Before, d.Changed, d.After = string(dr[:p]), string(dr[p:len(dr)-s]), string(dr[len(dr)-s:])
+			n.Before, n.Changed, n.After = string(nr[:p]), string(nr[p:len(nr)-s]), string(nr[len(nr)-s:])
+		}
+		lines = append(append(lines, del...), ins...)
+		del, ins = nil, nil
+	}
+	for _, l := range strings.Split(strings.TrimPrefix(message, utils.MismatchPrefix), "\n") {
+		marker, text := l, ""
+		if len(l) > 2 {
+			marker, text = l[:2], l[2:]
+		}
+		switch marker[0] {
+		case '-':
+			if len(ins) > 0 {
+				flush()
+			}
+			del = append(del, diffLine{Kind: "del", Marker: marker, Changed: text})
+		case '+':
+			ins = append(ins, diffLine{Kind: "ins", Marker: marker, Changed: text})
+		default:
+			flush()
+			lines = append(lines, diffLine{Marker: marker, Before: text})
+		}
+	}
+	flush()
+	return lines
+}
+
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{"diff": diffLines}).Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Run {{.RunID}}</title>
+<style>.del{background:#fdd}.ins{background:#dfd}.del mark{background:#f99}.ins mark{background:#9e9}</style></head>
+<body>
+<h1>Hotel Booking API validation run {{.RunID}}</h1>
+<p>Against {{.ServerAddr}} with validator {{.Validator}}{{with .Seed}}, seed {{.}}{{end}}</p>
+{{with .Scorecard}}<h2>Score {{printf "%.1f" .Score}}/100, grade {{.Grade}}</h2>
+{{if .Blocking}}<ul>
+{{range .Blocking}}<li>{{.}}</li>
+{{end}}</ul>
+{{end}}{{end}}<table border="1">
+<tr><th>Flow</th><th>Endpoint</th><th>Result</th></tr>
+{{range .Results}}<tr><td>{{.Flow}}</td><td>{{.Endpoint}}</td><td>{{if .Success}}passed{{else}}<b>failed</b>{{end}}</td></tr>
+{{end}}</table>
+{{range .Results}}{{if or .Error .Findings .Rules}}<h2>{{.Flow}}</h2>
+{{if .Error}}<pre>{{.Error}}</pre>
+{{end}}{{if .Rules}}<table border="1">
+<tr><th>Severity</th><th>Code</th><th>Rule</th><th>Requests</th></tr>
+{{$requests := .Requests}}{{range .Rules}}<tr><td>{{.Severity}}</td><td>{{.Code}}</td><td>{{.Rule}}</td><td>{{.Count}}/{{$requests}}</td></tr>
+{{end}}</table>
+{{end}}{{if .Findings}}<table border="1">
+<tr><th>Severity</th><th>Code</th><th>Field</th><th>Message</th></tr>
+{{range .Findings}}<tr><td>{{.Severity}}</td><td>{{.Code}}</td><td>{{.Field}}</td><td>{{with diff .Message}}did not match (-got +want)
+<pre>{{range .}}<span class="{{.Kind}}">{{.Marker}}{{.Before}}{{if .Changed}}<mark>{{.Changed}}</mark>{{end}}{{.After}}</span>
+{{end}}</pre>{{else}}<pre>{{.Message}}</pre>{{end}}</td></tr>
+{{end}}</table>
+{{end}}{{end}}{{end}}</body></html>
+`))
+
+// WriteHTML writes r to w as a standalone HTML page, with the scorecard, a table of the flows and the errors, rules
+// and findings of each flow, that can be shared without the validator. Echo field mismatches are shown as the json of
+// the field, with the values that differ between the response and the request highlighted.
+func (r *Report) WriteHTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, r)
+}
@@ -0,0 +1,72 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+func TestWriteHTML(t *testing.T) {
+	r := New("2f1c7c9e-4b8e-4c2a-9d55-0a7e4d3b6f10")
+	r.Add("BookingAvailability", "/v1/BookingAvailability", nil, nil)
+	r.Add("BookingSubmit", "/v1/BookingSubmit", []utils.Finding{{Severity: utils.Error, Field: "hotel_id", Message: "got <456> want 123", Code: utils.CodeEchoMismatch}}, errors.New("Validation error"))
+	r.Scorecard = r.Score()
+
+	var buf bytes.Buffer
+	if err := r.WriteHTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"<h1>Hotel Booking API validation run 2f1c7c9e-4b8e-4c2a-9d55-0a7e4d3b6f10</h1>",
+		"<tr><td>BookingSubmit</td><td>/v1/BookingSubmit</td><td><b>failed</b></td></tr>",
+		"<tr><td>Error</td><td>ECHO_001</td><td>hotel_id</td><td><pre>got &lt;456&gt; want 123</pre></td></tr>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("WriteHTML() does not contain %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "<h2>BookingAvailability</h2>") {
+		t.Errorf("WriteHTML() has a section for a flow that passed without findings:\n%s", got)
+	}
+}
+
+func TestWriteHTMLMismatch(t *testing.T) {
+	r := New("")
+	r.Add("BookingSubmit", "/v1/BookingSubmit", []utils.Finding{{Severity: utils.Error, Field: "reservation > room_rate", Message: utils.MismatchPrefix + "  {\n-   \"amount\": 100,\n+   \"amount\": 120,\n    \"currency\": \"USD\"\n  }"}}, errors.New("Validation error"))
+
+	var buf bytes.Buffer
+	if err := r.WriteHTML(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		`<span class="">  {</span>`,
+		`<span class="del">-   &#34;amount&#34;: 1<mark>0</mark>0,</span>`,
+		`<span class="ins">&#43;   &#34;amount&#34;: 1<mark>2</mark>0,</span>`,
+		`<span class="">    &#34;currency&#34;: &#34;USD&#34;</span>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("WriteHTML() does not contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestCommonAffixes(t *testing.T) {
+	for _, tc := range []struct {
+		a, b           string
+		prefix, suffix int
+	}{
+		{"100", "120", 1, 1},
+		{"café", "cafè", 3, 0},
+		{"Zürich", "Zurich", 1, 4},
+		{"ab", "ab", 2, 0},
+	} {
+		if p, s := commonAffixes([]rune(tc.a), []rune(tc.b)); p != tc.prefix || s != tc.suffix {
+			t.Errorf("commonAffixes(%q, %q) = %d, %d, want %d, %d", tc.a, tc.b, p, s, tc.prefix, tc.suffix)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitTestSuite struct {
+	XMLName    xml.Name        `xml:"testsuite"`
+	Name       string          `xml:"name,attr"`
+	ID         string          `xml:"id,attr,omitempty"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	TestCases  []junitTestCase `xml:"testcase"`
+}
+
+// WriteJUnit writes r to w as a JUnit XML test suite, the format most CI systems display test results in. Every flow
+// is a test case, failing with its error and its Error and Critical findings; warnings are recorded as its output.
+func (r *Report) WriteJUnit(w io.Writer) error {
+	suite := junitTestSuite{
+		Name: "hotel-booking-api-validator",
+		ID:   r.RunID,
+		Properties: []junitProperty{
+			{"server_addr", r.ServerAddr},
+			{"validator", r.Validator.String()},
+		},
+	}
+	if r.Scorecard != nil {
+		suite.Properties = append(suite.Properties,
+			junitProperty{"score", fmt.Sprintf("%.1f", r.Scorecard.Score)},
+			junitProperty{"grade", r.Scorecard.Grade})
+	}
+	for _, res := range r.Results {
+		tc := junitTestCase{Name: res.Flow, ClassName: res.Flow}
+		if res.Endpoint != "" {
+			tc.ClassName = res.Endpoint
+		}
+		var failures, warnings []string
+		for _, f := range res.Findings {
+			if f.Severity == utils.Warning {
+				warnings = append(warnings, f.String())
+			} else {
+				failures = append(failures, f.String())
+			}
+		}
+		if !res.Success {
+			suite.Failures++
+			tc.Failure = &junitFailure{Message: res.Error, Text: strings.Join(failures, "\n")}
+		}
+		tc.SystemOut = strings.Join(warnings, "\n")
+		suite.TestCases = append(suite.TestCases, tc)
+	}
+	suite.Tests = len(suite.TestCases)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	if err := e.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
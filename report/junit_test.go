@@ -0,0 +1,33 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	r := New("2f1c7c9e-4b8e-4c2a-9d55-0a7e4d3b6f10")
+	r.ServerAddr = "localhost:8080"
+	r.Add("BookingAvailability", "/v1/BookingAvailability", []utils.Finding{{Severity: utils.Warning, Field: "rate_plans[0]", Message: "short window"}}, nil)
+	r.Add("BookingSubmit", "/v1/BookingSubmit", []utils.Finding{{Severity: utils.Error, Field: "hotel_id", Message: "got 456 want 123", Code: utils.CodeEchoMismatch}}, errors.New("Validation error: echo field(s) did not match request: hotel_id"))
+
+	var buf bytes.Buffer
+	if err := r.WriteJUnit(&buf); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		`<testsuite name="hotel-booking-api-validator" id="2f1c7c9e-4b8e-4c2a-9d55-0a7e4d3b6f10" tests="2" failures="1">`,
+		`<property name="server_addr" value="localhost:8080"></property>`,
+		"<testcase name=\"BookingAvailability\" classname=\"/v1/BookingAvailability\">\n    <system-out>Warning: rate_plans[0] short window</system-out>",
+		"<failure message=\"Validation error: echo field(s) did not match request: hotel_id\">Error ECHO_001: hotel_id got 456 want 123</failure>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("WriteJUnit() does not contain %q:\n%s", want, got)
+		}
+	}
+}
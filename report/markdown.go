@@ -0,0 +1,142 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+// MaxMarkdownPayloadBytes bounds the size of each payload in a Markdown report, as issue trackers limit the length
+// of comments. Longer payloads are truncated.
+const MaxMarkdownPayloadBytes = 16 << 10
+
+// Payload is a request or response attached to a flow of a Markdown report, e.g. the request file it was run with.
+type Payload struct {
+	Name    string
+	Content string
+}
+
+// markdownCell escapes the text of a Markdown table cell.
+var markdownCell = strings.NewReplacer("|", `\|`, "\r\n", "<br>", "\n", "<br>")
+
+// fence returns a code fence longer than any run of backticks in content.
+func fence(content string) string {
+	longest, run := 0, 0
+	for _, c := range content {
+		if c != '`' {
+			run = 0
+			continue
+		}
+		if run++; run > longest {
+			longest = run
+		}
+	}
+	if longest < 3 {
+		return "```"
+	}
+	return strings.Repeat("`", longest+1)
+}
+
+// WriteMarkdown writes r to w as a Markdown document that can be pasted into an issue: a summary table of the flows,
+// a table of the optional room rate fields set in availability responses, a table of the rules broken by the requests
+// of each batch flow, a table of the findings of each flow, and payloads, which maps flow names to the requests and
+// responses of that flow, in collapsed sections.
+func (r *Report) WriteMarkdown(w io.Writer, payloads map[string][]Payload) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Hotel Booking API validation run %s\n\n", r.RunID)
+	if r.ServerAddr != "" {
+		fmt.Fprintf(&b, "Server `%s`, validator %s.\n\n", r.ServerAddr, r.Validator)
+	} else {
+		fmt.Fprintf(&b, "Validator %s.\n\n", r.Validator)
+	}
+	if r.Seed != 0 {
+		fmt.Fprintf(&b, "Seed `%d`.\n\n", r.Seed)
+	}
+	if s := r.Scorecard; s != nil {
+		fmt.Fprintf(&b, "Conformance score %.1f/100, launch-readiness grade %s.\n\n", s.Score, s.Grade)
+		b.WriteString("| Category | Score |\n| --- | --- |\n")
+		for _, c := range []Category{Schema, Pricing, Policies, Performance, Security} {
+			fmt.Fprintf(&b, "| %s | %.0f |\n", c, s.Categories[c])
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("| Flow | Endpoint | Result |\n| --- | --- | --- |\n")
+	for _, res := range r.Results {
+		result := "passed"
+		if !res.Success {
+			result = "**failed**"
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", res.Flow, markdownCell.Replace(res.Endpoint), result)
+	}
+	if p := r.Presence; p != nil {
+		fmt.Fprintf(&b, "\n## Room rate field presence\n\nOptional fields set across %d room_rates of %d availability response(s).\n\n", p.RoomRates, p.Responses)
+		b.WriteString("| Field | Room rates | Share |\n| --- | --- | --- |\n")
+		for _, f := range p.Fields {
+			share := fmt.Sprintf("%.0f%%", 100*f.Fraction)
+			if f.Partial {
+				share = "**" + share + "**"
+			}
+			fmt.Fprintf(&b, "| %s | %d/%d | %s |\n", f.Field, f.Present, p.RoomRates, share)
+		}
+	}
+	for _, res := range r.Results {
+		if res.Success && len(res.Findings) == 0 && len(res.Rules) == 0 && len(payloads[res.Flow]) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", res.Flow)
+		if res.Error != "" {
+			fmt.Fprintf(&b, "%s\n%s\n%s\n\n", fence(res.Error), res.Error, fence(res.Error))
+		}
+		if len(res.Rules) > 0 {
+			b.WriteString("| Severity | Code | Rule | Requests |\n| --- | --- | --- | --- |\n")
+			for _, rc := range res.Rules {
+				severity := rc.Severity.String()
+				if rc.Severity != utils.Warning {
+					severity = "**" + severity + "**"
+				}
+				fmt.Fprintf(&b, "| %s | %s | %s | %d/%d |\n", severity, rc.Code, markdownCell.Replace(rc.Rule), rc.Count, res.Requests)
+			}
+			b.WriteString("\n")
+		}
+		if len(res.Findings) > 0 {
+			b.WriteString("| Severity | Code | Field | Message |\n| --- | --- | --- | --- |\n")
+			for _, f := range res.Findings {
+				severity := f.Severity.String()
+				if f.Severity != utils.Warning {
+					severity = "**" + severity + "**"
+				}
+				fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", severity, f.Code, markdownCell.Replace(f.Field), markdownCell.Replace(f.Message))
+			}
+			b.WriteString("\n")
+		}
+		for _, p := range payloads[res.Flow] {
+			content := p.Content
+			if len(content) > MaxMarkdownPayloadBytes {
+				content = content[:MaxMarkdownPayloadBytes] + fmt.Sprintf("\n... truncated, %d bytes in total", len(p.Content))
+			}
+			content = strings.TrimRight(content, "\n")
+			fmt.Fprintf(&b, "<details>\n<summary>%s</summary>\n\n%sjson\n%s\n%s\n\n</details>\n\n", html.EscapeString(p.Name), fence(content), content, fence(content))
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
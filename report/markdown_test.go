@@ -0,0 +1,74 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+func TestWriteMarkdown(t *testing.T) {
+	r := New("2f1c7c9e-4b8e-4c2a-9d55-0a7e4d3b6f10")
+	r.ServerAddr = "localhost:8080"
+	r.Add("BookingAvailability", "/v1/BookingAvailability", nil, nil)
+	r.Add("BookingSubmit", "/v1/BookingSubmit", []utils.Finding{
+		{Severity: utils.Error, Field: "hotel_id", Message: "got 456 | want 123\nsecond line", Code: utils.CodeEchoMismatch},
+		{Severity: utils.Warning, Field: "artifacts", Message: "request saved to req.json"},
+	}, errors.New("Validation error: echo field(s) did not match request: hotel_id"))
+	payloads := map[string][]Payload{
+		"BookingSubmit": {{Name: "request <req.json>", Content: "{\"hotel_id\": \"```\"}\n"}},
+	}
+
+	var buf bytes.Buffer
+	if err := r.WriteMarkdown(&buf, payloads); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"# Hotel Booking API validation run 2f1c7c9e-4b8e-4c2a-9d55-0a7e4d3b6f10\n",
+		"| BookingAvailability | /v1/BookingAvailability | passed |\n",
+		"| BookingSubmit | /v1/BookingSubmit | **failed** |\n",
+		"## BookingSubmit\n\n```\nValidation error: echo field(s) did not match request: hotel_id\n```\n",
+		"| **Error** | ECHO_001 | hotel_id | got 456 \\| want 123<br>second line |\n",
+		"| Warning |  | artifacts | request saved to req.json |\n",
+		"<details>\n<summary>request &lt;req.json&gt;</summary>\n\n````json\n{\"hotel_id\": \"```\"}\n````\n\n</details>\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("WriteMarkdown() does not contain %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "## BookingAvailability") {
+		t.Errorf("WriteMarkdown() has a section for a flow that passed without findings:\n%s", got)
+	}
+}
+
+func TestWriteMarkdownTruncatesPayloads(t *testing.T) {
+	r := New("")
+	r.Add("BookingSubmit", "", nil, errors.New("failed"))
+	content := strings.Repeat("a", MaxMarkdownPayloadBytes+10)
+	var buf bytes.Buffer
+	if err := r.WriteMarkdown(&buf, map[string][]Payload{"BookingSubmit": {{Name: "response", Content: content}}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); strings.Contains(got, content) || !strings.Contains(got, "truncated, 16394 bytes in total") {
+		t.Errorf("WriteMarkdown() did not truncate the payload:\n%s", got[len(got)-200:])
+	}
+}
+
+func TestWriteMarkdownRules(t *testing.T) {
+	r := New("")
+	tally := &utils.RuleTally{}
+	tally.Add([]utils.Finding{{Severity: utils.Error, Field: "room_rates[0] > line_items", Message: "missing", Code: utils.CodeTaxMissing}}, errors.New("Validation error"))
+	tally.Add([]utils.Finding{{Severity: utils.Error, Field: "room_rates[3] > line_items", Message: "missing", Code: utils.CodeTaxMissing}}, errors.New("Validation error"))
+	tally.Add(nil, nil)
+	r.AddBatch("Sweep", "", tally, nil, nil)
+	var buf bytes.Buffer
+	if err := r.WriteMarkdown(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "## Sweep\n\n| Severity | Code | Rule | Requests |\n| --- | --- | --- | --- |\n| **Error** | TAX_002 | room_rates[] > line_items | 2/3 |\n"; !strings.Contains(got, want) {
+		t.Errorf("WriteMarkdown() does not contain %q:\n%s", want, got)
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+// NotifyTimeout bounds the time spent posting a notification, so an unreachable webhook does not stall a run.
+const NotifyTimeout = 10 * time.Second
+
+// Summary returns a short plain text description of the failed flows in r, suitable for chat notifications.
+// If artifact is not empty it is referenced as the location of the full report.
+func (r *Report) Summary(artifact string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Hotel Booking API validation run %s against %s failed\n", r.RunID, r.ServerAddr)
+	fmt.Fprintf(&b, "Validator %s\n", r.Validator)
+	for _, res := range r.Results {
+		if res.Success {
+			continue
+		}
+		fmt.Fprintf(&b, "• %s %s: %s\n", res.Flow, res.Endpoint, res.Error)
+		var fields []string
+		for _, f := range res.Findings {
+			if f.Severity != utils.Warning {
+				fields = append(fields, f.Field)
+			}
+		}
+		if len(fields) > 0 {
+			fmt.Fprintf(&b, "  failed fields: %s\n", strings.Join(fields, ", "))
+		}
+	}
+	if artifact != "" {
+		fmt.Fprintf(&b, "Report: %s\n", artifact)
+	}
+	return b.String()
+}
+
+// Notify posts the Summary of r to a Slack or Google Chat incoming webhook. Both accept a json body with a text field.
+func Notify(webhookURL string, r *Report, artifact string) error {
+	body, err := json.Marshal(map[string]string{"text": r.Summary(artifact)})
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: NotifyTimeout}
+	resp, err := client.Post(webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to post notification: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("notification webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+func TestNotify(t *testing.T) {
+	var got map[string]string
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("webhook received invalid json: %v", err)
+		}
+	}))
+	defer webhook.Close()
+
+	r := New("run-1")
+	r.ServerAddr = "partner.example.com:443"
+	r.Add("BookingAvailability", "/v1/BookingAvailability", nil, nil)
+	r.Add("BookingSubmit", "/v1/BookingSubmit", []utils.Finding{{Severity: utils.Error, Field: "hotel_id"}}, errors.New("Validation error: echo field(s) did not match request: hotel_id"))
+	if err := Notify(webhook.URL, r, "/tmp/report.json"); err != nil {
+		t.Fatalf("Notify() returned error: %v", err)
+	}
+	for _, want := range []string{"run-1", "partner.example.com:443", "BookingSubmit /v1/BookingSubmit", "failed fields: hotel_id", "/tmp/report.json", "rule set " + utils.RuleSetVersion} {
+		if !strings.Contains(got["text"], want) {
+			t.Errorf("Notify() posted %q, want it to contain %q", got["text"], want)
+		}
+	}
+	if strings.Contains(got["text"], "BookingAvailability") {
+		t.Errorf("Notify() posted %q, want only failed flows", got["text"])
+	}
+}
+
+func TestNotifyWebhookError(t *testing.T) {
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid token", http.StatusForbidden)
+	}))
+	defer webhook.Close()
+	if err := Notify(webhook.URL, New("run-1"), ""); err == nil {
+		t.Error("Notify() to a failing webhook returned nil, want error")
+	}
+}
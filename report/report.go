@@ -0,0 +1,111 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package report contains the machine-readable summary of a validation run.
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+// Result is the outcome of a single validated flow, e.g. BookingAvailability.
+type Result struct {
+	Flow     string          `json:"flow"`
+	Endpoint string          `json:"endpoint,omitempty"`
+	Success  bool            `json:"success"`
+	Error    string          `json:"error,omitempty"`
+	Findings []utils.Finding `json:"findings,omitempty"`
+	// Requests and Rules are set for batch flows, such as sweeps, that send many requests: the number of requests
+	// sent, and how many of them broke each rule. NotRun is the number of requests of the batch that were skipped,
+	// e.g. once a circuit breaker stopped it.
+	Requests int               `json:"requests,omitempty"`
+	NotRun   int               `json:"not_run,omitempty"`
+	Rules    []utils.RuleCount `json:"rules,omitempty"`
+}
+
+// Report summarizes every flow validated during a run.
+type Report struct {
+	RunID       string                 `json:"run_id"`
+	Validator   Build                  `json:"validator"`
+	Seed        int64                  `json:"seed,omitempty"`
+	ServerAddr  string                 `json:"server_addr,omitempty"`
+	TLS         *api.CertificateReport `json:"tls,omitempty"`
+	Resumption  *api.ResumptionReport  `json:"tls_resumption,omitempty"`
+	KeepAlive   *api.KeepAliveReport   `json:"keep_alive,omitempty"`
+	Redirects   *api.RedirectReport    `json:"redirects,omitempty"`
+	Compression *api.CompressionReport `json:"compression,omitempty"`
+	Presence    *utils.PresenceReport  `json:"field_presence,omitempty"`
+	Transport   []api.TransportProbe   `json:"transport,omitempty"`
+	Scorecard   *Scorecard             `json:"scorecard,omitempty"`
+	Results     []Result               `json:"results"`
+
+	reporter Reporter
+}
+
+// New returns an empty Report for the given run.
+func New(runID string) *Report {
+	return &Report{RunID: runID, Validator: CurrentBuild(), Results: []Result{}}
+}
+
+// SetReporter calls Start on rep and passes it every finding of the flows added to r from then on.
+func (r *Report) SetReporter(rep Reporter) {
+	r.reporter = rep
+	rep.Start(r)
+}
+
+// Add records the outcome of flow sent to endpoint, as returned by the api package.
+func (r *Report) Add(flow, endpoint string, findings []utils.Finding, err error) {
+	res := Result{Flow: flow, Endpoint: endpoint, Success: err == nil, Findings: findings}
+	if err != nil {
+		res.Error = err.Error()
+	}
+	r.Results = append(r.Results, res)
+	if r.reporter != nil {
+		for _, f := range findings {
+			r.reporter.Finding(flow, f)
+		}
+	}
+}
+
+// AddBatch records the outcome of a batch flow like Add, along with the per rule counts of its requests in tally.
+func (r *Report) AddBatch(flow, endpoint string, tally *utils.RuleTally, findings []utils.Finding, err error) {
+	r.Add(flow, endpoint, findings, err)
+	res := &r.Results[len(r.Results)-1]
+	res.Requests = tally.Requests()
+	res.NotRun = tally.NotRun()
+	res.Rules = tally.Rules()
+}
+
+// Failed reports whether any flow in the run failed.
+func (r *Report) Failed() bool {
+	for _, res := range r.Results {
+		if !res.Success {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteJSON writes the report to w as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(r)
+}
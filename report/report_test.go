@@ -0,0 +1,48 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+func TestWriteJSON(t *testing.T) {
+	r := New("2f1c7c9e-4b8e-4c2a-9d55-0a7e4d3b6f10")
+	r.Add("BookingAvailability", "", []utils.Finding{{Severity: utils.Warning, Field: "rate_plans[0]", Message: "short window"}}, nil)
+	r.Add("BookingSubmit", "", nil, errors.New("Validation error: echo field(s) did not match request: hotel_id"))
+
+	var buf bytes.Buffer
+	if err := r.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("WriteJSON() produced invalid json: %v", err)
+	}
+	want := map[string]interface{}{
+		"run_id":    "2f1c7c9e-4b8e-4c2a-9d55-0a7e4d3b6f10",
+		"validator": map[string]interface{}{"version": "dev", "rule_set": utils.RuleSetVersion},
+		"results": []interface{}{
+			map[string]interface{}{
+				"flow":    "BookingAvailability",
+				"success": true,
+				"findings": []interface{}{
+					map[string]interface{}{"severity": "Warning", "field": "rate_plans[0]", "message": "short window"},
+				},
+			},
+			map[string]interface{}{
+				"flow":    "BookingSubmit",
+				"success": false,
+				"error":   "Validation error: echo field(s) did not match request: hotel_id",
+			},
+		},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("WriteJSON() did not match (-got +want)\n%s", diff)
+	}
+}
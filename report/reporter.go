@@ -0,0 +1,161 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+// Reporter writes the outcome of a run in one output format.
+type Reporter interface {
+	// Start is called before the first flow of the run r is validated.
+	Start(r *Report)
+	// Finding is called for every finding of flow as the flow is added to the report.
+	Finding(flow string, f utils.Finding)
+	// Finish is called with the complete report, including its scorecard, once every flow was validated.
+	Finish(r *Report) error
+}
+
+// Reporters combines several Reporters, calling each in turn.
+type Reporters []Reporter
+
+// Start calls Start on every Reporter.
+func (rs Reporters) Start(r *Report) {
+	for _, rep := range rs {
+		rep.Start(r)
+	}
+}
+
+// Finding calls Finding on every Reporter.
+func (rs Reporters) Finding(flow string, f utils.Finding) {
+	for _, rep := range rs {
+		rep.Finding(flow, f)
+	}
+}
+
+// Finish calls Finish on every Reporter, even after one fails, and returns the first error.
+func (rs Reporters) Finish(r *Report) error {
+	var first error
+	for _, rep := range rs {
+		if err := rep.Finish(r); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// consoleReporter logs the scorecard and whether each flow succeeded at the end of the run.
+type consoleReporter struct{}
+
+// NewConsoleReporter returns a Reporter logging an overview of the run, with the scorecard and the result of every
+// flow, to the standard logger. Findings are not repeated, as they are logged as they are found.
+func NewConsoleReporter() Reporter {
+	return consoleReporter{}
+}
+
+func (consoleReporter) Start(r *Report) {}
+
+func (consoleReporter) Finding(flow string, f utils.Finding) {}
+
+func (consoleReporter) Finish(r *Report) error {
+	if s := r.Scorecard; s != nil {
+		log.Printf("Conformance score: %.1f/100, launch-readiness grade %s", s.Score, s.Grade)
+		for _, c := range []Category{Schema, Pricing, Policies, Performance, Security} {
+			log.Printf("  %s: %.0f", c, s.Categories[c])
+		}
+		for _, b := range s.Blocking {
+			log.Printf("Blocking: %s", b)
+		}
+	}
+
+	log.Print("\n************* Begin Stats *************\n")
+	log.Printf("Run ID: %s", r.RunID)
+	if r.Seed != 0 {
+		log.Printf("Seed: %d", r.Seed)
+	}
+	for _, res := range r.Results {
+		if !res.Success {
+			log.Printf("%s Failed", res.Flow)
+			continue
+		}
+		warnings := 0
+		for _, f := range res.Findings {
+			if f.Severity == utils.Warning {
+				warnings++
+			}
+		}
+		log.Printf("%s Succeeded with %d warning(s)", res.Flow, warnings)
+	}
+	if len(r.Results) > 0 && !r.Failed() {
+		log.Println("All tests pass!")
+	}
+	log.Print("\n************* End Stats *************\n")
+	return nil
+}
+
+// writerReporter writes the complete report to w in a single format when the run finishes.
+type writerReporter struct {
+	w     io.Writer
+	write func(r *Report, w io.Writer) error
+}
+
+func (writerReporter) Start(r *Report) {}
+
+func (writerReporter) Finding(flow string, f utils.Finding) {}
+
+func (wr writerReporter) Finish(r *Report) error {
+	return wr.write(r, wr.w)
+}
+
+// NewJSONReporter returns a Reporter writing the report to w as JSON, see WriteJSON.
+func NewJSONReporter(w io.Writer) Reporter {
+	return writerReporter{w, (*Report).WriteJSON}
+}
+
+// NewJUnitReporter returns a Reporter writing the report to w as JUnit XML, see WriteJUnit.
+func NewJUnitReporter(w io.Writer) Reporter {
+	return writerReporter{w, (*Report).WriteJUnit}
+}
+
+// NewHTMLReporter returns a Reporter writing the report to w as a standalone HTML page, see WriteHTML.
+func NewHTMLReporter(w io.Writer) Reporter {
+	return writerReporter{w, (*Report).WriteHTML}
+}
+
+// ReporterNames lists the formats accepted by NewReporter.
+var ReporterNames = []string{"console", "json", "junit", "html"}
+
+// NewReporter returns the Reporter of the named format, one of ReporterNames, writing to w. The console format logs
+// to the standard logger and ignores w.
+func NewReporter(name string, w io.Writer) (Reporter, error) {
+	switch name {
+	case "console":
+		return NewConsoleReporter(), nil
+	case "json":
+		return NewJSONReporter(w), nil
+	case "junit":
+		return NewJUnitReporter(w), nil
+	case "html":
+		return NewHTMLReporter(w), nil
+	}
+	return nil, fmt.Errorf("unknown reporter %q, want one of %s", name, strings.Join(ReporterNames, ", "))
+}
@@ -0,0 +1,67 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+// recordingReporter records the calls made to it.
+type recordingReporter struct {
+	calls []string
+	err   error
+}
+
+func (r *recordingReporter) Start(rep *Report) { r.calls = append(r.calls, "start "+rep.RunID) }
+
+func (r *recordingReporter) Finding(flow string, f utils.Finding) {
+	r.calls = append(r.calls, "finding "+flow+" "+f.Field)
+}
+
+func (r *recordingReporter) Finish(rep *Report) error {
+	r.calls = append(r.calls, "finish")
+	return r.err
+}
+
+func TestReporters(t *testing.T) {
+	first, second := &recordingReporter{err: errors.New("disk full")}, &recordingReporter{}
+	r := New("run")
+	r.SetReporter(Reporters{first, second})
+	r.Add("BookingAvailability", "", []utils.Finding{{Severity: utils.Warning, Field: "rate_plans[0]"}}, nil)
+	r.Add("BookingSubmit", "", nil, errors.New("failed"))
+	if err := r.reporter.Finish(r); err == nil || err.Error() != "disk full" {
+		t.Errorf("Finish() = %v, want disk full", err)
+	}
+
+	want := []string{"start run", "finding BookingAvailability rate_plans[0]", "finish"}
+	for _, rep := range []*recordingReporter{first, second} {
+		if diff := cmp.Diff(rep.calls, want); diff != "" {
+			t.Errorf("Reporter calls did not match (-got +want)\n%s", diff)
+		}
+	}
+}
+
+func TestNewReporter(t *testing.T) {
+	r := New("run")
+	r.Add("BookingAvailability", "", nil, nil)
+	for _, name := range ReporterNames {
+		var buf bytes.Buffer
+		rep, err := NewReporter(name, &buf)
+		if err != nil {
+			t.Fatalf("NewReporter(%q) failed: %v", name, err)
+		}
+		if err := rep.Finish(r); err != nil {
+			t.Errorf("NewReporter(%q).Finish() failed: %v", name, err)
+		}
+		if got := buf.Len() > 0; got != (name != "console") {
+			t.Errorf("NewReporter(%q) wrote %d bytes", name, buf.Len())
+		}
+	}
+	if _, err := NewReporter("xml", nil); err == nil {
+		t.Error("NewReporter(xml) succeeded, want error")
+	}
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+//go:generate go run gen_rules.go
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+// WriteRulesMarkdown writes the reference of rules to w as a Markdown document, with the code, severity, description
+// and an example finding of every check. RULES.md at the root of the repository is generated from utils.Rules with
+// go generate ./report.
+func WriteRulesMarkdown(w io.Writer, rules []utils.Rule) error {
+	var b strings.Builder
+	b.WriteString("<!-- Code generated by go generate ./report. DO NOT EDIT. -->\n\n")
+	fmt.Fprintf(&b, "# Validation rules\n\nRule set %s. Every finding carries the code of the check that found it; codes are never renumbered or reused. The severity is the one reported unless a validation profile overrides it.\n\n", utils.RuleSetVersion)
+	b.WriteString("| Code | Severity | Description | Example finding |\n| --- | --- | --- | --- |\n")
+	for _, r := range rules {
+		example := fmt.Sprintf("%v %s: %s", r.Severity, r.Code, r.Example)
+		fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", r.Code, r.Severity, markdownCell.Replace(r.Description), markdownCell.Replace(html.EscapeString(example)))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
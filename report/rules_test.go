@@ -0,0 +1,37 @@
+package report
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+func TestWriteRulesMarkdown(t *testing.T) {
+	var b strings.Builder
+	rules := []utils.Rule{{Code: "ECHO_001", Severity: utils.Error, Description: "echo | mismatch", Example: "hotel_id did not match\n- <nil>"}}
+	if err := WriteRulesMarkdown(&b, rules); err != nil {
+		t.Fatal(err)
+	}
+	want := `| ECHO_001 | Error | echo \| mismatch | Error ECHO_001: hotel_id did not match<br>- &lt;nil&gt; |`
+	if !strings.Contains(b.String(), want) {
+		t.Errorf("WriteRulesMarkdown() = %s, want a row %s", b.String(), want)
+	}
+}
+
+func TestRulesMarkdownUpToDate(t *testing.T) {
+	got, err := ioutil.ReadFile("../RULES.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want strings.Builder
+	if err := WriteRulesMarkdown(&want, utils.Rules); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(string(got), want.String()); diff != "" {
+		t.Errorf("RULES.md is out of date, run go generate ./report (-got +want):\n%s", diff)
+	}
+}
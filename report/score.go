@@ -0,0 +1,188 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+// Category groups related checks for conformance scoring.
+type Category string
+
+// Categories scored by Scorecard, with the share of the total score each contributes in categoryWeights.
+const (
+	Schema      Category = "schema"
+	Pricing     Category = "pricing"
+	Policies    Category = "policies"
+	Performance Category = "performance"
+	Security    Category = "security"
+)
+
+var categoryWeights = map[Category]float64{
+	Schema:      0.30,
+	Pricing:     0.25,
+	Policies:    0.20,
+	Performance: 0.10,
+	Security:    0.15,
+}
+
+// penalties is the number of points a category loses per issue of each severity.
+var penalties = map[utils.Severity]float64{
+	utils.Warning:  5,
+	utils.Error:    25,
+	utils.Critical: 100,
+}
+
+// categoryKeywords maps substrings of finding fields and error messages to their category. The first match wins and
+// anything unmatched is a Schema issue.
+var categoryKeywords = []struct {
+	keyword  string
+	category Category
+}{
+	{"sla > ", Performance},
+	{"SLA thresholds", Performance},
+	{"cancellation", Policies},
+	{"duplicate", Policies},
+	{"locator", Policies},
+	{"price", Pricing},
+	{"line_items", Pricing},
+	{"currency", Pricing},
+	{"Timeout", Performance},
+	{"timeout", Performance},
+	{"deadline exceeded", Performance},
+	{"certificate", Security},
+	{"x509", Security},
+	{"tls", Security},
+	{"redirects > ", Security},
+}
+
+// maxBlocking is the number of blocking issues listed in a Scorecard.
+const maxBlocking = 5
+
+// Scorecard is the conformance score of a run: a weighted score out of 100 per category, their total, and a
+// launch-readiness grade.
+type Scorecard struct {
+	Score      float64              `json:"score"`
+	Grade      string               `json:"grade"`
+	Ready      bool                 `json:"launch_ready"`
+	Categories map[Category]float64 `json:"categories"`
+	Blocking   []string             `json:"blocking_issues,omitempty"`
+}
+
+func categorize(s string) Category {
+	for _, k := range categoryKeywords {
+		if strings.Contains(s, k.keyword) {
+			return k.category
+		}
+	}
+	return Schema
+}
+
+type issue struct {
+	severity    utils.Severity
+	category    Category
+	description string
+}
+
+// issues lists everything that cost points in r. A failed flow without Error or Critical findings, e.g. a missing
+// required field, counts as a single Error. References to saved payloads are not issues.
+func (r *Report) issues() []issue {
+	var issues []issue
+	for _, res := range r.Results {
+		blocking := false
+		for _, f := range res.Findings {
+			if f.Field == api.ArtifactsField {
+				continue
+			}
+			issues = append(issues, issue{f.Severity, categorize(f.Field + " " + f.Message), fmt.Sprintf("%s: %s", res.Flow, f.Field)})
+			blocking = blocking || f.Severity != utils.Warning
+		}
+		if !res.Success && !blocking {
+			issues = append(issues, issue{utils.Error, categorize(res.Error), fmt.Sprintf("%s: %s", res.Flow, res.Error)})
+		}
+	}
+	if r.TLS != nil {
+		for _, w := range r.TLS.Warnings {
+			issues = append(issues, issue{utils.Warning, Security, "TLS: " + w})
+		}
+	}
+	if r.Resumption != nil {
+		for _, w := range r.Resumption.Warnings {
+			issues = append(issues, issue{utils.Warning, Performance, "TLS resumption: " + w})
+		}
+	}
+	if r.KeepAlive != nil {
+		for _, w := range r.KeepAlive.Warnings {
+			issues = append(issues, issue{utils.Warning, Performance, "Keep-alive: " + w})
+		}
+	}
+	if r.Compression != nil {
+		for _, w := range r.Compression.Warnings {
+			issues = append(issues, issue{utils.Warning, Performance, "Compression: " + w})
+		}
+	}
+	return issues
+}
+
+// grade converts a score into a letter grade. Any blocking issue caps the grade at C.
+func grade(score float64, blocking bool) string {
+	switch {
+	case score >= 90 && !blocking:
+		return "A"
+	case score >= 75 && !blocking:
+		return "B"
+	case score >= 60:
+		return "C"
+	case score >= 40:
+		return "D"
+	}
+	return "F"
+}
+
+// Score aggregates the findings and failures of r into a weighted conformance score. A run is launch ready
+// when it has no Error or Critical issues and scores at least 90.
+func (r *Report) Score() *Scorecard {
+	s := &Scorecard{Categories: map[Category]float64{}}
+	for c := range categoryWeights {
+		s.Categories[c] = 100
+	}
+
+	issues := r.issues()
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].severity > issues[j].severity })
+	for _, i := range issues {
+		s.Categories[i.category] -= penalties[i.severity]
+		if s.Categories[i.category] < 0 {
+			s.Categories[i.category] = 0
+		}
+		if i.severity != utils.Warning && len(s.Blocking) < maxBlocking {
+			s.Blocking = append(s.Blocking, fmt.Sprintf("%s (%s, %s)", i.description, i.severity, i.category))
+		}
+	}
+	for c, w := range categoryWeights {
+		s.Score += w * s.Categories[c]
+	}
+	s.Score = math.Round(s.Score*100) / 100
+	s.Grade = grade(s.Score, len(s.Blocking) > 0)
+	s.Ready = s.Grade == "A"
+	return s
+}
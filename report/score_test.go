@@ -0,0 +1,76 @@
+package report
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		name string
+		add  func(r *Report)
+		want *Scorecard
+	}{
+		{
+			name: "clean run",
+			add: func(r *Report) {
+				r.Add("BookingAvailability", "", nil, nil)
+			},
+			want: &Scorecard{
+				Score: 100,
+				Grade: "A",
+				Ready: true,
+				Categories: map[Category]float64{
+					Schema: 100, Pricing: 100, Policies: 100, Performance: 100, Security: 100,
+				},
+			},
+		},
+		{
+			name: "warnings only",
+			add: func(r *Report) {
+				r.Add("BookingAvailability", "", []utils.Finding{{Severity: utils.Warning, Field: "rate_plans[0] > cancellation_policy > cancellation_deadline"}}, nil)
+				r.TLS = &api.CertificateReport{Warnings: []string{"certificate expires in 12 days"}}
+			},
+			want: &Scorecard{
+				Score: 98.25,
+				Grade: "A",
+				Ready: true,
+				Categories: map[Category]float64{
+					Schema: 100, Pricing: 100, Policies: 95, Performance: 100, Security: 95,
+				},
+			},
+		},
+		{
+			name: "blocking issues",
+			add: func(r *Report) {
+				r.Add("BookingAvailability", "", []utils.Finding{{Severity: utils.Warning, Field: api.ArtifactsField, Message: "request saved to artifacts/run-1/1_request.json"}}, errors.New("Validation error: required field(s) missing: hotel_id"))
+				r.Add("BookingSubmit", "", []utils.Finding{{Severity: utils.Critical, Field: "reservation > locator > id"}}, errors.New("Validation error: partner did not deduplicate transaction_id 1"))
+			},
+			want: &Scorecard{
+				Score: 72.5,
+				Grade: "C",
+				Categories: map[Category]float64{
+					Schema: 75, Pricing: 100, Policies: 0, Performance: 100, Security: 100,
+				},
+				Blocking: []string{
+					"BookingSubmit: reservation > locator > id (Critical, policies)",
+					"BookingAvailability: Validation error: required field(s) missing: hotel_id (Error, schema)",
+				},
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := New("run-1")
+			tc.add(r)
+			if diff := cmp.Diff(r.Score(), tc.want); diff != "" {
+				t.Errorf("Score() did not match (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package report
+
+import (
+	"fmt"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+// Version, Commit and BuildDate describe the validator binary. They are set at build time, e.g.
+//
+//	go install -ldflags "-X github.com/google/hotel-booking-api-validator/report.Version=v1.4.0 -X github.com/google/hotel-booking-api-validator/report.Commit=$(git rev-parse HEAD) -X github.com/google/hotel-booking-api-validator/report.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildDate = ""
+)
+
+// Build identifies the validator and the checks that produced a report.
+type Build struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+	RuleSet   string `json:"rule_set"`
+}
+
+// CurrentBuild returns the Build of the running validator.
+func CurrentBuild() Build {
+	return Build{Version: Version, Commit: Commit, BuildDate: BuildDate, RuleSet: utils.RuleSetVersion}
+}
+
+func (b Build) String() string {
+	s := b.Version
+	if b.Commit != "" {
+		s += fmt.Sprintf(" (commit %s)", b.Commit)
+	}
+	if b.BuildDate != "" {
+		s += fmt.Sprintf(" built %s", b.BuildDate)
+	}
+	return s + fmt.Sprintf(", rule set %s", b.RuleSet)
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scenario
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+// DefaultMaxTransportFailures is the number of consecutive transport failures after which a batch stops.
+const DefaultMaxTransportFailures = 10
+
+// breaker stops a batch once the partner hard-fails, instead of sending every remaining request only to record the
+// same transport error. It opens after threshold consecutive requests fail with api.ErrTransport, which includes
+// timeouts, and never closes again; a threshold of 0 disables it.
+type breaker struct {
+	threshold   int
+	consecutive int
+	lastErr     error
+	notRun      int
+}
+
+// record records the outcome of a request.
+func (b *breaker) record(err error) {
+	if !errors.Is(err, api.ErrTransport) {
+		b.consecutive = 0
+		return
+	}
+	b.consecutive++
+	b.lastErr = err
+	if b.open() && b.consecutive == b.threshold {
+		log.Printf("Circuit breaker opened after %d consecutive transport failures, skipping the remaining requests of the batch: %v", b.consecutive, err)
+	}
+}
+
+// open reports whether the remaining requests of the batch should be skipped.
+func (b *breaker) open() bool {
+	return b.threshold > 0 && b.consecutive >= b.threshold
+}
+
+// skip records a request that is not sent because the breaker is open.
+func (b *breaker) skip(rules *utils.RuleTally) {
+	b.notRun++
+	rules.Skip()
+}
+
+// findings returns the Error finding of an open breaker, or nil if it never opened.
+func (b *breaker) findings(batch string) []utils.Finding {
+	if !b.open() {
+		return nil
+	}
+	f := utils.Finding{
+		Severity: utils.Error,
+		Field:    "circuit_breaker",
+		Message:  fmt.Sprintf("%s stopped after %d consecutive transport failures, %d request(s) not run; last error: %v", batch, b.threshold, b.notRun, b.lastErr),
+		Code:     utils.CodeCircuitOpen,
+	}
+	log.Println(f)
+	return []utils.Finding{f}
+}
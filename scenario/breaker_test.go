@@ -0,0 +1,57 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+func TestBreaker(t *testing.T) {
+	transport := fmt.Errorf("%w: connection refused", api.ErrTransport)
+	b := &breaker{threshold: 2}
+	for _, err := range []error{transport, api.ErrValidation, transport} {
+		b.record(err)
+	}
+	if b.open() {
+		t.Error("breaker opened after failures that were not consecutive")
+	}
+	b.record(transport)
+	if !b.open() {
+		t.Error("breaker did not open after 2 consecutive transport failures")
+	}
+	if f := b.findings("batch"); len(f) != 1 || f[0].Code != utils.CodeCircuitOpen {
+		t.Errorf("findings() of an open breaker = %v, want a %s finding", f, utils.CodeCircuitOpen)
+	}
+
+	disabled := &breaker{}
+	for i := 0; i < 100; i++ {
+		disabled.record(transport)
+	}
+	if disabled.open() {
+		t.Error("breaker with threshold 0 opened")
+	}
+}
+
+func TestSweepCircuitBreaker(t *testing.T) {
+	availability, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, server := newEchoServer(t, 7, 8)
+	server.Close()
+
+	cfg := SweepConfig{MinAdults: 1, MaxAdults: 2, Nights: []int{1, 2, 3}, Rules: &utils.RuleTally{}, MaxTransportFailures: 3}
+	cases, findings, err := Sweep(availability.ReqPb, conn, "/v1/BookingAvailability", cfg)
+	if err == nil || !strings.Contains(err.Error(), "3 not run") {
+		t.Errorf("Sweep() returned error %v, want 3 combinations not run", err)
+	}
+	if len(cases) != 3 || cfg.Rules.Requests() != 3 || cfg.Rules.NotRun() != 3 {
+		t.Errorf("Sweep() ran %d cases, %d requests and skipped %d, want 3 of each", len(cases), cfg.Rules.Requests(), cfg.Rules.NotRun())
+	}
+	if last := findings[len(findings)-1]; last.Code != utils.CodeCircuitOpen {
+		t.Errorf("Sweep() last finding is %v, want a %s finding", last, utils.CodeCircuitOpen)
+	}
+}
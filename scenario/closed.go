@@ -0,0 +1,77 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scenario
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// ClosedDates searches availability for stays starting on each of dates, in the date layout of base, on which the
+// partner's hotel is closed or sold out. The v1 API has no sold out error type: the partner must answer with an empty
+// room_rates list, a response that otherwise passes validation. An error response, or any room rate, is an Error
+// finding, and an error is returned if any date was answered wrongly. Each stay keeps the length of base. Spaces
+// around dates are ignored.
+func ClosedDates(base *pb.BookingAvailabilityRequest, conn *api.HTTPConnection, endpoint string, dates []string) ([]utils.Finding, error) {
+	layout := utils.FormatsFor(base.GetApiVersion()).DateLayout
+	var findings []utils.Finding
+	var failed []string
+	for _, date := range dates {
+		date = strings.TrimSpace(date)
+		day, err := time.Parse(layout, date)
+		if err != nil {
+			return findings, fmt.Errorf("invalid closed date %q, expected the layout %s: %v", date, layout, err)
+		}
+		req := proto.Clone(base).(*pb.BookingAvailabilityRequest)
+		req.StartDate, req.EndDate = shiftStay(req.GetApiVersion(), req.GetStartDate(), req.GetEndDate(), day)
+		field := "closed " + date
+
+		resp, err := api.SendBookingAvailability(req, conn, endpoint)
+		if err == nil && resp.GetError() != nil {
+			err = fmt.Errorf("returned availability error %v, a closed or sold out hotel should return an empty room_rates list", resp.GetError().GetType())
+		}
+		if err == nil {
+			var validation []utils.Finding
+			validation, err = utils.ValidateBookingAvailabilityResponse(req, resp)
+			findings = append(findings, validation...)
+		}
+		if err == nil && len(resp.GetRoomRates()) > 0 {
+			err = fmt.Errorf("returned %d room rate(s) for a date the hotel is closed or sold out", len(resp.GetRoomRates()))
+		}
+		if err != nil {
+			failed = append(failed, date)
+			f := utils.Finding{Severity: utils.Error, Field: field, Message: err.Error(), Code: utils.CodeClosedDate}
+			log.Println(f)
+			findings = append(findings, f)
+			continue
+		}
+		log.Printf("Closed date %s returned no room rates as expected", date)
+	}
+	if len(failed) > 0 {
+		return findings, fmt.Errorf("closed date(s) not answered with an empty room_rates list: %s", strings.Join(failed, ", "))
+	}
+	return findings, nil
+}
@@ -0,0 +1,67 @@
+package scenario
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestClosedDates(t *testing.T) {
+	availability, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The hotel is sold out from 2019-12-24, answers 2019-12-25 with an error and stays open on 2019-12-26.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req pb.BookingAvailabilityRequest
+		if err := jsonpb.Unmarshal(r.Body, &req); err != nil {
+			t.Errorf("server received invalid request: %v", err)
+		}
+		resp := proto.Clone(availability.RespPb).(*pb.BookingAvailabilityResponse)
+		resp.StartDate, resp.EndDate = req.GetStartDate(), req.GetEndDate()
+		switch req.GetStartDate() {
+		case "2019-12-24":
+			resp.RoomRates = nil
+		case "2019-12-25":
+			resp = &pb.BookingAvailabilityResponse{ApiVersion: 1, TransactionId: req.GetTransactionId(), Error: &pb.AvailabilityError{Type: pb.AvailabilityError_SUPPLIER_ERROR, Message: "sold out"}}
+		}
+		m := jsonpb.Marshaler{OrigName: true}
+		if err := m.Marshal(w, resp); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+	conn, err := api.InitHTTPConnection(strings.TrimPrefix(server.URL, "http://"), "", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if findings, err := ClosedDates(availability.ReqPb, conn, "", []string{" 2019-12-24 "}); err != nil || len(findings) != 0 {
+		t.Errorf("ClosedDates() of a sold out date = %v, %v, want no findings", findings, err)
+	}
+	findings, err := ClosedDates(availability.ReqPb, conn, "", []string{"2019-12-24", "2019-12-25", "2019-12-26"})
+	if err == nil || !strings.HasSuffix(err.Error(), ": 2019-12-25, 2019-12-26") {
+		t.Errorf("ClosedDates() returned error %v, want the dates answered with an error and with room rates", err)
+	}
+	var fields []string
+	for _, f := range findings {
+		if f.Severity == utils.Error {
+			fields = append(fields, f.Field)
+		}
+	}
+	if got := strings.Join(fields, ", "); got != "closed 2019-12-25, closed 2019-12-26" {
+		t.Errorf("ClosedDates() returned Error findings for %s, want closed 2019-12-25 and closed 2019-12-26", got)
+	}
+	if _, err := ClosedDates(availability.ReqPb, conn, "", []string{"24/12/2019"}); err == nil {
+		t.Error("ClosedDates() of a date in the wrong layout returned nil error")
+	}
+}
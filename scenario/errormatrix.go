@@ -0,0 +1,368 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scenario
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// ErrorCase is an entry of the error matrix: a condition triggered by altering one of the sample requests, and the
+// error types the partner may answer it with.
+type ErrorCase struct {
+	Name string `json:"name"`
+	// Flow is either availability or submit.
+	Flow string `json:"flow"`
+	// Trigger names the alteration of the request, one of availabilityTriggers or submitTriggers.
+	Trigger string `json:"trigger"`
+	// HotelID is the sandbox property used by the sandbox_hotel trigger, e.g. one that is always sold out.
+	HotelID string `json:"hotel_id,omitempty"`
+	// CardNumber is the card number sent by the test_card trigger, one your payment processor documents as declined,
+	// e.g. for insufficient funds or a fraud check.
+	CardNumber string `json:"card_number,omitempty"`
+	// Status is the HTTP status the partner must answer with, e.g. 400. If 0 any status is accepted.
+	Status int `json:"status,omitempty"`
+	// Expect lists the accepted error types. It may be left empty if Status is set, in which case the body of the
+	// response is not checked.
+	Expect []string `json:"expect,omitempty"`
+	// Now is an RFC 3339 timestamp sent as the current time to partner sandboxes supporting a time override header,
+	// e.g. to book after a cancellation deadline. The triggers and validation of the case are relative to it.
+	Now string `json:"now,omitempty"`
+}
+
+// UnknownHotelID is a hotel_id no partner lists, sent by the invalid_hotel error cases.
+const UnknownHotelID = "validator-unknown-hotel"
+
+// now is the clock the past_dates and expired_card triggers are relative to, faked by a time override.
+var now = utils.Now
+
+// shiftStay moves the stay of start and end, in the layout of apiVersion, to begin on day, keeping its length.
+func shiftStay(apiVersion int32, start, end string, day time.Time) (string, string) {
+	layout := utils.FormatsFor(apiVersion).DateLayout
+	s, err1 := time.Parse(layout, start)
+	e, err2 := time.Parse(layout, end)
+	if err1 != nil || err2 != nil {
+		return day.Format(layout), day.AddDate(0, 0, 1).Format(layout)
+	}
+	return day.Format(layout), day.Add(e.Sub(s)).Format(layout)
+}
+
+var availabilityTriggers = map[string]func(*pb.BookingAvailabilityRequest, ErrorCase){
+	"invalid_hotel": func(r *pb.BookingAvailabilityRequest, _ ErrorCase) { r.HotelId = UnknownHotelID },
+	"sandbox_hotel": func(r *pb.BookingAvailabilityRequest, c ErrorCase) { r.HotelId = c.HotelID },
+	"past_dates": func(r *pb.BookingAvailabilityRequest, _ ErrorCase) {
+		r.StartDate, r.EndDate = shiftStay(r.GetApiVersion(), r.GetStartDate(), r.GetEndDate(), now().AddDate(-1, 0, 0))
+	},
+	"reversed_dates":          func(r *pb.BookingAvailabilityRequest, _ ErrorCase) { r.StartDate, r.EndDate = r.EndDate, r.StartDate },
+	"unsupported_api_version": func(r *pb.BookingAvailabilityRequest, _ ErrorCase) { r.ApiVersion = 999 },
+}
+
+var submitTriggers = map[string]func(*pb.BookingSubmitRequest, ErrorCase){
+	"invalid_hotel": func(r *pb.BookingSubmitRequest, _ ErrorCase) { r.HotelId = UnknownHotelID },
+	"sandbox_hotel": func(r *pb.BookingSubmitRequest, c ErrorCase) { r.HotelId = c.HotelID },
+	"past_dates": func(r *pb.BookingSubmitRequest, _ ErrorCase) {
+		r.StartDate, r.EndDate = shiftStay(r.GetApiVersion(), r.GetStartDate(), r.GetEndDate(), now().AddDate(-1, 0, 0))
+	},
+	"price_change": func(r *pb.BookingSubmitRequest, _ ErrorCase) {
+		rate := r.GetRoomRate()
+		for _, p := range []*pb.Price{rate.GetTotalPriceAtBooking(), rate.GetTotalPriceAtCheckout()} {
+			if p != nil {
+				p.Amount /= 2
+			}
+		}
+		for _, l := range rate.GetLineItems() {
+			if l.GetPrice() != nil {
+				l.Price.Amount /= 2
+			}
+		}
+	},
+	"unknown_room_rate": func(r *pb.BookingSubmitRequest, _ ErrorCase) {
+		if r.RoomRate == nil {
+			r.RoomRate = &pb.RoomRate{}
+		}
+		r.RoomRate.Code = "validator-unknown-room-rate"
+	},
+	"expired_card": func(r *pb.BookingSubmitRequest, _ ErrorCase) {
+		if c := r.GetPayment().GetPaymentCardParameters(); c != nil {
+			c.ExpirationMonth, c.ExpirationYear = "01", strconv.Itoa(now().Year()-1)
+		}
+	},
+	"test_card": func(r *pb.BookingSubmitRequest, c ErrorCase) {
+		if p := r.GetPayment().GetPaymentCardParameters(); p != nil {
+			p.CardNumber = c.CardNumber
+			if t, ok := cardType(c.CardNumber); ok {
+				p.CardType = t
+			}
+		}
+	},
+}
+
+// cardPrefixes maps the leading digits of card numbers to their card type.
+var cardPrefixes = []struct {
+	prefix string
+	typ    pb.CardType
+}{
+	{"34", pb.CardType_AX}, {"37", pb.CardType_AX},
+	{"30", pb.CardType_DC}, {"36", pb.CardType_DC}, {"38", pb.CardType_DC},
+	{"6011", pb.CardType_DS}, {"65", pb.CardType_DS},
+	{"35", pb.CardType_JC},
+	{"2", pb.CardType_MC}, {"5", pb.CardType_MC},
+	{"4", pb.CardType_VI},
+}
+
+// cardType returns the type of the card number, so a test card of another network than the sample request's is sent
+// with a matching card_type.
+func cardType(number string) (pb.CardType, bool) {
+	for _, p := range cardPrefixes {
+		if strings.HasPrefix(number, p.prefix) {
+			return p.typ, true
+		}
+	}
+	return 0, false
+}
+
+// testCardNumber matches the card numbers accepted by the test_card trigger.
+var testCardNumber = regexp.MustCompile(`^[0-9]{12,19}$`)
+
+// genericSubmitErrors are the error types that do not tell the customer why a payment failed. They fail payment cases
+// with CodeErrorCaseGeneric, as a partner returning them likely maps every processor response to one error.
+var genericSubmitErrors = map[string]bool{
+	"UNKNOWN_ERROR":           true,
+	"NETWORK_ERROR":           true,
+	"RECOVERABLE_ERROR":       true,
+	"SUPPLIER_ERROR":          true,
+	"PAYMENT_INVALID":         true,
+	"PAYMENT_PROCESSOR_ERROR": true,
+}
+
+// paymentCase reports whether every error type expected by c is a payment error.
+func paymentCase(c ErrorCase) bool {
+	for _, e := range c.Expect {
+		if !strings.HasPrefix(e, "PAYMENT_") {
+			return false
+		}
+	}
+	return c.Flow == "submit" && len(c.Expect) > 0
+}
+
+// LoadErrorMatrix reads a json encoded list of ErrorCases, such as data/error_matrix.json, and ensures every case
+// has a known flow, trigger and error types.
+func LoadErrorMatrix(path string) ([]ErrorCase, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cases []ErrorCase
+	if err := json.Unmarshal(b, &cases); err != nil {
+		return nil, fmt.Errorf("unable to parse error matrix %s: %v", path, err)
+	}
+	for _, c := range cases {
+		var known bool
+		var codes map[string]int32
+		switch c.Flow {
+		case "availability":
+			_, known = availabilityTriggers[c.Trigger]
+			codes = pb.AvailabilityError_AvailabilityErrorType_value
+		case "submit":
+			_, known = submitTriggers[c.Trigger]
+			codes = pb.SubmitError_SubmitErrorType_value
+		default:
+			return nil, fmt.Errorf("error case %s has unknown flow %q, expected availability or submit", c.Name, c.Flow)
+		}
+		if !known {
+			return nil, fmt.Errorf("error case %s has unknown %s trigger %q", c.Name, c.Flow, c.Trigger)
+		}
+		if c.Trigger == "test_card" && !testCardNumber.MatchString(c.CardNumber) {
+			return nil, fmt.Errorf("error case %s has card_number %q, want 12 to 19 digits", c.Name, c.CardNumber)
+		}
+		if len(c.Expect) == 0 && c.Status == 0 {
+			return nil, fmt.Errorf("error case %s expects neither an error type nor a status", c.Name)
+		}
+		if _, err := time.Parse(time.RFC3339, c.Now); c.Now != "" && err != nil {
+			return nil, fmt.Errorf("error case %s has now %q, want an RFC 3339 timestamp", c.Name, c.Now)
+		}
+		for _, e := range c.Expect {
+			if _, ok := codes[e]; !ok {
+				return nil, fmt.Errorf("error case %s expects unknown %s error type %s", c.Name, c.Flow, e)
+			}
+		}
+	}
+	return cases, nil
+}
+
+// SelectErrorCases returns the cases whose name is in names, or all cases if names is empty.
+func SelectErrorCases(cases []ErrorCase, names []string) ([]ErrorCase, error) {
+	if len(names) == 0 {
+		return cases, nil
+	}
+	byName := map[string]ErrorCase{}
+	for _, c := range cases {
+		byName[c.Name] = c
+	}
+	var selected []ErrorCase
+	for _, n := range names {
+		c, ok := byName[n]
+		if !ok {
+			return nil, fmt.Errorf("error case %s is not in the error matrix", n)
+		}
+		selected = append(selected, c)
+	}
+	return selected, nil
+}
+
+// ErrorMatrix triggers each of cases by altering availReq or submitReq and ensures the partner answers with one of
+// the expected error types. Cases of a flow without a request, and sandbox_hotel cases without a hotel_id, are
+// skipped, and so are test_card cases if submitReq pays without card parameters and cases with a Now if conn has no
+// time override header. Each submit case is sent with its own transaction_id; a partner that accepts one makes a
+// real booking. The error messages of the responses are linted as well. A case with a Status fails unless the partner
+// answers with that HTTP status. A payment case answered with a generic error type, such as PAYMENT_PROCESSOR_ERROR,
+// fails with CodeErrorCaseGeneric.
+func ErrorMatrix(cases []ErrorCase, availReq *pb.BookingAvailabilityRequest, submitReq *pb.BookingSubmitRequest, conn *api.HTTPConnection, availEndpoint, submitEndpoint string) ([]utils.Finding, error) {
+	var findings []utils.Finding
+	var failed []string
+	for i, c := range cases {
+		if c.Trigger == "sandbox_hotel" && c.HotelID == "" {
+			log.Printf("Skipping error case %s: no sandbox hotel_id configured", c.Name)
+			continue
+		}
+		var got string
+		var lint []utils.Finding
+		var err error
+		switch c.Flow {
+		case "availability":
+			if availReq == nil {
+				log.Printf("Skipping error case %s: no availability request", c.Name)
+				continue
+			}
+			restoreTime, ok := overrideTime(conn, c.Now)
+			if !ok {
+				log.Printf("Skipping error case %s: no time override header configured", c.Name)
+				continue
+			}
+			req := proto.Clone(availReq).(*pb.BookingAvailabilityRequest)
+			availabilityTriggers[c.Trigger](req, c)
+			restore := expectStatus(conn, availEndpoint, c.Status)
+			var resp *pb.BookingAvailabilityResponse
+			if resp, err = api.SendBookingAvailability(req, conn, availEndpoint); err == nil && resp.GetError() != nil {
+				got, lint = resp.GetError().GetType().String(), utils.LintAvailabilityError(resp.GetError())
+			}
+			restore()
+			restoreTime()
+		case "submit":
+			if submitReq == nil {
+				log.Printf("Skipping error case %s: no submit request", c.Name)
+				continue
+			}
+			if c.Trigger == "test_card" && submitReq.GetPayment().GetPaymentCardParameters() == nil {
+				log.Printf("Skipping error case %s: submit request has no payment_card_parameters", c.Name)
+				continue
+			}
+			restoreTime, ok := overrideTime(conn, c.Now)
+			if !ok {
+				log.Printf("Skipping error case %s: no time override header configured", c.Name)
+				continue
+			}
+			req := proto.Clone(submitReq).(*pb.BookingSubmitRequest)
+			req.TransactionId = fmt.Sprintf("%s-error-%d", submitReq.GetTransactionId(), i)
+			submitTriggers[c.Trigger](req, c)
+			restore := expectStatus(conn, submitEndpoint, c.Status)
+			var resp *pb.BookingSubmitResponse
+			if resp, err = api.SendBookingSubmit(req, conn, submitEndpoint); err == nil && resp.GetStatus() == pb.BookingSubmitResponse_FAILURE {
+				got, lint = resp.GetError().GetType().String(), utils.LintSubmitError(resp.GetError())
+			}
+			restore()
+			restoreTime()
+		}
+		if len(c.Expect) == 0 && (err == nil || errors.Is(err, api.ErrParse)) {
+			findings = append(findings, lint...)
+			log.Printf("Error case %s returned HTTP status %d as expected", c.Name, c.Status)
+			continue
+		}
+		if err != nil {
+			failed = append(failed, c.Name)
+			findings = append(findings, utils.Finding{Severity: utils.Error, Field: c.Name, Message: err.Error(), Code: utils.CodeErrorCaseFailed})
+			continue
+		}
+		findings = append(findings, lint...)
+		if !expected(got, c.Expect) {
+			if got == "" {
+				got = "success"
+			}
+			failed = append(failed, c.Name)
+			f := utils.Finding{Severity: utils.Error, Field: c.Name, Message: fmt.Sprintf("returned %s, want one of %s", got, strings.Join(c.Expect, ", ")), Code: utils.CodeErrorCaseType}
+			if genericSubmitErrors[got] && paymentCase(c) {
+				f.Message, f.Code = fmt.Sprintf("returned %s, a generic error type, want the payment failure %s", got, strings.Join(c.Expect, " or ")), utils.CodeErrorCaseGeneric
+			}
+			log.Println(f)
+			findings = append(findings, f)
+			continue
+		}
+		log.Printf("Error case %s returned %s as expected", c.Name, got)
+	}
+	if len(failed) > 0 {
+		return findings, fmt.Errorf("error case(s) not answered with the expected error type: %s", strings.Join(failed, ", "))
+	}
+	return findings, nil
+}
+
+// overrideTime fakes the current time to the RFC 3339 timestamp now for a single error case, if any, and returns a
+// function restoring the previous time. It returns false if now is set but conn has no time override header.
+func overrideTime(conn *api.HTTPConnection, now string) (func(), bool) {
+	if now == "" {
+		return func() {}, true
+	}
+	t, _ := time.Parse(time.RFC3339, now)
+	prev := conn.OverriddenTime()
+	if err := conn.OverrideTime(t); err != nil {
+		return nil, false
+	}
+	return func() { conn.OverrideTime(prev) }, true
+}
+
+// expectStatus sets the expected status of endpoint for a single error case, if any, and returns a function restoring
+// the previous expectation.
+func expectStatus(conn *api.HTTPConnection, endpoint string, status int) func() {
+	if status == 0 {
+		return func() {}
+	}
+	prev := conn.ExpectedStatus(endpoint)
+	conn.SetExpectedStatus(endpoint, status)
+	return func() { conn.SetExpectedStatus(endpoint, prev) }
+}
+
+func expected(got string, want []string) bool {
+	for _, w := range want {
+		if got == w {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package scenario
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestLoadErrorMatrix(t *testing.T) {
+	cases, err := LoadErrorMatrix("../data/error_matrix.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) == 0 {
+		t.Fatal("LoadErrorMatrix() returned no cases")
+	}
+	if _, err := SelectErrorCases(cases, []string{"submit_price_change", "no_such_case"}); err == nil {
+		t.Error("SelectErrorCases() of an unknown case returned nil error")
+	}
+}
+
+func TestErrorMatrix(t *testing.T) {
+	availability, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The server knows hotel 123 only, but accepts any dates.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req pb.BookingAvailabilityRequest
+		if err := jsonpb.Unmarshal(r.Body, &req); err != nil {
+			t.Errorf("server received invalid request: %v", err)
+		}
+		resp := proto.Clone(availability.RespPb).(*pb.BookingAvailabilityResponse)
+		if req.GetHotelId() != "123" {
+			resp = &pb.BookingAvailabilityResponse{Error: &pb.AvailabilityError{Type: pb.AvailabilityError_HOTEL_NOT_FOUND, Message: "hotel not found (request id: 7f3a2c91)"}}
+		}
+		m := jsonpb.Marshaler{OrigName: true}
+		if err := m.Marshal(w, resp); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+	conn, err := api.InitHTTPConnection(strings.TrimPrefix(server.URL, "http://"), "", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []ErrorCase{
+		{Name: "hotel_not_found", Flow: "availability", Trigger: "invalid_hotel", Expect: []string{"HOTEL_NOT_FOUND"}},
+		{Name: "dates_reversed", Flow: "availability", Trigger: "reversed_dates", Expect: []string{"DATE_SELECTION_INVALID"}},
+		{Name: "sold_out", Flow: "availability", Trigger: "sandbox_hotel", Expect: []string{"HOTEL_NOT_FOUND"}},
+		{Name: "price_change", Flow: "submit", Trigger: "price_change", Expect: []string{"ROOM_RATE_PRICE_MISMATCH"}},
+	}
+	findings, err := ErrorMatrix(cases, availability.ReqPb, nil, conn, "", "")
+	if err == nil || !strings.HasSuffix(err.Error(), ": dates_reversed") {
+		t.Errorf("ErrorMatrix() returned error %v, want only dates_reversed to fail", err)
+	}
+	if len(findings) != 1 || findings[0].Field != "dates_reversed" || findings[0].Message != "returned success, want one of DATE_SELECTION_INVALID" {
+		t.Errorf("ErrorMatrix() returned findings %v, want a single finding for dates_reversed", findings)
+	}
+}
+
+func TestErrorMatrixStatus(t *testing.T) {
+	availability, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The server rejects unknown hotels with a plain text 400 Bad Request.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unknown hotel", http.StatusBadRequest)
+	}))
+	defer server.Close()
+	conn, err := api.InitHTTPConnection(strings.TrimPrefix(server.URL, "http://"), "", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []ErrorCase{
+		{Name: "bad_request", Flow: "availability", Trigger: "invalid_hotel", Status: http.StatusBadRequest},
+		{Name: "conflict", Flow: "availability", Trigger: "invalid_hotel", Status: http.StatusConflict, Expect: []string{"HOTEL_NOT_FOUND"}},
+	}
+	findings, err := ErrorMatrix(cases, availability.ReqPb, nil, conn, "/v1/BookingAvailability", "")
+	if err == nil || !strings.HasSuffix(err.Error(), ": conflict") {
+		t.Errorf("ErrorMatrix() returned error %v, want only conflict to fail", err)
+	}
+	if len(findings) != 1 || findings[0].Field != "conflict" || !strings.Contains(findings[0].Message, "returned 400 Bad Request, want 409 Conflict") {
+		t.Errorf("ErrorMatrix() returned findings %v, want a single finding for conflict with the actual status", findings)
+	}
+	if got := conn.ExpectedStatus("/v1/BookingAvailability"); got != 0 {
+		t.Errorf("ErrorMatrix() left expected status %d, want it restored to 0", got)
+	}
+}
+
+func TestErrorMatrixTestCards(t *testing.T) {
+	submit, err := utils.BookingSubmitData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The processor declines every card ending in 0002, the partner maps other declines to a generic error.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req pb.BookingSubmitRequest
+		if err := jsonpb.Unmarshal(r.Body, &req); err != nil {
+			t.Errorf("server received invalid request: %v", err)
+		}
+		card := req.GetPayment().GetPaymentCardParameters()
+		if card.GetCardType() != pb.CardType_MC && strings.HasPrefix(card.GetCardNumber(), "5") {
+			t.Errorf("test card %s sent with card_type %s, want MC", card.GetCardNumber(), card.GetCardType())
+		}
+		errType := pb.SubmitError_PAYMENT_PROCESSOR_ERROR
+		if strings.HasSuffix(card.GetCardNumber(), "0002") {
+			errType = pb.SubmitError_PAYMENT_DECLINED
+		}
+		resp := &pb.BookingSubmitResponse{TransactionId: req.GetTransactionId(), Status: pb.BookingSubmitResponse_FAILURE, Error: &pb.SubmitError{Type: errType, Message: "card declined (request id: 7f3a2c91)"}}
+		m := jsonpb.Marshaler{OrigName: true}
+		if err := m.Marshal(w, resp); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+	conn, err := api.InitHTTPConnection(strings.TrimPrefix(server.URL, "http://"), "", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []ErrorCase{
+		{Name: "declined", Flow: "submit", Trigger: "test_card", CardNumber: "4000000000000002", Expect: []string{"PAYMENT_DECLINED"}},
+		{Name: "insufficient_funds", Flow: "submit", Trigger: "test_card", CardNumber: "5100000000009995", Expect: []string{"PAYMENT_INSUFFICIENT", "PAYMENT_DECLINED"}},
+	}
+	findings, err := ErrorMatrix(cases, nil, submit.ReqPb, conn, "", "/v1/BookingSubmit")
+	if err == nil || !strings.HasSuffix(err.Error(), ": insufficient_funds") {
+		t.Errorf("ErrorMatrix() returned error %v, want only insufficient_funds to fail", err)
+	}
+	if len(findings) != 1 || findings[0].Code != utils.CodeErrorCaseGeneric {
+		t.Errorf("ErrorMatrix() returned findings %v, want a single %s finding", findings, utils.CodeErrorCaseGeneric)
+	}
+}
+
+func TestErrorMatrixTimeOverride(t *testing.T) {
+	availability, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The sandbox answers every search as of the time it is told it is, rejecting stays starting before then.
+	var times []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		times = append(times, r.Header.Get("X-Sandbox-Time"))
+		resp := &pb.BookingAvailabilityResponse{Error: &pb.AvailabilityError{Type: pb.AvailabilityError_DATE_SELECTION_INVALID, Message: "dates in the past (request id: 7f3a2c91)"}}
+		m := jsonpb.Marshaler{OrigName: true}
+		if err := m.Marshal(w, resp); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+	conn, err := api.InitHTTPConnection(strings.TrimPrefix(server.URL, "http://"), "", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []ErrorCase{
+		{Name: "past_dates", Flow: "availability", Trigger: "past_dates", Now: "2030-03-28T12:00:00Z", Expect: []string{"DATE_SELECTION_INVALID"}},
+	}
+	if _, err := ErrorMatrix(cases, availability.ReqPb, nil, conn, "", ""); err != nil || len(times) != 0 {
+		t.Errorf("ErrorMatrix() without a time override header returned error %v after %d request(s), want the case skipped", err, len(times))
+	}
+	conn.SetTimeOverrideHeader("X-Sandbox-Time")
+	if _, err := ErrorMatrix(cases, availability.ReqPb, nil, conn, "", ""); err != nil {
+		t.Errorf("ErrorMatrix() returned error %v, want nil", err)
+	}
+	if strings.Join(times, ",") != "2030-03-28T12:00:00Z" {
+		t.Errorf("ErrorMatrix() sent times %q, want 2030-03-28T12:00:00Z", times)
+	}
+	if !conn.OverriddenTime().IsZero() || utils.Now().Year() == 2030 {
+		t.Errorf("ErrorMatrix() left the time overridden to %v", conn.OverriddenTime())
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// ExtensionField is the name of the field, unknown to the v1 schema, added to echoed structures by the extension
+// scenarios.
+const ExtensionField = "x_validator_extension"
+
+// extensionPoint is a nested structure of a request and the place its echo is found in the response.
+type extensionPoint struct {
+	request  []string
+	response []string
+}
+
+var (
+	availabilityExtensionPoints = []extensionPoint{
+		{[]string{"party"}, []string{"party"}},
+	}
+	submitExtensionPoints = []extensionPoint{
+		{[]string{"customer"}, []string{"reservation", "customer"}},
+		{[]string{"traveler"}, []string{"reservation", "traveler"}},
+		{[]string{"traveler", "occupancy"}, []string{"reservation", "traveler", "occupancy"}},
+		{[]string{"room_rate"}, []string{"reservation", "room_rate"}},
+		{[]string{"room_rate", "maximum_allowed_occupancy"}, []string{"reservation", "room_rate", "maximum_allowed_occupancy"}},
+		{[]string{"room_rate", "line_items", "0"}, []string{"reservation", "room_rate", "line_items", "0"}},
+	}
+)
+
+// AvailabilityExtensions sends req with an ExtensionField in every echoed structure and ensures the partner echoes
+// the structures in full, extension included, as a forward compatible server would. See extensions.
+func AvailabilityExtensions(req *pb.BookingAvailabilityRequest, conn *api.HTTPConnection, endpoint string) ([]utils.Finding, error) {
+	var resp pb.BookingAvailabilityResponse
+	return extensions(req, &resp, availabilityExtensionPoints, conn, endpoint, func() error {
+		if resp.GetError() != nil {
+			return fmt.Errorf("returned error %v", resp.GetError().GetType())
+		}
+		return nil
+	}, func() ([]utils.Finding, error) {
+		return utils.ValidateBookingAvailabilityResponse(req, &resp)
+	})
+}
+
+// SubmitExtensions is the BookingSubmit counterpart of AvailabilityExtensions. The request is sent with its own
+// transaction_id and, if accepted, is a real booking.
+func SubmitExtensions(req *pb.BookingSubmitRequest, conn *api.HTTPConnection, endpoint string) ([]utils.Finding, error) {
+	req = proto.Clone(req).(*pb.BookingSubmitRequest)
+	req.TransactionId += "-extensions"
+	var resp pb.BookingSubmitResponse
+	return extensions(req, &resp, submitExtensionPoints, conn, endpoint, func() error {
+		if resp.GetStatus() == pb.BookingSubmitResponse_FAILURE {
+			return fmt.Errorf("returned error %v", resp.GetError().GetType())
+		}
+		return nil
+	}, func() ([]utils.Finding, error) {
+		return utils.ValidateBookingSubmitResponse(req, &resp)
+	})
+}
+
+// extensions adds an ExtensionField to each of the points present in req, sends it and parses the response into
+// resp. A request the partner rejected, as reported by rejected, is a Warning finding. Otherwise the known fields must
+// pass validate, which compares the echoed structures with the request, and every extension missing from the echo is
+// an Error finding.
+func extensions(req, resp proto.Message, points []extensionPoint, conn *api.HTTPConnection, endpoint string, rejected func() error, validate func() ([]utils.Finding, error)) ([]utils.Finding, error) {
+	s, err := (&jsonpb.Marshaler{OrigName: true}).MarshalToString(req)
+	if err != nil {
+		return nil, fmt.Errorf("Could not convert pb3 to json: %v", err)
+	}
+	var body interface{}
+	if err := json.Unmarshal([]byte(s), &body); err != nil {
+		return nil, err
+	}
+	var sent []extensionPoint
+	for i, p := range points {
+		if m, ok := lookup(body, p.request).(map[string]interface{}); ok {
+			m[ExtensionField] = map[string]interface{}{"probe": fmt.Sprintf("extension-%d", i), "nested": []interface{}{"a", 1.0}}
+			sent = append(sent, p)
+		}
+	}
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	httpResp, err := api.SendJSON(string(b), conn, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	var echo interface{}
+	if err := json.Unmarshal([]byte(httpResp), &echo); err != nil {
+		return nil, fmt.Errorf("%w: %v", api.ErrParse, err)
+	}
+	if err := (&jsonpb.Unmarshaler{AllowUnknownFields: true}).Unmarshal(strings.NewReader(httpResp), resp); err != nil {
+		return nil, fmt.Errorf("%w: %v", api.ErrParse, err)
+	}
+	if err := rejected(); err != nil {
+		f := utils.Finding{Severity: utils.Warning, Field: ExtensionField, Message: fmt.Sprintf("request with unknown fields was rejected: %v", err), Code: utils.CodeExtensionRejected}
+		log.Println(f)
+		return []utils.Finding{f}, nil
+	}
+	findings, err := validate()
+	if err != nil {
+		return findings, fmt.Errorf("%w: %v", api.ErrValidation, err)
+	}
+
+	var dropped []string
+	for _, p := range sent {
+		field := strings.Join(p.response, " > ")
+		got := lookup(echo, append(p.response, ExtensionField))
+		want := lookup(body, append(p.request, ExtensionField))
+		if !reflect.DeepEqual(got, want) {
+			dropped = append(dropped, field)
+			f := utils.Finding{Severity: utils.Error, Field: field, Message: fmt.Sprintf("unknown field %s was not echoed, got %v want %v", ExtensionField, got, want), Code: utils.CodeExtensionDropped}
+			log.Println(f)
+			findings = append(findings, f)
+		}
+	}
+	if len(dropped) > 0 {
+		return findings, fmt.Errorf("%w: unknown fields dropped from echoed field(s): %s", api.ErrValidation, strings.Join(dropped, ", "))
+	}
+	return findings, nil
+}
+
+// lookup returns the value at path in the generic json value v, or nil. Numeric path elements index arrays.
+func lookup(v interface{}, path []string) interface{} {
+	for _, p := range path {
+		switch vv := v.(type) {
+		case map[string]interface{}:
+			v = vv[p]
+		case []interface{}:
+			i, err := strconv.Atoi(p)
+			if err != nil || i < 0 || i >= len(vv) {
+				return nil
+			}
+			v = vv[i]
+		default:
+			return nil
+		}
+	}
+	return v
+}
@@ -0,0 +1,96 @@
+package scenario
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestSubmitExtensions(t *testing.T) {
+	submit, err := utils.BookingSubmitData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name         string
+		handler      func(w http.ResponseWriter, r *http.Request)
+		wantErr      error
+		wantSeverity utils.Severity
+		wantFindings int
+	}{
+		{
+			name: "forward compatible echo",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				var req, resp map[string]interface{}
+				if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+					t.Error(err)
+				}
+				if err := json.Unmarshal([]byte(submit.Resp), &resp); err != nil {
+					t.Error(err)
+				}
+				reservation := resp["reservation"].(map[string]interface{})
+				for _, f := range []string{"customer", "traveler", "room_rate"} {
+					reservation[f] = req[f]
+				}
+				json.NewEncoder(w).Encode(resp)
+			},
+		},
+		{
+			name: "unknown fields dropped",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				var req pb.BookingSubmitRequest
+				if err := (&jsonpb.Unmarshaler{AllowUnknownFields: true}).Unmarshal(r.Body, &req); err != nil {
+					t.Error(err)
+				}
+				resp := proto.Clone(submit.RespPb).(*pb.BookingSubmitResponse)
+				resp.Reservation.Customer, resp.Reservation.Traveler, resp.Reservation.RoomRate = req.Customer, req.Traveler, req.RoomRate
+				(&jsonpb.Marshaler{OrigName: true}).Marshal(w, resp)
+			},
+			wantErr:      api.ErrValidation,
+			wantSeverity: utils.Error,
+			wantFindings: 5,
+		},
+		{
+			name: "unknown fields rejected",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				(&jsonpb.Marshaler{OrigName: true}).Marshal(w, &pb.BookingSubmitResponse{
+					Status: pb.BookingSubmitResponse_FAILURE,
+					Error:  &pb.SubmitError{Type: pb.SubmitError_REQUEST_DATA_INVALID, Message: "unknown field x_validator_extension (request id: 7f3a2c91)"},
+				})
+			},
+			wantSeverity: utils.Warning,
+			wantFindings: 1,
+		},
+	}
+	for _, tc := range cases {
+		server := httptest.NewServer(http.HandlerFunc(tc.handler))
+		conn, err := api.InitHTTPConnection(strings.TrimPrefix(server.URL, "http://"), "", "", "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		findings, err := SubmitExtensions(submit.ReqPb, conn, "")
+		server.Close()
+		if !errors.Is(err, tc.wantErr) {
+			t.Errorf("%s: SubmitExtensions() returned error %v, want %v", tc.name, err, tc.wantErr)
+		}
+		if len(findings) != tc.wantFindings {
+			t.Errorf("%s: SubmitExtensions() returned %d finding(s), want %d: %v", tc.name, len(findings), tc.wantFindings, findings)
+		}
+		for _, f := range findings {
+			if f.Severity != tc.wantSeverity {
+				t.Errorf("%s: SubmitExtensions() returned finding %v, want severity %v", tc.name, f, tc.wantSeverity)
+			}
+		}
+	}
+}
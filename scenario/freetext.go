@@ -0,0 +1,95 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scenario
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// FreeTextVariant is a traveler name exercising the partner's handling of free text.
+type FreeTextVariant struct {
+	Name      string
+	FirstName string
+	LastName  string
+}
+
+// FreeTextVariants are the names booked by FreeText. The v1 API has no special requests field, so traveler names
+// are the free-text fields echoed back in the reservation.
+var FreeTextVariants = []FreeTextVariant{
+	{"accents", "Zoë", "Ñúñez-Ørsted"},
+	{"cjk", "太郎", "山田"},
+	{"rtl", "محمد", "العلي"},
+	{"emoji", "Ann 😀", "Lee 🏨"},
+	{"long", strings.Repeat("Maximilian", 26), strings.Repeat("Wolfeschlegel", 20)},
+}
+
+// FreeText books a copy of submitReq for each of FreeTextVariants, with a distinct transaction_id. The partner must
+// either echo the traveler exactly or reject the booking with CUSTOMER_NAME_INVALID; a traveler that comes back
+// altered, e.g. truncated or with mangled characters, is an Error finding. Note that accepted variants are real
+// bookings.
+func FreeText(submitReq *pb.BookingSubmitRequest, conn *api.HTTPConnection, endpoint string) ([]utils.Finding, error) {
+	var findings []utils.Finding
+	var failed []string
+	for i, v := range FreeTextVariants {
+		req := proto.Clone(submitReq).(*pb.BookingSubmitRequest)
+		req.TransactionId = fmt.Sprintf("%s-freetext-%d", submitReq.GetTransactionId(), i)
+		if req.Traveler == nil {
+			req.Traveler = &pb.Traveler{}
+		}
+		req.Traveler.FirstName = v.FirstName
+		req.Traveler.LastName = v.LastName
+
+		field := fmt.Sprintf("traveler (%s)", v.Name)
+		resp, err := api.SendBookingSubmit(req, conn, endpoint)
+		if err != nil {
+			failed = append(failed, v.Name)
+			findings = append(findings, utils.Finding{Severity: utils.Error, Field: field, Message: err.Error(), Code: utils.CodeFreeTextFailed})
+			continue
+		}
+		if resp.GetStatus() == pb.BookingSubmitResponse_FAILURE {
+			if t := resp.GetError().GetType(); t != pb.SubmitError_CUSTOMER_NAME_INVALID {
+				failed = append(failed, v.Name)
+				findings = append(findings, utils.Finding{Severity: utils.Error, Field: field, Message: fmt.Sprintf("rejected with %v, want success or CUSTOMER_NAME_INVALID", t), Code: utils.CodeFreeTextRejected})
+				continue
+			}
+			log.Printf("Free text variant %s was rejected with CUSTOMER_NAME_INVALID", v.Name)
+			continue
+		}
+		if diff := cmp.Diff(resp.GetReservation().GetTraveler(), req.GetTraveler(), cmp.Comparer(proto.Equal)); diff != "" {
+			failed = append(failed, v.Name)
+			f := utils.Finding{Severity: utils.Error, Field: field, Message: fmt.Sprintf("was not echoed intact (-got +want)\n%s", diff), Code: utils.CodeFreeTextAltered}
+			log.Println(f)
+			findings = append(findings, f)
+			continue
+		}
+		log.Printf("Free text variant %s was echoed intact as reservation %s", v.Name, resp.GetReservation().GetLocator().GetId())
+	}
+	if len(failed) > 0 {
+		return findings, fmt.Errorf("free text variant(s) corrupted or rejected incorrectly: %s", strings.Join(failed, ", "))
+	}
+	return findings, nil
+}
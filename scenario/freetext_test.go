@@ -0,0 +1,60 @@
+package scenario
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestFreeText(t *testing.T) {
+	submit, err := utils.BookingSubmitData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The server rejects emoji as documented and silently truncates names to 64 bytes.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req pb.BookingSubmitRequest
+		if err := jsonpb.Unmarshal(r.Body, &req); err != nil {
+			t.Errorf("server received invalid request: %v", err)
+		}
+		resp := proto.Clone(submit.RespPb).(*pb.BookingSubmitResponse)
+		switch {
+		case strings.Contains(req.GetTraveler().GetFirstName(), "😀"):
+			resp = &pb.BookingSubmitResponse{
+				Status: pb.BookingSubmitResponse_FAILURE,
+				Error:  &pb.SubmitError{Type: pb.SubmitError_CUSTOMER_NAME_INVALID},
+			}
+		default:
+			resp.Reservation.Traveler = proto.Clone(req.GetTraveler()).(*pb.Traveler)
+			if len(resp.Reservation.Traveler.FirstName) > 64 {
+				resp.Reservation.Traveler.FirstName = resp.Reservation.Traveler.FirstName[:64]
+			}
+		}
+		m := jsonpb.Marshaler{OrigName: true}
+		if err := m.Marshal(w, resp); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+	conn, err := api.InitHTTPConnection(strings.TrimPrefix(server.URL, "http://"), "", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	findings, err := FreeText(submit.ReqPb, conn, "")
+	if err == nil {
+		t.Error("FreeText() returned nil error, want an error for the truncated name")
+	}
+	if len(findings) != 1 || findings[0].Field != "traveler (long)" {
+		t.Errorf("FreeText() returned findings %v, want a single finding for traveler (long)", findings)
+	}
+}
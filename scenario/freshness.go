@@ -0,0 +1,135 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package scenario contains multi-step validation flows built on top of the api package.
+package scenario
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// sleep is replaced in tests to avoid waiting for the freshness interval.
+var sleep = time.Sleep
+
+// FreshnessResult records how the partner treated a quote that was booked some time after it was returned.
+type FreshnessResult struct {
+	RoomRateCode string        `json:"room_rate_code"`
+	QuoteAge     time.Duration `json:"quote_age"`
+	// Honored is true when the booking succeeded at the quoted price.
+	Honored bool `json:"honored"`
+	// RateChanged is true when the partner rejected the booking with a documented rate-changed error.
+	RateChanged bool `json:"rate_changed"`
+	// Quoted is the total returned by the quote endpoint just before the booking, and Booked the total of the
+	// booking, if a quote endpoint is set.
+	Quoted *Total `json:"quoted_total,omitempty"`
+	Booked *Total `json:"booked_total,omitempty"`
+}
+
+// rateChangedErrors are the submit errors a partner may return when a quote is no longer valid.
+var rateChangedErrors = map[pb.SubmitError_SubmitErrorType]bool{
+	pb.SubmitError_ROOM_RATE_PRICE_MISMATCH: true,
+	pb.SubmitError_ROOM_RATE_UNAVAILABLE:    true,
+	pb.SubmitError_RATE_PLAN_UNAVAILABLE:    true,
+}
+
+// Freshness performs an availability check, waits for wait, then books the quoted room rate using submitReq as a
+// template for the customer, traveler and payment details. The partner must either honor the quoted price or reject
+// the booking with a rate-changed error. The booked room rate is the one matching submitReq's room_rate code, or the
+// first returned room rate when there is no match. If quoteEndpoint is set, the booking is first priced with Quote
+// and an honored booking must match the quoted total.
+func Freshness(availReq *pb.BookingAvailabilityRequest, submitReq *pb.BookingSubmitRequest, conn *api.HTTPConnection, availEndpoint, quoteEndpoint, submitEndpoint string, wait time.Duration) (*FreshnessResult, error) {
+	availResp, err := api.SendBookingAvailability(availReq, conn, availEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	quotedAt := time.Now()
+	if _, err := utils.ValidateBookingAvailabilityResponse(availReq, availResp); err != nil {
+		return nil, fmt.Errorf("%w: %v", api.ErrValidation, err)
+	}
+	if len(availResp.GetRoomRates()) == 0 {
+		return nil, fmt.Errorf("availability response returned no room_rates to book")
+	}
+	quote := availResp.GetRoomRates()[0]
+	for _, r := range availResp.GetRoomRates() {
+		if r.GetCode() == submitReq.GetRoomRate().GetCode() {
+			quote = r
+			break
+		}
+	}
+
+	if wait > 0 {
+		log.Printf("Waiting %v before booking room rate %s", wait, quote.GetCode())
+		sleep(wait)
+	}
+
+	req := proto.Clone(submitReq).(*pb.BookingSubmitRequest)
+	// Use a transaction_id of its own, or a deduplicating partner returns the booking of the submit flow.
+	req.TransactionId = submitReq.GetTransactionId() + "-freshness"
+	req.HotelId = availReq.GetHotelId()
+	req.StartDate = availReq.GetStartDate()
+	req.EndDate = availReq.GetEndDate()
+	req.RoomRate = proto.Clone(quote).(*pb.RoomRate)
+	// Book the quote the way the sample BookingSubmitRequest does, without its line items and cancellation rules.
+	req.RoomRate.LineItems = nil
+	req.RoomRate.CancellationRules = nil
+
+	result := &FreshnessResult{RoomRateCode: quote.GetCode(), QuoteAge: time.Since(quotedAt)}
+	if quoteEndpoint != "" {
+		quoted, err := Quote(req, conn, quoteEndpoint)
+		if err != nil {
+			return result, err
+		}
+		result.Quoted = &quoted
+		log.Printf("Room rate %s quoted at %v", quote.GetCode(), quoted)
+	}
+	resp, err := api.SendBookingSubmit(req, conn, submitEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	if resp.GetStatus() == pb.BookingSubmitResponse_FAILURE {
+		if !rateChangedErrors[resp.GetError().GetType()] {
+			return result, fmt.Errorf("quote %s booked after %v failed with %v, want success or one of ROOM_RATE_PRICE_MISMATCH, ROOM_RATE_UNAVAILABLE, RATE_PLAN_UNAVAILABLE", quote.GetCode(), result.QuoteAge, resp.GetError().GetType())
+		}
+		result.RateChanged = true
+		log.Printf("Quote %s was no longer valid after %v: %v", quote.GetCode(), result.QuoteAge, resp.GetError().GetType())
+		return result, nil
+	}
+	if _, err := utils.ValidateBookingSubmitResponse(req, resp); err != nil {
+		return result, fmt.Errorf("quote %s booked after %v was not honored: %v", quote.GetCode(), result.QuoteAge, err)
+	}
+	if result.Quoted != nil {
+		booked, err := roomRateTotal(resp.GetReservation().GetRoomRate())
+		if err != nil {
+			return result, fmt.Errorf("submit response: %v", err)
+		}
+		result.Booked = &booked
+		if !booked.matches(*result.Quoted) {
+			return result, fmt.Errorf("quote %s was booked at %v, but %s quoted %v", quote.GetCode(), booked, quoteEndpoint, *result.Quoted)
+		}
+	}
+	result.Honored = true
+	log.Printf("Quote %s was honored after %v", quote.GetCode(), result.QuoteAge)
+	return result, nil
+}
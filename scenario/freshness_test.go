@@ -0,0 +1,138 @@
+package scenario
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+// newFakeServer serves the sample availability response on /availability and submitResp on /submit.
+func newFakeServer(t *testing.T, submitResp string) (*api.HTTPConnection, *httptest.Server) {
+	availability, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/availability":
+			fmt.Fprintln(w, availability.Resp)
+		case "/submit":
+			fmt.Fprintln(w, submitResp)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	conn, err := api.InitHTTPConnection(strings.TrimPrefix(server.URL, "http://"), "", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn, server
+}
+
+func TestFreshness(t *testing.T) {
+	defer func(s func(time.Duration)) { sleep = s }(sleep)
+	sleep = func(time.Duration) {}
+	submit, err := utils.BookingSubmitData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	availability, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name            string
+		submitResp      string
+		wantHonored     bool
+		wantRateChanged bool
+		wantErr         bool
+	}{
+		{
+			name:        "quote honored",
+			submitResp:  submit.Resp,
+			wantHonored: true,
+		},
+		{
+			name:            "rate changed",
+			submitResp:      `{"api_version": 1, "transaction_id": "84dd3b20-a556-4b3a-bc77-d5449c0a58cd", "status": "FAILURE", "error": {"type": "ROOM_RATE_PRICE_MISMATCH"}}`,
+			wantRateChanged: true,
+		},
+		{
+			name:       "unexpected error",
+			submitResp: `{"api_version": 1, "transaction_id": "84dd3b20-a556-4b3a-bc77-d5449c0a58cd", "status": "FAILURE", "error": {"type": "SUPPLIER_ERROR"}}`,
+			wantErr:    true,
+		},
+	}
+	for _, tc := range cases {
+		conn, server := newFakeServer(t, tc.submitResp)
+		got, err := Freshness(availability.ReqPb, submit.ReqPb, conn, "/availability", "", "/submit", time.Minute)
+		server.Close()
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: Freshness() returned error %v, want error %v", tc.name, err, tc.wantErr)
+			continue
+		}
+		if got.Honored != tc.wantHonored || got.RateChanged != tc.wantRateChanged {
+			t.Errorf("%s: Freshness() got %+v, want honored %v and rate changed %v", tc.name, got, tc.wantHonored, tc.wantRateChanged)
+		}
+		if got.RoomRateCode != "RATE1" {
+			t.Errorf("%s: Freshness() booked room rate %s, want RATE1", tc.name, got.RoomRateCode)
+		}
+	}
+}
+
+func TestFreshnessQuote(t *testing.T) {
+	submit, err := utils.BookingSubmitData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	availability, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name      string
+		quoteResp string
+		wantErr   bool
+	}{
+		{name: "quote matches", quoteResp: submit.Resp},
+		{name: "quote differs", quoteResp: strings.Replace(submit.Resp, `"amount":552`, `"amount":500`, 1), wantErr: true},
+		{name: "quote failed", quoteResp: `{"api_version": 1, "transaction_id": "84dd3b20-a556-4b3a-bc77-d5449c0a58cd", "status": "FAILURE", "error": {"type": "ROOM_RATE_UNAVAILABLE"}}`, wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var booked bool
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/availability":
+					fmt.Fprintln(w, availability.Resp)
+				case "/quote":
+					fmt.Fprintln(w, tc.quoteResp)
+				case "/submit":
+					booked = true
+					fmt.Fprintln(w, submit.Resp)
+				}
+			}))
+			defer server.Close()
+			conn, err := api.InitHTTPConnection(strings.TrimPrefix(server.URL, "http://"), "", "", "", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Freshness(availability.ReqPb, submit.ReqPb, conn, "/availability", "/quote", "/submit", 0)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Freshness() returned error %v, want error %v", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if !booked || got.Quoted == nil || got.Booked == nil || *got.Quoted != *got.Booked {
+				t.Errorf("Freshness() got quoted %v and booked %v, want the same total booked", got.Quoted, got.Booked)
+			}
+		})
+	}
+}
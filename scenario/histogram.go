@@ -0,0 +1,234 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scenario
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"strconv"
+	"time"
+)
+
+const (
+	// histogramLowest and histogramHighest bound the latencies LatencyHistogram tells apart; longer ones are
+	// recorded as histogramHighest.
+	histogramLowest  = int64(time.Microsecond)
+	histogramHighest = int64(time.Hour)
+	// histogramDigits is the number of significant decimal digits latencies are recorded with.
+	histogramDigits = 3
+
+	// hdrEncodingCookie and hdrCompressedCookie identify the V2 encoding of HdrHistogram and its compressed envelope.
+	hdrEncodingCookie   = 0x1c849303 | 0x10
+	hdrCompressedCookie = 0x1c849304 | 0x10
+)
+
+// LatencyHistogram records latencies in nanoseconds as an HdrHistogram, the layout of buckets shared by the
+// HdrHistogram libraries and plotting tools, so that histograms of different runs can be merged and compared.
+type LatencyHistogram struct {
+	unitMagnitude               uint
+	subBucketHalfCountMagnitude uint
+	subBucketHalfCount          int
+	subBucketMask               int64
+	counts                      []int64
+	total                       int64
+	max                         int64
+}
+
+// NewLatencyHistogram returns an empty histogram of latencies from 1µs to 1h with 3 significant digits.
+func NewLatencyHistogram() *LatencyHistogram {
+	subBucketCountMagnitude := uint(math.Ceil(math.Log2(2 * math.Pow10(histogramDigits))))
+	subBucketCount := int64(1) << subBucketCountMagnitude
+	h := &LatencyHistogram{
+		unitMagnitude:               uint(math.Floor(math.Log2(float64(histogramLowest)))),
+		subBucketHalfCountMagnitude: subBucketCountMagnitude - 1,
+		subBucketHalfCount:          int(subBucketCount / 2),
+	}
+	h.subBucketMask = (subBucketCount - 1) << h.unitMagnitude
+	buckets := 1
+	for v := subBucketCount << h.unitMagnitude; v <= histogramHighest; v <<= 1 {
+		buckets++
+	}
+	h.counts = make([]int64, (buckets+1)*h.subBucketHalfCount)
+	return h
+}
+
+// Record adds a latency of d to h.
+func (h *LatencyHistogram) Record(d time.Duration) {
+	v := int64(d)
+	switch {
+	case v < 0:
+		v = 0
+	case v > histogramHighest:
+		v = histogramHighest
+	}
+	h.counts[h.index(v)]++
+	h.total++
+	if v > h.max {
+		h.max = v
+	}
+}
+
+// Count returns the number of latencies recorded in h.
+func (h *LatencyHistogram) Count() int64 {
+	return h.total
+}
+
+// Max returns the longest latency recorded in h.
+func (h *LatencyHistogram) Max() time.Duration {
+	return time.Duration(h.max)
+}
+
+// Quantile returns the latency which a fraction q of the latencies recorded in h do not exceed, to the precision of
+// the histogram, or 0 if h is empty.
+func (h *LatencyHistogram) Quantile(q float64) time.Duration {
+	target := int64(math.Ceil(q * float64(h.total)))
+	if target < 1 {
+		target = 1
+	}
+	var seen int64
+	for i, c := range h.counts {
+		if seen += c; c > 0 && seen >= target {
+			return time.Duration(h.highestEquivalent(i))
+		}
+	}
+	return 0
+}
+
+// index returns the index of the bucket of counts value v is recorded in.
+func (h *LatencyHistogram) index(v int64) int {
+	bucket := int(64-h.unitMagnitude-h.subBucketHalfCountMagnitude-1) - bits.LeadingZeros64(uint64(v|h.subBucketMask))
+	sub := int(v >> (uint(bucket) + h.unitMagnitude))
+	return (bucket+1)<<h.subBucketHalfCountMagnitude + sub - h.subBucketHalfCount
+}
+
+// highestEquivalent returns the largest value recorded at index i of counts.
+func (h *LatencyHistogram) highestEquivalent(i int) int64 {
+	bucket := i>>h.subBucketHalfCountMagnitude - 1
+	sub := i&(h.subBucketHalfCount-1) + h.subBucketHalfCount
+	if bucket < 0 {
+		sub -= h.subBucketHalfCount
+		bucket = 0
+	}
+	shift := uint(bucket) + h.unitMagnitude
+	return int64(sub)<<shift + int64(1)<<shift - 1
+}
+
+// encode returns h in the base64 encoded, compressed V2 encoding of HdrHistogram. Counts are ZigZag LEB128 encoded,
+// with runs of empty buckets as negative lengths.
+func (h *LatencyHistogram) encode() (string, error) {
+	var payload []byte
+	limit := h.index(h.max) + 1
+	for i := 0; i < limit; {
+		c := h.counts[i]
+		i++
+		zeros := int64(0)
+		if c == 0 {
+			for zeros = 1; i < limit && h.counts[i] == 0; i++ {
+				zeros++
+			}
+		}
+		if zeros > 1 {
+			c = -zeros
+		}
+		payload = appendZigZag(payload, c)
+	}
+
+	var raw bytes.Buffer
+	for _, v := range []interface{}{
+		int32(hdrEncodingCookie),
+		int32(len(payload)),
+		int32(0), // normalizing index offset
+		int32(histogramDigits),
+		histogramLowest,
+		histogramHighest,
+		float64(1), // integer to double value conversion ratio
+	} {
+		binary.Write(&raw, binary.BigEndian, v)
+	}
+	raw.Write(payload)
+
+	var compressed bytes.Buffer
+	z, err := zlib.NewWriterLevel(&compressed, zlib.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := z.Write(raw.Bytes()); err != nil {
+		return "", err
+	}
+	if err := z.Close(); err != nil {
+		return "", err
+	}
+	var out bytes.Buffer
+	binary.Write(&out, binary.BigEndian, int32(hdrCompressedCookie))
+	binary.Write(&out, binary.BigEndian, int32(compressed.Len()))
+	out.Write(compressed.Bytes())
+	return base64.StdEncoding.EncodeToString(out.Bytes()), nil
+}
+
+// appendZigZag appends v to b as a ZigZag encoded LEB128-64b9B integer: 7 bits per byte, and all 8 bits of the 9th.
+func appendZigZag(b []byte, v int64) []byte {
+	u := uint64(v<<1) ^ uint64(v>>63)
+	for i := 0; i < 8; i++ {
+		if u < 0x80 {
+			return append(b, byte(u))
+		}
+		b = append(b, byte(u)|0x80)
+		u >>= 7
+	}
+	return append(b, byte(u))
+}
+
+// WriteHistogramLog writes the latencies of the steps of result to w as an HdrHistogram interval log (.hlog), with
+// one interval per step tagged with its rate, e.g. Tag=8qps, timestamped in seconds since the epoch so that logs of
+// runs on different days can be merged. Maximum latencies are in milliseconds. A nil result is an error.
+func WriteHistogramLog(w io.Writer, result *LoadResult) error {
+	if result == nil {
+		return errors.New("no load test result to write")
+	}
+	var start time.Time
+	if len(result.Steps) > 0 {
+		start = result.Steps[0].Start
+	}
+	seconds := func(t time.Time) float64 { return float64(t.UnixNano()) / float64(time.Second) }
+	header := fmt.Sprintf("#[Histogram log format version 1.3]\n#[StartTime: %.3f (seconds since epoch), %s]\n", seconds(start), start.UTC().Format(time.RFC3339))
+	header += "\"StartTimestamp\",\"Interval_Length\",\"Interval_Max\",\"Interval_Compressed_Histogram\"\n"
+	if _, err := io.WriteString(w, header); err != nil {
+		return err
+	}
+	for _, s := range result.Steps {
+		if s.Latency == nil {
+			continue
+		}
+		encoded, err := s.Latency.encode()
+		if err != nil {
+			return fmt.Errorf("could not encode latencies of the %.1f QPS step: %v", s.QPS, err)
+		}
+		tag := strconv.FormatFloat(s.QPS, 'f', -1, 64) + "qps"
+		line := fmt.Sprintf("Tag=%s,%.3f,%.3f,%.3f,%s\n", tag, seconds(s.Start), s.End.Sub(s.Start).Seconds(), float64(s.Latency.max)/float64(time.Millisecond), encoded)
+		if _, err := io.WriteString(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package scenario
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"encoding/binary"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLatencyHistogram(t *testing.T) {
+	h := NewLatencyHistogram()
+	for i := 1; i <= 100; i++ {
+		h.Record(time.Duration(i) * time.Millisecond)
+	}
+	h.Record(2 * time.Hour)
+	if h.Count() != 101 || h.Max() != time.Hour {
+		t.Errorf("histogram has %d latencies up to %v, want 101 up to 1h", h.Count(), h.Max())
+	}
+	for q, want := range map[float64]time.Duration{0.5: 51 * time.Millisecond, 0.99: 100 * time.Millisecond} {
+		// Latencies are recorded with 3 significant digits.
+		if got := h.Quantile(q); got < want || got > want+want/1000 {
+			t.Errorf("Quantile(%v) = %v, want %v", q, got, want)
+		}
+	}
+	if got := NewLatencyHistogram().Quantile(0.5); got != 0 {
+		t.Errorf("Quantile(0.5) of an empty histogram = %v, want 0", got)
+	}
+}
+
+func TestAppendZigZag(t *testing.T) {
+	cases := map[int64][]byte{
+		0:        {0x00},
+		-1:       {0x01},
+		1:        {0x02},
+		64:       {0x80, 0x01},
+		-65:      {0x81, 0x01},
+		-1 << 63: {0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+	}
+	for v, want := range cases {
+		if diff := cmp.Diff(appendZigZag(nil, v), want); diff != "" {
+			t.Errorf("appendZigZag(%d) differs (-got +want):\n%s", v, diff)
+		}
+	}
+}
+
+func TestWriteHistogramLog(t *testing.T) {
+	h := NewLatencyHistogram()
+	h.Record(3 * time.Millisecond)
+	start := time.Unix(1700000000, 0)
+	result := &LoadResult{Steps: []LoadStep{{QPS: 2.5, Start: start, End: start.Add(10 * time.Second), Latency: h}}}
+	var b bytes.Buffer
+	if err := WriteHistogramLog(&b, result); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	if len(lines) != 4 || lines[1] != "#[StartTime: 1700000000.000 (seconds since epoch), 2023-11-14T22:13:20Z]" {
+		t.Fatalf("WriteHistogramLog() wrote %q, want a header and one interval", lines)
+	}
+	fields := strings.Split(lines[3], ",")
+	if diff := cmp.Diff(fields[:4], []string{"Tag=2.5qps", "1700000000.000", "10.000", "3.000"}); diff != "" {
+		t.Errorf("WriteHistogramLog() wrote an interval that differs (-got +want):\n%s", diff)
+	}
+
+	encoded, err := base64.StdEncoding.DecodeString(fields[4])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cookie := binary.BigEndian.Uint32(encoded); cookie != hdrCompressedCookie {
+		t.Errorf("histogram has cookie %x, want %x", cookie, hdrCompressedCookie)
+	}
+	z, err := zlib.NewReader(bytes.NewReader(encoded[8:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := ioutil.ReadAll(z)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cookie, digits := binary.BigEndian.Uint32(raw), binary.BigEndian.Uint32(raw[12:]); cookie != hdrEncodingCookie || digits != histogramDigits {
+		t.Errorf("histogram has cookie %x and %d digits, want %x and %d", cookie, digits, hdrEncodingCookie, histogramDigits)
+	}
+	if length := binary.BigEndian.Uint32(raw[4:]); int(length) != len(raw)-40 {
+		t.Errorf("histogram declares a payload of %d bytes, has %d", length, len(raw)-40)
+	}
+}
+
+func TestWriteHistogramLogNilResult(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteHistogramLog(&b, nil); err == nil {
+		t.Error("WriteHistogramLog(nil) succeeded, want an error")
+	}
+	if b.Len() != 0 {
+		t.Errorf("WriteHistogramLog(nil) wrote %q, want nothing", b.String())
+	}
+}
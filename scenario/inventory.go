@@ -0,0 +1,120 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scenario
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// InventoryResult records the room_count of a room rate before and after booking it.
+type InventoryResult struct {
+	RoomRateCode string `json:"room_rate_code"`
+	Before       int32  `json:"before"`
+	// After is 0 when the room rate is no longer returned.
+	After       int32 `json:"after"`
+	Decremented bool  `json:"decremented"`
+}
+
+// Inventory books a room rate that reports its room_count, using submitReq as a template for the customer, traveler
+// and payment details, then repeats the availability check. The booked room rate is the one matching submitReq's
+// room_rate code, or the first returned room rate with a room_count when there is no match. The second check must
+// succeed; a room_count that did not decrement, e.g. because the partner caches inventory, is reported as a Warning
+// finding.
+func Inventory(availReq *pb.BookingAvailabilityRequest, submitReq *pb.BookingSubmitRequest, conn *api.HTTPConnection, availEndpoint, submitEndpoint string) (*InventoryResult, []utils.Finding, error) {
+	availResp, err := checkAvailability(availReq, conn, availEndpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	var quote *pb.RoomRate
+	for _, r := range availResp.GetRoomRates() {
+		if r.GetRoomCount() <= 0 {
+			continue
+		}
+		if quote == nil || r.GetCode() == submitReq.GetRoomRate().GetCode() {
+			quote = r
+		}
+	}
+	if quote == nil {
+		return nil, nil, fmt.Errorf("availability response returned no room_rates with a room_count to book")
+	}
+
+	req := proto.Clone(submitReq).(*pb.BookingSubmitRequest)
+	// Use a transaction_id of its own, or a deduplicating partner returns the booking of the submit flow.
+	req.TransactionId = submitReq.GetTransactionId() + "-inventory"
+	req.HotelId = availReq.GetHotelId()
+	req.StartDate = availReq.GetStartDate()
+	req.EndDate = availReq.GetEndDate()
+	req.RoomRate = proto.Clone(quote).(*pb.RoomRate)
+	req.RoomRate.LineItems = nil
+	req.RoomRate.CancellationRules = nil
+	req.RoomRate.RoomCount = 0
+
+	result := &InventoryResult{RoomRateCode: quote.GetCode(), Before: quote.GetRoomCount()}
+	resp, err := api.SendBookingSubmit(req, conn, submitEndpoint)
+	if err != nil {
+		return result, nil, err
+	}
+	if resp.GetStatus() == pb.BookingSubmitResponse_FAILURE {
+		return result, nil, fmt.Errorf("booking room rate %s with %d room(s) left failed with %v", quote.GetCode(), result.Before, resp.GetError().GetType())
+	}
+	if _, err := utils.ValidateBookingSubmitResponse(req, resp); err != nil {
+		return result, nil, fmt.Errorf("%w: %v", api.ErrValidation, err)
+	}
+
+	availResp, err = checkAvailability(availReq, conn, availEndpoint)
+	if err != nil {
+		return result, nil, fmt.Errorf("availability check after booking room rate %s failed: %v", quote.GetCode(), err)
+	}
+	for _, r := range availResp.GetRoomRates() {
+		if r.GetCode() == quote.GetCode() {
+			result.After = r.GetRoomCount()
+		}
+	}
+	result.Decremented = result.After < result.Before
+	log.Printf("Room rate %s had %d room(s) left before booking and %d after", quote.GetCode(), result.Before, result.After)
+	if result.Decremented {
+		return result, nil, nil
+	}
+	f := utils.Finding{
+		Severity: utils.Warning,
+		Field:    "room_rates > room_count",
+		Message:  fmt.Sprintf("room rate %s still had %d room(s) left after booking one of %d", quote.GetCode(), result.After, result.Before),
+		Code:     utils.CodeRoomCountUnchanged,
+	}
+	log.Println(f)
+	return result, []utils.Finding{f}, nil
+}
+
+// checkAvailability sends req and validates the response against it.
+func checkAvailability(req *pb.BookingAvailabilityRequest, conn *api.HTTPConnection, endpoint string) (*pb.BookingAvailabilityResponse, error) {
+	resp, err := api.SendBookingAvailability(req, conn, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := utils.ValidateBookingAvailabilityResponse(req, resp); err != nil {
+		return nil, fmt.Errorf("%w: %v", api.ErrValidation, err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,93 @@
+package scenario
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestInventory(t *testing.T) {
+	submit, err := utils.BookingSubmitData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	availability, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name         string
+		counts       []int32
+		wantResult   *InventoryResult
+		wantFindings int
+		wantErr      bool
+	}{
+		{
+			name:       "decremented",
+			counts:     []int32{2, 1},
+			wantResult: &InventoryResult{RoomRateCode: "RATE1", Before: 2, After: 1, Decremented: true},
+		},
+		{
+			name:       "sold out",
+			counts:     []int32{1, 0},
+			wantResult: &InventoryResult{RoomRateCode: "RATE1", Before: 1, Decremented: true},
+		},
+		{
+			name:         "not decremented",
+			counts:       []int32{2, 2},
+			wantResult:   &InventoryResult{RoomRateCode: "RATE1", Before: 2, After: 2},
+			wantFindings: 1,
+		},
+		{
+			name:    "no room counts",
+			counts:  []int32{0, 0},
+			wantErr: true,
+		},
+	}
+	for _, tc := range cases {
+		checks := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/availability":
+				resp := proto.Clone(availability.RespPb).(*pb.BookingAvailabilityResponse)
+				resp.RoomRates[0].RoomCount = tc.counts[checks]
+				checks++
+				if err := (&jsonpb.Marshaler{OrigName: true}).Marshal(w, resp); err != nil {
+					t.Error(err)
+				}
+			case "/submit":
+				w.Write([]byte(submit.Resp))
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		conn, err := api.InitHTTPConnection(strings.TrimPrefix(server.URL, "http://"), "", "", "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, findings, err := Inventory(availability.ReqPb, submit.ReqPb, conn, "/availability", "/submit")
+		server.Close()
+		if (err != nil) != tc.wantErr {
+			t.Errorf("%s: Inventory() returned error %v, want error %v", tc.name, err, tc.wantErr)
+			continue
+		}
+		if tc.wantErr {
+			continue
+		}
+		if *got != *tc.wantResult {
+			t.Errorf("%s: Inventory() got %+v, want %+v", tc.name, *got, *tc.wantResult)
+		}
+		if len(findings) != tc.wantFindings {
+			t.Errorf("%s: Inventory() got %d finding(s), want %d: %v", tc.name, len(findings), tc.wantFindings, findings)
+		}
+	}
+}
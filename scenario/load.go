@@ -0,0 +1,201 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scenario
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// LoadConfig controls the search for the partner's sustainable availability throughput.
+type LoadConfig struct {
+	// StartQPS is the rate of the first step. It doubles after every step without throttling.
+	StartQPS float64
+	// MaxQPS is the highest rate tried.
+	MaxQPS float64
+	// Step is how long each rate is sustained.
+	Step time.Duration
+	// Resolution stops the search once the gap between the sustained and the throttled rate is this small.
+	Resolution float64
+	// Stream validates every response with api.BookingAvailabilityStream, which does not hold the response in
+	// memory, and counts responses failing validation as errors. Otherwise responses are only parsed.
+	Stream bool
+	// Rules, if set, counts the rules broken by each request.
+	Rules *utils.RuleTally
+	// MaxTransportFailures, if positive, stops the load test after that many consecutive requests failed in
+	// transport, e.g. DefaultMaxTransportFailures. The remaining requests of the step are not run.
+	MaxTransportFailures int
+}
+
+// DefaultLoadConfig ramps from 1 to at most 64 QPS in 10 second steps.
+var DefaultLoadConfig = LoadConfig{StartQPS: 1, MaxQPS: 64, Step: 10 * time.Second, Resolution: 1}
+
+// maxInFlight caps the requests of a step awaiting their response. Once reached, the next request waits for one to
+// complete, so a partner answering slower than maxInFlight/qps is sent fewer requests than the rate of the step.
+const maxInFlight = 256
+
+// tick returns a channel receiving every interval, and a function stopping it. Tests replace it with a fake ticker.
+var tick = func(interval time.Duration) (<-chan time.Time, func()) {
+	t := time.NewTicker(interval)
+	return t.C, t.Stop
+}
+
+// LoadStep is the outcome of sustaining a single rate.
+type LoadStep struct {
+	QPS       float64 `json:"qps"`
+	Sent      int     `json:"sent"`
+	Throttled int     `json:"throttled"`
+	Errors    int     `json:"errors"`
+	// Start and End are when the step began and when its last response was received.
+	Start time.Time `json:"-"`
+	End   time.Time `json:"-"`
+	// Latency holds the latency of every request of the step, throttled and failed ones included.
+	Latency *LatencyHistogram `json:"-"`
+}
+
+// LoadResult summarizes a Load run.
+type LoadResult struct {
+	Steps []LoadStep `json:"steps"`
+	// MaxSustainableQPS is the highest rate at which no request was throttled.
+	MaxSustainableQPS float64 `json:"max_sustainable_qps"`
+}
+
+// runStep sends qps*d, at least one, requests req at qps, with every request in its own goroutine so slow responses
+// do not lower the rate, up to maxInFlight. Once br opens the remaining requests of the step are skipped.
+func runStep(req *pb.BookingAvailabilityRequest, conn *api.HTTPConnection, endpoint string, qps float64, d time.Duration, stream bool, rules *utils.RuleTally, br *breaker) LoadStep {
+	step := LoadStep{QPS: qps, Start: time.Now(), Latency: NewLatencyHistogram()}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	inFlight := make(chan struct{}, maxInFlight)
+	ticks, stop := tick(time.Duration(float64(time.Second) / qps))
+	defer stop()
+	n := int(qps * d.Seconds())
+	if n < 1 {
+		n = 1
+	}
+	for i := 0; i < n; i++ {
+		mu.Lock()
+		open := br.open()
+		if open {
+			br.skip(rules)
+		}
+		mu.Unlock()
+		if open {
+			continue
+		}
+		if i > 0 {
+			<-ticks
+		}
+		inFlight <- struct{}{}
+		step.Sent++
+		wg.Add(1)
+		go func() {
+			defer func() { <-inFlight }()
+			defer wg.Done()
+			var findings []utils.Finding
+			var err error
+			start := time.Now()
+			if stream {
+				findings, err = api.BookingAvailabilityStream(req, conn, endpoint)
+			} else {
+				_, err = api.SendBookingAvailability(req, conn, endpoint)
+			}
+			latency := time.Since(start)
+			rules.Add(findings, err)
+			mu.Lock()
+			defer mu.Unlock()
+			br.record(err)
+			step.Latency.Record(latency)
+			switch {
+			case errors.Is(err, api.ErrThrottled):
+				step.Throttled++
+			case err != nil:
+				step.Errors++
+			}
+		}()
+	}
+	wg.Wait()
+	step.End = time.Now()
+	return step
+}
+
+// Load searches for the highest availability request rate the partner sustains without returning 429 or 503. The
+// rate doubles from cfg.StartQPS while no request is throttled; once throttling starts it backs off and bisects
+// between the highest sustained and the lowest throttled rate. Throttling at cfg.StartQPS is an error, and throttling
+// below cfg.MaxQPS is reported as a Warning finding. Once cfg.MaxTransportFailures consecutive requests failed in
+// transport the search stops, which is an Error finding and fails the run.
+func Load(req *pb.BookingAvailabilityRequest, conn *api.HTTPConnection, endpoint string, cfg LoadConfig) (*LoadResult, []utils.Finding, error) {
+	switch {
+	case cfg.StartQPS <= 0:
+		return nil, nil, fmt.Errorf("load start rate %v QPS is not positive", cfg.StartQPS)
+	case cfg.MaxQPS < cfg.StartQPS:
+		return nil, nil, fmt.Errorf("load maximum rate %v QPS is below the start rate %v QPS", cfg.MaxQPS, cfg.StartQPS)
+	case cfg.Step <= 0:
+		return nil, nil, fmt.Errorf("load step %v is not positive", cfg.Step)
+	}
+	result := &LoadResult{}
+	br := &breaker{threshold: cfg.MaxTransportFailures}
+	sustained, throttled := 0.0, 0.0
+	for qps := cfg.StartQPS; qps > 0; {
+		step := runStep(req, conn, endpoint, qps, cfg.Step, cfg.Stream, cfg.Rules, br)
+		result.Steps = append(result.Steps, step)
+		log.Printf("Load step at %.1f QPS: %d sent, %d throttled, %d errors, latency p50 %v, p99 %v, max %v", step.QPS, step.Sent, step.Throttled, step.Errors, step.Latency.Quantile(0.5), step.Latency.Quantile(0.99), step.Latency.Max())
+		if step.Throttled > 0 {
+			throttled = qps
+		} else {
+			sustained = qps
+		}
+
+		switch {
+		case br.open():
+			qps = 0
+		case throttled == 0 && qps >= cfg.MaxQPS:
+			qps = 0
+		case throttled == 0:
+			qps *= 2
+			if qps > cfg.MaxQPS {
+				qps = cfg.MaxQPS
+			}
+		case throttled-sustained <= cfg.Resolution:
+			qps = 0
+		default:
+			qps = (sustained + throttled) / 2
+		}
+	}
+	result.MaxSustainableQPS = sustained
+	if f := br.findings("the load test"); f != nil {
+		return result, f, fmt.Errorf("load test stopped at %.1f QPS, %d request(s) not run after repeated transport failures", result.Steps[len(result.Steps)-1].QPS, br.notRun)
+	}
+
+	if sustained == 0 {
+		return result, nil, fmt.Errorf("throttled at the starting rate of %.1f QPS", cfg.StartQPS)
+	}
+	log.Printf("Maximum sustainable throughput: %.1f QPS", sustained)
+	if throttled > 0 {
+		f := utils.Finding{Severity: utils.Warning, Field: "throughput", Message: fmt.Sprintf("throttled above %.1f QPS", sustained), Code: utils.CodeLoadThrottled}
+		return result, []utils.Finding{f}, nil
+	}
+	return result, nil, nil
+}
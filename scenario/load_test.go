@@ -0,0 +1,129 @@
+package scenario
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+// newThrottlingServer serves the sample availability response, returning 429 to requests beyond limit per load step.
+// It replaces tick by a fake ticker, which restores it, that starts a new step and does not wait between requests.
+func newThrottlingServer(t *testing.T, limit int) (*api.HTTPConnection, *httptest.Server, func()) {
+	availability, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	count := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		count++
+		over := count > limit
+		mu.Unlock()
+		if over {
+			http.Error(w, "slow down", http.StatusTooManyRequests)
+			return
+		}
+		fmt.Fprintln(w, availability.Resp)
+	}))
+	conn, err := api.InitHTTPConnection(strings.TrimPrefix(server.URL, "http://"), "", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := tick
+	tick = func(time.Duration) (<-chan time.Time, func()) {
+		mu.Lock()
+		count = 0
+		mu.Unlock()
+		c := make(chan time.Time)
+		close(c)
+		return c, func() {}
+	}
+	return conn, server, func() { tick = prev }
+}
+
+func TestLoad(t *testing.T) {
+	availability, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The server accepts 60 requests per one second step.
+	conn, server, restore := newThrottlingServer(t, 60)
+	defer server.Close()
+	defer restore()
+
+	cfg := LoadConfig{StartQPS: 10, MaxQPS: 640, Step: time.Second, Resolution: 20}
+	result, findings, err := Load(availability.ReqPb, conn, "", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var qps []float64
+	for _, s := range result.Steps {
+		qps = append(qps, s.QPS)
+		if want := int(s.QPS); s.Sent != want {
+			t.Errorf("Load() step at %.1f QPS sent %d requests, want %d", s.QPS, s.Sent, want)
+		}
+	}
+	if fmt.Sprint(qps) != "[10 20 40 80 60]" || result.MaxSustainableQPS != 60 {
+		t.Errorf("Load() tried %v QPS and found a sustainable rate of %.1f QPS, want [10 20 40 80 60] and 60", qps, result.MaxSustainableQPS)
+	}
+	if len(findings) != 1 {
+		t.Errorf("Load() returned findings %v, want a throttling warning", findings)
+	}
+}
+
+func TestLoadThrottledAtStart(t *testing.T) {
+	availability, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, server, restore := newThrottlingServer(t, 0)
+	defer server.Close()
+	defer restore()
+
+	cfg := LoadConfig{StartQPS: 20, MaxQPS: 40, Step: 100 * time.Millisecond, Resolution: 20}
+	if _, _, err := Load(availability.ReqPb, conn, "", cfg); err == nil {
+		t.Error("Load() returned nil error, want an error when throttled at the starting rate")
+	}
+}
+
+func TestLoadCircuitBreaker(t *testing.T) {
+	availability, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, server, restore := newThrottlingServer(t, 1000)
+	defer restore()
+	server.Close()
+
+	cfg := LoadConfig{StartQPS: 10, MaxQPS: 40, Step: time.Second, Resolution: 1, MaxTransportFailures: 3}
+	result, findings, err := Load(availability.ReqPb, conn, "", cfg)
+	if err == nil {
+		t.Fatal("Load() returned nil error, want an error once the circuit breaker opened")
+	}
+	if len(result.Steps) != 1 {
+		t.Errorf("Load() ran %d steps, want the search to stop after the first", len(result.Steps))
+	}
+	if len(findings) != 1 || findings[0].Code != utils.CodeCircuitOpen {
+		t.Errorf("Load() returned findings %v, want a %s finding", findings, utils.CodeCircuitOpen)
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	for _, cfg := range []LoadConfig{
+		{StartQPS: 0, MaxQPS: 10, Step: time.Second},
+		{StartQPS: 10, MaxQPS: 5, Step: time.Second},
+		{StartQPS: 1, MaxQPS: 10},
+	} {
+		if _, _, err := Load(nil, nil, "", cfg); err == nil {
+			t.Errorf("Load() with config %+v returned nil error, want an error", cfg)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scenario
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// LocaleResult records how the partner answered a request for one locale.
+type LocaleResult struct {
+	Locale string `json:"locale"`
+	// Supported is true when every display string in the response is in the requested language.
+	Supported bool `json:"supported"`
+	// Languages lists the languages of the display strings in the response.
+	Languages []string `json:"languages"`
+}
+
+// displayString is a localized text in a response, with the path of its field.
+type displayString struct {
+	field string
+	text  *pb.DisplayString
+}
+
+func displayStrings(resp *pb.BookingAvailabilityResponse) []displayString {
+	var ds []displayString
+	add := func(field string, d *pb.DisplayString) {
+		if d.GetText() != "" {
+			ds = append(ds, displayString{field, d})
+		}
+	}
+	for i, r := range resp.GetRoomTypes() {
+		add(fmt.Sprintf("room_types[%d] > name", i), r.GetName())
+		add(fmt.Sprintf("room_types[%d] > description", i), r.GetDescription())
+	}
+	for i, r := range resp.GetRatePlans() {
+		add(fmt.Sprintf("rate_plans[%d] > name", i), r.GetName())
+		add(fmt.Sprintf("rate_plans[%d] > description", i), r.GetDescription())
+	}
+	return ds
+}
+
+// primaryLanguage returns the primary subtag of a BCP 47 language tag, e.g. "fr" for "fr-CA".
+func primaryLanguage(tag string) string {
+	return strings.ToLower(strings.SplitN(strings.Replace(tag, "_", "-", -1), "-", 2)[0])
+}
+
+// Locales repeats req for each locale, setting both its language field and the Accept-Language header, and reports
+// which locales the partner supports. Each response must pass validation and label its display strings with their
+// actual language: falling back to the default language is fine, but a text labelled with the requested language
+// that is identical to the default text, or a response mixing languages, is a Warning finding.
+func Locales(req *pb.BookingAvailabilityRequest, conn *api.HTTPConnection, endpoint string, locales []string) ([]LocaleResult, []utils.Finding, error) {
+	defer conn.SetHeader("Accept-Language", conn.Header("Accept-Language"))
+	baseline, err := api.SendBookingAvailability(req, conn, endpoint)
+	if err != nil {
+		return nil, nil, err
+	}
+	defaults := map[string]*pb.DisplayString{}
+	for _, d := range displayStrings(baseline) {
+		defaults[d.field] = d.text
+	}
+
+	var results []LocaleResult
+	var findings []utils.Finding
+	var failed []string
+	for _, locale := range locales {
+		r := proto.Clone(req).(*pb.BookingAvailabilityRequest)
+		r.Language = locale
+		conn.SetHeader("Accept-Language", locale)
+		resp, err := api.SendBookingAvailability(r, conn, endpoint)
+		if err == nil {
+			_, err = utils.ValidateBookingAvailabilityResponse(r, resp)
+		}
+		if err != nil {
+			failed = append(failed, locale)
+			findings = append(findings, utils.Finding{Severity: utils.Error, Field: "language " + locale, Message: err.Error(), Code: utils.CodeLocaleFailed})
+			continue
+		}
+
+		result := LocaleResult{Locale: locale, Supported: true}
+		seen := map[string]bool{}
+		for _, d := range displayStrings(resp) {
+			lang := primaryLanguage(d.text.GetLanguage())
+			if !seen[lang] {
+				seen[lang] = true
+				result.Languages = append(result.Languages, lang)
+			}
+			if lang != primaryLanguage(locale) {
+				result.Supported = false
+				continue
+			}
+			if def, ok := defaults[d.field]; ok && primaryLanguage(def.GetLanguage()) != lang && def.GetText() == d.text.GetText() {
+				findings = append(findings, utils.Finding{Severity: utils.Warning, Field: d.field, Message: fmt.Sprintf("labelled %s but identical to the %s text", d.text.GetLanguage(), def.GetLanguage()), Code: utils.CodeLocaleUntranslated})
+			}
+		}
+		if len(result.Languages) > 1 {
+			findings = append(findings, utils.Finding{Severity: utils.Warning, Field: "language " + locale, Message: fmt.Sprintf("response mixes languages %s", strings.Join(result.Languages, ", ")), Code: utils.CodeLocaleMixed})
+		}
+		log.Printf("Locale %s supported: %v, languages returned: %v", locale, result.Supported, result.Languages)
+		results = append(results, result)
+	}
+	if len(failed) > 0 {
+		return results, findings, fmt.Errorf("availability request failed for locale(s): %s", strings.Join(failed, ", "))
+	}
+	return results, findings, nil
+}
@@ -0,0 +1,71 @@
+package scenario
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestLocales(t *testing.T) {
+	availability, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The server translates room type names into French, only relabels German, and defaults everything else to
+	// English.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := proto.Clone(availability.RespPb).(*pb.BookingAvailabilityResponse)
+		switch primaryLanguage(r.Header.Get("Accept-Language")) {
+		case "fr":
+			for _, ds := range displayStrings(resp) {
+				ds.text.Text, ds.text.Language = "fr: "+ds.text.Text, "fr"
+			}
+		case "de":
+			for _, ds := range displayStrings(resp) {
+				ds.text.Language = "de"
+			}
+		}
+		m := jsonpb.Marshaler{OrigName: true}
+		if err := m.Marshal(w, resp); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+	conn, err := api.InitHTTPConnection(strings.TrimPrefix(server.URL, "http://"), "", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn.SetHeader("Accept-Language", "en-GB")
+	results, findings, err := Locales(availability.ReqPb, conn, "", []string{"fr-FR", "de", "ja"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := conn.Header("Accept-Language"); got != "en-GB" {
+		t.Errorf("Locales() left Accept-Language %q, want the previous en-GB", got)
+	}
+	supported := map[string]bool{}
+	for _, r := range results {
+		supported[r.Locale] = r.Supported
+	}
+	if !supported["fr-FR"] || !supported["de"] || supported["ja"] {
+		t.Errorf("Locales() reported support %v, want fr-FR and de", supported)
+	}
+	for _, f := range findings {
+		if f.Severity != utils.Warning || !strings.Contains(f.Message, "labelled de") {
+			t.Errorf("Locales() returned unexpected finding %v", f)
+		}
+	}
+	if len(findings) == 0 {
+		t.Error("Locales() returned no findings, want warnings for the relabelled German text")
+	}
+}
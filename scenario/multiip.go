@@ -0,0 +1,99 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scenario
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// SlowIPFactor is how many times slower than the median of all addresses an address must answer to be reported.
+const SlowIPFactor = 3
+
+// IPResult is the outcome of validating an availability request against a single address of the partner.
+type IPResult struct {
+	IP      string        `json:"ip"`
+	Success bool          `json:"success"`
+	Latency time.Duration `json:"latency"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// MultiIP resolves the partner's server name and sends req to each of its addresses in turn, so a bad backend behind
+// a load balancer or DNS round robin is not hidden by the others. An address failing validation is an Error finding,
+// and one answering more than SlowIPFactor times slower than the median is a Warning. The connection dials the
+// resolver's choice again afterwards.
+func MultiIP(req *pb.BookingAvailabilityRequest, conn *api.HTTPConnection, endpoint string) ([]IPResult, []utils.Finding, error) {
+	ips, err := conn.ResolveServer(context.Background())
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(ips) == 0 {
+		return nil, nil, fmt.Errorf("the server name has no addresses")
+	}
+	defer conn.SetDialAddress("")
+	var results []IPResult
+	var findings []utils.Finding
+	var failed []string
+	for _, ip := range ips {
+		if err := conn.SetDialAddress(ip); err != nil {
+			return nil, nil, err
+		}
+		r := IPResult{IP: ip}
+		start := time.Now()
+		_, err := api.BookingAvailability(req, conn, endpoint)
+		r.Latency = time.Since(start)
+		if err != nil {
+			r.Error = err.Error()
+			failed = append(failed, ip)
+			f := utils.Finding{Severity: utils.Error, Field: fmt.Sprintf("ip %s", ip), Message: err.Error(), Code: utils.CodeMultiIPFailed}
+			log.Println(f)
+			findings = append(findings, f)
+		} else {
+			r.Success = true
+		}
+		log.Printf("Address %s answered in %v, success %v", ip, r.Latency, r.Success)
+		results = append(results, r)
+	}
+
+	latencies := make([]time.Duration, len(results))
+	for i, r := range results {
+		latencies[i] = r.Latency
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	if median := latencies[len(latencies)/2]; len(results) > 1 {
+		for _, r := range results {
+			if r.Latency > SlowIPFactor*median {
+				f := utils.Finding{Severity: utils.Warning, Field: fmt.Sprintf("ip %s", r.IP), Message: fmt.Sprintf("answered in %v, more than %d times the median of %v across %d addresses", r.Latency.Round(time.Millisecond), SlowIPFactor, median.Round(time.Millisecond), len(results)), Code: utils.CodeMultiIPSlow}
+				log.Println(f)
+				findings = append(findings, f)
+			}
+		}
+	}
+	if len(failed) > 0 {
+		return results, findings, fmt.Errorf("%d of %d addresses failed: %s", len(failed), len(results), strings.Join(failed, ", "))
+	}
+	return results, findings, nil
+}
@@ -0,0 +1,42 @@
+package scenario
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+func TestMultiIP(t *testing.T) {
+	availability, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	response := availability.Resp
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, response)
+	}))
+	defer server.Close()
+	conn, err := api.InitHTTPConnection(strings.TrimPrefix(server.URL, "http://"), "", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, findings, err := MultiIP(availability.ReqPb, conn, "/v1/BookingAvailability")
+	if err != nil {
+		t.Fatalf("MultiIP() returned error %v", err)
+	}
+	if len(results) != 1 || results[0].IP != "127.0.0.1" || !results[0].Success || len(findings) != 0 {
+		t.Errorf("MultiIP() = %+v, %v, want a single successful result for 127.0.0.1", results, findings)
+	}
+
+	response = "{}"
+	results, findings, err = MultiIP(availability.ReqPb, conn, "/v1/BookingAvailability")
+	if err == nil || len(results) != 1 || results[0].Success || len(findings) != 1 || findings[0].Field != "ip 127.0.0.1" {
+		t.Errorf("MultiIP() with an invalid response = %+v, %v, %v, want a failed result for 127.0.0.1", results, findings, err)
+	}
+}
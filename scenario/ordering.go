@@ -0,0 +1,90 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scenario
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// totalPrice is the price of a room rate used to recognize responses sorted by price.
+func totalPrice(r *pb.RoomRate) float32 {
+	return r.GetTotalPriceAtBooking().GetAmount() + r.GetTotalPriceAtCheckout().GetAmount()
+}
+
+func sortedByPrice(rates []*pb.RoomRate) bool {
+	return sort.SliceIsSorted(rates, func(i, j int) bool { return totalPrice(rates[i]) < totalPrice(rates[j]) })
+}
+
+func roomRateCodes(rates []*pb.RoomRate) []string {
+	codes := make([]string, len(rates))
+	for i, r := range rates {
+		codes[i] = r.GetCode()
+	}
+	return codes
+}
+
+// Ordering sends req repeats times and verifies room_rates come back in the same order every time. Responses that
+// are all sorted by ascending total price pass even if rates with equal prices swap places. If the set of room rates
+// itself changes between requests, a Warning finding is returned and the order is not compared.
+func Ordering(req *pb.BookingAvailabilityRequest, conn *api.HTTPConnection, endpoint string, repeats int) ([]utils.Finding, error) {
+	if repeats < 2 {
+		return nil, fmt.Errorf("ordering check needs at least 2 requests, got %d", repeats)
+	}
+	var orders [][]string
+	byPrice := true
+	for i := 0; i < repeats; i++ {
+		resp, err := api.SendBookingAvailability(req, conn, endpoint)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, roomRateCodes(resp.GetRoomRates()))
+		byPrice = byPrice && sortedByPrice(resp.GetRoomRates())
+	}
+
+	first := strings.Join(orders[0], ",")
+	sortedFirst := append([]string(nil), orders[0]...)
+	sort.Strings(sortedFirst)
+	for i, o := range orders[1:] {
+		got := strings.Join(o, ",")
+		if got == first {
+			continue
+		}
+		sortedGot := append([]string(nil), o...)
+		sort.Strings(sortedGot)
+		if strings.Join(sortedGot, ",") != strings.Join(sortedFirst, ",") {
+			f := utils.Finding{Severity: utils.Warning, Field: "room_rates", Message: fmt.Sprintf("room rates changed between identical requests 1 and %d, order not compared", i+2), Code: utils.CodeOrderingRatesChanged}
+			log.Println(f)
+			return []utils.Finding{f}, nil
+		}
+		if byPrice {
+			continue
+		}
+		f := utils.Finding{Severity: utils.Error, Field: "room_rates", Message: fmt.Sprintf("order changed between identical requests 1 and %d: [%s] then [%s]; return room rates in a stable order, e.g. sorted by price", i+2, first, got), Code: utils.CodeOrderingUnstable}
+		log.Println(f)
+		return []utils.Finding{f}, fmt.Errorf("room_rates order is not stable across identical requests")
+	}
+	log.Printf("room_rates order was stable across %d identical requests", repeats)
+	return nil, nil
+}
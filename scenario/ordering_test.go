@@ -0,0 +1,85 @@
+package scenario
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// newRotatingServer serves the sample availability response with its room rates rotated by one more position on
+// every request, after applying modify.
+func newRotatingServer(t *testing.T, modify func(*pb.BookingAvailabilityResponse)) (*api.HTTPConnection, *httptest.Server) {
+	availability, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	modify(availability.RespPb)
+	n := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := proto.Clone(availability.RespPb).(*pb.BookingAvailabilityResponse)
+		k := n % len(resp.RoomRates)
+		resp.RoomRates = append(resp.RoomRates[k:], resp.RoomRates[:k]...)
+		n++
+		m := jsonpb.Marshaler{OrigName: true}
+		if err := m.Marshal(w, resp); err != nil {
+			t.Error(err)
+		}
+	}))
+	conn, err := api.InitHTTPConnection(strings.TrimPrefix(server.URL, "http://"), "", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn, server
+}
+
+func TestOrdering(t *testing.T) {
+	availability, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name    string
+		modify  func(*pb.BookingAvailabilityResponse)
+		wantErr bool
+	}{
+		{
+			name:   "single room rate",
+			modify: func(resp *pb.BookingAvailabilityResponse) { resp.RoomRates = resp.RoomRates[:1] },
+		},
+		{
+			name: "equal prices",
+			modify: func(resp *pb.BookingAvailabilityResponse) {
+				for _, r := range resp.RoomRates {
+					r.TotalPriceAtBooking = nil
+					r.TotalPriceAtCheckout = &pb.Price{Amount: 500, Currency: "USD"}
+				}
+			},
+		},
+		{
+			name:    "unstable order",
+			modify:  func(resp *pb.BookingAvailabilityResponse) {},
+			wantErr: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			conn, server := newRotatingServer(t, tc.modify)
+			defer server.Close()
+			findings, err := Ordering(availability.ReqPb, conn, "", 3)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Ordering() returned error [%v], want error: %v", err, tc.wantErr)
+			}
+			if tc.wantErr && len(findings) != 1 {
+				t.Errorf("Ordering() returned findings %v, want one", findings)
+			}
+		})
+	}
+}
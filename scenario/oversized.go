@@ -0,0 +1,107 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scenario
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// DefaultOversizedBytes is the size of the request sent by Oversized, far larger than any valid availability request.
+const DefaultOversizedBytes = 512 << 10
+
+// OversizedField is the field, unknown to the v1 schema, padding the request of Oversized to its size.
+const OversizedField = "x_validator_padding"
+
+// Oversized sends req padded with an OversizedField to size bytes and ensures the partner rejects it with 413
+// Request Entity Too Large or 400 Bad Request, then sends req as is to ensure the partner still answers. A timeout,
+// a connection closed without a response or any other status fails the scenario; a padded request the partner
+// accepts is a Warning finding, as any request this large is an abuse. An expected status set for endpoint applies
+// only to the request sent as is.
+func Oversized(req *pb.BookingAvailabilityRequest, conn *api.HTTPConnection, endpoint string, size int) ([]utils.Finding, error) {
+	s, err := (&jsonpb.Marshaler{OrigName: true}).MarshalToString(req)
+	if err != nil {
+		return nil, fmt.Errorf("Could not convert pb3 to json: %v", err)
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(s), &body); err != nil {
+		return nil, err
+	}
+	padding := size - len(s)
+	if padding < 0 {
+		padding = 0
+	}
+	body[OversizedField] = strings.Repeat("x", padding)
+	b, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	desc := fmt.Sprintf("a request of %d KB", len(b)>>10)
+
+	var findings []utils.Finding
+	report := func(s utils.Severity, code, msg string) {
+		f := utils.Finding{Severity: s, Field: "oversized_request", Message: msg, Code: code}
+		log.Println(f)
+		findings = append(findings, f)
+	}
+	// An expected status set for endpoint applies to valid requests, not to the padded one.
+	prev := conn.ExpectedStatus(endpoint)
+	conn.SetExpectedStatus(endpoint, 0)
+	status, _, err := api.SendJSONStatus(string(b), conn, endpoint)
+	conn.SetExpectedStatus(endpoint, prev)
+	switch {
+	case errors.Is(err, api.ErrTimeout):
+		report(utils.Error, utils.CodeOversizedTimeout, fmt.Sprintf("%s timed out instead of being rejected with 413 or 400: %v", desc, err))
+	case errors.Is(err, api.ErrTransport):
+		report(utils.Error, utils.CodeOversizedClosed, fmt.Sprintf("the connection was closed without a response to %s, want 413 or 400: %v", desc, err))
+	case errors.Is(err, api.ErrAuth):
+		return nil, err
+	case err != nil:
+		report(utils.Error, utils.CodeOversizedStatus, fmt.Sprintf("%s yielded %v, want 413 %s or 400 %s", desc, err, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusText(http.StatusBadRequest)))
+	case status == http.StatusRequestEntityTooLarge || status == http.StatusBadRequest:
+		log.Printf("Oversized request of %d KB rejected with %d %s as expected", len(b)>>10, status, http.StatusText(status))
+	case status >= 200 && status < 300:
+		report(utils.Warning, utils.CodeOversizedAccepted, fmt.Sprintf("%s was accepted; reject requests far larger than any valid request with 413", desc))
+	default:
+		report(utils.Error, utils.CodeOversizedStatus, fmt.Sprintf("%s was answered with %d %s, want 413 %s or 400 %s", desc, status, http.StatusText(status), http.StatusText(http.StatusRequestEntityTooLarge), http.StatusText(http.StatusBadRequest)))
+	}
+
+	if _, err := api.SendBookingAvailability(req, conn, endpoint); err != nil {
+		report(utils.Error, utils.CodeOversizedUnavailable, fmt.Sprintf("the availability request following %s failed: %v", desc, err))
+	}
+	var failed []string
+	for _, f := range findings {
+		if f.Severity != utils.Warning {
+			failed = append(failed, f.Message)
+		}
+	}
+	if len(failed) > 0 {
+		return findings, errors.New(strings.Join(failed, "; "))
+	}
+	return findings, nil
+}
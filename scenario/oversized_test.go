@@ -0,0 +1,65 @@
+package scenario
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+func TestOversized(t *testing.T) {
+	availability, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name      string
+		status    int
+		expected  int
+		wantErr   bool
+		wantCodes []string
+	}{
+		{name: "rejected", status: http.StatusRequestEntityTooLarge},
+		{name: "bad request", status: http.StatusBadRequest},
+		{name: "rejected with an expected status", status: http.StatusRequestEntityTooLarge, expected: http.StatusOK},
+		{name: "accepted", status: http.StatusOK, wantCodes: []string{utils.CodeOversizedAccepted}},
+		{name: "crashed", status: http.StatusInternalServerError, wantErr: true, wantCodes: []string{utils.CodeOversizedStatus}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, 64<<10))
+				if err != nil {
+					http.Error(w, "too large", tc.status)
+					return
+				}
+				if strings.Contains(string(body), OversizedField) {
+					t.Error("padding sent below the oversized size")
+				}
+				w.Write([]byte(availability.Resp))
+			}))
+			defer server.Close()
+			conn, err := api.InitHTTPConnection(strings.TrimPrefix(server.URL, "http://"), "", "", "", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			conn.SetExpectedStatus("/v1/BookingAvailability", tc.expected)
+			findings, err := Oversized(availability.ReqPb, conn, "/v1/BookingAvailability", DefaultOversizedBytes)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Oversized() returned error %v, want error %v", err, tc.wantErr)
+			}
+			var codes []string
+			for _, f := range findings {
+				codes = append(codes, f.Code)
+			}
+			if strings.Join(codes, ",") != strings.Join(tc.wantCodes, ",") {
+				t.Errorf("Oversized() returned finding codes %v, want %v", codes, tc.wantCodes)
+			}
+		})
+	}
+}
@@ -0,0 +1,269 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+// planVariable matches a reference to a variable of an execution plan, such as {run_id}.
+var planVariable = regexp.MustCompile(`\{([a-z0-9_]+)\}`)
+
+// PlanStep is a request sent by an execution plan before or after the flows of a run.
+type PlanStep struct {
+	Name     string `json:"name"`
+	Endpoint string `json:"endpoint"`
+	// Request is the path of the json body of the step, relative to the plan file. Body is used if it is not set.
+	Request string          `json:"request,omitempty"`
+	Body    json.RawMessage `json:"body,omitempty"`
+	// Status is the HTTP status the step must be answered with, 200 if unset.
+	Status int `json:"status,omitempty"`
+	// Set replaces the fields of the body at dotted paths, e.g. transaction_id, with strings that may refer to
+	// variables, so that a request file can be reused with values of its own.
+	Set map[string]string `json:"set,omitempty"`
+	// Save names variables set to the field of the response at a dotted path, e.g. reservation.locator.id.
+	Save map[string]string `json:"save,omitempty"`
+
+	body string
+}
+
+// Plan is an execution plan: setup steps sent before the flows of a run, e.g. to create a booking for cancel testing,
+// and teardown steps sent after them, e.g. to cancel leftover test bookings, so a run leaves the sandbox clean.
+// Endpoints and bodies of steps may refer to {run_id} and to the variables saved by earlier steps.
+type Plan struct {
+	Setup    []PlanStep `json:"setup"`
+	Teardown []PlanStep `json:"teardown"`
+
+	vars map[string]string
+}
+
+// LoadPlan reads the execution plan at path and the request files of its steps.
+func LoadPlan(path string) (*Plan, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p Plan
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, fmt.Errorf("unable to parse execution plan %s: %v", path, err)
+	}
+	for _, steps := range [][]PlanStep{p.Setup, p.Teardown} {
+		for i := range steps {
+			s := &steps[i]
+			if s.Name == "" || s.Endpoint == "" {
+				return nil, fmt.Errorf("step %d of execution plan %s needs a name and an endpoint", i, path)
+			}
+			s.body = string(s.Body)
+			if s.Request != "" {
+				fp := s.Request
+				if !filepath.IsAbs(fp) {
+					fp = filepath.Join(filepath.Dir(path), fp)
+				}
+				body, err := ioutil.ReadFile(fp)
+				if err != nil {
+					return nil, fmt.Errorf("step %s of execution plan %s: %v", s.Name, path, err)
+				}
+				s.body = string(body)
+			}
+			if s.body == "" {
+				s.body = "{}"
+			}
+		}
+	}
+	return &p, nil
+}
+
+// RunSetup sends the setup steps of p in order, stopping at the first failure, after which the flows of the run should
+// be skipped. The run's teardown steps should be sent either way.
+func (p *Plan) RunSetup(conn *api.HTTPConnection, runID string) ([]utils.Finding, error) {
+	p.vars = map[string]string{"run_id": runID}
+	for _, s := range p.Setup {
+		if err := p.run(s, conn); err != nil {
+			f := utils.Finding{Severity: utils.Error, Field: "setup > " + s.Name, Message: err.Error(), Code: utils.CodePlanSetup}
+			log.Println(f)
+			return []utils.Finding{f}, fmt.Errorf("setup step %s failed: %v", s.Name, err)
+		}
+	}
+	return nil, nil
+}
+
+// RunTeardown sends every teardown step of p, even after a failure, as each may clean up after a different flow. A
+// step referring to a variable the setup did not save fails.
+func (p *Plan) RunTeardown(conn *api.HTTPConnection, runID string) ([]utils.Finding, error) {
+	if p.vars == nil {
+		p.vars = map[string]string{"run_id": runID}
+	}
+	var findings []utils.Finding
+	var failed []string
+	for _, s := range p.Teardown {
+		if err := p.run(s, conn); err != nil {
+			f := utils.Finding{Severity: utils.Error, Field: "teardown > " + s.Name, Message: err.Error(), Code: utils.CodePlanTeardown}
+			log.Println(f)
+			findings = append(findings, f)
+			failed = append(failed, s.Name)
+		}
+	}
+	if len(failed) > 0 {
+		return findings, fmt.Errorf("teardown step(s) failed: %s", strings.Join(failed, ", "))
+	}
+	return findings, nil
+}
+
+// run sends step s and saves the variables it names from the response.
+func (p *Plan) run(s PlanStep, conn *api.HTTPConnection) error {
+	endpoint, err := p.expand(s.Endpoint, url.PathEscape)
+	if err != nil {
+		return err
+	}
+	body, err := p.expand(s.body, func(v string) string {
+		b, _ := json.Marshal(v)
+		return string(b[1 : len(b)-1])
+	})
+	if err != nil {
+		return err
+	}
+	if len(s.Set) > 0 {
+		if body, err = p.setFields(body, s.Set); err != nil {
+			return fmt.Errorf("unable to set the fields of the body of %s: %v", s.Name, err)
+		}
+	}
+	want := s.Status
+	if want == 0 {
+		want = http.StatusOK
+	}
+	restore := expectStatus(conn, endpoint, s.Status)
+	status, resp, err := api.SendJSONStatus(body, conn, endpoint)
+	restore()
+	if err != nil {
+		return err
+	}
+	if status != want {
+		return fmt.Errorf("%s returned %d %s, want %d %s", endpoint, status, http.StatusText(status), want, http.StatusText(want))
+	}
+	if len(s.Save) == 0 {
+		log.Printf("Step %s sent to %s", s.Name, endpoint)
+		return nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(resp), &fields); err != nil {
+		return fmt.Errorf("unable to read the response of %s: %v", endpoint, err)
+	}
+	for name, path := range s.Save {
+		v, ok := planField(fields, strings.Split(path, "."))
+		if !ok {
+			return fmt.Errorf("the response of %s has no string or number field %s to save as %s", endpoint, path, name)
+		}
+		p.vars[name] = v
+		log.Printf("Step %s saved %s=%s", s.Name, name, v)
+	}
+	return nil
+}
+
+// expand replaces the variable references of s with the values of p, escaped with escape. Other references, such as
+// the request fields of an endpoint template, are left alone, but a variable no step has saved yet is an error.
+func (p *Plan) expand(s string, escape func(string) string) (string, error) {
+	var err error
+	expanded := planVariable.ReplaceAllStringFunc(s, func(ref string) string {
+		name := strings.Trim(ref, "{}")
+		if v, ok := p.vars[name]; ok {
+			return escape(v)
+		}
+		if p.saves(name) && err == nil {
+			err = fmt.Errorf("variable %s was not saved by the setup", name)
+		}
+		return ref
+	})
+	return expanded, err
+}
+
+// setFields returns body, a json object, with the field at each dotted path of set replaced by its value, with the
+// variable references expanded. Numbers elsewhere in body are kept as written.
+func (p *Plan) setFields(body string, set map[string]string) (string, error) {
+	d := json.NewDecoder(strings.NewReader(body))
+	d.UseNumber()
+	var fields map[string]interface{}
+	if err := d.Decode(&fields); err != nil {
+		return "", err
+	}
+	for path, value := range set {
+		v, err := p.expand(value, func(v string) string { return v })
+		if err != nil {
+			return "", err
+		}
+		if err := setPlanField(fields, strings.Split(path, "."), v); err != nil {
+			return "", fmt.Errorf("%s: %v", path, err)
+		}
+	}
+	b, err := json.Marshal(fields)
+	return string(b), err
+}
+
+// setPlanField sets the field at path in fields, a decoded json object, to v, adding the objects leading to it.
+func setPlanField(fields map[string]interface{}, path []string, v string) error {
+	if len(path) == 1 {
+		fields[path[0]] = v
+		return nil
+	}
+	next, ok := fields[path[0]].(map[string]interface{})
+	if !ok {
+		if fields[path[0]] != nil {
+			return fmt.Errorf("%s is not an object", path[0])
+		}
+		next = map[string]interface{}{}
+		fields[path[0]] = next
+	}
+	return setPlanField(next, path[1:], v)
+}
+
+// saves reports whether a step of p saves the variable name.
+func (p *Plan) saves(name string) bool {
+	for _, steps := range [][]PlanStep{p.Setup, p.Teardown} {
+		for _, s := range steps {
+			if _, ok := s.Save[name]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// planField returns the string or number at path in fields, a decoded json object.
+func planField(fields map[string]interface{}, path []string) (string, bool) {
+	switch v := fields[path[0]].(type) {
+	case map[string]interface{}:
+		if len(path) > 1 {
+			return planField(v, path[1:])
+		}
+	case string:
+		return v, len(path) == 1
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), len(path) == 1
+	}
+	return "", false
+}
@@ -0,0 +1,98 @@
+package scenario
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+func TestPlan(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "booking.json"), []byte(`{"transaction_id": "t1", "party": {"adults": 2}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fp := filepath.Join(dir, "plan.json")
+	if err := ioutil.WriteFile(fp, []byte(`{
+		"setup": [{"name": "book", "endpoint": "/book", "request": "booking.json", "set": {"transaction_id": "setup-{run_id}"}, "save": {"locator": "reservation.locator.id"}}],
+		"teardown": [
+			{"name": "cancel", "endpoint": "/cancel/{locator}", "body": {"run_id": "{run_id}"}},
+			{"name": "missing", "endpoint": "/missing"},
+			{"name": "cleanup", "endpoint": "/cleanup", "status": 204}
+		]
+	}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		sent = append(sent, r.URL.Path+" "+string(body))
+		switch r.URL.Path {
+		case "/book":
+			w.Write([]byte(`{"reservation": {"locator": {"id": "L 1"}}}`))
+		case "/cancel/L 1":
+			w.Write([]byte(`{}`))
+		case "/cleanup":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	conn, err := api.InitHTTPConnection(strings.TrimPrefix(server.URL, "http://"), "", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := LoadPlan(fp)
+	if err != nil {
+		t.Fatalf("LoadPlan() returned error %v", err)
+	}
+	if _, err := p.RunSetup(conn, "run1"); err != nil {
+		t.Errorf("RunSetup() returned error %v, want nil", err)
+	}
+	findings, err := p.RunTeardown(conn, "run1")
+	if err == nil {
+		t.Error("RunTeardown() returned no error, want the missing step to fail")
+	}
+	if len(findings) != 1 || findings[0].Field != "teardown > missing" || findings[0].Code != utils.CodePlanTeardown {
+		t.Errorf("RunTeardown() returned findings %v, want a single %s for the missing step", findings, utils.CodePlanTeardown)
+	}
+	want := []string{`/book {"party":{"adults":2},"transaction_id":"setup-run1"}`, `/cancel/L 1 {"run_id": "run1"}`, "/missing {}", "/cleanup {}"}
+	if strings.Join(sent, "\n") != strings.Join(want, "\n") {
+		t.Errorf("plan sent %q, want %q", sent, want)
+	}
+}
+
+func TestPlanSetupFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	conn, err := api.InitHTTPConnection(strings.TrimPrefix(server.URL, "http://"), "", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &Plan{
+		Setup:    []PlanStep{{Name: "book", Endpoint: "/book", Save: map[string]string{"locator": "id"}, body: "{}"}},
+		Teardown: []PlanStep{{Name: "cancel", Endpoint: "/cancel/{locator}", body: "{}"}},
+	}
+	findings, err := p.RunSetup(conn, "run1")
+	if err == nil || len(findings) != 1 || findings[0].Code != utils.CodePlanSetup {
+		t.Errorf("RunSetup() returned findings %v and error %v, want a single %s", findings, err, utils.CodePlanSetup)
+	}
+	if _, err := p.RunTeardown(conn, "run1"); err == nil || !strings.Contains(err.Error(), "cancel") {
+		t.Errorf("RunTeardown() returned error %v, want the cancel step of an unsaved locator to fail", err)
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scenario
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/google/hotel-booking-api-validator/api"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// quoteTolerance is the largest difference between a quoted and a booked total still considered a match, absorbing
+// the rounding of float amounts.
+const quoteTolerance = 0.01
+
+// Total is the price of a room rate, the sum of its total_price_at_booking and total_price_at_checkout.
+type Total struct {
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+func (t Total) String() string {
+	return fmt.Sprintf("%.2f %s", t.Amount, t.Currency)
+}
+
+// matches reports whether t and o are the same amount in the same currency.
+func (t Total) matches(o Total) bool {
+	return t.Currency == o.Currency && math.Abs(t.Amount-o.Amount) <= quoteTolerance
+}
+
+// roomRateTotal returns the total of r, or an error if r has no price or prices in several currencies.
+func roomRateTotal(r *pb.RoomRate) (Total, error) {
+	var t Total
+	for _, p := range []*pb.Price{r.GetTotalPriceAtBooking(), r.GetTotalPriceAtCheckout()} {
+		if p == nil {
+			continue
+		}
+		if t.Currency != "" && p.GetCurrency() != t.Currency {
+			return Total{}, fmt.Errorf("room rate %s is priced in both %s and %s", r.GetCode(), t.Currency, p.GetCurrency())
+		}
+		t.Currency = p.GetCurrency()
+		t.Amount += float64(p.GetAmount())
+	}
+	if t.Currency == "" {
+		return Total{}, fmt.Errorf("room rate %s has neither total_price_at_booking nor total_price_at_checkout", r.GetCode())
+	}
+	return t, nil
+}
+
+// Quote sends req to endpoint, the quote or price-check endpoint of partners implementing one, which prices the
+// booking of req without making it and answers with a BookingSubmitResponse whose reservation holds the quoted room
+// rate. It returns the total of that room rate.
+func Quote(req *pb.BookingSubmitRequest, conn *api.HTTPConnection, endpoint string) (Total, error) {
+	resp, err := api.SendBookingSubmit(req, conn, endpoint)
+	if err != nil {
+		return Total{}, err
+	}
+	if resp.GetStatus() == pb.BookingSubmitResponse_FAILURE {
+		return Total{}, fmt.Errorf("quote of room rate %s failed with %v", req.GetRoomRate().GetCode(), resp.GetError().GetType())
+	}
+	t, err := roomRateTotal(resp.GetReservation().GetRoomRate())
+	if err != nil {
+		return Total{}, fmt.Errorf("quote response: %v", err)
+	}
+	return t, nil
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scenario
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/mock"
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+// SelfTestCheck is the outcome of one check of SelfTest. Err is nil if the check passed.
+type SelfTestCheck struct {
+	Name string
+	Err  error
+}
+
+// SelfTest verifies that the validator works on this machine without contacting a partner: the embedded sample
+// requests and responses are loaded and validated, then sent through the mock partner over http and over https,
+// using a certificate generated for the test, and the system root certificates are loaded. Every check is run, even
+// after a failure, and their outcomes returned in order.
+func SelfTest() []SelfTestCheck {
+	var checks []SelfTestCheck
+	check := func(name string, f func() error) {
+		checks = append(checks, SelfTestCheck{name, f()})
+	}
+	availability, availErr := utils.BookingAvailabilityData()
+	submit, submitErr := utils.BookingSubmitData()
+	check("sample data", func() error {
+		if availErr != nil {
+			return fmt.Errorf("failed to load the sample availability request and response: %v", availErr)
+		}
+		if submitErr != nil {
+			return fmt.Errorf("failed to load the sample submit request and response: %v", submitErr)
+		}
+		if _, err := utils.ValidateBookingAvailabilityResponse(availability.ReqPb, availability.RespPb); err != nil {
+			return fmt.Errorf("sample availability response failed validation: %v", err)
+		}
+		if _, err := utils.ValidateBookingSubmitResponse(submit.ReqPb, submit.RespPb); err != nil {
+			return fmt.Errorf("sample submit response failed validation: %v", err)
+		}
+		return nil
+	})
+	if availErr != nil || submitErr != nil {
+		checks = append(checks, SelfTestCheck{"mock over http", fmt.Errorf("skipped, sample data is missing")})
+		checks = append(checks, SelfTestCheck{"mock over https", fmt.Errorf("skipped, sample data is missing")})
+	} else {
+		server := mock.New(availability.RespPb, submit.RespPb, mock.Faults{})
+		check("mock over http", func() error {
+			return selfTestMock(server, availability, submit, false)
+		})
+		check("mock over https", func() error {
+			return selfTestMock(server, availability, submit, true)
+		})
+	}
+	check("system root certificates", func() error {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			return fmt.Errorf("failed to load system root certificates: %v", err)
+		}
+		if pool.Equal(x509.NewCertPool()) {
+			return fmt.Errorf("no system root certificates are installed")
+		}
+		return nil
+	})
+	return checks
+}
+
+// selfTestMock serves the mock partner on a local port and validates the sample flows against it.
+func selfTestMock(server *mock.Server, availability *utils.BookingAvailabilityDataStruct, submit *utils.BookingSubmitDataStruct, useTLS bool) error {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("failed to listen on a local port: %v", err)
+	}
+	caFile := ""
+	if useTLS {
+		dir, err := ioutil.TempDir("", "selftest")
+		if err != nil {
+			l.Close()
+			return err
+		}
+		defer os.RemoveAll(dir)
+		caFile = filepath.Join(dir, "ca.pem")
+		cert, err := selfTestCertificate(caFile)
+		if err != nil {
+			l.Close()
+			return fmt.Errorf("failed to generate a certificate: %v", err)
+		}
+		l = tls.NewListener(l, &tls.Config{Certificates: []tls.Certificate{cert}})
+	}
+	s := &http.Server{Handler: server}
+	go s.Serve(l)
+	defer s.Close()
+
+	conn, err := api.InitHTTPConnection(l.Addr().String(), "", caFile, "localhost", "")
+	if err != nil {
+		return err
+	}
+	if _, err := api.BookingAvailability(availability.ReqPb, conn, "/v1/BookingAvailability"); err != nil {
+		return fmt.Errorf("BookingAvailability: %v", err)
+	}
+	if _, err := api.BookingSubmit(submit.ReqPb, conn, "/v1/BookingSubmit"); err != nil {
+		return fmt.Errorf("BookingSubmit: %v", err)
+	}
+	return nil
+}
+
+// selfTestCertificate generates a self-signed certificate for localhost and writes it to caFile in PEM format.
+func selfTestCertificate(caFile string) (tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "localhost"},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	if err := ioutil.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
@@ -0,0 +1,23 @@
+package scenario
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSelfTest(t *testing.T) {
+	checks := SelfTest()
+	var names []string
+	for _, c := range checks {
+		names = append(names, c.Name)
+		// The system root certificates depend on the machine running the test.
+		if c.Err != nil && c.Name != "system root certificates" {
+			t.Errorf("SelfTest() check %s failed: %v", c.Name, c.Err)
+		}
+	}
+	want := []string{"sample data", "mock over http", "mock over https", "system root certificates"}
+	if diff := cmp.Diff(names, want); diff != "" {
+		t.Errorf("SelfTest() checks did not match (-got +want)\n%s", diff)
+	}
+}
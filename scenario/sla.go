@@ -0,0 +1,247 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scenario
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// errorClasses names the classes of failed requests counted by Soak. A timeout is also a transport error, so the
+// first match wins.
+var errorClasses = []struct {
+	err  error
+	name string
+}{
+	{api.ErrTimeout, "timeout"},
+	{api.ErrThrottled, "throttled"},
+	{api.ErrAuth, "auth"},
+	{api.ErrStatus, "status"},
+	{api.ErrInsecure, "insecure"},
+	{api.ErrTransport, "transport"},
+	{api.ErrParse, "parse"},
+	{api.ErrValidation, "validation"},
+}
+
+// errorClass returns the name of the class of err, or "other".
+func errorClass(err error) string {
+	for _, c := range errorClasses {
+		if errors.Is(err, c.err) {
+			return c.name
+		}
+	}
+	return "other"
+}
+
+// SLAThresholds are the service levels a Soak run must meet. Rates are shares between 0 and 1.
+type SLAThresholds struct {
+	// MinBookingSuccessRate is the lowest share of bookings that must succeed.
+	MinBookingSuccessRate float64
+	// MinAvailabilityNonEmptyRate is the lowest share of availability requests that must return room rates.
+	MinAvailabilityNonEmptyRate float64
+	// MaxErrorRates maps error classes, e.g. timeout or throttled, to the highest share of all requests that may
+	// fail with that class.
+	MaxErrorRates map[string]float64
+}
+
+// SLAConfig controls a Soak run.
+type SLAConfig struct {
+	// Iterations is the number of times the availability request, and the booking, are sent.
+	Iterations int
+	// Interval is the pause between iterations.
+	Interval   time.Duration
+	Thresholds SLAThresholds
+	// Rules, if set, counts the rules broken by each request.
+	Rules *utils.RuleTally
+	// MaxTransportFailures, if positive, stops the soak test after that many consecutive requests failed in
+	// transport, e.g. DefaultMaxTransportFailures. The remaining requests are not run.
+	MaxTransportFailures int
+}
+
+// validate returns an error for a rate of t outside 0 and 1, or an error class of MaxErrorRates not named by
+// errorClasses or "other".
+func (t SLAThresholds) validate() error {
+	valid := func(r float64) bool { return r >= 0 && r <= 1 }
+	if !valid(t.MinBookingSuccessRate) {
+		return fmt.Errorf("minimum booking success rate %v is not between 0 and 1", t.MinBookingSuccessRate)
+	}
+	if !valid(t.MinAvailabilityNonEmptyRate) {
+		return fmt.Errorf("minimum availability non-empty rate %v is not between 0 and 1", t.MinAvailabilityNonEmptyRate)
+	}
+	for class, max := range t.MaxErrorRates {
+		known := class == "other"
+		for _, c := range errorClasses {
+			known = known || c.name == class
+		}
+		if !known {
+			return fmt.Errorf("unknown error class %q", class)
+		}
+		if !valid(max) {
+			return fmt.Errorf("maximum %s error rate %v is not between 0 and 1", class, max)
+		}
+	}
+	return nil
+}
+
+// DefaultSLAThresholds require 95% of bookings to succeed, 90% of availability requests to return room rates, and
+// at most 1% of requests to time out or fail in transport.
+var DefaultSLAThresholds = SLAThresholds{
+	MinBookingSuccessRate:       0.95,
+	MinAvailabilityNonEmptyRate: 0.9,
+	MaxErrorRates:               map[string]float64{"timeout": 0.01, "transport": 0.01},
+}
+
+// SLAReport counts the requests of a Soak run by outcome.
+type SLAReport struct {
+	AvailabilityRequests     int                `json:"availability_requests"`
+	AvailabilityNonEmpty     int                `json:"availability_non_empty"`
+	AvailabilityNonEmptyRate float64            `json:"availability_non_empty_rate"`
+	Bookings                 int                `json:"bookings"`
+	BookingsSucceeded        int                `json:"bookings_succeeded"`
+	BookingSuccessRate       float64            `json:"booking_success_rate"`
+	Errors                   map[string]int     `json:"errors_by_class,omitempty"`
+	ErrorRates               map[string]float64 `json:"error_rates_by_class,omitempty"`
+}
+
+// Soak sends availReq, then books a copy of submitReq with a distinct transaction_id, cfg.Iterations times, and
+// reports the booking success rate, the share of availability responses with room rates, and the rate of failed
+// requests by error class. Either request may be nil to leave out its flow. Responses are validated; a response
+// failing validation counts in the validation class, and a booking rejected with a FAILURE status as unsuccessful.
+// Each threshold of cfg missed by the run is an Error finding and fails the run. Once cfg.MaxTransportFailures
+// consecutive requests failed in transport the remaining ones are skipped, which is an Error finding and fails the
+// run. Note that every successful booking is a real booking.
+func Soak(availReq *pb.BookingAvailabilityRequest, submitReq *pb.BookingSubmitRequest, conn *api.HTTPConnection, availEndpoint, submitEndpoint string, cfg SLAConfig) (*SLAReport, []utils.Finding, error) {
+	if availReq == nil && submitReq == nil {
+		return nil, nil, fmt.Errorf("the soak test requires an availability or submit request")
+	}
+	if err := cfg.Thresholds.validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid SLA thresholds: %v", err)
+	}
+	r := &SLAReport{Errors: map[string]int{}, ErrorRates: map[string]float64{}}
+	failed := func(flow string, err error) {
+		class := errorClass(err)
+		r.Errors[class]++
+		log.Printf("Soak %s request failed with a %s error: %v", flow, class, err)
+	}
+	br := &breaker{threshold: cfg.MaxTransportFailures}
+	for i := 0; i < cfg.Iterations; i++ {
+		if i > 0 && !br.open() {
+			time.Sleep(cfg.Interval)
+		}
+		if availReq != nil && br.open() {
+			br.skip(cfg.Rules)
+		} else if availReq != nil {
+			r.AvailabilityRequests++
+			resp, err := checkAvailability(availReq, conn, availEndpoint)
+			cfg.Rules.Add(nil, err)
+			br.record(err)
+			if err != nil {
+				failed("availability", err)
+			} else {
+				conn.SamplePassing(availEndpoint, availReq, resp)
+				if len(resp.GetRoomRates()) > 0 {
+					r.AvailabilityNonEmpty++
+				}
+			}
+		}
+		if submitReq != nil && br.open() {
+			br.skip(cfg.Rules)
+		} else if submitReq != nil {
+			r.Bookings++
+			req := proto.Clone(submitReq).(*pb.BookingSubmitRequest)
+			req.TransactionId = fmt.Sprintf("%s-soak-%d", submitReq.GetTransactionId(), i)
+			resp, err := api.SendBookingSubmit(req, conn, submitEndpoint)
+			br.record(err)
+			if err != nil {
+				cfg.Rules.Add(nil, err)
+				failed("submit", err)
+				continue
+			}
+			if resp.GetStatus() != pb.BookingSubmitResponse_SUCCESS {
+				cfg.Rules.Add(nil, fmt.Errorf("booking rejected with %v", resp.GetError().GetType()))
+				log.Printf("Soak booking %s was rejected with %v", req.GetTransactionId(), resp.GetError().GetType())
+				continue
+			}
+			validation, err := utils.ValidateBookingSubmitResponse(req, resp)
+			if err != nil {
+				err = fmt.Errorf("%w: %v", api.ErrValidation, err)
+				failed("submit", err)
+			} else {
+				r.BookingsSucceeded++
+				conn.SamplePassing(submitEndpoint, req, resp)
+			}
+			cfg.Rules.Add(validation, err)
+		}
+	}
+	r.AvailabilityNonEmptyRate = rate(r.AvailabilityNonEmpty, r.AvailabilityRequests)
+	r.BookingSuccessRate = rate(r.BookingsSucceeded, r.Bookings)
+	for class, n := range r.Errors {
+		r.ErrorRates[class] = rate(n, r.AvailabilityRequests+r.Bookings)
+	}
+
+	findings := br.findings("the soak test")
+	var violated []string
+	if findings != nil {
+		violated = append(violated, "circuit_breaker")
+	}
+	violation := func(field, format string, args ...interface{}) {
+		f := utils.Finding{Severity: utils.Error, Field: "sla > " + field, Message: fmt.Sprintf(format, args...), Code: utils.CodeSLAMissed}
+		log.Println(f)
+		findings = append(findings, f)
+		violated = append(violated, field)
+	}
+	t := cfg.Thresholds
+	if r.Bookings > 0 && r.BookingSuccessRate < t.MinBookingSuccessRate {
+		violation("booking_success_rate", "%d of %d bookings succeeded (%.1f%%), want at least %.1f%%", r.BookingsSucceeded, r.Bookings, 100*r.BookingSuccessRate, 100*t.MinBookingSuccessRate)
+	}
+	if r.AvailabilityRequests > 0 && r.AvailabilityNonEmptyRate < t.MinAvailabilityNonEmptyRate {
+		violation("availability_non_empty_rate", "%d of %d availability requests returned room rates (%.1f%%), want at least %.1f%%", r.AvailabilityNonEmpty, r.AvailabilityRequests, 100*r.AvailabilityNonEmptyRate, 100*t.MinAvailabilityNonEmptyRate)
+	}
+	classes := make([]string, 0, len(t.MaxErrorRates))
+	for class := range t.MaxErrorRates {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	for _, class := range classes {
+		if max := t.MaxErrorRates[class]; r.ErrorRates[class] > max {
+			violation("error_rate > "+class, "%d of %d requests failed with a %s error (%.1f%%), want at most %.1f%%", r.Errors[class], r.AvailabilityRequests+r.Bookings, class, 100*r.ErrorRates[class], 100*max)
+		}
+	}
+	if len(violated) > 0 {
+		return r, findings, fmt.Errorf("SLA thresholds violated: %s", strings.Join(violated, ", "))
+	}
+	return r, findings, nil
+}
+
+// rate returns n as a share of total, or 0 if total is 0.
+func rate(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
@@ -0,0 +1,105 @@
+package scenario
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestSoak(t *testing.T) {
+	submit, err := utils.BookingSubmitData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	availability, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	empty := proto.Clone(availability.RespPb).(*pb.BookingAvailabilityResponse)
+	empty.RoomTypes, empty.RatePlans, empty.RoomRates = nil, nil, nil
+	emptyResp, err := (&jsonpb.Marshaler{OrigName: true}).MarshalToString(empty)
+	if err != nil {
+		t.Fatal(err)
+	}
+	availabilityRequests, submitRequests := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/availability":
+			availabilityRequests++
+			if availabilityRequests == 2 {
+				fmt.Fprintln(w, emptyResp)
+				return
+			}
+			fmt.Fprintln(w, availability.Resp)
+		case "/submit":
+			submitRequests++
+			if submitRequests == 1 {
+				http.Error(w, "slow down", http.StatusTooManyRequests)
+				return
+			}
+			fmt.Fprintln(w, submit.Resp)
+		}
+	}))
+	defer server.Close()
+	conn, err := api.InitHTTPConnection(strings.TrimPrefix(server.URL, "http://"), "", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := SLAConfig{Iterations: 4, Thresholds: DefaultSLAThresholds}
+	got, findings, err := Soak(availability.ReqPb, submit.ReqPb, conn, "/availability", "/submit", cfg)
+	want := &SLAReport{
+		AvailabilityRequests:     4,
+		AvailabilityNonEmpty:     3,
+		AvailabilityNonEmptyRate: 0.75,
+		Bookings:                 4,
+		BookingsSucceeded:        3,
+		BookingSuccessRate:       0.75,
+		Errors:                   map[string]int{"throttled": 1},
+		ErrorRates:               map[string]float64{"throttled": 0.125},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Soak() report did not match (-got +want)\n%s", diff)
+	}
+	if err == nil || err.Error() != "SLA thresholds violated: booking_success_rate, availability_non_empty_rate" {
+		t.Errorf("Soak() returned error %v, want the booking and availability thresholds violated", err)
+	}
+	if len(findings) != 2 {
+		t.Errorf("Soak() returned findings %v, want 2", findings)
+	}
+
+	cfg.Thresholds = SLAThresholds{MaxErrorRates: map[string]float64{"throttled": 0.2}}
+	if _, findings, err := Soak(availability.ReqPb, submit.ReqPb, conn, "/availability", "/submit", cfg); err != nil || len(findings) != 0 {
+		t.Errorf("Soak() with lenient thresholds returned findings %v and error %v, want none", findings, err)
+	}
+	cfg.Thresholds = SLAThresholds{MaxErrorRates: map[string]float64{"throttled": 0.1}}
+	availabilityRequests, submitRequests = 0, 0
+	if _, findings, err := Soak(nil, submit.ReqPb, conn, "/availability", "/submit", cfg); err == nil || len(findings) != 1 || findings[0].Field != "sla > error_rate > throttled" {
+		t.Errorf("Soak() returned findings %v and error %v, want the throttled error rate violated", findings, err)
+	}
+}
+
+func TestSoakThresholdsInvalid(t *testing.T) {
+	req := &pb.BookingAvailabilityRequest{}
+	for _, thresholds := range []SLAThresholds{
+		{MinBookingSuccessRate: 1.5},
+		{MinAvailabilityNonEmptyRate: -0.1},
+		{MaxErrorRates: map[string]float64{"timeuot": 0.01}},
+		{MaxErrorRates: map[string]float64{"timeout": 2}},
+	} {
+		if _, _, err := Soak(req, nil, nil, "", "", SLAConfig{Iterations: 1, Thresholds: thresholds}); err == nil {
+			t.Errorf("Soak() with thresholds %+v returned nil error, want an error", thresholds)
+		}
+	}
+}
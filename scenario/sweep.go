@@ -0,0 +1,139 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scenario
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// SweepConfig describes the matrix of party sizes and stay lengths searched by Sweep.
+type SweepConfig struct {
+	MinAdults, MaxAdults     int
+	MinChildren, MaxChildren int
+	// ChildAge is the age sent for every child in the party.
+	ChildAge int32
+	// Nights lists the stay lengths to search, counted from the start_date of the base request.
+	Nights []int
+	// Rules, if set, counts the rules broken by the response to each combination.
+	Rules *utils.RuleTally
+	// MaxTransportFailures, if positive, stops the sweep after that many consecutive combinations failed in
+	// transport, e.g. DefaultMaxTransportFailures. The remaining combinations are not run.
+	MaxTransportFailures int
+}
+
+// DefaultSweepConfig searches 1-8 adults with 0-4 children for stays of 1, 2, 3 and 7 nights.
+var DefaultSweepConfig = SweepConfig{
+	MinAdults:   1,
+	MaxAdults:   8,
+	MinChildren: 0,
+	MaxChildren: 4,
+	ChildAge:    8,
+	Nights:      []int{1, 2, 3, 7},
+}
+
+// SweepCase is the outcome of a single combination searched by Sweep.
+type SweepCase struct {
+	Adults    int    `json:"adults"`
+	Children  int    `json:"children"`
+	Nights    int    `json:"nights"`
+	RoomRates int    `json:"room_rates"`
+	Error     string `json:"error,omitempty"`
+}
+
+func (c SweepCase) String() string {
+	return fmt.Sprintf("adults=%d children=%d nights=%d", c.Adults, c.Children, c.Nights)
+}
+
+// Sweep searches availability for every combination in cfg, using base for the hotel, start_date and remaining
+// fields, and validates each response. The returned findings list combinations with no availability as Warnings and
+// combinations that failed as Errors; an error is returned if any combination failed. Once cfg.MaxTransportFailures
+// consecutive combinations failed in transport the remaining ones are skipped, which is an Error finding.
+func Sweep(base *pb.BookingAvailabilityRequest, conn *api.HTTPConnection, endpoint string, cfg SweepConfig) ([]SweepCase, []utils.Finding, error) {
+	layout := utils.FormatsFor(base.GetApiVersion()).DateLayout
+	start, err := time.Parse(layout, base.GetStartDate())
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid start_date %q in sweep request: %v", base.GetStartDate(), err)
+	}
+
+	var cases []SweepCase
+	var findings []utils.Finding
+	failed := 0
+	br := &breaker{threshold: cfg.MaxTransportFailures}
+	for adults := cfg.MinAdults; adults <= cfg.MaxAdults; adults++ {
+		for children := cfg.MinChildren; children <= cfg.MaxChildren; children++ {
+			for _, nights := range cfg.Nights {
+				if br.open() {
+					br.skip(cfg.Rules)
+					continue
+				}
+				req := proto.Clone(base).(*pb.BookingAvailabilityRequest)
+				req.EndDate = start.AddDate(0, 0, nights).Format(layout)
+				req.Party = &pb.Occupancy{Adults: int32(adults)}
+				for i := 0; i < children; i++ {
+					req.Party.Children = append(req.Party.Children, cfg.ChildAge)
+				}
+
+				c := SweepCase{Adults: adults, Children: children, Nights: nights}
+				validation, err := searchCase(req, conn, endpoint, &c)
+				cfg.Rules.Add(validation, err)
+				br.record(err)
+				if err != nil {
+					c.Error = err.Error()
+					failed++
+					findings = append(findings, utils.Finding{Severity: utils.Error, Field: c.String(), Message: c.Error, Code: utils.CodeSweepFailed})
+				} else if c.RoomRates == 0 {
+					findings = append(findings, utils.Finding{Severity: utils.Warning, Field: c.String(), Message: "no availability", Code: utils.CodeSweepNoAvailability})
+				}
+				cases = append(cases, c)
+			}
+		}
+	}
+	log.Printf("Sweep searched %d combination(s): %d failed, %d without availability", len(cases), failed, len(findings)-failed)
+	if f := br.findings("the sweep"); f != nil {
+		return cases, append(findings, f...), fmt.Errorf("%d of %d sweep combination(s) failed, %d not run after repeated transport failures", failed, len(cases), br.notRun)
+	}
+	if failed > 0 {
+		return cases, findings, fmt.Errorf("%d of %d sweep combination(s) failed", failed, len(cases))
+	}
+	return cases, findings, nil
+}
+
+// searchCase sends req and sets the number of room rates of c, returning the validation findings of the response.
+func searchCase(req *pb.BookingAvailabilityRequest, conn *api.HTTPConnection, endpoint string, c *SweepCase) ([]utils.Finding, error) {
+	resp, err := api.SendBookingAvailability(req, conn, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if e := resp.GetError(); e != nil {
+		return nil, fmt.Errorf("availability error %v: %s", e.GetType(), e.GetMessage())
+	}
+	findings, err := utils.ValidateBookingAvailabilityResponse(req, resp)
+	if err != nil {
+		return findings, fmt.Errorf("%w: %v", api.ErrValidation, err)
+	}
+	c.RoomRates = len(resp.GetRoomRates())
+	return findings, nil
+}
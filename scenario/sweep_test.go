@@ -0,0 +1,88 @@
+package scenario
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// newEchoServer returns the sample availability response with the party and dates of each request echoed back. Stays
+// longer than maxNights return no room rates, and parties of more than maxAdults return the wrong party.
+func newEchoServer(t *testing.T, maxNights int, maxAdults int32) (*api.HTTPConnection, *httptest.Server) {
+	availability, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req pb.BookingAvailabilityRequest
+		if err := jsonpb.Unmarshal(r.Body, &req); err != nil {
+			t.Errorf("server received invalid request: %v", err)
+		}
+		resp := proto.Clone(availability.RespPb).(*pb.BookingAvailabilityResponse)
+		resp.EndDate = req.GetEndDate()
+		resp.Party = req.GetParty()
+		if req.GetParty().GetAdults() > maxAdults {
+			resp.Party = &pb.Occupancy{Adults: maxAdults}
+		}
+		start, _ := time.Parse("2006-01-02", req.GetStartDate())
+		end, _ := time.Parse("2006-01-02", req.GetEndDate())
+		if end.Sub(start) > time.Duration(maxNights)*24*time.Hour {
+			resp.RoomRates = nil
+		}
+		m := jsonpb.Marshaler{OrigName: true}
+		if err := m.Marshal(w, resp); err != nil {
+			t.Error(err)
+		}
+	}))
+	conn, err := api.InitHTTPConnection(strings.TrimPrefix(server.URL, "http://"), "", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn, server
+}
+
+func TestSweep(t *testing.T) {
+	availability, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, server := newEchoServer(t, 2, 2)
+	defer server.Close()
+
+	cfg := SweepConfig{MinAdults: 1, MaxAdults: 3, MinChildren: 0, MaxChildren: 1, ChildAge: 8, Nights: []int{1, 3}}
+	cases, findings, err := Sweep(availability.ReqPb, conn, "", cfg)
+	if err == nil {
+		t.Error("Sweep() returned nil error, want an error for parties of 3 adults")
+	}
+	if len(cases) != 12 {
+		t.Fatalf("Sweep() returned %d cases, want 12", len(cases))
+	}
+	var errs, warnings int
+	for _, f := range findings {
+		switch f.Severity {
+		case utils.Error:
+			errs++
+			if !strings.HasPrefix(f.Field, "adults=3") {
+				t.Errorf("Sweep() returned unexpected error finding %v", f)
+			}
+		case utils.Warning:
+			warnings++
+			if !strings.HasSuffix(f.Field, "nights=3") {
+				t.Errorf("Sweep() returned unexpected warning finding %v", f)
+			}
+		}
+	}
+	if errs != 4 || warnings != 4 {
+		t.Errorf("Sweep() returned %d error(s) and %d warning(s), want 4 and 4", errs, warnings)
+	}
+}
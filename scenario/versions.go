@@ -0,0 +1,133 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scenario
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// DefaultProbeVersions are the api_version values sent by APIVersions unless others are given.
+var DefaultProbeVersions = []int32{0, 2, 999}
+
+// VersionProbe is the outcome of sending a request of one flow with one api_version.
+type VersionProbe struct {
+	Flow       string `json:"flow"`
+	APIVersion int32  `json:"api_version"`
+	// Processed is set if the partner answered as if it supported the version.
+	Processed bool `json:"processed"`
+	// Rejected is set if the partner answered with API_VERSION_UNSUPPORTED.
+	Rejected bool `json:"rejected"`
+}
+
+// VersionResult records how the partner handled each probed api_version.
+type VersionResult struct {
+	Probes []VersionProbe `json:"probes"`
+	// Supported lists the probed versions processed by the partner in any flow, in ascending order.
+	Supported []int32 `json:"supported,omitempty"`
+}
+
+// APIVersions sends copies of availReq and submitReq with each of versions as api_version, and records which the
+// partner processes and which it rejects. A version the validator does not support, see utils.KnownAPIVersion, must be
+// rejected with API_VERSION_UNSUPPORTED; processing it, or rejecting it with another error, is an Error finding.
+// Either request may be nil to leave out its flow. Each submit copy has a distinct transaction_id; note that a copy
+// the partner processes is a real booking.
+func APIVersions(availReq *pb.BookingAvailabilityRequest, submitReq *pb.BookingSubmitRequest, conn *api.HTTPConnection, availEndpoint, submitEndpoint string, versions []int32) (*VersionResult, []utils.Finding, error) {
+	if availReq == nil && submitReq == nil {
+		return nil, nil, fmt.Errorf("the api_version scenario requires an availability or submit request")
+	}
+	result := &VersionResult{}
+	var findings []utils.Finding
+	var failed []string
+	supported := map[int32]bool{}
+	record := func(p VersionProbe, errorType fmt.Stringer, err error) {
+		field := fmt.Sprintf("api_version (%s %d)", p.Flow, p.APIVersion)
+		var msg, code string
+		switch {
+		case err != nil:
+			msg, code = err.Error(), utils.CodeVersionFailed
+		case p.Rejected:
+			log.Printf("%s api_version %d was rejected with API_VERSION_UNSUPPORTED", p.Flow, p.APIVersion)
+		case !p.Processed:
+			if !utils.KnownAPIVersion(p.APIVersion) {
+				msg, code = fmt.Sprintf("rejected with %v, want API_VERSION_UNSUPPORTED", errorType), utils.CodeVersionRejected
+			}
+		default:
+			supported[p.APIVersion] = true
+			log.Printf("%s api_version %d was processed", p.Flow, p.APIVersion)
+			if !utils.KnownAPIVersion(p.APIVersion) {
+				msg, code = "the request was processed; api_version is not defined by the API and must be rejected with API_VERSION_UNSUPPORTED", utils.CodeVersionProcessed
+			}
+		}
+		result.Probes = append(result.Probes, p)
+		if msg != "" {
+			f := utils.Finding{Severity: utils.Error, Field: field, Message: msg, Code: code}
+			log.Println(f)
+			findings = append(findings, f)
+			failed = append(failed, field)
+		}
+	}
+	for _, v := range versions {
+		if availReq != nil {
+			req := proto.Clone(availReq).(*pb.BookingAvailabilityRequest)
+			req.ApiVersion = v
+			req.TransactionId = fmt.Sprintf("%s-version-%d", availReq.GetTransactionId(), v)
+			p := VersionProbe{Flow: "availability", APIVersion: v}
+			resp, err := api.SendBookingAvailability(req, conn, availEndpoint)
+			if err == nil {
+				t := resp.GetError().GetType()
+				p.Rejected = resp.GetError() != nil && t == pb.AvailabilityError_API_VERSION_UNSUPPORTED
+				p.Processed = resp.GetError() == nil
+				record(p, t, nil)
+			} else {
+				record(p, nil, err)
+			}
+		}
+		if submitReq != nil {
+			req := proto.Clone(submitReq).(*pb.BookingSubmitRequest)
+			req.ApiVersion = v
+			req.TransactionId = fmt.Sprintf("%s-version-%d", submitReq.GetTransactionId(), v)
+			p := VersionProbe{Flow: "submit", APIVersion: v}
+			resp, err := api.SendBookingSubmit(req, conn, submitEndpoint)
+			if err == nil {
+				t := resp.GetError().GetType()
+				p.Rejected = resp.GetError() != nil && t == pb.SubmitError_API_VERSION_UNSUPPORTED
+				p.Processed = resp.GetStatus() == pb.BookingSubmitResponse_SUCCESS && resp.GetError() == nil
+				record(p, t, nil)
+			} else {
+				record(p, nil, err)
+			}
+		}
+	}
+	for v := range supported {
+		result.Supported = append(result.Supported, v)
+	}
+	sort.Slice(result.Supported, func(i, j int) bool { return result.Supported[i] < result.Supported[j] })
+	if len(failed) > 0 {
+		return result, findings, fmt.Errorf("unsupported api_version values were not rejected with API_VERSION_UNSUPPORTED: %s", strings.Join(failed, ", "))
+	}
+	return result, findings, nil
+}
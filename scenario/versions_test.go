@@ -0,0 +1,96 @@
+package scenario
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestAPIVersions(t *testing.T) {
+	submit, err := utils.BookingSubmitData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	availability, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The partner supports versions 1 and 2, rejects 0 as documented, and answers availability requests of other
+	// versions with the wrong error.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var resp proto.Message
+		switch r.URL.Path {
+		case "/availability":
+			var req pb.BookingAvailabilityRequest
+			if err := jsonpb.Unmarshal(r.Body, &req); err != nil {
+				t.Error(err)
+				return
+			}
+			switch req.GetApiVersion() {
+			case 1, 2:
+				resp = availability.RespPb
+			case 0:
+				resp = &pb.BookingAvailabilityResponse{Error: &pb.AvailabilityError{Type: pb.AvailabilityError_API_VERSION_UNSUPPORTED}}
+			default:
+				resp = &pb.BookingAvailabilityResponse{Error: &pb.AvailabilityError{Type: pb.AvailabilityError_HOTEL_NOT_FOUND}}
+			}
+		case "/submit":
+			var req pb.BookingSubmitRequest
+			if err := jsonpb.Unmarshal(r.Body, &req); err != nil {
+				t.Error(err)
+				return
+			}
+			resp = submit.RespPb
+			if v := req.GetApiVersion(); v != 1 && v != 2 {
+				resp = &pb.BookingSubmitResponse{Status: pb.BookingSubmitResponse_FAILURE, Error: &pb.SubmitError{Type: pb.SubmitError_API_VERSION_UNSUPPORTED}}
+			}
+		}
+		if err := (&jsonpb.Marshaler{OrigName: true}).Marshal(w, resp); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+	conn, err := api.InitHTTPConnection(strings.TrimPrefix(server.URL, "http://"), "", "", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, findings, err := APIVersions(availability.ReqPb, submit.ReqPb, conn, "/availability", "/submit", []int32{0, 1, 2, 999})
+	want := &VersionResult{
+		Probes: []VersionProbe{
+			{Flow: "availability", APIVersion: 0, Rejected: true},
+			{Flow: "submit", APIVersion: 0, Rejected: true},
+			{Flow: "availability", APIVersion: 1, Processed: true},
+			{Flow: "submit", APIVersion: 1, Processed: true},
+			{Flow: "availability", APIVersion: 2, Processed: true},
+			{Flow: "submit", APIVersion: 2, Processed: true},
+			{Flow: "availability", APIVersion: 999},
+			{Flow: "submit", APIVersion: 999, Rejected: true},
+		},
+		Supported: []int32{1, 2},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("APIVersions() result did not match (-got +want)\n%s", diff)
+	}
+	var fields []string
+	for _, f := range findings {
+		fields = append(fields, f.Field)
+	}
+	wantFields := []string{"api_version (availability 2)", "api_version (submit 2)", "api_version (availability 999)"}
+	if diff := cmp.Diff(fields, wantFields); diff != "" {
+		t.Errorf("APIVersions() findings did not match (-got +want)\n%s", diff)
+	}
+	if err == nil {
+		t.Error("APIVersions() returned nil error, want the processed versions reported")
+	}
+}
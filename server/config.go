@@ -0,0 +1,197 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+// Profile tunes the results of validation, e.g. for a partner whose known issues are being worked on. It is selected
+// with the profile query parameter of /validate/availability and /validate/submit, or the profile field of /run.
+type Profile struct {
+	// IgnoreFields drops the findings of fields starting with any of these, e.g. "room_rates > line_items".
+	IgnoreFields []string `json:"ignore_fields,omitempty"`
+	// IgnoreCodes drops the findings of these codes, e.g. "JSON_001", see utils.Rules.
+	IgnoreCodes []string `json:"ignore_codes,omitempty"`
+	// Severities overrides the severity of the findings of fields starting with a key, the longest key winning.
+	Severities map[string]utils.Severity `json:"severities,omitempty"`
+	// IgnoreErrors turns failures whose error contains any of these into a Warning finding.
+	IgnoreErrors []string `json:"ignore_errors,omitempty"`
+}
+
+// Config is the configuration file of the server, holding its validation profiles by name.
+type Config struct {
+	Profiles map[string]Profile `json:"profiles"`
+}
+
+// LoadConfig reads a json encoded Config from path.
+func LoadConfig(path string) (Config, error) {
+	var c Config
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, fmt.Errorf("unable to parse config %s: %v", path, err)
+	}
+	return c, nil
+}
+
+// SetConfig replaces the configuration of s. Requests already being validated keep the profile they started with.
+func (s *Server) SetConfig(c Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config = c
+}
+
+// WatchConfig loads the config at path and reloads it whenever its modification time changes, checking every
+// interval until stop is closed. An invalid config is logged and the previous one kept, so a mistake made while
+// editing the file does not interrupt the server. The interval must be positive.
+func (s *Server) WatchConfig(path string, interval time.Duration, stop <-chan struct{}) error {
+	if interval <= 0 {
+		return fmt.Errorf("config poll interval %v is not positive", interval)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	c, err := LoadConfig(path)
+	if err != nil {
+		return err
+	}
+	s.SetConfig(c)
+	modified := info.ModTime()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			info, err := os.Stat(path)
+			if err != nil || info.ModTime().Equal(modified) {
+				continue
+			}
+			modified = info.ModTime()
+			c, err := LoadConfig(path)
+			if err != nil {
+				log.Printf("Keeping previous config: %v", err)
+				continue
+			}
+			s.SetConfig(c)
+			log.Printf("Reloaded config %s with %d profile(s)", path, len(c.Profiles))
+		}
+	}()
+	return nil
+}
+
+// Profile returns the profile called name, or an empty profile if name is empty.
+func (c Config) Profile(name string) (Profile, error) {
+	if name == "" {
+		return Profile{}, nil
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		return p, fmt.Errorf("unknown profile %q", name)
+	}
+	return p, nil
+}
+
+// profile returns the profile of the current config called name, see Config.Profile.
+func (s *Server) profile(name string) (Profile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.config.Profile(name)
+}
+
+// ActiveRules returns the rules whose findings p reports, i.e. those whose code it does not ignore. Findings of an
+// active rule may still be dropped by IgnoreFields or have their severity overridden for some fields.
+func (p Profile) ActiveRules(rules []utils.Rule) []utils.Rule {
+	var active []utils.Rule
+	for _, r := range rules {
+		if !contains(p.IgnoreCodes, r.Code) {
+			active = append(active, r)
+		}
+	}
+	return active
+}
+
+// requestProfile returns the profile selected by the profile query parameter of r, writing an error if it is unknown.
+func (s *Server) requestProfile(w http.ResponseWriter, r *http.Request) (Profile, bool) {
+	p, err := s.profile(r.URL.Query().Get("profile"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return p, false
+	}
+	return p, true
+}
+
+// apply returns the findings and error of a flow tuned by p.
+func (p Profile) apply(findings []utils.Finding, err error) ([]utils.Finding, error) {
+	var tuned []utils.Finding
+	for _, f := range findings {
+		if hasPrefix(f.Field, p.IgnoreFields) || contains(p.IgnoreCodes, f.Code) {
+			continue
+		}
+		longest := -1
+		for prefix, severity := range p.Severities {
+			if strings.HasPrefix(f.Field, prefix) && len(prefix) > longest {
+				longest = len(prefix)
+				f.Severity = severity
+			}
+		}
+		tuned = append(tuned, f)
+	}
+	if err != nil {
+		for _, e := range p.IgnoreErrors {
+			if strings.Contains(err.Error(), e) {
+				tuned = append(tuned, utils.Finding{Severity: utils.Warning, Field: "profile", Message: fmt.Sprintf("ignored failure: %v", err), Code: utils.CodeProfileIgnored})
+				return tuned, nil
+			}
+		}
+	}
+	return tuned, err
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
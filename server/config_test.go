@@ -0,0 +1,118 @@
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/hotel-booking-api-validator/report"
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+func TestWatchConfig(t *testing.T) {
+	data, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := ValidateRequest{
+		Request:  json.RawMessage(data.Req),
+		Response: json.RawMessage(strings.Replace(data.Resp, `"hotel_id": "123"`, `"hotel_id": "xxx"`, 1)),
+	}
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := ioutil.WriteFile(path, []byte(`{"profiles": {"strict": {}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := New()
+	stop := make(chan struct{})
+	defer close(stop)
+	if err := s.WatchConfig(path, 0, stop); err == nil {
+		t.Error("WatchConfig() with a zero interval returned nil, want an error")
+	}
+	if err := s.WatchConfig(path, 10*time.Millisecond, stop); err != nil {
+		t.Fatal(err)
+	}
+	if w := post(t, s, "/validate/availability?profile=lenient", body); w.Code != http.StatusBadRequest {
+		t.Fatalf("POST /validate/availability with unknown profile returned %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	// An invalid config is ignored, then a valid one picked up without restarting.
+	modified := time.Now().Add(time.Hour)
+	for _, config := range []string{`{"profiles": `, `{"profiles": {"lenient": {"ignore_errors": ["hotel_id"]}}}`} {
+		if err := ioutil.WriteFile(path, []byte(config), 0644); err != nil {
+			t.Fatal(err)
+		}
+		modified = modified.Add(time.Second)
+		if err := os.Chtimes(path, modified, modified); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if _, err := s.profile("lenient"); err == nil {
+			break
+		}
+	}
+
+	w := post(t, s, "/validate/availability?profile=lenient", body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST /validate/availability returned %d: %s", w.Code, w.Body)
+	}
+	var got report.Result
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Success {
+		t.Errorf("POST /validate/availability with reloaded profile got %+v, want success", got)
+	}
+}
+
+func TestProfileApply(t *testing.T) {
+	p := Profile{
+		IgnoreFields: []string{"room_rates > line_items"},
+		IgnoreCodes:  []string{utils.CodeJSONKeyOrder},
+		Severities:   map[string]utils.Severity{"rate_plans": utils.Warning, "rate_plans > refundable": utils.Critical},
+	}
+	findings := []utils.Finding{
+		{Severity: utils.Error, Field: "room_rates > line_items[0]"},
+		{Severity: utils.Error, Field: "rate_plans[0]"},
+		{Severity: utils.Warning, Field: "rate_plans > refundable"},
+		{Severity: utils.Warning, Field: "hotel_id", Code: utils.CodeJSONKeyOrder},
+	}
+	got, err := p.apply(findings, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []utils.Finding{
+		{Severity: utils.Warning, Field: "rate_plans[0]"},
+		{Severity: utils.Critical, Field: "rate_plans > refundable"},
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("apply() = %+v, want %+v", got, want)
+	}
+}
+
+func TestProfileActiveRules(t *testing.T) {
+	c := Config{Profiles: map[string]Profile{"lenient": {IgnoreCodes: []string{utils.CodeJSONKeyOrder}}}}
+	p, err := c.Profile("lenient")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := p.ActiveRules(utils.Rules)
+	if len(got) != len(utils.Rules)-1 {
+		t.Errorf("ActiveRules() returned %d rules, want %d", len(got), len(utils.Rules)-1)
+	}
+	for _, r := range got {
+		if r.Code == utils.CodeJSONKeyOrder {
+			t.Errorf("ActiveRules() returned ignored rule %s", r.Code)
+		}
+	}
+	if _, err := c.Profile("strict"); err == nil {
+		t.Error("Profile(strict) returned no error, want an unknown profile error")
+	}
+}
@@ -0,0 +1,182 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/hotel-booking-api-validator/report"
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+// maxRuns is the number of runs kept in memory for the dashboard. Older runs are dropped first.
+const maxRuns = 100
+
+// runRecord is a run made through POST /run, as shown on the dashboard.
+type runRecord struct {
+	Started    time.Time
+	ServerAddr string
+	Report     *report.Report
+}
+
+// ruleStat is the pass rate of a single flow, or of a single field across the runs of a flow.
+type ruleStat struct {
+	Rule   string
+	Runs   int
+	Passed int
+}
+
+// PassRate returns the percentage of runs in which the rule passed.
+func (r ruleStat) PassRate() float64 {
+	if r.Runs == 0 {
+		return 0
+	}
+	return 100 * float64(r.Passed) / float64(r.Runs)
+}
+
+func (s *Server) addRun(r runRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runs = append(s.runs, r)
+	if len(s.runs) > maxRuns {
+		s.runs = s.runs[len(s.runs)-maxRuns:]
+	}
+}
+
+// history returns the recorded runs, newest first.
+func (s *Server) history() []runRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	runs := make([]runRecord, len(s.runs))
+	for i, r := range s.runs {
+		runs[len(s.runs)-1-i] = r
+	}
+	return runs
+}
+
+// ruleStats computes the pass rate of every flow, and of every field with an Error or Critical finding, in runs.
+func ruleStats(runs []runRecord) []ruleStat {
+	flows := map[string]*ruleStat{}
+	fields := map[string]map[string]bool{}
+	failures := map[string]int{}
+	for _, r := range runs {
+		for _, res := range r.Report.Results {
+			st, ok := flows[res.Flow]
+			if !ok {
+				st = &ruleStat{Rule: res.Flow}
+				flows[res.Flow] = st
+				fields[res.Flow] = map[string]bool{}
+			}
+			st.Runs++
+			if res.Success {
+				st.Passed++
+			}
+			failed := map[string]bool{}
+			for _, f := range res.Findings {
+				if f.Severity != utils.Warning && !failed[f.Field] {
+					failed[f.Field] = true
+					fields[res.Flow][f.Field] = true
+					failures[res.Flow+"\x00"+f.Field]++
+				}
+			}
+		}
+	}
+
+	var stats []ruleStat
+	for flow, st := range flows {
+		stats = append(stats, *st)
+		for field := range fields[flow] {
+			stats = append(stats, ruleStat{
+				Rule:   fmt.Sprintf("%s: %s", flow, field),
+				Runs:   st.Runs,
+				Passed: st.Runs - failures[flow+"\x00"+field],
+			})
+		}
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Rule < stats[j].Rule })
+	return stats
+}
+
+var dashboardTemplates = template.Must(template.New("index").Funcs(template.FuncMap{
+	"timestamp": func(t time.Time) string { return t.UTC().Format(time.RFC3339) },
+}).Parse(`<!DOCTYPE html>
+<html><head><title>Hotel Booking API Validator</title></head>
+<body>
+<h1>Hotel Booking API Validator</h1>
+<h2>Pass rates</h2>
+<table border="1">
+<tr><th>Rule</th><th>Passed</th><th>Runs</th><th>Pass rate</th></tr>
+{{range .Stats}}<tr><td>{{.Rule}}</td><td>{{.Passed}}</td><td>{{.Runs}}</td><td>{{printf "%.1f" .PassRate}}%</td></tr>
+{{end}}</table>
+<h2>Runs</h2>
+<table border="1">
+<tr><th>Started</th><th>Run ID</th><th>Server</th><th>Results</th></tr>
+{{range .Runs}}<tr><td>{{timestamp .Started}}</td><td><a href="/runs/{{.Report.RunID}}">{{.Report.RunID}}</a></td><td>{{.ServerAddr}}</td>
+<td>{{range .Report.Results}}{{.Flow}}: {{if .Success}}passed{{else}}failed{{end}}<br>{{end}}</td></tr>
+{{end}}</table>
+</body></html>
+`))
+
+func init() {
+	template.Must(dashboardTemplates.New("run").Parse(`<!DOCTYPE html>
+<html><head><title>Run {{.Report.RunID}}</title></head>
+<body>
+<p><a href="/">All runs</a></p>
+<h1>Run {{.Report.RunID}}</h1>
+<p>Started {{timestamp .Started}} against {{.ServerAddr}} with validator {{.Report.Validator}}</p>
+{{range .Report.Results}}<h2>{{.Flow}}: {{if .Success}}passed{{else}}failed{{end}}</h2>
+{{if .Error}}<p>{{.Error}}</p>{{end}}
+{{range .Findings}}<h3>{{.Severity}}{{if .Code}} {{.Code}}{{end}}: {{.Field}}</h3>
+<pre>{{.Message}}</pre>
+{{end}}{{end}}
+</body></html>
+`))
+}
+
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	runs := s.history()
+	data := struct {
+		Runs  []runRecord
+		Stats []ruleStat
+	}{runs, ruleStats(runs)}
+	if err := dashboardTemplates.ExecuteTemplate(w, "index", data); err != nil {
+		log.Printf("Failed to render dashboard: %v", err)
+	}
+}
+
+func (s *Server) handleRunDetails(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/runs/")
+	for _, run := range s.history() {
+		if run.Report.RunID == id {
+			if err := dashboardTemplates.ExecuteTemplate(w, "run", run); err != nil {
+				log.Printf("Failed to render run %s: %v", id, err)
+			}
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/google/hotel-booking-api-validator/report"
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+func newTestRun(id string, findings []utils.Finding, err error) runRecord {
+	rep := report.New(id)
+	rep.Add("BookingSubmit", "/v1/BookingSubmit", findings, err)
+	return runRecord{Started: time.Unix(0, 0), ServerAddr: "localhost:8080", Report: rep}
+}
+
+func TestRuleStats(t *testing.T) {
+	mismatch := []utils.Finding{{Severity: utils.Error, Field: "hotel_id", Message: "did not match"}}
+	runs := []runRecord{
+		newTestRun("1", nil, nil),
+		newTestRun("2", mismatch, errors.New("Validation error: echo field(s) did not match request: hotel_id")),
+		newTestRun("3", []utils.Finding{{Severity: utils.Warning, Field: "customer"}}, nil),
+		newTestRun("4", nil, nil),
+	}
+	want := []ruleStat{
+		{Rule: "BookingSubmit", Runs: 4, Passed: 3},
+		{Rule: "BookingSubmit: hotel_id", Runs: 4, Passed: 3},
+	}
+	if diff := cmp.Diff(ruleStats(runs), want); diff != "" {
+		t.Errorf("ruleStats() did not match (-got +want)\n%s", diff)
+	}
+}
+
+func TestDashboard(t *testing.T) {
+	s := New()
+	s.addRun(newTestRun("run-1", []utils.Finding{{Severity: utils.Error, Field: "hotel_id", Message: "hotel_id did not match"}}, errors.New("Validation error")))
+
+	for path, want := range map[string]string{
+		"/":            "run-1",
+		"/runs/run-1":  "hotel_id did not match",
+		"/runs/absent": "404 page not found",
+	} {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("GET %s returned %q, want it to contain %q", path, w.Body, want)
+		}
+	}
+}
@@ -0,0 +1,320 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package server exposes the validator as a long-running HTTP service.
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/report"
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// ValidateRequest is the body of POST /validate/availability and POST /validate/submit. Request and Response hold
+// the json encoded BookingService messages to validate against each other.
+type ValidateRequest struct {
+	Request  json.RawMessage `json:"request"`
+	Response json.RawMessage `json:"response"`
+}
+
+// RunConfig is the body of POST /run. It describes a validation run against a partner server, mirroring the flags of
+// the command line client. ServerAddr must be one of the targets allowed by SetAllowedTargets, and CACert holds the
+// PEM encoded root certificates of the server, rather than the path of a file, as the caller is not on the host of the
+// validator. QueryParams maps an endpoint, or "" for all endpoints, to the query parameters appended to its requests.
+type RunConfig struct {
+	ServerAddr           string                `json:"server_addr"`
+	CACert               string                `json:"ca_cert"`
+	FullServerName       string                `json:"full_server_name"`
+	HostHeader           string                `json:"host_header"`
+	UseSystemRoots       bool                  `json:"use_system_roots"`
+	AvailabilityEndpoint string                `json:"availability_endpoint"`
+	SubmitEndpoint       string                `json:"submit_endpoint"`
+	AvailabilityRequest  json.RawMessage       `json:"availability_request"`
+	SubmitRequest        json.RawMessage       `json:"submit_request"`
+	Profile              string                `json:"profile"`
+	QueryParams          map[string]url.Values `json:"query_params"`
+}
+
+// Server handles validation requests over HTTP and keeps the history of runs for the dashboard.
+type Server struct {
+	mux     *http.ServeMux
+	token   string
+	targets []string
+	// mu guards runs and config.
+	mu     sync.Mutex
+	runs   []runRecord
+	config Config
+}
+
+// New returns a Server with all routes registered.
+func New() *Server {
+	s := &Server{mux: http.NewServeMux()}
+	s.mux.HandleFunc("/validate/availability", s.handleValidateAvailability)
+	s.mux.HandleFunc("/validate/submit", s.handleValidateSubmit)
+	s.mux.HandleFunc("/run", s.handleRun)
+	s.mux.HandleFunc("/runs/", s.handleRunDetails)
+	s.mux.HandleFunc("/", s.handleDashboard)
+	return s
+}
+
+// SetToken requires every request to carry token in an "Authorization: Bearer" header. No token is required if it is
+// empty, which is only safe on a loopback address.
+func (s *Server) SetToken(token string) {
+	s.token = token
+}
+
+// SetAllowedTargets sets the partner servers POST /run may send requests to, each a host:port, or a host to allow any
+// of its ports. POST /run is refused while no target is allowed, as it sends real bookings.
+func (s *Server) SetAllowedTargets(targets []string) {
+	s.targets = targets
+}
+
+// allowedTarget reports whether addr, a host:port, is one of the allowed targets.
+func (s *Server) allowedTarget(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	for _, t := range s.targets {
+		if strings.EqualFold(t, addr) || strings.EqualFold(t, host) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.token != "" {
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("missing or invalid bearer token"))
+			return
+		}
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// IsLoopback reports whether the listen address addr, a host:port, only accepts connections from the local host.
+func IsLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Failed to write response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// present reports whether an embedded message was provided in a request body.
+func present(raw json.RawMessage) bool {
+	return len(raw) > 0 && string(raw) != "null"
+}
+
+// unmarshal parses a json encoded message embedded in a request body.
+func unmarshal(field string, raw json.RawMessage, m proto.Message) error {
+	if !present(raw) {
+		return fmt.Errorf("missing %s", field)
+	}
+	if err := jsonpb.UnmarshalString(string(raw), m); err != nil {
+		return fmt.Errorf("unable to parse %s as json: %v", field, err)
+	}
+	return nil
+}
+
+func decodeValidateRequest(w http.ResponseWriter, r *http.Request, req, resp proto.Message) bool {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("%s requires POST", r.URL.Path))
+		return false
+	}
+	var body ValidateRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unable to parse body: %v", err))
+		return false
+	}
+	if err := unmarshal("request", body.Request, req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return false
+	}
+	if err := unmarshal("response", body.Response, resp); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return false
+	}
+	return true
+}
+
+func writeResult(w http.ResponseWriter, p Profile, flow string, findings []utils.Finding, err error) {
+	if err != nil {
+		err = fmt.Errorf("%w: %v", api.ErrValidation, err)
+	}
+	findings, err = p.apply(findings, err)
+	res := report.New("")
+	res.Add(flow, "", findings, err)
+	writeJSON(w, http.StatusOK, res.Results[0])
+}
+
+func (s *Server) handleValidateAvailability(w http.ResponseWriter, r *http.Request) {
+	var req pb.BookingAvailabilityRequest
+	var resp pb.BookingAvailabilityResponse
+	if !decodeValidateRequest(w, r, &req, &resp) {
+		return
+	}
+	p, ok := s.requestProfile(w, r)
+	if !ok {
+		return
+	}
+	findings, err := utils.ValidateBookingAvailabilityResponse(&req, &resp)
+	findings = append(utils.LintAvailabilityError(resp.GetError()), findings...)
+	writeResult(w, p, "BookingAvailability", findings, err)
+}
+
+func (s *Server) handleValidateSubmit(w http.ResponseWriter, r *http.Request) {
+	var req pb.BookingSubmitRequest
+	var resp pb.BookingSubmitResponse
+	if !decodeValidateRequest(w, r, &req, &resp) {
+		return
+	}
+	p, ok := s.requestProfile(w, r)
+	if !ok {
+		return
+	}
+	findings, err := utils.ValidateBookingSubmitResponse(&req, &resp)
+	findings = append(utils.LintSubmitError(resp.GetError()), findings...)
+	writeResult(w, p, "BookingSubmit", findings, err)
+}
+
+func (s *Server) handleRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("%s requires POST", r.URL.Path))
+		return
+	}
+	cfg := RunConfig{
+		AvailabilityEndpoint: "/v1/BookingAvailability",
+		SubmitEndpoint:       "/v1/BookingSubmit",
+	}
+	if err := json.NewDecoder(r.Body).Decode(&cfg); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unable to parse body: %v", err))
+		return
+	}
+	if cfg.ServerAddr == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("missing server_addr"))
+		return
+	}
+	if !s.allowedTarget(cfg.ServerAddr) {
+		writeError(w, http.StatusForbidden, fmt.Errorf("server_addr %s is not an allowed target of this server", cfg.ServerAddr))
+		return
+	}
+	if !present(cfg.AvailabilityRequest) && !present(cfg.SubmitRequest) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("you must provide availability_request or submit_request"))
+		return
+	}
+	p, err := s.profile(cfg.Profile)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	var availReq pb.BookingAvailabilityRequest
+	if present(cfg.AvailabilityRequest) {
+		if err := unmarshal("availability_request", cfg.AvailabilityRequest, &availReq); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	var submitReq pb.BookingSubmitRequest
+	if present(cfg.SubmitRequest) {
+		if err := unmarshal("submit_request", cfg.SubmitRequest, &submitReq); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	runID, err := utils.NewRunID()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	conn, err := api.InitHTTPConnection(cfg.ServerAddr, "", "", cfg.FullServerName, runID)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("failed to init http connection: %v", err))
+		return
+	}
+	conn.SetHostHeader(cfg.HostHeader)
+	for endpoint, params := range cfg.QueryParams {
+		conn.SetQueryParameters(endpoint, params)
+	}
+	if cfg.CACert != "" || cfg.UseSystemRoots {
+		if err := conn.TrustRoots([]byte(cfg.CACert), cfg.FullServerName, cfg.UseSystemRoots); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("failed to load root certificates: %v", err))
+			return
+		}
+	}
+
+	log.Printf("Starting run %s against %s", runID, cfg.ServerAddr)
+	started := time.Now()
+	rep := report.New(runID)
+	rep.ServerAddr = cfg.ServerAddr
+	if present(cfg.AvailabilityRequest) {
+		findings, err := p.apply(api.BookingAvailability(&availReq, conn, cfg.AvailabilityEndpoint))
+		rep.Add("BookingAvailability", cfg.AvailabilityEndpoint, findings, err)
+	}
+	if present(cfg.SubmitRequest) {
+		findings, err := p.apply(api.BookingSubmit(&submitReq, conn, cfg.SubmitEndpoint))
+		rep.Add("BookingSubmit", cfg.SubmitEndpoint, findings, err)
+	}
+	rep.TLS = conn.CertificateReport()
+	rep.Resumption = conn.ResumptionReport()
+	rep.KeepAlive = conn.KeepAliveReport()
+	rep.Redirects = conn.RedirectReport()
+	if findings, err := p.apply(rep.Redirects.Findings(), nil); len(findings) > 0 {
+		rep.Add("Redirects", "", findings, err)
+	}
+	rep.Compression = conn.CompressionReport()
+	rep.Presence = conn.FieldPresence()
+	rep.Scorecard = rep.Score()
+	log.Printf("Finished run %s against %s", runID, cfg.ServerAddr)
+	s.addRun(runRecord{Started: started, ServerAddr: cfg.ServerAddr, Report: rep})
+	writeJSON(w, http.StatusOK, rep)
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/hotel-booking-api-validator/report"
+	"github.com/google/hotel-booking-api-validator/utils"
+)
+
+func post(t *testing.T, s *Server, path string, body interface{}) *httptest.ResponseRecorder {
+	b, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(b)))
+	return w
+}
+
+func TestValidateAvailability(t *testing.T) {
+	data, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name        string
+		response    string
+		wantSuccess bool
+	}{
+		{name: "valid response", response: data.Resp, wantSuccess: true},
+		{name: "wrong hotel_id", response: strings.Replace(data.Resp, `"hotel_id": "123"`, `"hotel_id": "xxx"`, 1)},
+	}
+	for _, tc := range cases {
+		w := post(t, New(), "/validate/availability", ValidateRequest{Request: json.RawMessage(data.Req), Response: json.RawMessage(tc.response)})
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: POST /validate/availability returned %d: %s", tc.name, w.Code, w.Body)
+		}
+		var got report.Result
+		if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Success != tc.wantSuccess {
+			t.Errorf("%s: POST /validate/availability got %+v, want success %v", tc.name, got, tc.wantSuccess)
+		}
+	}
+}
+
+func TestValidateSubmitBadRequest(t *testing.T) {
+	w := post(t, New(), "/validate/submit", ValidateRequest{Request: json.RawMessage(`{"hotel_id": "123"}`)})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("POST /validate/submit without response returned %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestRun(t *testing.T) {
+	data, err := utils.BookingSubmitData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	partner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, data.Resp)
+	}))
+	defer partner.Close()
+
+	s := New()
+	addr := strings.TrimPrefix(partner.URL, "http://")
+	cfg := RunConfig{ServerAddr: addr, SubmitRequest: json.RawMessage(data.Req)}
+	if w := post(t, s, "/run", cfg); w.Code != http.StatusForbidden {
+		t.Errorf("POST /run to a target not allowed returned %d, want %d", w.Code, http.StatusForbidden)
+	}
+	s.SetAllowedTargets([]string{addr})
+	w := post(t, s, "/run", cfg)
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST /run returned %d: %s", w.Code, w.Body)
+	}
+	var got report.Report
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.RunID == "" || len(got.Results) != 1 || !got.Results[0].Success {
+		t.Errorf("POST /run got %+v, want a successful BookingSubmit result", got)
+	}
+}
+
+func TestToken(t *testing.T) {
+	s := New()
+	s.SetToken("secret")
+	cases := []struct {
+		header string
+		want   int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Bearer wrong", http.StatusUnauthorized},
+		{"Bearer secret", http.StatusOK},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tc.header != "" {
+			r.Header.Set("Authorization", tc.header)
+		}
+		s.ServeHTTP(w, r)
+		if w.Code != tc.want {
+			t.Errorf("GET / with Authorization %q returned %d, want %d", tc.header, w.Code, tc.want)
+		}
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"localhost:8090": true,
+		"127.0.0.1:8090": true,
+		"[::1]:8090":     true,
+		":8090":          false,
+		"0.0.0.0:8090":   false,
+		"10.0.0.1:8090":  false,
+	} {
+		if got := IsLoopback(addr); got != want {
+			t.Errorf("IsLoopback(%s) = %v, want %v", addr, got, want)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+
+	"github.com/google/hotel-booking-api-validator/api"
+	"github.com/google/hotel-booking-api-validator/report"
+	"github.com/google/hotel-booking-api-validator/utils"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestRunCredentialsCheckSendsLoadedRequest(t *testing.T) {
+	data, err := utils.BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	reqFile := filepath.Join(dir, "request.json")
+	credFile := filepath.Join(dir, "credentials")
+	if err := os.WriteFile(reqFile, []byte(data.Req), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(credFile, []byte("partner:secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	saved := *availabilityRequest
+	defer func() { *availabilityRequest = saved }()
+	*availabilityRequest = reqFile
+
+	var mu sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		if user, _, _ := r.BasicAuth(); user != "partner" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(data.Resp))
+	}))
+	defer server.Close()
+	conn, err := api.InitHTTPConnection(strings.TrimPrefix(server.URL, "http://"), credFile, "", "", "run")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	availReq, err := loadAvailabilityRequest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	runCredentialsCheck(report.New("run"), availReq, conn)
+
+	if len(bodies) < 2 {
+		t.Fatalf("got %d requests, want the valid and invalid credentials probes", len(bodies))
+	}
+	for i, b := range bodies {
+		got := &pb.BookingAvailabilityRequest{}
+		if err := jsonpb.UnmarshalString(b, got); err != nil {
+			t.Fatalf("probe %d: %v", i, err)
+		}
+		if got.GetHotelId() != data.ReqPb.GetHotelId() || !proto.Equal(got.GetParty(), data.ReqPb.GetParty()) {
+			t.Errorf("probe %d sent %v, want the loaded request %v", i, got, data.ReqPb)
+		}
+	}
+}
+
+func TestRunLoadInvalidConfig(t *testing.T) {
+	dir := t.TempDir()
+	histogram := filepath.Join(dir, "load.hlog")
+	savedStart, savedHistogram := *loadStartQPS, *loadHistogram
+	defer func() { *loadStartQPS, *loadHistogram = savedStart, savedHistogram }()
+	*loadStartQPS = 0
+	*loadHistogram = histogram
+
+	rep := report.New("run")
+	runLoad(rep, &pb.BookingAvailabilityRequest{}, &api.HTTPConnection{})
+
+	if _, err := os.Stat(histogram); !os.IsNotExist(err) {
+		t.Errorf("histogram file written for an invalid load config, stat error %v", err)
+	}
+}
+
+func TestSubcommandFlagsFromEnv(t *testing.T) {
+	t.Setenv("FLAG_JSON", "true")
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := os.Stdout
+	defer func() { os.Stdout = saved }()
+	os.Stdout = w
+	read := make(chan []byte)
+	go func() {
+		out, _ := ioutil.ReadAll(r)
+		read <- out
+	}()
+	runRules(nil)
+	w.Close()
+	out := <-read
+
+	var rules []utils.Rule
+	if err := json.Unmarshal(out, &rules); err != nil {
+		t.Fatalf("rules with FLAG_JSON=true printed %q, want a json list: %v", out, err)
+	}
+	if len(rules) != len(utils.Rules) {
+		t.Errorf("rules printed %d rules, want %d", len(rules), len(utils.Rules))
+	}
+}
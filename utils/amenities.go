@@ -0,0 +1,151 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// basicAmenity is an inclusion of basic_amenities along with the English phrases that describe it in free text.
+// A phrase matching excluded takes precedence over one matching included in the same text, e.g. "no breakfast
+// included". mealPlan matches the names of meal plans excluding it, e.g. "room only", which only say so in the text
+// of a rate plan; room type prose such as "room only accessible by stairs" is not matched against it.
+type basicAmenity struct {
+	field    string
+	get      func(*pb.BasicAmenities) bool
+	included *regexp.Regexp
+	excluded *regexp.Regexp
+	mealPlan *regexp.Regexp
+}
+
+var basicAmenities = []basicAmenity{
+	{
+		"free_breakfast", (*pb.BasicAmenities).GetFreeBreakfast,
+		regexp.MustCompile(`\b(free|complimentary|includes?|including|with) breakfast\b|\bbreakfast (is )?(included|inclusive)\b|\bbed (and|&) breakfast\b`),
+		regexp.MustCompile(`\b(no|without|excluding) breakfast\b|\bbreakfast (is )?not included\b`),
+		regexp.MustCompile(`\broom only\b|\bno meals?\b`),
+	},
+	{
+		"free_wifi", (*pb.BasicAmenities).GetFreeWifi,
+		regexp.MustCompile(`\b(free|complimentary) (wi-?fi|internet)\b|\b(wi-?fi|internet) (is )?(free|included)\b`),
+		regexp.MustCompile(`\b(no|paid) (wi-?fi|internet)\b|\b(wi-?fi|internet) (is )?(not included|not available|extra)\b`),
+		nil,
+	},
+	{
+		"free_parking", (*pb.BasicAmenities).GetFreeParking,
+		regexp.MustCompile(`\b(free|complimentary) parking\b|\bparking (is )?(free|included)\b`),
+		regexp.MustCompile(`\b(no|paid) parking\b|\bparking (is )?(not included|not available|extra)\b`),
+		nil,
+	},
+}
+
+// excludedBy returns the phrase of t excluding b, or "". Meal plan names are only matched if ratePlan is set.
+func (b basicAmenity) excludedBy(t string, ratePlan bool) string {
+	if m := b.excluded.FindString(t); m != "" {
+		return m
+	}
+	if ratePlan && b.mealPlan != nil {
+		return b.mealPlan.FindString(t)
+	}
+	return ""
+}
+
+// amenityTexts returns the free text displayed for a room type or rate plan.
+func amenityTexts(name, description *pb.DisplayString, policies []*pb.DisplayString) []string {
+	texts := []string{name.GetText(), description.GetText()}
+	for _, p := range policies {
+		texts = append(texts, p.GetText())
+	}
+	return texts
+}
+
+// checkBasicAmenities checks the basic_amenities at prefix, e.g. "rate_plans[0]", against the free texts of the
+// same room type or, if ratePlan is set, rate plan. An inclusion only described in text, which is not shown as an
+// amenity, is a Warning finding. A text contradicting a set inclusion, or texts contradicting each other, are an
+// Error finding, and a field is returned for each.
+func checkBasicAmenities(prefix string, a *pb.BasicAmenities, texts []string, ratePlan bool) ([]Finding, []string) {
+	var findings []Finding
+	var conflicts []string
+	for _, b := range basicAmenities {
+		field := prefix + " > basic_amenities > " + b.field
+		var included, excluded string
+		for _, t := range texts {
+			t = strings.ToLower(t)
+			if m := b.excludedBy(t, ratePlan); m != "" {
+				if excluded == "" {
+					excluded = m
+				}
+			} else if m := b.included.FindString(t); m != "" && included == "" {
+				included = m
+			}
+		}
+		var f Finding
+		switch {
+		case b.get(a) && excluded != "":
+			f = Finding{Error, field, fmt.Sprintf("is set, but the text says %q", excluded), CodeAmenityConflict}
+		case included != "" && excluded != "":
+			f = Finding{Error, field, fmt.Sprintf("the texts say both %q and %q", included, excluded), CodeAmenityConflict}
+		case !b.get(a) && included != "":
+			f = Finding{Warning, field, fmt.Sprintf("is not set, but the text says %q; set it so the amenity is displayed", included), CodeAmenityFreeText}
+		default:
+			continue
+		}
+		if f.Severity == Error {
+			conflicts = append(conflicts, field)
+		}
+		log.Println(f)
+		findings = append(findings, f)
+	}
+	return findings, conflicts
+}
+
+// validateAmenities checks the amenities of every room type and rate plan of resp. Room type amenities must be
+// documented RoomAmenityType values other than UNKNOWN_ROOM_AMENITY_TYPE, and basic_amenities must agree with the
+// free text of the room type or rate plan, see checkBasicAmenities. An error is returned for conflicting amenities.
+func validateAmenities(resp *pb.BookingAvailabilityResponse) ([]Finding, error) {
+	var findings []Finding
+	var conflicts []string
+	for i, r := range resp.GetRoomTypes() {
+		prefix := fmt.Sprintf("room_types[%d]", i)
+		for j, a := range r.GetAmenities() {
+			msg := fmt.Sprintf("%d is not a documented RoomAmenityType and is not displayed", a)
+			if a == pb.RoomAmenityType_UNKNOWN_ROOM_AMENITY_TYPE {
+				msg = "UNKNOWN_ROOM_AMENITY_TYPE is not displayed, leave out amenities without a documented type"
+			} else if _, ok := pb.RoomAmenityType_name[int32(a)]; ok {
+				continue
+			}
+			f := Finding{Warning, fmt.Sprintf("%s > amenities[%d]", prefix, j), msg, CodeAmenityUnknown}
+			log.Println(f)
+			findings = append(findings, f)
+		}
+		f, c := checkBasicAmenities(prefix, r.GetBasicAmenities(), amenityTexts(r.GetName(), r.GetDescription(), r.GetUnstructuredPolicies()), false)
+		findings, conflicts = append(findings, f...), append(conflicts, c...)
+	}
+	for i, r := range resp.GetRatePlans() {
+		f, c := checkBasicAmenities(fmt.Sprintf("rate_plans[%d]", i), r.GetBasicAmenities(), amenityTexts(r.GetName(), r.GetDescription(), r.GetUnstructuredPolicies()), true)
+		findings, conflicts = append(findings, f...), append(conflicts, c...)
+	}
+	if len(conflicts) > 0 {
+		return findings, fmt.Errorf("conflicting amenities: %s", joinFields(conflicts))
+	}
+	return findings, nil
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestValidateAmenities(t *testing.T) {
+	cases := []struct {
+		name        string
+		amenities   []pb.RoomAmenityType
+		basic       *pb.BasicAmenities
+		description string
+		want        error
+		wantCodes   []string
+	}{
+		{
+			name:        "breakfast set and described",
+			amenities:   []pb.RoomAmenityType{pb.RoomAmenityType_KETTLE},
+			basic:       &pb.BasicAmenities{FreeBreakfast: true},
+			description: "Breakfast included",
+		},
+		{
+			name:      "undocumented and unknown room amenities",
+			amenities: []pb.RoomAmenityType{pb.RoomAmenityType_UNKNOWN_ROOM_AMENITY_TYPE, pb.RoomAmenityType(999)},
+			basic:     &pb.BasicAmenities{},
+			wantCodes: []string{CodeAmenityUnknown, CodeAmenityUnknown},
+		},
+		{
+			name:        "free text only",
+			basic:       &pb.BasicAmenities{},
+			description: "Includes breakfast and free Wi-Fi",
+			wantCodes:   []string{CodeAmenityFreeText, CodeAmenityFreeText},
+		},
+		{
+			name:        "breakfast set but room only",
+			basic:       &pb.BasicAmenities{FreeBreakfast: true},
+			description: "Room only, no meals",
+			want:        fmt.Errorf("conflicting amenities: rate_plans[0] > basic_amenities > free_breakfast"),
+			wantCodes:   []string{CodeAmenityConflict},
+		},
+		{
+			name:        "breakfast not included",
+			basic:       &pb.BasicAmenities{},
+			description: "No breakfast included",
+		},
+	}
+	for _, tc := range cases {
+		data, err := BookingAvailabilityData()
+		if err != nil {
+			t.Fatalf("error fetching BookingAvailabilityData: %q", err)
+		}
+		data.RespPb.RoomTypes[0].Amenities = tc.amenities
+		data.RespPb.RatePlans[0].BasicAmenities = tc.basic
+		data.RespPb.RatePlans[0].Description = &pb.DisplayString{Text: tc.description, Language: "en"}
+		findings, got := validateAmenities(data.RespPb)
+		if diff := cmp.Diff(got, tc.want, equateErrorMessage); diff != "" {
+			t.Errorf("%s: unexpected error (diff -got +want): %s", tc.name, diff)
+		}
+		var codes []string
+		for _, f := range findings {
+			codes = append(codes, f.Code)
+		}
+		if diff := cmp.Diff(codes, tc.wantCodes); diff != "" {
+			t.Errorf("%s: unexpected finding codes (diff -got +want): %s", tc.name, diff)
+		}
+	}
+}
+
+func TestRoomTypeMealPlanWords(t *testing.T) {
+	data, err := BookingAvailabilityData()
+	if err != nil {
+		t.Fatalf("error fetching BookingAvailabilityData: %q", err)
+	}
+	data.RespPb.RoomTypes[0].BasicAmenities = &pb.BasicAmenities{FreeBreakfast: true}
+	data.RespPb.RoomTypes[0].Description = &pb.DisplayString{Text: "Attic room only accessible by stairs", Language: "en"}
+	if findings, err := validateAmenities(data.RespPb); err != nil || len(findings) != 0 {
+		t.Errorf("validateAmenities() of a room type described as \"room only accessible\" = %v, %v, want no findings", findings, err)
+	}
+}
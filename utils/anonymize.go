@@ -0,0 +1,252 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// Anonymizer scrubs PII and partner-identifying data from captured payloads while preserving their structure.
+// Each distinct value is replaced by the same placeholder every time it is seen, so fields echoed between a request
+// and its response still match once both have been anonymized with the same Anonymizer.
+type Anonymizer struct {
+	replacements map[string]string
+	counts       map[string]int
+}
+
+// NewAnonymizer returns an Anonymizer with no recorded replacements.
+func NewAnonymizer() *Anonymizer {
+	return &Anonymizer{
+		replacements: map[string]string{},
+		counts:       map[string]int{},
+	}
+}
+
+// placeholders maps each kind of scrubbed value to the format of its replacement.
+var placeholders = map[string]string{
+	"email":   "user%d@example.com",
+	"name":    "Name%d",
+	"phone":   "+1-555-000-%04d",
+	"address": "%d Example St.",
+	"city":    "City%d",
+	"postal":  "%05d",
+	"locator": "LOCATOR%d",
+	"loyalty": "MEMBER%d",
+	"hotel":   "HOTEL%d",
+	"url":     "https://example.com/%d",
+	"partner": "PARTNER_DATA_%d",
+	"ip":      "192.0.2.%d",
+}
+
+// replace returns the placeholder for value v of the given kind, leaving empty values untouched.
+func (a *Anonymizer) replace(kind, v string) string {
+	if v == "" {
+		return ""
+	}
+	key := kind + "\x00" + v
+	if r, ok := a.replacements[key]; ok {
+		return r
+	}
+	a.counts[kind]++
+	r := fmt.Sprintf(placeholders[kind], a.counts[kind])
+	a.replacements[key] = r
+	return r
+}
+
+// redact replaces a non-empty value with a fixed token, matching the sample data in this repository.
+func (a *Anonymizer) redact(v, token string) string {
+	if v == "" {
+		return ""
+	}
+	a.replacements["redact\x00"+v] = token
+	return token
+}
+
+var (
+	// emailAddress and cardNumber match PII in text that is not a message Anonymize knows.
+	emailAddress = regexp.MustCompile(`[\w.+-]+@[\w-]+(\.[\w-]+)+`)
+	cardNumber   = regexp.MustCompile(`\b\d(?:[ -]?\d){11,18}\b`)
+)
+
+// valuePattern matches v in text, as a whole word where it begins or ends with a word character, so that e.g. the
+// hotel_id 123 is not replaced within 1234.
+func valuePattern(v string) *regexp.Regexp {
+	p := regexp.QuoteMeta(v)
+	if isWordByte(v[0]) {
+		p = `\b` + p
+	}
+	if isWordByte(v[len(v)-1]) {
+		p += `\b`
+	}
+	return regexp.MustCompile(p)
+}
+
+func isWordByte(b byte) bool {
+	return b == '_' || '0' <= b && b <= '9' || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z'
+}
+
+// AnonymizeText scrubs s, e.g. a response body that could not be parsed, as text: every value replaced by this
+// Anonymizer so far is replaced by the same placeholder, and other email addresses and card numbers are redacted.
+// Anonymize the request first, so that the values it echoes are recognized.
+func (a *Anonymizer) AnonymizeText(s string) string {
+	values := make([]string, 0, len(a.replacements))
+	for key := range a.replacements {
+		values = append(values, key)
+	}
+	// Replace longer values first, so that a value containing another is replaced whole.
+	sort.Slice(values, func(i, j int) bool {
+		vi, vj := values[i][strings.IndexByte(values[i], 0)+1:], values[j][strings.IndexByte(values[j], 0)+1:]
+		if len(vi) != len(vj) {
+			return len(vi) > len(vj)
+		}
+		return vi < vj
+	})
+	for _, key := range values {
+		s = valuePattern(key[strings.IndexByte(key, 0)+1:]).ReplaceAllLiteralString(s, a.replacements[key])
+	}
+	s = emailAddress.ReplaceAllStringFunc(s, func(e string) string { return a.replace("email", e) })
+	return cardNumber.ReplaceAllString(s, "---PAN---")
+}
+
+// Anonymize scrubs m in place. m must be one of the BookingAvailability or BookingSubmit request or response messages.
+func (a *Anonymizer) Anonymize(m proto.Message) error {
+	switch m := m.(type) {
+	case *pb.BookingAvailabilityRequest:
+		a.tracking(m.GetTracking())
+		m.HotelId = a.replace("hotel", m.GetHotelId())
+	case *pb.BookingAvailabilityResponse:
+		m.HotelId = a.replace("hotel", m.GetHotelId())
+		for _, r := range m.GetRoomTypes() {
+			for _, p := range r.GetPhotos() {
+				p.Url = a.replace("url", p.GetUrl())
+			}
+		}
+		for _, r := range m.GetRoomRates() {
+			a.roomRate(r)
+		}
+		a.hotelDetails(m.GetHotelDetails())
+	case *pb.BookingSubmitRequest:
+		a.tracking(m.GetTracking())
+		m.HotelId = a.replace("hotel", m.GetHotelId())
+		m.IpAddress = a.replace("ip", m.GetIpAddress())
+		a.customer(m.GetCustomer())
+		a.traveler(m.GetTraveler())
+		a.roomRate(m.GetRoomRate())
+		if p := m.GetPayment(); p != nil {
+			if c := p.GetPaymentCardParameters(); c != nil {
+				c.CardNumber = a.redact(c.GetCardNumber(), "---PAN---")
+				c.CardholderName = a.replace("name", c.GetCardholderName())
+				c.Cvc = a.redact(c.GetCvc(), "---CVN---")
+				c.Cavv = a.redact(c.GetCavv(), "---CAVV---")
+				c.Eci = a.redact(c.GetEci(), "---ECI---")
+			}
+			p.PaymentToken = a.redact(p.GetPaymentToken(), "---TOKEN---")
+			a.address(p.GetBillingAddress())
+		}
+	case *pb.BookingSubmitResponse:
+		if r := m.GetReservation(); r != nil {
+			a.locator(r.GetLocator())
+			for _, l := range r.GetHotelLocators() {
+				a.locator(l)
+			}
+			r.HotelId = a.replace("hotel", r.GetHotelId())
+			a.customer(r.GetCustomer())
+			a.traveler(r.GetTraveler())
+			a.roomRate(r.GetRoomRate())
+		}
+	default:
+		return fmt.Errorf("unable to anonymize message of type %T", m)
+	}
+	return nil
+}
+
+func (a *Anonymizer) tracking(t *pb.Tracking) {
+	if t == nil {
+		return
+	}
+	t.PosUrl = a.replace("url", t.GetPosUrl())
+}
+
+func (a *Anonymizer) customer(c *pb.Customer) {
+	if c == nil {
+		return
+	}
+	c.FirstName = a.replace("name", c.GetFirstName())
+	c.LastName = a.replace("name", c.GetLastName())
+	c.PhoneNumber = a.replace("phone", c.GetPhoneNumber())
+	c.Email = a.replace("email", c.GetEmail())
+	c.LoyaltyMemberId = a.replace("loyalty", c.GetLoyaltyMemberId())
+}
+
+func (a *Anonymizer) traveler(t *pb.Traveler) {
+	if t == nil {
+		return
+	}
+	t.FirstName = a.replace("name", t.GetFirstName())
+	t.LastName = a.replace("name", t.GetLastName())
+}
+
+func (a *Anonymizer) address(ad *pb.Address) {
+	if ad == nil {
+		return
+	}
+	ad.Address1 = a.replace("address", ad.GetAddress1())
+	ad.Address2 = a.replace("address", ad.GetAddress2())
+	ad.Address3 = a.replace("address", ad.GetAddress3())
+	ad.City = a.replace("city", ad.GetCity())
+	ad.PostalCode = a.replace("postal", ad.GetPostalCode())
+}
+
+func (a *Anonymizer) locator(l *pb.BookingSubmitResponse_Reservation_Locator) {
+	if l == nil {
+		return
+	}
+	l.Id = a.replace("locator", l.GetId())
+	l.Pin = a.redact(l.GetPin(), "---PIN---")
+}
+
+func (a *Anonymizer) roomRate(r *pb.RoomRate) {
+	if r == nil {
+		return
+	}
+	for i, d := range r.GetPartnerData() {
+		r.PartnerData[i] = a.replace("partner", d)
+	}
+}
+
+func (a *Anonymizer) hotelDetails(h *pb.HotelDetails) {
+	if h == nil {
+		return
+	}
+	h.Name = a.replace("name", h.GetName())
+	a.address(h.GetAddress())
+	h.PhoneNumber = a.replace("phone", h.GetPhoneNumber())
+	h.Email = a.replace("email", h.GetEmail())
+	h.HomepageUrl = a.replace("url", h.GetHomepageUrl())
+	// The hotel's coordinates identify it as reliably as its name.
+	h.Geolocation = nil
+	for _, p := range h.GetPhotos() {
+		p.Url = a.replace("url", p.GetUrl())
+	}
+}
@@ -0,0 +1,84 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+)
+
+func TestAnonymizeSubmitPair(t *testing.T) {
+	data, err := BookingSubmitData()
+	if err != nil {
+		t.Fatalf("error fetching BookingSubmitData: %q", err)
+	}
+	a := NewAnonymizer()
+	for _, m := range []proto.Message{data.ReqPb, data.RespPb} {
+		if err := a.Anonymize(m); err != nil {
+			t.Fatalf("Anonymize(%T) returned error: %v", m, err)
+		}
+	}
+
+	m := &jsonpb.Marshaler{OrigName: true}
+	for _, msg := range []proto.Message{data.ReqPb, data.RespPb} {
+		got, err := m.MarshalToString(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, pii := range []string{"email@example.com", "John", "Doe", "James Carter", "+1-555-4443333", "abcdefg", "10 Main St.", "1.2.3.4", "googleapi-e7fafbb0a132fb519d0e1b82b23dc794", "ARB_0403"} {
+			if strings.Contains(got, pii) {
+				t.Errorf("Anonymize(%T) left %q in %s", msg, pii, got)
+			}
+		}
+	}
+	if data.RespPb.GetReservation().GetLocator().GetId() == "" {
+		t.Error("Anonymize() removed reservation > locator > id, want it replaced")
+	}
+	// Echoed values are replaced consistently, so the pair still validates.
+	if _, err := ValidateBookingSubmitResponse(data.ReqPb, data.RespPb); err != nil {
+		t.Errorf("ValidateBookingSubmitResponse() after Anonymize() returned error: %v", err)
+	}
+}
+
+func TestAnonymizeAvailabilityPair(t *testing.T) {
+	data, err := BookingAvailabilityData()
+	if err != nil {
+		t.Fatalf("error fetching BookingAvailabilityData: %q", err)
+	}
+	a := NewAnonymizer()
+	for _, m := range []proto.Message{data.ReqPb, data.RespPb} {
+		if err := a.Anonymize(m); err != nil {
+			t.Fatalf("Anonymize(%T) returned error: %v", m, err)
+		}
+	}
+	if got := data.RespPb.GetHotelDetails().GetEmail(); got == "test@example.com" {
+		t.Errorf("Anonymize() left hotel_details > email %q", got)
+	}
+	if _, err := ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb); err != nil {
+		t.Errorf("ValidateBookingAvailabilityResponse() after Anonymize() returned error: %v", err)
+	}
+}
+
+func TestAnonymizeText(t *testing.T) {
+	data, err := BookingSubmitData()
+	if err != nil {
+		t.Fatalf("error fetching BookingSubmitData: %q", err)
+	}
+	req := data.ReqPb
+	email, name := req.GetCustomer().GetEmail(), req.GetCustomer().GetFirstName()
+	a := NewAnonymizer()
+	if err := a.Anonymize(req); err != nil {
+		t.Fatal(err)
+	}
+	body := "<html>booking of " + name + " <" + email + "> for hotel 123 failed, card 4111 1111 1111 1111, contact ops@partner.example, ref 1234</html>"
+	got := a.AnonymizeText(body)
+	for _, pii := range []string{name, email, "4111 1111 1111 1111", "ops@partner.example", "hotel 123 "} {
+		if strings.Contains(got, pii) {
+			t.Errorf("AnonymizeText() left %q in %s", pii, got)
+		}
+	}
+	if want := "hotel " + req.GetHotelId() + " failed"; !strings.Contains(got, want) || !strings.Contains(got, "ref 1234") {
+		t.Errorf("AnonymizeText() = %s, want the placeholders of the request, e.g. %q, and other values kept", got, want)
+	}
+}
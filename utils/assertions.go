@@ -0,0 +1,176 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/cel-go/cel"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// CustomAssertions, if set, are evaluated against every response that passes the built-in validation.
+var CustomAssertions *Assertions
+
+// assertionSpec is an assertion as written in an assertion file.
+type assertionSpec struct {
+	Name string `json:"name"`
+	// Response is the type of response checked: availability or submit.
+	Response string `json:"response"`
+	// Expr is a CEL expression evaluating to true if the response passes, over the variables req and resp.
+	Expr string `json:"expr"`
+	// Severity of the finding of a response failing the assertion, Error if unset.
+	Severity *Severity `json:"severity"`
+}
+
+// assertion is a compiled assertionSpec.
+type assertion struct {
+	name     string
+	expr     string
+	severity Severity
+	program  cel.Program
+}
+
+// Assertions are custom checks of responses written as CEL expressions, loaded by LoadAssertions.
+type Assertions struct {
+	availability, submit []assertion
+}
+
+// assertionEnvs returns the CEL environments of availability and submit assertions, in which req and resp are the
+// request and response messages, their fields named as in json.
+func assertionEnvs() (map[string]*cel.Env, error) {
+	envs := map[string]*cel.Env{}
+	for kind, msgs := range map[string][2]proto.Message{
+		"availability": {&pb.BookingAvailabilityRequest{}, &pb.BookingAvailabilityResponse{}},
+		"submit":       {&pb.BookingSubmitRequest{}, &pb.BookingSubmitResponse{}},
+	} {
+		env, err := cel.NewEnv(
+			cel.Types(msgs[0], msgs[1]),
+			cel.Variable("req", cel.ObjectType(proto.MessageName(msgs[0]))),
+			cel.Variable("resp", cel.ObjectType(proto.MessageName(msgs[1]))),
+			cel.CrossTypeNumericComparisons(true),
+		)
+		if err != nil {
+			return nil, err
+		}
+		envs[kind] = env
+	}
+	return envs, nil
+}
+
+// LoadAssertions reads and compiles the assertion file fp, a json list of assertions with a name, the response they
+// check, availability or submit, a CEL expression over req and resp which must be true, e.g.
+// "resp.room_rates.all(r, r.total_price_at_checkout.amount > 0)", and an optional severity, Error by default.
+func LoadAssertions(fp string) (*Assertions, error) {
+	content, err := reader(fp)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read assertion file: %v", err)
+	}
+	var specs []assertionSpec
+	if err := json.Unmarshal(content, &specs); err != nil {
+		return nil, fmt.Errorf("invalid assertion file %s: %v", fp, err)
+	}
+	envs, err := assertionEnvs()
+	if err != nil {
+		return nil, err
+	}
+	a := &Assertions{}
+	for i, s := range specs {
+		if s.Name == "" {
+			return nil, fmt.Errorf("assertion %d of %s has no name", i, fp)
+		}
+		env, ok := envs[s.Response]
+		if !ok {
+			return nil, fmt.Errorf("assertion %s checks response %q, want availability or submit", s.Name, s.Response)
+		}
+		ast, iss := env.Compile(s.Expr)
+		if iss.Err() != nil {
+			return nil, fmt.Errorf("assertion %s: %v", s.Name, iss.Err())
+		}
+		if ast.OutputType() != cel.BoolType {
+			return nil, fmt.Errorf("assertion %s evaluates to %v, want bool", s.Name, ast.OutputType())
+		}
+		program, err := env.Program(ast)
+		if err != nil {
+			return nil, fmt.Errorf("assertion %s: %v", s.Name, err)
+		}
+		c := assertion{name: s.Name, expr: s.Expr, severity: Error, program: program}
+		if s.Severity != nil {
+			c.severity = *s.Severity
+		}
+		if s.Response == "availability" {
+			a.availability = append(a.availability, c)
+		} else {
+			a.submit = append(a.submit, c)
+		}
+	}
+	return a, nil
+}
+
+// check evaluates assertions against req and resp. An assertion that is false, or fails to evaluate, e.g. on a
+// missing field, is a finding, and an error is returned if any of those findings is not a Warning.
+func check(assertions []assertion, req, resp proto.Message) ([]Finding, error) {
+	var findings []Finding
+	var failed []string
+	for _, a := range assertions {
+		f := Finding{Severity: a.severity, Field: "assertion > " + a.name, Code: CodeAssertionFailed}
+		out, _, err := a.program.Eval(map[string]interface{}{"req": req, "resp": resp})
+		switch {
+		case err != nil:
+			f.Severity, f.Code, f.Message = Error, CodeAssertionError, fmt.Sprintf("%s could not be evaluated: %v", a.expr, err)
+		case out.Value() != true:
+			f.Message = fmt.Sprintf("%s is false", a.expr)
+		default:
+			continue
+		}
+		log.Println(f)
+		findings = append(findings, f)
+		if f.Severity != Warning {
+			failed = append(failed, a.name)
+		}
+	}
+	if len(failed) > 0 {
+		return findings, fmt.Errorf("custom assertion(s) failed: %s", strings.Join(failed, ", "))
+	}
+	return findings, nil
+}
+
+// HasAvailability reports whether a, which may be nil, has assertions on availability responses.
+func (a *Assertions) HasAvailability() bool {
+	return a != nil && len(a.availability) > 0
+}
+
+// checkAvailability evaluates the availability assertions of a, which may be nil, against req and resp.
+func (a *Assertions) checkAvailability(req *pb.BookingAvailabilityRequest, resp *pb.BookingAvailabilityResponse) ([]Finding, error) {
+	if a == nil {
+		return nil, nil
+	}
+	return check(a.availability, req, resp)
+}
+
+// checkSubmit evaluates the submit assertions of a, which may be nil, against req and resp.
+func (a *Assertions) checkSubmit(req *pb.BookingSubmitRequest, resp *pb.BookingSubmitResponse) ([]Finding, error) {
+	if a == nil {
+		return nil, nil
+	}
+	return check(a.submit, req, resp)
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLoadAssertions(t *testing.T) {
+	defer func(r func(string) ([]byte, error)) { reader = r }(reader)
+	cases := []struct {
+		name    string
+		file    string
+		wantErr string
+	}{
+		{name: "sample", file: "@data"},
+		{name: "syntax error", file: `[{"name": "broken", "response": "availability", "expr": "resp.room_rates.all(r,"}]`, wantErr: "assertion broken"},
+		{name: "unknown field", file: `[{"name": "typo", "response": "availability", "expr": "resp.room_rate.size() > 0"}]`, wantErr: "assertion typo"},
+		{name: "not bool", file: `[{"name": "count", "response": "availability", "expr": "resp.room_rates.size()"}]`, wantErr: "want bool"},
+		{name: "unknown response", file: `[{"name": "cancel", "response": "cancel", "expr": "true"}]`, wantErr: "want availability or submit"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			content := []byte(tc.file)
+			if tc.file == "@data" {
+				var err error
+				if content, err = ioutil.ReadFile(absPath("assertions.json")); err != nil {
+					t.Fatal(err)
+				}
+			}
+			reader = func(string) ([]byte, error) { return content, nil }
+			_, err := LoadAssertions("assertions.json")
+			if tc.wantErr == "" && err != nil || tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Errorf("LoadAssertions() returned error %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCustomAssertions(t *testing.T) {
+	defer func(r func(string) ([]byte, error)) { reader, CustomAssertions = r, nil }(reader)
+	reader = func(string) ([]byte, error) {
+		return []byte(`[
+			{"name": "positive", "response": "availability", "expr": "resp.room_rates.all(r, r.total_price_at_checkout.amount > 0)"},
+			{"name": "usd", "response": "availability", "expr": "resp.room_rates.all(r, r.total_price_at_checkout.currency == 'USD')", "severity": "Warning"},
+			{"name": "booked_rate", "response": "submit", "expr": "resp.reservation.room_rate.code == req.room_rate.code"}
+		]`), nil
+	}
+	a, err := LoadAssertions("assertions.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	CustomAssertions = a
+
+	availability, err := BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if findings, err := ValidateBookingAvailabilityResponse(availability.ReqPb, availability.RespPb); err != nil || len(findings) != 0 {
+		t.Errorf("ValidateBookingAvailabilityResponse() of the sample = %v, %v, want no findings", findings, err)
+	}
+	for _, r := range availability.RespPb.GetRoomRates() {
+		r.TotalPriceAtCheckout.Amount = 0
+		r.TotalPriceAtCheckout.Currency = "EUR"
+	}
+	findings, err := ValidateBookingAvailabilityResponse(availability.ReqPb, availability.RespPb)
+	if err == nil || !strings.Contains(err.Error(), "custom assertion(s) failed: positive") {
+		t.Errorf("ValidateBookingAvailabilityResponse() returned error %v, want the positive assertion failed", err)
+	}
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Severity.String()+" "+f.Field)
+	}
+	if diff := cmp.Diff(got, []string{"Error assertion > positive", "Warning assertion > usd"}); diff != "" {
+		t.Errorf("ValidateBookingAvailabilityResponse() returned findings that differ (-got +want):\n%s", diff)
+	}
+
+	v := NewAvailabilityValidator(availability.ReqPb, availability.RespPb.GetApiVersion())
+	availability.RespPb.RoomRates = nil
+	if _, err := v.Validate(availability.RespPb); err == nil {
+		t.Errorf("AvailabilityValidator.Validate() with availability assertions returned nil error")
+	}
+
+	submit, err := BookingSubmitData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	submit.RespPb.Reservation.RoomRate.Code = "RATE2"
+	submit.ReqPb.RoomRate.Code = "RATE2"
+	if _, err := ValidateBookingSubmitResponse(submit.ReqPb, submit.RespPb); err != nil {
+		t.Errorf("ValidateBookingSubmitResponse() returned error %v, want nil", err)
+	}
+}
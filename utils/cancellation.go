@@ -0,0 +1,133 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// ShortCancellationWindow is the minimum gap between a free-cancellation deadline and check-in that is not flagged.
+const ShortCancellationWindow = 24 * time.Hour
+
+// noShowDeadline is the deadline of a penalty charged only if the guest neither cancels nor shows up.
+const noShowDeadline = "NO_SHOW"
+
+// checkInTime returns the check-in instant for resp in loc, using the hotel's check_in_time when it is provided.
+func checkInTime(resp *pb.BookingAvailabilityResponse, loc *time.Location) (time.Time, error) {
+	checkIn, err := time.ParseInLocation(FormatsFor(resp.GetApiVersion()).DateLayout, resp.GetStartDate(), loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to parse start_date %q: %v", resp.GetStartDate(), err)
+	}
+	if h, m, s := checkIn.Clock(); h+m+s != 0 {
+		// The start_date is a timestamp which already includes the check-in time.
+		return checkIn, nil
+	}
+	if t, err := time.Parse("15:04", resp.GetHotelDetails().GetPolicies().GetCheckInTime()); err == nil {
+		checkIn = checkIn.Add(time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute)
+	}
+	return checkIn, nil
+}
+
+// validateCancellationPolicies ensures cancellation deadlines fall strictly before check-in and are not declared on
+// non-refundable rate plans; NO_SHOW deadlines are not timestamps and are not compared. Free-cancellation deadlines
+// closer than ShortCancellationWindow to check-in, or deadlines in a common variant of RFC 3339, are returned as
+// warnings. While the current time is faked by SetNow, free-cancellation deadlines must not have passed; the real time
+// is not checked, so that sample and captured responses validate alike whenever replayed.
+func validateCancellationPolicies(resp *pb.BookingAvailabilityResponse) ([]Finding, error) {
+	return validateCancellationDeadlines(resp, indexRates(resp.GetRoomRates()))
+}
+
+// validateCancellationDeadlines behaves like validateCancellationPolicies for the room rates given separately from
+// resp.
+func validateCancellationDeadlines(resp *pb.BookingAvailabilityResponse, rates []indexedRate) ([]Finding, error) {
+	var findings []Finding
+	var errorFields []string
+	invalid := func(field, msg, code string) {
+		errorFields = append(errorFields, field)
+		f := Finding{Error, field, msg, code}
+		log.Println(f)
+		findings = append(findings, f)
+	}
+
+	checkDeadline := func(field, value string) *time.Duration {
+		deadline, deviation, err := parseTimestamp(value)
+		if err != nil {
+			invalid(field, fmt.Sprintf("%s is not an RFC 3339 timestamp", value), CodeDeadlineInvalid)
+			return nil
+		}
+		if deviation != "" {
+			f := nonCanonicalTimestamp(field, value, deviation)
+			log.Println(f)
+			findings = append(findings, f)
+		}
+		checkIn, err := checkInTime(resp, deadline.Location())
+		if err != nil {
+			invalid(field, fmt.Sprintf("could not be compared to check-in: %v", err), CodeDeadlineInvalid)
+			return nil
+		}
+		if !deadline.Before(checkIn) {
+			invalid(field, fmt.Sprintf("%s is not before check-in at %s", value, checkIn.Format(time.RFC3339)), CodeDeadlineInvalid)
+			return nil
+		}
+		window := checkIn.Sub(deadline)
+		return &window
+	}
+
+	for i, r := range resp.GetRatePlans() {
+		p := r.GetCancellationPolicy()
+		if p.GetCancellationDeadline() == "" || p.GetCancellationDeadline() == noShowDeadline {
+			continue
+		}
+		field := fmt.Sprintf("rate_plans[%d] > cancellation_policy > cancellation_deadline", i)
+		if p.GetSummary() == pb.CancellationPolicy_NON_REFUNDABLE {
+			invalid(field, "declares a free-cancellation window on a NON_REFUNDABLE rate plan", CodeDeadlineRefundable)
+			continue
+		}
+		window := checkDeadline(field, p.GetCancellationDeadline())
+		if p.GetSummary() != pb.CancellationPolicy_FREE_CANCELLATION {
+			continue
+		}
+		if window != nil && *window < ShortCancellationWindow {
+			f := Finding{Warning, field, fmt.Sprintf("free cancellation ends %v before check-in, less than %v", *window, ShortCancellationWindow), CodeShortCancellation}
+			log.Println(f)
+			findings = append(findings, f)
+		}
+		if now, ok := fakedNow(); ok {
+			if deadline, _, err := parseTimestamp(p.GetCancellationDeadline()); err == nil && deadline.Before(now) {
+				invalid(field, fmt.Sprintf("free cancellation ended at %s, before the faked current time %s", p.GetCancellationDeadline(), now.Format(time.RFC3339)), CodeDeadlinePassed)
+			}
+		}
+	}
+
+	for _, r := range rates {
+		for j, c := range r.rate.GetCancellationRules() {
+			if c.GetDeadline() == "" || c.GetDeadline() == noShowDeadline {
+				continue
+			}
+			checkDeadline(fmt.Sprintf("room_rates[%d] > cancellation_rules[%d] > deadline", r.index, j), c.GetDeadline())
+		}
+	}
+
+	if len(errorFields) > 0 {
+		return findings, fmt.Errorf("invalid cancellation deadline(s): %s", joinFields(errorFields))
+	}
+	return findings, nil
+}
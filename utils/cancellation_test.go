@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestValidateCancellationPolicies(t *testing.T) {
+	cases := []struct {
+		name         string
+		summary      pb.CancellationPolicy_CancellationSummary
+		deadline     string
+		want         error
+		wantWarnings int
+	}{
+		{
+			name:     "deadline well before check-in",
+			summary:  pb.CancellationPolicy_FREE_CANCELLATION,
+			deadline: "2019-03-28T12:00:00+00:00",
+		},
+		{
+			name:         "deadline within 24h of check-in",
+			summary:      pb.CancellationPolicy_FREE_CANCELLATION,
+			deadline:     "2019-04-03T02:00:00+00:00",
+			wantWarnings: 1,
+		},
+		{
+			name:     "deadline after check-in",
+			summary:  pb.CancellationPolicy_FREE_CANCELLATION,
+			deadline: "2019-04-04T12:00:00+00:00",
+			want:     fmt.Errorf("invalid cancellation deadline(s): rate_plans[0] > cancellation_policy > cancellation_deadline"),
+		},
+		{
+			name:     "deadline on non-refundable plan",
+			summary:  pb.CancellationPolicy_NON_REFUNDABLE,
+			deadline: "2019-03-28T12:00:00+00:00",
+			want:     fmt.Errorf("invalid cancellation deadline(s): rate_plans[0] > cancellation_policy > cancellation_deadline"),
+		},
+		{
+			name:         "deadline without colon in offset",
+			summary:      pb.CancellationPolicy_FREE_CANCELLATION,
+			deadline:     "2019-03-28T12:00:00+0000",
+			wantWarnings: 1,
+		},
+		{
+			name:     "no show deadline",
+			summary:  pb.CancellationPolicy_FREE_CANCELLATION,
+			deadline: "NO_SHOW",
+		},
+		{
+			name:     "partial refund deadline within 24h of check-in",
+			summary:  pb.CancellationPolicy_PARTIAL_REFUND,
+			deadline: "2019-04-03T02:00:00+00:00",
+		},
+		{
+			name:     "unparseable deadline",
+			summary:  pb.CancellationPolicy_FREE_CANCELLATION,
+			deadline: "28/03/2019",
+			want:     fmt.Errorf("invalid cancellation deadline(s): rate_plans[0] > cancellation_policy > cancellation_deadline"),
+		},
+	}
+	for _, tc := range cases {
+		data, err := BookingAvailabilityData()
+		if err != nil {
+			t.Fatalf("error fetching BookingAvailabilityData: %q", err)
+		}
+		data.RespPb.RatePlans[0].CancellationPolicy.Summary = tc.summary
+		data.RespPb.RatePlans[0].CancellationPolicy.CancellationDeadline = tc.deadline
+		findings, got := validateCancellationPolicies(data.RespPb)
+		if diff := cmp.Diff(got, tc.want, equateErrorMessage); diff != "" {
+			t.Errorf("%s: unexpected error (diff -got +want): %s", tc.name, diff)
+		}
+		var warnings, errs int
+		for _, f := range findings {
+			if f.Severity == Warning {
+				warnings++
+			} else {
+				errs++
+			}
+		}
+		if warnings != tc.wantWarnings || (errs > 0) != (tc.want != nil) {
+			t.Errorf("%s: got findings %v, want %d warning(s) and an error finding per invalid deadline", tc.name, findings, tc.wantWarnings)
+		}
+	}
+}
+
+func TestValidateCancellationRulesAfterCheckIn(t *testing.T) {
+	data, err := BookingAvailabilityData()
+	if err != nil {
+		t.Fatalf("error fetching BookingAvailabilityData: %q", err)
+	}
+	data.RespPb.RoomRates[1].CancellationRules[0].Deadline = "2019-04-03T15:00:00+00:00"
+	want := fmt.Errorf("invalid cancellation deadline(s): room_rates[1] > cancellation_rules[0] > deadline")
+	_, got := ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb)
+	if diff := cmp.Diff(got, want, equateErrorMessage); diff != "" {
+		t.Errorf("failed to catch cancellation rule after check-in (diff -got +want): %s", diff)
+	}
+}
+
+func TestValidateCancellationRulesNoShow(t *testing.T) {
+	data, err := BookingAvailabilityData()
+	if err != nil {
+		t.Fatalf("error fetching BookingAvailabilityData: %q", err)
+	}
+	data.RespPb.RoomRates[1].CancellationRules[0].Deadline = "NO_SHOW"
+	if _, err := ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb); err != nil {
+		t.Errorf("ValidateBookingAvailabilityResponse() of a NO_SHOW cancellation rule returned error %v, want nil", err)
+	}
+}
+
+func TestValidateCancellationDeadlinePassed(t *testing.T) {
+	defer SetNow(time.Time{})
+	cases := []struct {
+		name      string
+		now       time.Time
+		wantCodes []string
+	}{
+		{name: "real time"},
+		{name: "before deadline", now: time.Date(2019, 3, 28, 11, 0, 0, 0, time.UTC)},
+		{name: "after deadline", now: time.Date(2019, 3, 28, 13, 0, 0, 0, time.UTC), wantCodes: []string{CodeDeadlinePassed}},
+	}
+	for _, tc := range cases {
+		data, err := BookingAvailabilityData()
+		if err != nil {
+			t.Fatalf("error fetching BookingAvailabilityData: %q", err)
+		}
+		data.RespPb.RatePlans = data.RespPb.RatePlans[:1]
+		data.RespPb.RatePlans[0].CancellationPolicy.Summary = pb.CancellationPolicy_FREE_CANCELLATION
+		data.RespPb.RatePlans[0].CancellationPolicy.CancellationDeadline = "2019-03-28T12:00:00+00:00"
+		SetNow(tc.now)
+		findings, err := validateCancellationPolicies(data.RespPb)
+		var codes []string
+		for _, f := range findings {
+			codes = append(codes, f.Code)
+		}
+		if diff := cmp.Diff(codes, tc.wantCodes); diff != "" {
+			t.Errorf("%s: unexpected finding codes (diff -got +want): %s", tc.name, diff)
+		}
+		if (err != nil) != (tc.wantCodes != nil) {
+			t.Errorf("%s: got error %v, want error %v", tc.name, err, tc.wantCodes != nil)
+		}
+	}
+}
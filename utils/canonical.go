@@ -0,0 +1,95 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+)
+
+// FormatRequest returns content, a json or pb3 encoded message of the type of m, canonically formatted in the same
+// encoding: fields in the order of the schema, two space indentation and a trailing newline. m holds the parsed
+// message on return.
+func FormatRequest(content []byte, isJSON bool, m proto.Message) ([]byte, error) {
+	if err := parseRequest(content, isJSON, m); err != nil {
+		return nil, err
+	}
+	if isJSON {
+		out, err := (&jsonpb.Marshaler{OrigName: true, Indent: "  "}).MarshalToString(m)
+		if err != nil {
+			return nil, fmt.Errorf("unable to convert request to json: %v", err)
+		}
+		return append(unescapeHTML([]byte(out)), '\n'), nil
+	}
+	return []byte(proto.MarshalTextString(m)), nil
+}
+
+// FormatRequestFile canonically formats the request file fp, keeping its format, and reports whether its content
+// changed. The file is only rewritten when write is true.
+func FormatRequestFile(fp string, m proto.Message, write bool) (bool, error) {
+	ext := path.Ext(fp)
+	if ext != ".json" && ext != ".pb3" {
+		return false, fmt.Errorf("unexpected extension for file %q, expected .json or .pb3", fp)
+	}
+	content, err := reader(fp)
+	if err != nil {
+		return false, fmt.Errorf("unable to read input file: %v", err)
+	}
+	formatted, err := FormatRequest(content, ext == ".json", m)
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(content, formatted) {
+		return false, nil
+	}
+	if write {
+		if err := ioutil.WriteFile(fp, formatted, 0644); err != nil {
+			return true, fmt.Errorf("unable to write %s: %v", fp, err)
+		}
+	}
+	return true, nil
+}
+
+// htmlEscapes are the characters jsonpb escapes for safe embedding in HTML, which only obscure urls in fixtures.
+var htmlEscapes = map[string]byte{`u0026`: '&', `u003c`: '<', `u003e`: '>'}
+
+// unescapeHTML replaces the html escapes of the json encoded b with the characters they stand for.
+func unescapeHTML(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		if b[i] != '\\' || i+1 == len(b) {
+			out = append(out, b[i])
+			continue
+		}
+		if i+6 <= len(b) {
+			if c, ok := htmlEscapes[string(b[i+1:i+6])]; ok {
+				out = append(out, c)
+				i += 5
+				continue
+			}
+		}
+		// Keep any other escape, including an escaped backslash, as is.
+		out = append(out, b[i], b[i+1])
+		i++
+	}
+	return out
+}
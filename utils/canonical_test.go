@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestFormatRequestFile(t *testing.T) {
+	dir := t.TempDir()
+	cases := []struct {
+		name        string
+		file        string
+		content     string
+		want        string
+		wantChanged bool
+		wantErr     bool
+	}{
+		{
+			name:        "json out of order",
+			file:        "req.json",
+			content:     `{"end_date":"2019-04-05", "hotel_id": "123",` + "\n" + `"api_version": 1}`,
+			want:        "{\n  \"api_version\": 1,\n  \"hotel_id\": \"123\",\n  \"end_date\": \"2019-04-05\"\n}\n",
+			wantChanged: true,
+		},
+		{
+			name:        "json with url",
+			file:        "url.json",
+			content:     `{"hotel_id": "a&b<c>\\u0026"}`,
+			want:        "{\n  \"hotel_id\": \"a&b<c>\\\\u0026\"\n}\n",
+			wantChanged: true,
+		},
+		{
+			name:    "canonical json",
+			file:    "canonical.json",
+			content: "{\n  \"api_version\": 1,\n  \"hotel_id\": \"123\"\n}\n",
+			want:    "{\n  \"api_version\": 1,\n  \"hotel_id\": \"123\"\n}\n",
+		},
+		{
+			name:        "pb3 out of order",
+			file:        "req.pb3",
+			content:     `hotel_id: "123" api_version: 1`,
+			want:        "api_version: 1\nhotel_id: \"123\"\n",
+			wantChanged: true,
+		},
+		{
+			name:    "unknown field",
+			file:    "unknown.json",
+			content: `{"api_version": 1, "hotel": "123"}`,
+			want:    `{"api_version": 1, "hotel": "123"}`,
+			wantErr: true,
+		},
+	}
+	for _, tc := range cases {
+		fp := filepath.Join(dir, tc.file)
+		if err := ioutil.WriteFile(fp, []byte(tc.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		changed, err := FormatRequestFile(fp, &pb.BookingAvailabilityRequest{}, true)
+		if (err != nil) != tc.wantErr || changed != tc.wantChanged {
+			t.Errorf("%s: FormatRequestFile() = %v, %v, want changed %v and error %v", tc.name, changed, err, tc.wantChanged, tc.wantErr)
+		}
+		got, err := ioutil.ReadFile(fp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("%s: FormatRequestFile() wrote %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
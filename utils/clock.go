@@ -0,0 +1,51 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// clock is the current time faked by SetNow, zero while the real time is used.
+var clock struct {
+	sync.Mutex
+	now time.Time
+}
+
+// SetNow fakes the current time validation is relative to, e.g. to the time a partner sandbox was told it is with a
+// time override header. The zero time restores the real time.
+func SetNow(t time.Time) {
+	clock.Lock()
+	defer clock.Unlock()
+	clock.now = t
+}
+
+// Now returns the time set by SetNow, or the current time if none is set.
+func Now() time.Time {
+	if t, ok := fakedNow(); ok {
+		return t
+	}
+	return time.Now()
+}
+
+// fakedNow returns the time set by SetNow, and whether one is set.
+func fakedNow() (time.Time, bool) {
+	clock.Lock()
+	defer clock.Unlock()
+	return clock.now, !clock.now.IsZero()
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+// Codes of the checks behind findings. A code is stable across releases: it is never renumbered or reused for
+// another check, so partner automation can track and suppress findings by code. New checks take the next number of
+// their group, and the code of a removed check is retired.
+const (
+	CodeEchoMismatch      = "ECHO_001"
+	CodeEchoNormalized    = "ECHO_002"
+	CodeEchoZeroChildren  = "ECHO_003"
+	CodeRequiredMissing   = "REQUIRED_001"
+	CodeRateCodeUndefined = "REFERENCE_001"
+	CodeFormatInvalid     = "FORMAT_001"
+
+	CodeNumberNotFinite      = "NUMBER_001"
+	CodeNumberNotInteger     = "NUMBER_002"
+	CodeNumberAsString       = "NUMBER_003"
+	CodeIntegerWithFraction  = "NUMBER_004"
+	CodeIntegerFractional    = "NUMBER_005"
+	CodeNumberExponent       = "NUMBER_006"
+	CodeJSONKeyOrder         = "JSON_001"
+	CodeJSONRepeatedKey      = "JSON_002"
+	CodeJSONPrefix           = "JSON_003"
+	CodeFieldLength          = "LENGTH_001"
+	CodeTimestampFormat      = "TIME_001"
+	CodePriceMinorUnits      = "PRICE_001"
+	CodePricePrecision       = "PRICE_002"
+	CodePriceRounding        = "PRICE_003"
+	CodeCurrencyMissing      = "PRICE_004"
+	CodeTaxAtBooking         = "TAX_001"
+	CodeTaxMissing           = "TAX_002"
+	CodeShortCancellation    = "POLICY_001"
+	CodeDeadlinePassed       = "POLICY_002"
+	CodeDeadlineInvalid      = "POLICY_003"
+	CodeDeadlineRefundable   = "POLICY_004"
+	CodePropertyPolicy       = "POLICY_005"
+	CodeRoomCountNotPositive = "INVENTORY_001"
+	CodeRoomCountLarge       = "INVENTORY_002"
+	CodeRoomCountUnchanged   = "INVENTORY_003"
+	CodeAmenityUnknown       = "AMENITY_001"
+	CodeAmenityFreeText      = "AMENITY_002"
+	CodeAmenityConflict      = "AMENITY_003"
+
+	CodeErrorUnknownType   = "ERROR_001"
+	CodeErrorEmptyMessage  = "ERROR_002"
+	CodeErrorStackTrace    = "ERROR_003"
+	CodeErrorSQL           = "ERROR_004"
+	CodeErrorCorrelationID = "ERROR_005"
+
+	CodePaymentMissing       = "PAYMENT_001"
+	CodePaymentBoth          = "PAYMENT_002"
+	CodePaymentUnexpected    = "PAYMENT_003"
+	CodePaymentCardType      = "PAYMENT_004"
+	CodePaymentMonth         = "PAYMENT_005"
+	CodePaymentYear          = "PAYMENT_006"
+	CodePaymentExpired       = "PAYMENT_007"
+	CodePaymentFullNumber    = "PAYMENT_008"
+	CodePaymentRevealsDigits = "PAYMENT_009"
+
+	CodeLocatorReused       = "SUBMIT_001"
+	CodeDuplicateBooking    = "SUBMIT_002"
+	CodeReplayFailed        = "SUBMIT_003"
+	CodeLocatorFormat       = "LOCATOR_001"
+	CodeLocatorTrivial      = "LOCATOR_002"
+	CodeTransactionReused   = "TRANSACTION_001"
+	CodeTransactionIDLength = "TRANSACTION_002"
+
+	CodeHTTPStatus            = "HTTP_001"
+	CodeTimeoutConnect        = "TIMEOUT_001"
+	CodeTimeoutTLS            = "TIMEOUT_002"
+	CodeTimeoutFirstByte      = "TIMEOUT_003"
+	CodeTimeoutTotal          = "TIMEOUT_004"
+	CodeTimeoutGateway        = "TIMEOUT_005"
+	CodePlaintextHTTP         = "HTTPS_001"
+	CodeRedirectMethod        = "HTTPS_002"
+	CodePlaintextAnswered     = "HTTPS_003"
+	CodeRedirectTarget        = "HTTPS_004"
+	CodePlaintextAvailability = "HTTPS_005"
+	CodePlaintextDiverges     = "HTTPS_006"
+	CodeRedirectCredentials   = "REDIRECT_001"
+	CodeRedirectCrossOrigin   = "REDIRECT_002"
+	CodeTransportFailed       = "TRANSPORT_001"
+	CodeTransportAuth         = "TRANSPORT_002"
+	CodeTransportThrottled    = "TRANSPORT_003"
+	CodeTransportSlow         = "TRANSPORT_004"
+	CodeCredentialsRejected   = "AUTH_001"
+	CodeCredentialsAccepted   = "AUTH_002"
+	CodeCredentialsForbidden  = "AUTH_003"
+	CodeCredentialsStatus     = "AUTH_004"
+	CodeConditionalHonored    = "CACHE_001"
+	CodeCacheableResponse     = "CACHE_002"
+	CodeCachedResponse        = "CACHE_003"
+	CodeSavedPayloads         = "ARTIFACT_001"
+	CodeProfileIgnored        = "PROFILE_001"
+
+	CodeSweepFailed          = "SWEEP_001"
+	CodeSweepNoAvailability  = "SWEEP_002"
+	CodeLoadThrottled        = "LOAD_001"
+	CodeSLAMissed            = "SLA_001"
+	CodeFreeTextFailed       = "FREETEXT_001"
+	CodeFreeTextRejected     = "FREETEXT_002"
+	CodeFreeTextAltered      = "FREETEXT_003"
+	CodeExtensionRejected    = "EXTENSION_001"
+	CodeExtensionDropped     = "EXTENSION_002"
+	CodeMultiIPFailed        = "MULTIIP_001"
+	CodeMultiIPSlow          = "MULTIIP_002"
+	CodeOversizedTimeout     = "OVERSIZED_001"
+	CodeOversizedClosed      = "OVERSIZED_002"
+	CodeOversizedStatus      = "OVERSIZED_003"
+	CodeOversizedAccepted    = "OVERSIZED_004"
+	CodeOversizedUnavailable = "OVERSIZED_005"
+	CodeErrorCaseFailed      = "ERRORMATRIX_001"
+	CodeErrorCaseType        = "ERRORMATRIX_002"
+	CodeErrorCaseGeneric     = "ERRORMATRIX_003"
+	CodeVersionFailed        = "VERSION_001"
+	CodeVersionRejected      = "VERSION_002"
+	CodeVersionProcessed     = "VERSION_003"
+	CodeOrderingRatesChanged = "ORDERING_001"
+	CodeOrderingUnstable     = "ORDERING_002"
+	CodeLocaleFailed         = "LOCALE_001"
+	CodeLocaleUntranslated   = "LOCALE_002"
+	CodeLocaleMixed          = "LOCALE_003"
+	CodeClosedDate           = "CLOSED_001"
+	CodeCircuitOpen          = "CIRCUIT_001"
+	CodeAssertionFailed      = "ASSERT_001"
+	CodeAssertionError       = "ASSERT_002"
+	CodePlanSetup            = "PLAN_001"
+	CodePlanTeardown         = "PLAN_002"
+)
+
+// Rule describes the check behind the findings of a code.
+type Rule struct {
+	Code string `json:"code"`
+	// Severity is the severity of the findings of the check, before any profile overrides it.
+	Severity    Severity `json:"severity"`
+	Description string   `json:"description"`
+	// Example is the field and message of a typical finding of the check, as logged after its code.
+	Example string `json:"example"`
+}
+
+// Rules is the registry of every code, in the order they are listed by the rules subcommand.
+var Rules = []Rule{
+	{CodeEchoMismatch, Error, "a field echoed in the response does not match the request", "hotel_id did not match (-got +want)\n- \"124\"\n+ \"123\""},
+	{CodeEchoNormalized, Error, "a field echoed in the response differs from the request only in letter case or whitespace; echo it exactly as sent", "hotel_id did not match (-got +want)\n- \"ABC123\"\n+ \"abc123\""},
+	{CodeEchoZeroChildren, Error, "an echoed party drops children aged 0, who are infants, or adds children aged 0 to a party without children", "party did not match (-got +want)\n  {\n    \"adults\": 2,\n+   \"children\": [\n+     0\n+   ]\n  }"},
+	{CodeRequiredMissing, Error, "a required field is not set", "room_rates[0] > code required field is not set"},
+	{CodeRateCodeUndefined, Error, "a room rate references a room type or rate plan the response does not define", "room_rates[2] > rate_plan_code flex is not defined in rate_plans > code"},
+	{CodeFormatInvalid, Error, "a field does not match the format of its API version, e.g. a date that is not YYYY-MM-DD", "start_date value 20190403 did not match pattern ^([12]\\d{3}-(0[1-9]|1[0-2])-(0[1-9]|[12]\\d|3[01]))$"},
+	{CodeNumberNotFinite, Error, "a numeric field is a string that is not a finite number", "room_rates[0] > total_amount value \"NaN\" is not a finite number; encode it as a JSON number, e.g. 123.45"},
+	{CodeNumberNotInteger, Error, "an integer field is a string that is not a plain integer", "party > adults value \"two\" is not a plain integer; encode it as a JSON integer, e.g. 2"},
+	{CodeNumberAsString, Warning, "a numeric field is encoded as a string", "api_version integer is encoded as the string \"1\"; encode it as a JSON number, e.g. 1"},
+	{CodeIntegerWithFraction, Error, "an integer field is encoded with a fraction or exponent", "party > adults integer is encoded as 2.0, which fails to parse; encode it without a fraction or exponent, e.g. 2"},
+	{CodeIntegerFractional, Error, "an integer field has a fractional value", "party > adults value 2.5 is not an integer; encode it as a JSON integer, e.g. 2"},
+	{CodeNumberExponent, Warning, "a number is encoded in exponent notation", "room_rates[0] > total_amount number is encoded in exponent notation as 1.5e2; encode it in plain decimal notation, e.g. 150"},
+	{CodeJSONKeyOrder, Warning, "a json key comes out of the order of the schema", "hotel_id key hotel_id comes after start_date, out of the order of the schema"},
+	{CodeJSONRepeatedKey, Warning, "a json key is repeated, or a field sent under both of its names", "hotel_id key hotel_id is repeated, the last value is used but other json parsers may use the first"},
+	{CodeJSONPrefix, Warning, "the response body starts with a byte order mark or XSSI prefix", "response body starts with a XSSI prefix )]}'; it was stripped but should be removed"},
+	{CodeFieldLength, Warning, "a string is longer than the validator default maximum for its field", "rate_plans[0] > name > text 300 characters is longer than the validator default of 255, the value may be truncated for display"},
+	{CodeTimestampFormat, Warning, "a timestamp is not in RFC 3339", "rate_plans[0] > cancellation_policy > cancellation_deadline timestamp 2019-04-03 15:00:00+02:00 uses a space instead of T, send RFC 3339 timestamps such as 2019-04-03T15:00:00+02:00 instead"},
+	{CodePriceMinorUnits, Error, "an amount is not the sum of its line items, e.g. because it is in minor units", "room_rates[0] > total_amount amount 12345 is 100 times the sum of its line_items (123.45); api_version 1 expects every amount in major units of the currency, e.g. 123.45 rather than 12345"},
+	{CodePricePrecision, Error, "an amount has more decimals than its currency", "room_rates[0] > total_price_at_checkout amount 540.125 has 3 decimal(s), USD amounts have 2; rounded half up it is 540.13 but half even 540.12, so send the rounded amount"},
+	{CodePriceRounding, Warning, "a total is the sum of its line items only when they are rounded another way", "room_rates[0] > total_price_at_checkout amount 20 matches its line_items only when each is rounded half up to 2 decimal(s) before they are added; the exact sum of its line_items, 20.008, rounds half up to 20.01"},
+	{CodeCurrencyMissing, Error, "an amount charged at booking has no currency", "room_rates[1] > total_price_at_booking > currency an amount charged at booking has no currency"},
+	{CodeTaxAtBooking, Warning, "a municipal tax is charged at booking where it is usually collected at checkout", "room_rates[0] > line_items[1] > paid_at_checkout municipal tax is charged at booking; in IT it is usually collected by the property at checkout"},
+	{CodeTaxMissing, Warning, "no municipal tax line item where hotels usually levy one", "room_rates[0] > line_items no TAX_MUNICIPAL line item, but hotels in IT usually levy an occupancy tax"},
+	{CodeShortCancellation, Warning, "free cancellation ends shortly before check-in", "rate_plans[0] > cancellation_policy > cancellation_deadline free cancellation ends 2h0m0s before check-in, less than 24h0m0s"},
+	{CodeDeadlinePassed, Error, "a free-cancellation deadline has already passed at the faked current time of a time override", "rate_plans[0] > cancellation_policy > cancellation_deadline free cancellation ended at 2030-03-28T12:00:00Z, before the faked current time 2030-03-29T00:00:00Z"},
+	{CodeDeadlineInvalid, Error, "a cancellation deadline is not an RFC 3339 timestamp before check-in", "rate_plans[0] > cancellation_policy > cancellation_deadline 2019-04-04T12:00:00+00:00 is not before check-in at 2019-04-03T00:00:00Z"},
+	{CodeDeadlineRefundable, Error, "a NON_REFUNDABLE rate plan declares a cancellation deadline", "rate_plans[0] > cancellation_policy > cancellation_deadline declares a free-cancellation window on a NON_REFUNDABLE rate plan"},
+	{CodePropertyPolicy, Error, "a property-level policy is malformed: a check-in or check-out time, max_child_age or a policy text", "hotel_details > policies > unstructured_policies[0] > text is 1200 characters long, more than 1000"},
+	{CodeRoomCountNotPositive, Error, "room_count is a negative number of rooms; 0 is the same as leaving it unset", "room_rates[0] > room_count room_count -1 must be a positive number of rooms, or omitted if unknown"},
+	{CodeRoomCountLarge, Warning, "room_count is implausibly large", "room_rates[0] > room_count room_count 100000 is implausibly large, report the rooms actually left for the stay or omit it"},
+	{CodeRoomCountUnchanged, Warning, "room_count did not decrement after a booking", "room_rates > room_count room rate rate-1 still had 5 room(s) left after booking one of 5"},
+	{CodeAmenityUnknown, Warning, "a room amenity is not a documented RoomAmenityType", "room_types[0] > amenities[0] 999 is not a documented RoomAmenityType and is not displayed"},
+	{CodeAmenityFreeText, Warning, "an inclusion is only described in free text rather than basic_amenities", "rate_plans[0] > basic_amenities > free_breakfast is not set, but the text says \"breakfast included\"; set it so the amenity is displayed"},
+	{CodeAmenityConflict, Error, "basic_amenities or texts contradict each other, e.g. breakfast included and room only", "rate_plans[0] > basic_amenities > free_breakfast is set, but the text says \"room only\""},
+	{CodeErrorUnknownType, Warning, "an error has type UNKNOWN_ERROR", "error > type error type is UNKNOWN_ERROR; use the most specific type so the failure can be handled automatically"},
+	{CodeErrorEmptyMessage, Warning, "an error has an empty message", "error > message error message is empty"},
+	{CodeErrorStackTrace, Warning, "an error message contains a stack trace", "error > message error message contains a stack trace"},
+	{CodeErrorSQL, Warning, "an error message contains SQL or a database error", "error > message error message contains SQL or a database error"},
+	{CodeErrorCorrelationID, Warning, "an error message has no correlation ID", "error > message error message does not include a correlation ID, e.g. \"request id: 7f3a2c91\""},
+	{CodePaymentMissing, Warning, "a PAYMENT_CARD payment has no payment method", "payment type is PAYMENT_CARD but neither payment_card_parameters nor payment_token is set"},
+	{CodePaymentBoth, Warning, "a payment has both card parameters and a token", "payment both payment_card_parameters and payment_token are set; set exactly one payment method"},
+	{CodePaymentUnexpected, Warning, "a payment method is set for a payment type that takes none", "payment > type type is PAY_AT_HOTEL but a payment method is set"},
+	{CodePaymentCardType, Warning, "the card type is unknown", "payment > payment_card_parameters > card_type unknown card type 42"},
+	{CodePaymentMonth, Warning, "the card expiration month is not formatted MM", "payment > payment_card_parameters > expiration_month expiration month \"1\" is not formatted MM"},
+	{CodePaymentYear, Warning, "the card expiration year is not formatted YYYY", "payment > payment_card_parameters > expiration_year expiration year \"25\" is not formatted YYYY"},
+	{CodePaymentExpired, Warning, "the card has expired", "payment > payment_card_parameters card expired in 01/2019"},
+	{CodePaymentFullNumber, Critical, "the response contains the full card number", "payment > payment_card_parameters > card_number the response contains the full card number sent in the request"},
+	{CodePaymentRevealsDigits, Error, "a masked card number reveals more than, or other than, the last four digits of the card number", "payment > payment_card_parameters > card_number a masked card number in the response reveals 6 digits; reveal at most the last 4"},
+	{CodeLocatorReused, Critical, "a reservation locator was returned for another transaction_id", "reservation > locator > id locator L123 was already returned for transaction_id t1"},
+	{CodeDuplicateBooking, Critical, "a retried submit was booked twice", "reservation > locator > id duplicate booking: transaction_id t1 was booked as both L1 and L2"},
+	{CodeReplayFailed, Warning, "the replay of a retried submit failed, so deduplication could not be verified", "error > type replayed submit with transaction_id t1 failed with SUPPLIER_ERROR rather than returning reservation L1 or DUPLICATE_BOOKING, so deduplication could not be verified"},
+	{CodeLocatorFormat, Error, "a reservation locator id does not match the locator format", "reservation > locator > id value A1 did not match pattern ^[A-Za-z0-9_-]{6,64}$"},
+	{CodeLocatorTrivial, Error, "a reservation locator id is trivially guessable, e.g. a repeated character or a counting sequence", "reservation > hotel_locators[0] > id value 123-456 is a trivial locator"},
+	{CodeTransactionReused, Error, "a response transaction_id was returned for different requests", "transaction_id transaction_id t1 was returned for requests with transaction_id t1 and t2"},
+	{CodeTransactionIDLength, Error, "a response transaction_id is longer than the validator default maximum", "transaction_id is 129 characters long, more than 128"},
+	{CodeHTTPStatus, Error, "the response has an unexpected HTTP status", "http_status unexpected HTTP status: /v1/BookingAvailability returned 500 Internal Server Error, want 200 OK"},
+	{CodeTimeoutConnect, Error, "a request timed out resolving the host or connecting to it", "timeout > connect /v1/BookingAvailability: TCP connect timed out after 30s (dns 2ms)"},
+	{CodeTimeoutTLS, Error, "a request timed out in the TLS handshake", "timeout > tls_handshake /v1/BookingAvailability: TLS handshake timed out after 30s (dns 2ms, connect 40ms)"},
+	{CodeTimeoutFirstByte, Error, "a request was sent but no response was received within the client deadline", "timeout > client_deadline /v1/BookingAvailability: response not received within the client deadline of 30s after 30s (connect 40ms, request sent at 41ms)"},
+	{CodeTimeoutTotal, Error, "a response started but was not received in full within the client deadline", "timeout > client_deadline /v1/BookingAvailability: response not received within the client deadline of 30s after 30s (request sent at 41ms, first byte at 2.5s)"},
+	{CodeTimeoutGateway, Error, "the partner answered with 504 Gateway Timeout", "timeout > gateway /v1/BookingAvailability: partner returned 504 Gateway Timeout after 10s (request sent at 41ms)"},
+	{CodePlaintextHTTP, Error, "an endpoint is served over plaintext http", "https http://partner.example.com/v1/BookingAvailability is served over plaintext http"},
+	{CodeRedirectMethod, Warning, "the plaintext port redirects with a status that turns POST into GET", "https http://partner.example.com:80/v1/BookingAvailability redirects with 301 Moved Permanently, which turns POST requests into GET; use 307 or 308"},
+	{CodePlaintextAnswered, Error, "the plaintext port answers instead of redirecting to https", "https http://partner.example.com:80/v1/BookingAvailability answered 200 OK over plaintext http instead of redirecting to https"},
+	{CodeRedirectTarget, Error, "the plaintext port redirects elsewhere than https on the same host", "https http://partner.example.com:80/v1/BookingAvailability redirects to \"http://partner.example.com/\", want https://partner.example.com"},
+	{CodePlaintextAvailability, Critical, "the plaintext port serves availability without credentials", "https http://partner.example.com:80/v1/BookingAvailability served an availability response over plaintext http without credentials"},
+	{CodePlaintextDiverges, Error, "the plaintext port answers differently than https", "https the plaintext http response differs from the https response (-https +http):\n- hotel_id: \"123\"\n+ hotel_id: \"stale\""},
+	{CodeRedirectCredentials, Warning, "a request carrying credentials was redirected to another host or plaintext http, which was not followed", "redirects > https://partner.example.com/v1/BookingAvailability redirects with 307 to https://cdn.example.net/v1/BookingAvailability, which was not followed so as not to send it the credentials"},
+	{CodeRedirectCrossOrigin, Warning, "a redirect to another host or scheme was followed", "redirects > https://partner.example.com/v1/BookingAvailability redirects with 307 to https://cdn.example.net/v1/BookingAvailability on another host or scheme"},
+	{CodeTransportFailed, Error, "a transport probe could not reach the server", "transport > /v1/BookingAvailability transport error: /v1/BookingAvailability yielded error: dial tcp 203.0.113.7:443: connect: connection refused"},
+	{CodeTransportAuth, Error, "a transport probe was rejected for its credentials", "transport > /v1/BookingAvailability credentials were rejected: authentication error: /v1/BookingAvailability returned 401 Unauthorized"},
+	{CodeTransportThrottled, Warning, "a transport probe was throttled", "transport > /v1/BookingAvailability the probe was throttled: throttled: /v1/BookingAvailability returned 429 Too Many Requests"},
+	{CodeTransportSlow, Warning, "an endpoint is slow to send the first byte of its responses", "transport > /v1/BookingAvailability median time to the first byte of 2.5s is above 2s"},
+	{CodeCredentialsRejected, Error, "valid credentials were rejected", "credentials > secondary valid credentials were rejected: authentication error: /v1/BookingAvailability returned 401 Unauthorized"},
+	{CodeCredentialsAccepted, Critical, "invalid credentials were accepted", "credentials > invalid invalid credentials were accepted and answered with an availability response"},
+	{CodeCredentialsForbidden, Warning, "invalid credentials were rejected with 403 rather than 401", "credentials > invalid invalid credentials were rejected with 403 Forbidden, want 401 Unauthorized"},
+	{CodeCredentialsStatus, Error, "invalid credentials were answered with a status other than 401", "credentials > invalid invalid credentials were answered with 200 OK and an error body, want 401 Unauthorized"},
+	{CodeConditionalHonored, Error, "a conditional POST was not answered with a full availability response", "caching answered 304 Not Modified to a POST with If-None-Match: \"v1\" and If-Modified-Since: Mon, 07 Jan 2030 15:04:05 GMT; availability requests must always be answered in full"},
+	{CodeCacheableResponse, Warning, "an availability response allows shared caches to store it", "caching the availability response allows shared caches to store it with Cache-Control: public, max-age=300; send Cache-Control: no-store"},
+	{CodeCachedResponse, Error, "an availability response was served from a cache", "caching the response to transaction_id 42-cache-2 echoes transaction_id 42-cache-1, it is the cached response of another request"},
+	{CodeSavedPayloads, Warning, "the payloads of a failed flow were saved", "artifacts request saved to artifacts/run-1/BookingAvailability-request.json"},
+	{CodeProfileIgnored, Warning, "a failure was ignored by a validation profile", "profile ignored failure: required field(s) missing: hotel_details"},
+	{CodeSweepFailed, Error, "a sweep combination failed", "adults=2 children=0 nights=7 required field(s) missing: room_rates[0] > code"},
+	{CodeSweepNoAvailability, Warning, "a sweep combination returned no availability", "adults=4 children=2 nights=1 no availability"},
+	{CodeLoadThrottled, Warning, "requests were throttled below the maximum load rate", "throughput throttled above 20.0 QPS"},
+	{CodeSLAMissed, Error, "a soak test missed an SLA threshold", "sla > booking_success_rate 90 of 100 bookings succeeded (90.0%), want at least 95.0%"},
+	{CodeFreeTextFailed, Error, "a free text booking failed", "traveler (emoji) HTTP response yielded error: 500 Internal Server Error"},
+	{CodeFreeTextRejected, Error, "a free text booking was rejected with an error other than CUSTOMER_NAME_INVALID", "traveler (emoji) rejected with PAYMENT_ERROR, want success or CUSTOMER_NAME_INVALID"},
+	{CodeFreeTextAltered, Error, "a free text traveler name was not echoed intact", "traveler (accents) was not echoed intact (-got +want)\n- \"Zoe\"\n+ \"Zoë\""},
+	{CodeExtensionRejected, Warning, "a request with unknown fields was rejected", "x_validator_extension request with unknown fields was rejected: 400 Bad Request"},
+	{CodeExtensionDropped, Error, "an unknown field was not echoed", "reservation > traveler unknown field x_validator_extension was not echoed, got <nil> want probe"},
+	{CodeMultiIPFailed, Error, "a server address failed", "ip 203.0.113.7 dial tcp 203.0.113.7:443: connection refused"},
+	{CodeMultiIPSlow, Warning, "a server address is much slower than the others", "ip 203.0.113.7 answered in 3s, more than 3 times the median of 200ms across 4 addresses"},
+	{CodeOversizedTimeout, Error, "an oversized request timed out", "oversized_request a request of 512 KB timed out instead of being rejected with 413 or 400: timeout > client_deadline"},
+	{CodeOversizedClosed, Error, "an oversized request was answered with a closed connection", "oversized_request the connection was closed without a response to a request of 512 KB, want 413 or 400: connection reset by peer"},
+	{CodeOversizedStatus, Error, "an oversized request was answered with a status other than 413 or 400", "oversized_request a request of 512 KB was answered with 500 Internal Server Error, want 413 Request Entity Too Large or 400 Bad Request"},
+	{CodeOversizedAccepted, Warning, "an oversized request was accepted", "oversized_request a request of 512 KB was accepted; reject requests far larger than any valid request with 413"},
+	{CodeOversizedUnavailable, Error, "the server failed after an oversized request", "oversized_request the availability request following a request of 512 KB failed: connection refused"},
+	{CodeErrorCaseFailed, Error, "an error matrix case failed", "availability_dates_in_past HTTP response yielded error: 500 Internal Server Error"},
+	{CodeErrorCaseType, Error, "an error matrix case returned an unexpected error type", "availability_dates_in_past returned UNKNOWN_ERROR, want one of DATE_SELECTION_INVALID"},
+	{CodeErrorCaseGeneric, Error, "a payment failure was reported with a generic error type", "submit_card_declined returned PAYMENT_PROCESSOR_ERROR, a generic error type, want the payment failure PAYMENT_DECLINED"},
+	{CodeVersionFailed, Error, "an api_version probe failed", "api_version (availability 2) HTTP response yielded error: 500 Internal Server Error"},
+	{CodeVersionRejected, Error, "an unknown api_version was rejected with an error other than API_VERSION_UNSUPPORTED", "api_version (availability 99) rejected with UNKNOWN_ERROR, want API_VERSION_UNSUPPORTED"},
+	{CodeVersionProcessed, Error, "an unknown api_version was processed", "api_version (availability 99) the request was processed; api_version is not defined by the API and must be rejected with API_VERSION_UNSUPPORTED"},
+	{CodeOrderingRatesChanged, Warning, "room rates changed between identical requests", "room_rates room rates changed between identical requests 1 and 2, order not compared"},
+	{CodeOrderingUnstable, Error, "the order of room rates changed between identical requests", "room_rates order changed between identical requests 1 and 2: [a b] then [b a]; return room rates in a stable order, e.g. sorted by price"},
+	{CodeLocaleFailed, Error, "a request in another language failed", "language fr HTTP response yielded error: 500 Internal Server Error"},
+	{CodeLocaleUntranslated, Warning, "a text labelled in another language is identical to the default text", "room_types[0] > name labelled fr but identical to the en text"},
+	{CodeLocaleMixed, Warning, "a response mixes languages", "language fr response mixes languages en, fr"},
+	{CodeClosedDate, Error, "a closed or sold out date returned room rates or an error", "closed 2030-12-25 returned 2 room rate(s) for a date the hotel is closed or sold out"},
+	{CodeCircuitOpen, Error, "a batch was stopped after repeated transport failures", "circuit_breaker the sweep stopped after 10 consecutive transport failures, 150 request(s) not run; last error: HTTP response yielded error: transport error: /v1/BookingAvailability yielded error: connection refused"},
+	{CodeAssertionFailed, Error, "a response fails a custom assertion of the assertion file; the severity is set by the assertion", "assertion > priced resp.room_rates.all(r, r.total_price_at_checkout.amount > 0) is false"},
+	{CodeAssertionError, Error, "a custom assertion could not be evaluated against a response, e.g. on a missing field", "assertion > priced resp.room_rates.all(r, r.total_price_at_checkout.amount > 0) could not be evaluated: no such key: total_price_at_checkout"},
+	{CodePlanSetup, Error, "a setup step of the execution plan failed, and the flows of the run were skipped", "setup > create_booking /v1/BookingSubmit returned 500 Internal Server Error, want 200 OK"},
+	{CodePlanTeardown, Error, "a teardown step of the execution plan failed, and the sandbox may not be clean", "teardown > cancel_booking /sandbox/CancelBooking returned 404 Not Found, want 200 OK"},
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestRules(t *testing.T) {
+	code := regexp.MustCompile(`^[A-Z]+_\d{3}$`)
+	seen := map[string]bool{}
+	for _, r := range Rules {
+		if !code.MatchString(r.Code) {
+			t.Errorf("rule code %q is not of the form GROUP_NNN", r.Code)
+		}
+		if seen[r.Code] {
+			t.Errorf("rule code %s is registered twice", r.Code)
+		}
+		seen[r.Code] = true
+		if r.Description == "" {
+			t.Errorf("rule %s has no description", r.Code)
+		}
+	}
+}
+
+func TestRulesRegisterEveryCode(t *testing.T) {
+	registered := map[string]bool{}
+	for _, r := range Rules {
+		registered[r.Code] = true
+	}
+	f, err := parser.ParseFile(token.NewFileSet(), "codes.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ast.Inspect(f, func(n ast.Node) bool {
+		if v, ok := n.(*ast.ValueSpec); ok && len(v.Values) == 1 {
+			if lit, ok := v.Values[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				code, _ := strconv.Unquote(lit.Value)
+				if !registered[code] {
+					t.Errorf("%s = %s is not in Rules", v.Names[0].Name, code)
+				}
+			}
+		}
+		return true
+	})
+}
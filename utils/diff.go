@@ -0,0 +1,86 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"github.com/google/go-cmp/cmp"
+)
+
+// VolatileFields are the fields that legitimately differ between two responses to the same request, such as
+// identifiers generated per transaction, and are ignored by DiffResponses.
+var VolatileFields = []string{
+	"transaction_id",
+	"room_rates > code",
+	"reservation > locator",
+	"reservation > hotel_locators",
+}
+
+// DiffResponses returns a semantic diff of two responses of the same type, e.g. captured from staging and
+// production, or an empty string if they match. Fields are compared by value rather than encoding, and
+// VolatileFields and the ignored fields, in the "room_rates > code" notation, are left out.
+func DiffResponses(a, b proto.Message, ignore []string) (string, error) {
+	ignore = append(append([]string{}, VolatileFields...), ignore...)
+	va, err := diffValue(a, ignore)
+	if err != nil {
+		return "", err
+	}
+	vb, err := diffValue(b, ignore)
+	if err != nil {
+		return "", err
+	}
+	return cmp.Diff(va, vb), nil
+}
+
+// diffValue converts m to generic json values without the ignored fields.
+func diffValue(m proto.Message, ignore []string) (interface{}, error) {
+	s, err := (&jsonpb.Marshaler{OrigName: true}).MarshalToString(m)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		return nil, err
+	}
+	for _, field := range ignore {
+		removeField(v, strings.Split(field, " > "))
+	}
+	return v, nil
+}
+
+// removeField deletes the field at path from v, descending into every element of repeated fields.
+func removeField(v interface{}, path []string) {
+	switch v := v.(type) {
+	case []interface{}:
+		for _, e := range v {
+			removeField(e, path)
+		}
+	case map[string]interface{}:
+		name := strings.TrimSpace(path[0])
+		if len(path) == 1 {
+			delete(v, name)
+			return
+		}
+		if child, ok := v[name]; ok {
+			removeField(child, path[1:])
+		}
+	}
+}
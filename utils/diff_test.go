@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestDiffResponses(t *testing.T) {
+	data, err := BookingAvailabilityData()
+	if err != nil {
+		t.Fatalf("error fetching BookingAvailabilityData: %q", err)
+	}
+	other := proto.Clone(data.RespPb).(*pb.BookingAvailabilityResponse)
+	other.TransactionId = "5b8e1c2a-7f3d-4e9a-a0c1-3d2f6e8b9a01"
+	for _, r := range other.RoomRates {
+		r.Code = "prod-" + r.Code
+	}
+
+	diff, err := DiffResponses(data.RespPb, other, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff != "" {
+		t.Errorf("DiffResponses() of responses differing only in volatile fields returned diff:\n%s", diff)
+	}
+
+	other.RoomRates[1].LineItems[0].Price.Amount++
+	diff, err = DiffResponses(data.RespPb, other, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(diff, "amount") {
+		t.Errorf("DiffResponses() of responses with different prices returned diff %q, want the amount", diff)
+	}
+
+	diff, err = DiffResponses(data.RespPb, other, []string{"room_rates > line_items > price"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff != "" {
+		t.Errorf("DiffResponses() ignoring line item prices returned diff:\n%s", diff)
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// jsonValue returns the decoded json encoding of v, a proto message or a plain value, see jsonLines.
+func jsonValue(v interface{}) (interface{}, bool) {
+	lines, ok := jsonLines(v)
+	if !ok {
+		return nil, false
+	}
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(strings.Join(lines, "\n")), &decoded); err != nil {
+		return nil, false
+	}
+	return decoded, true
+}
+
+// mapStrings returns v, a decoded json value, with fold applied to every string it contains. Keys are left as is.
+func mapStrings(v interface{}, fold func(string) string) interface{} {
+	switch v := v.(type) {
+	case string:
+		return fold(v)
+	case []interface{}:
+		mapped := make([]interface{}, len(v))
+		for i, e := range v {
+			mapped[i] = mapStrings(e, fold)
+		}
+		return mapped
+	case map[string]interface{}:
+		mapped := make(map[string]interface{}, len(v))
+		for k, e := range v {
+			mapped[k] = mapStrings(e, fold)
+		}
+		return mapped
+	}
+	return v
+}
+
+// collapseSpace trims s and replaces every run of whitespace inside it with a single space.
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// echoNormalization describes what got, an echoed value, changed about want, the value sent, if they differ only in
+// the letter case or whitespace of their strings: "letter case", "whitespace" or "letter case and whitespace". It
+// returns "" if the values differ otherwise.
+func echoNormalization(got, want interface{}) string {
+	g, ok := jsonValue(got)
+	if !ok {
+		return ""
+	}
+	w, ok := jsonValue(want)
+	if !ok {
+		return ""
+	}
+	equal := func(fold func(string) string) bool {
+		return reflect.DeepEqual(mapStrings(g, fold), mapStrings(w, fold))
+	}
+	switch {
+	case reflect.DeepEqual(g, w):
+		return ""
+	case equal(collapseSpace):
+		return "whitespace"
+	case equal(strings.ToLower):
+		return "letter case"
+	case equal(func(s string) string { return strings.ToLower(collapseSpace(s)) }):
+		return "letter case and whitespace"
+	}
+	return ""
+}
+
+// occupancy returns the occupancy of v, an echoed party or traveler, or nil if v is neither.
+func occupancy(v interface{}) *pb.Occupancy {
+	switch v := v.(type) {
+	case *pb.Occupancy:
+		return v
+	case *pb.Traveler:
+		return v.GetOccupancy()
+	}
+	return nil
+}
+
+// echoZeroChildren describes how got, an echoed party or traveler, mishandled children aged 0 in want, the value
+// sent, if that is all they differ in. Children are a list of ages, so a child aged 0 is an infant rather than an
+// unset value, and a party without children has an empty list rather than a child aged 0. It returns "" if the
+// values differ otherwise.
+func echoZeroChildren(got, want interface{}) string {
+	g, w := occupancy(got), occupancy(want)
+	if g == nil || w == nil {
+		return ""
+	}
+	var nonZero []int32
+	for _, age := range w.GetChildren() {
+		if age != 0 {
+			nonZero = append(nonZero, age)
+		}
+	}
+	allZero := len(g.GetChildren()) > 0
+	for _, age := range g.GetChildren() {
+		allZero = allZero && age == 0
+	}
+	// Compare everything but the children, which are classified below.
+	fixed := proto.Clone(got.(proto.Message))
+	occupancy(fixed).Children = w.GetChildren()
+	if !proto.Equal(fixed, want.(proto.Message)) {
+		return ""
+	}
+	switch {
+	case len(nonZero) < len(w.GetChildren()) && reflect.DeepEqual(g.GetChildren(), nonZero):
+		return "children aged 0 were dropped, but 0 is the age of an infant"
+	case len(w.GetChildren()) == 0 && allZero:
+		return "a party without children was echoed with children aged 0"
+	}
+	return ""
+}
@@ -0,0 +1,108 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestEchoNormalization(t *testing.T) {
+	cases := []struct {
+		got, want interface{}
+		wantKind  string
+	}{
+		{got: "ABC123", want: "abc123", wantKind: "letter case"},
+		{got: " abc123", want: "abc123", wantKind: "whitespace"},
+		{got: "Jane  Doe", want: "Jane Doe", wantKind: "whitespace"},
+		{got: " ABC123 ", want: "abc123", wantKind: "letter case and whitespace"},
+		{got: "abc124", want: "abc123"},
+		{got: &pb.Traveler{FirstName: "JANE", LastName: "Doe "}, want: &pb.Traveler{FirstName: "Jane", LastName: "Doe"}, wantKind: "letter case and whitespace"},
+		{got: &pb.Traveler{FirstName: "Jane"}, want: &pb.Traveler{FirstName: "Jane", LastName: "Doe"}},
+		{got: "abc123", want: "abc123"},
+	}
+	for _, tc := range cases {
+		if got := echoNormalization(tc.got, tc.want); got != tc.wantKind {
+			t.Errorf("echoNormalization(%v, %v) = %q, want %q", tc.got, tc.want, got, tc.wantKind)
+		}
+	}
+}
+
+func TestValidateBookingSubmitResponseNormalizedEcho(t *testing.T) {
+	data, err := BookingSubmitData()
+	if err != nil {
+		t.Fatalf("error fetching BookingSubmitData: %q", err)
+	}
+	data.RespPb.Reservation.HotelId = strings.ToUpper(data.ReqPb.GetHotelId()) + "x"
+	data.RespPb.Reservation.Traveler.FirstName = strings.ToUpper(data.ReqPb.GetTraveler().GetFirstName())
+	data.RespPb.Reservation.EndDate = data.ReqPb.GetEndDate() + " "
+	want := fmt.Errorf("echo field(s) did not match request: hotel_id; echo field(s) differ from request only in letter case or whitespace, echo them exactly as sent: end_date (whitespace),traveler (letter case)")
+	findings, got := ValidateBookingSubmitResponse(data.ReqPb, data.RespPb)
+	if diff := cmp.Diff(got, want, equateErrorMessage); diff != "" {
+		t.Errorf("ValidateBookingSubmitResponse() returned an error that differs (-got +want):\n%s", diff)
+	}
+	var codes []string
+	for _, f := range findings {
+		codes = append(codes, f.Code)
+	}
+	if diff := cmp.Diff(codes, []string{CodeEchoMismatch, CodeEchoNormalized, CodeEchoNormalized}); diff != "" {
+		t.Errorf("ValidateBookingSubmitResponse() returned finding codes that differ (-got +want):\n%s", diff)
+	}
+}
+
+func TestEchoZeroChildren(t *testing.T) {
+	cases := []struct {
+		got, want interface{}
+		wantKind  string
+	}{
+		{got: &pb.Occupancy{Adults: 2}, want: &pb.Occupancy{Adults: 2, Children: []int32{0}}, wantKind: "children aged 0 were dropped, but 0 is the age of an infant"},
+		{got: &pb.Occupancy{Adults: 2, Children: []int32{7}}, want: &pb.Occupancy{Adults: 2, Children: []int32{0, 7}}, wantKind: "children aged 0 were dropped, but 0 is the age of an infant"},
+		{got: &pb.Occupancy{Adults: 2, Children: []int32{0}}, want: &pb.Occupancy{Adults: 2}, wantKind: "a party without children was echoed with children aged 0"},
+		{got: &pb.Traveler{FirstName: "Jane", Occupancy: &pb.Occupancy{Adults: 1}}, want: &pb.Traveler{FirstName: "Jane", Occupancy: &pb.Occupancy{Adults: 1, Children: []int32{0}}}, wantKind: "children aged 0 were dropped, but 0 is the age of an infant"},
+		{got: &pb.Traveler{FirstName: "John", Occupancy: &pb.Occupancy{Adults: 1}}, want: &pb.Traveler{FirstName: "Jane", Occupancy: &pb.Occupancy{Adults: 1, Children: []int32{0}}}},
+		{got: &pb.Occupancy{Adults: 3}, want: &pb.Occupancy{Adults: 2, Children: []int32{0}}},
+		{got: &pb.Occupancy{Adults: 2, Children: []int32{8}}, want: &pb.Occupancy{Adults: 2}},
+	}
+	for _, tc := range cases {
+		if got := echoZeroChildren(tc.got, tc.want); got != tc.wantKind {
+			t.Errorf("echoZeroChildren(%v, %v) = %q, want %q", tc.got, tc.want, got, tc.wantKind)
+		}
+	}
+}
+
+func TestValidateBookingAvailabilityResponseZeroChildren(t *testing.T) {
+	cases := []struct {
+		name      string
+		req, resp []int32
+		wantCodes []string
+	}{
+		{name: "infant echoed", req: []int32{0}, resp: []int32{0}},
+		{name: "no children echoed empty", resp: []int32{}},
+		{name: "infant dropped", req: []int32{0}, wantCodes: []string{CodeEchoZeroChildren}},
+		{name: "child aged 0 added", resp: []int32{0}, wantCodes: []string{CodeEchoZeroChildren}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := BookingAvailabilityData()
+			if err != nil {
+				t.Fatal(err)
+			}
+			data.ReqPb.Party.Children = tc.req
+			data.RespPb.Party.Children = tc.resp
+			findings, err := ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb)
+			var codes []string
+			for _, f := range findings {
+				codes = append(codes, f.Code)
+			}
+			if diff := cmp.Diff(codes, tc.wantCodes); diff != "" {
+				t.Errorf("ValidateBookingAvailabilityResponse() returned finding codes that differ (-got +want):\n%s", diff)
+			}
+			if (err != nil) != (tc.wantCodes != nil) {
+				t.Errorf("ValidateBookingAvailabilityResponse() returned error %v, want error %v", err, tc.wantCodes != nil)
+			}
+		})
+	}
+}
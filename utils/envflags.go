@@ -0,0 +1,54 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// EnvFlagPrefix prefixes the environment variable of every flag, see FlagEnvVar.
+const EnvFlagPrefix = "FLAG_"
+
+// FlagEnvVar returns the environment variable that sets the flag name, e.g. FLAG_SERVER_ADDR for server_addr.
+func FlagEnvVar(name string) string {
+	return EnvFlagPrefix + strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
+
+// SetFlagsFromEnv sets every flag of fs not given on the command line from its environment variable, looked up with
+// lookup, e.g. os.LookupEnv. It must be called after fs is parsed, so the command line takes precedence over the
+// environment. An empty variable sets the flag to the empty value, and a value the flag rejects is an error naming the
+// variable.
+func SetFlagsFromEnv(fs *flag.FlagSet, lookup func(string) (string, bool)) error {
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if set[f.Name] || err != nil {
+			return
+		}
+		value, ok := lookup(FlagEnvVar(f.Name))
+		if !ok {
+			return
+		}
+		if e := fs.Set(f.Name, value); e != nil {
+			err = fmt.Errorf("invalid value %q of %s for flag -%s: %v", value, FlagEnvVar(f.Name), f.Name, e)
+		}
+	})
+	return err
+}
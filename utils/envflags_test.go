@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"flag"
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+func TestFlagEnvVar(t *testing.T) {
+	for name, want := range map[string]string{
+		"server_addr":                         "FLAG_SERVER_ADDR",
+		"sla_min_availability_non_empty_rate": "FLAG_SLA_MIN_AVAILABILITY_NON_EMPTY_RATE",
+		"dry-run":                             "FLAG_DRY_RUN",
+	} {
+		if got := FlagEnvVar(name); got != want {
+			t.Errorf("FlagEnvVar(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestSetFlagsFromEnv(t *testing.T) {
+	env := map[string]string{
+		"FLAG_SERVER_ADDR": "partner.example.com:443",
+		"FLAG_QUIET":       "true",
+		"FLAG_SOAK":        "5m",
+		"FLAG_REPORTERS":   "json",
+		"SERVER_ADDR":      "ignored:80",
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	serverAddr := fs.String("server_addr", "localhost:8080", "")
+	quiet := fs.Bool("quiet", false, "")
+	soak := fs.Duration("soak", 0, "")
+	reporters := fs.String("reporters", "console", "")
+	runID := fs.String("run_id", "", "")
+	if err := fs.Parse([]string{"-reporters=junit"}); err != nil {
+		t.Fatal(err)
+	}
+	lookup := func(k string) (string, bool) {
+		v, ok := env[k]
+		return v, ok
+	}
+	if err := SetFlagsFromEnv(fs, lookup); err != nil {
+		t.Fatalf("SetFlagsFromEnv() returned error: %v", err)
+	}
+	if *serverAddr != "partner.example.com:443" || !*quiet || *soak != 5*time.Minute || *runID != "" {
+		t.Errorf("SetFlagsFromEnv() set server_addr %q, quiet %v, soak %v and run_id %q, want the environment values", *serverAddr, *quiet, *soak, *runID)
+	}
+	if *reporters != "junit" {
+		t.Errorf("SetFlagsFromEnv() set reporters %q, want the command line value junit", *reporters)
+	}
+
+	env["FLAG_QUIET"] = "maybe"
+	fs = flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	fs.Bool("quiet", false, "")
+	if err := SetFlagsFromEnv(fs, lookup); err == nil {
+		t.Error("SetFlagsFromEnv() with FLAG_QUIET=maybe returned no error, want an error")
+	}
+}
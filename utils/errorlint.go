@@ -0,0 +1,101 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"log"
+	"regexp"
+	"strings"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+var (
+	// stackTracePatterns match the stack trace formats of common server runtimes.
+	stackTracePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`\tat [\w.$<>]+\(.*(\.java|\.kt|\.scala):\d+\)`), // JVM
+		regexp.MustCompile(`Traceback \(most recent call last\)`),           // Python
+		regexp.MustCompile(`goroutine \d+ \[`),                              // Go
+		regexp.MustCompile(`\.go:\d+ \+0x[0-9a-f]+`),                        // Go
+		regexp.MustCompile(` at .+ in .+\.cs:line \d+`),                     // .NET
+		regexp.MustCompile(`\.(php|rb|js|ts):\d+`),                          // PHP, Ruby, Node
+	}
+	// sqlPatterns match leaked SQL statements and database driver errors.
+	sqlPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`(?i)\bselect\s+.+\s+from\s+\w+`),
+		regexp.MustCompile(`(?i)\binsert\s+into\s+\w+`),
+		regexp.MustCompile(`(?i)\bupdate\s+\w+\s+set\s+\w+`),
+		regexp.MustCompile(`(?i)\bdelete\s+from\s+\w+`),
+		regexp.MustCompile(`SQLSTATE`),
+		regexp.MustCompile(`ORA-\d{5}`),
+		regexp.MustCompile(`(?i)syntax error at or near`),
+	}
+	// correlationID matches a UUID, or an identifier introduced by a label such as "request id" or "ref". An error
+	// label needs an id or # suffix, so that a status such as "Error 500" does not pass for an identifier.
+	correlationID = regexp.MustCompile(`(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}|\b((request|correlation|trace|reference|ref|incident)[ _-]?(id|#)?|error[ _-]?(id|#))\s*[:=#]?\s*[\w-]*\d[\w-]*`)
+)
+
+// LintAvailabilityError checks the hygiene of an error returned in a BookingAvailabilityResponse. Problems are
+// returned as warnings: they make errors harder to act on, but the response is still valid.
+func LintAvailabilityError(e *pb.AvailabilityError) []Finding {
+	if e == nil {
+		return nil
+	}
+	return lintErrorMessage("error", e.GetType() == pb.AvailabilityError_UNKNOWN_ERROR, e.GetMessage())
+}
+
+// LintSubmitError checks the hygiene of an error returned in a BookingSubmitResponse, like LintAvailabilityError.
+func LintSubmitError(e *pb.SubmitError) []Finding {
+	if e == nil {
+		return nil
+	}
+	return lintErrorMessage("error", e.GetType() == pb.SubmitError_UNKNOWN_ERROR, e.GetMessage())
+}
+
+// lintErrorMessage ensures an error has a machine-readable type and a human readable message that includes a
+// correlation ID for support requests and leaks no stack traces or SQL.
+func lintErrorMessage(field string, unknownType bool, message string) []Finding {
+	var findings []Finding
+	add := func(field, msg, code string) {
+		f := Finding{Warning, field, msg, code}
+		log.Println(f)
+		findings = append(findings, f)
+	}
+	if unknownType {
+		add(field+" > type", "error type is UNKNOWN_ERROR; use the most specific type so the failure can be handled automatically", CodeErrorUnknownType)
+	}
+	if strings.TrimSpace(message) == "" {
+		add(field+" > message", "error message is empty", CodeErrorEmptyMessage)
+		return findings
+	}
+	for _, p := range stackTracePatterns {
+		if p.MatchString(message) {
+			add(field+" > message", "error message contains a stack trace", CodeErrorStackTrace)
+			break
+		}
+	}
+	for _, p := range sqlPatterns {
+		if p.MatchString(message) {
+			add(field+" > message", "error message contains SQL or a database error", CodeErrorSQL)
+			break
+		}
+	}
+	if !correlationID.MatchString(message) {
+		add(field+" > message", "error message does not include a correlation ID, e.g. \"request id: 7f3a2c91\"", CodeErrorCorrelationID)
+	}
+	return findings
+}
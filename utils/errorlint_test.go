@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestLintSubmitError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *pb.SubmitError
+		want []Finding
+	}{
+		{
+			name: "no error",
+		},
+		{
+			name: "clean error",
+			err:  &pb.SubmitError{Type: pb.SubmitError_ROOM_RATE_UNAVAILABLE, Message: "Room rate no longer available (request id: 7f3a2c91)"},
+		},
+		{
+			name: "uuid correlation id",
+			err:  &pb.SubmitError{Type: pb.SubmitError_PAYMENT_DECLINED, Message: "Card declined 2f1c7c9e-4b8e-4c2a-9d55-0a7e4d3b6f10"},
+		},
+		{
+			name: "error id",
+			err:  &pb.SubmitError{Type: pb.SubmitError_SUPPLIER_ERROR, Message: "Supplier unavailable, error id: E1234"},
+		},
+		{
+			name: "http status",
+			err:  &pb.SubmitError{Type: pb.SubmitError_SUPPLIER_ERROR, Message: "Supplier returned Error 500"},
+			want: []Finding{{Warning, "error > message", "error message does not include a correlation ID, e.g. \"request id: 7f3a2c91\"", CodeErrorCorrelationID}},
+		},
+		{
+			name: "unknown type and empty message",
+			err:  &pb.SubmitError{},
+			want: []Finding{
+				{Warning, "error > type", "error type is UNKNOWN_ERROR; use the most specific type so the failure can be handled automatically", CodeErrorUnknownType},
+				{Warning, "error > message", "error message is empty", CodeErrorEmptyMessage},
+			},
+		},
+		{
+			name: "java stack trace",
+			err: &pb.SubmitError{Type: pb.SubmitError_SUPPLIER_ERROR, Message: "java.lang.NullPointerException ref=E1234\n" +
+				"\tat com.example.booking.Submit.handle(Submit.java:42)"},
+			want: []Finding{{Warning, "error > message", "error message contains a stack trace", CodeErrorStackTrace}},
+		},
+		{
+			name: "leaked sql",
+			err:  &pb.SubmitError{Type: pb.SubmitError_SUPPLIER_ERROR, Message: "ERROR: syntax error at or near \"WHERE\": SELECT * FROM reservations WHERE id="},
+			want: []Finding{
+				{Warning, "error > message", "error message contains SQL or a database error", CodeErrorSQL},
+				{Warning, "error > message", "error message does not include a correlation ID, e.g. \"request id: 7f3a2c91\"", CodeErrorCorrelationID},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(LintSubmitError(tt.err), tt.want); diff != "" {
+				t.Errorf("LintSubmitError() did not match (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestLintAvailabilityError(t *testing.T) {
+	got := LintAvailabilityError(&pb.AvailabilityError{Type: pb.AvailabilityError_HOTEL_NOT_FOUND, Message: "Traceback (most recent call last):\n  File \"app.py\", line 12"})
+	want := []Finding{
+		{Warning, "error > message", "error message contains a stack trace", CodeErrorStackTrace},
+		{Warning, "error > message", "error message does not include a correlation ID, e.g. \"request id: 7f3a2c91\"", CodeErrorCorrelationID},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("LintAvailabilityError() did not match (-got +want)\n%s", diff)
+	}
+}
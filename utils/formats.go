@@ -0,0 +1,92 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// DefaultAPIVersion is the API version whose Formats are used for versions without registered Formats.
+const DefaultAPIVersion = 1
+
+// RFC3339Format provides the regular expression for validating an RFC 3339 timestamp such as 2019-04-03T15:00:00Z,
+// for API versions that exchange timestamps rather than dates.
+const RFC3339Format = `^\d{4}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01])T([01]\d|2[0-3]):[0-5]\d:[0-5]\d(\.\d+)?(Z|[+-]([01]\d|2[0-3]):[0-5]\d)$`
+
+// Formats are the regular expressions field values are validated against for one API version.
+type Formats struct {
+	// Date matches start_date and end_date.
+	Date string
+	// DateLayout is the time layout of Date, used to parse stay dates.
+	DateLayout string
+	// Country matches hotel_details > address > country.
+	Country string
+	// PolicyTime matches check_in_time and check_out_time.
+	PolicyTime string
+}
+
+var formats = map[int32]Formats{
+	1: {Date: DateFormat, DateLayout: "2006-01-02", Country: ISO3166, PolicyTime: PolicyTimeFormat},
+}
+
+// policyTimes are the compiled PolicyTime formats of each API version of formats.
+var policyTimes = map[int32]*regexp.Regexp{
+	1: regexp.MustCompile(PolicyTimeFormat),
+}
+
+// RegisterFormats sets the Formats of apiVersion, replacing any registered before. It must be called before
+// validation starts, e.g. from an init function.
+func RegisterFormats(apiVersion int32, f Formats) error {
+	for _, p := range []string{f.Date, f.Country} {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("invalid format for api_version %d: %v", apiVersion, err)
+		}
+	}
+	policyTime, err := regexp.Compile(f.PolicyTime)
+	if err != nil {
+		return fmt.Errorf("invalid format for api_version %d: %v", apiVersion, err)
+	}
+	if f.DateLayout == "" {
+		return fmt.Errorf("missing date layout for api_version %d", apiVersion)
+	}
+	formats[apiVersion] = f
+	policyTimes[apiVersion] = policyTime
+	return nil
+}
+
+// KnownAPIVersion reports whether Formats are registered for apiVersion, i.e. whether the validator supports it.
+func KnownAPIVersion(apiVersion int32) bool {
+	_, ok := formats[apiVersion]
+	return ok
+}
+
+// policyTimeFor returns the compiled PolicyTime format of apiVersion, falling back to that of DefaultAPIVersion.
+func policyTimeFor(apiVersion int32) *regexp.Regexp {
+	if r, ok := policyTimes[apiVersion]; ok {
+		return r
+	}
+	return policyTimes[DefaultAPIVersion]
+}
+
+// FormatsFor returns the Formats of apiVersion, falling back to those of DefaultAPIVersion.
+func FormatsFor(apiVersion int32) Formats {
+	if f, ok := formats[apiVersion]; ok {
+		return f
+	}
+	return formats[DefaultAPIVersion]
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestFormatsFor(t *testing.T) {
+	if got := FormatsFor(1).Date; got != DateFormat {
+		t.Errorf("FormatsFor(1).Date = %q, want DateFormat", got)
+	}
+	if !KnownAPIVersion(1) || KnownAPIVersion(99) {
+		t.Errorf("KnownAPIVersion(1), KnownAPIVersion(99) = %v, %v, want true, false", KnownAPIVersion(1), KnownAPIVersion(99))
+	}
+	if got := FormatsFor(99).Date; got != DateFormat {
+		t.Errorf("FormatsFor(99).Date = %q, want DateFormat of DefaultAPIVersion", got)
+	}
+	if err := RegisterFormats(98, Formats{Date: "[", DateLayout: "2006-01-02"}); err == nil {
+		t.Error("RegisterFormats() with an invalid pattern returned nil, want error")
+	}
+	if _, ok := formats[98]; ok {
+		t.Error("RegisterFormats() with an invalid pattern registered the formats")
+	}
+}
+
+func TestValidateBookingAvailabilityResponseFormats(t *testing.T) {
+	const version = 97
+	f := FormatsFor(DefaultAPIVersion)
+	f.Date, f.DateLayout = RFC3339Format, "2006-01-02T15:04:05Z07:00"
+	if err := RegisterFormats(version, f); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		delete(formats, version)
+		delete(policyTimes, version)
+	}()
+
+	data, err := BookingAvailabilityData()
+	if err != nil {
+		t.Fatalf("error fetching BookingAvailabilityData: %q", err)
+	}
+	if _, err := ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb); err != nil {
+		t.Fatalf("ValidateBookingAvailabilityResponse() of api_version 1 returned error %v, want nil", err)
+	}
+
+	data.ReqPb.ApiVersion, data.RespPb.ApiVersion = version, version
+	if _, err := ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb); err == nil {
+		t.Errorf("ValidateBookingAvailabilityResponse() of api_version %d with dates returned nil, want error", version)
+	}
+	data.ReqPb.StartDate, data.RespPb.StartDate = "2019-04-03T15:00:00Z", "2019-04-03T15:00:00Z"
+	data.ReqPb.EndDate, data.RespPb.EndDate = "2019-04-05T11:00:00Z", "2019-04-05T11:00:00Z"
+	if _, err := ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb); err != nil {
+		t.Errorf("ValidateBookingAvailabilityResponse() of api_version %d with timestamps returned error %v, want nil", version, err)
+	}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"log"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// MaxPlausibleRoomCount is the room_count above which a room rate is reported with a Warning, as no single hotel
+// sells that many rooms of one type and the value is more likely a sentinel or an unrelated identifier.
+var MaxPlausibleRoomCount int32 = 1000
+
+// validateRoomCounts checks the number of rooms left of each room rate that reports one.
+func validateRoomCounts(rates []*pb.RoomRate) ([]Finding, error) {
+	var findings []Finding
+	for i, r := range rates {
+		findings = append(findings, roomCountFindings(i, r)...)
+	}
+	return findings, roomCountsError(findings)
+}
+
+// roomCountFindings returns an Error finding if the room_count of r, the room rate at index i, is negative, or a
+// Warning finding if it is implausibly large. A room_count of 0 is the same as not reporting it.
+func roomCountFindings(i int, r *pb.RoomRate) []Finding {
+	field := fmt.Sprintf("room_rates[%d] > room_count", i)
+	var f Finding
+	switch c := r.GetRoomCount(); {
+	case c < 0:
+		f = Finding{Error, field, fmt.Sprintf("room_count %d must be a positive number of rooms, or omitted if unknown", c), CodeRoomCountNotPositive}
+	case c > MaxPlausibleRoomCount:
+		f = Finding{Warning, field, fmt.Sprintf("room_count %d is implausibly large, report the rooms actually left for the stay or omit it", c), CodeRoomCountLarge}
+	default:
+		return nil
+	}
+	log.Println(f)
+	return []Finding{f}
+}
+
+func roomCountsError(findings []Finding) error {
+	var errorFields []string
+	for _, f := range findings {
+		if f.Severity != Warning {
+			errorFields = append(errorFields, f.Field)
+		}
+	}
+	if len(errorFields) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid room_count in field(s): %s", joinFields(errorFields))
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidateRoomCounts(t *testing.T) {
+	cases := []struct {
+		name         string
+		count        int32
+		want         error
+		wantFindings int
+	}{
+		{name: "not reported"},
+		{name: "rooms left", count: 3},
+		{
+			name:         "negative",
+			count:        -1,
+			want:         fmt.Errorf("invalid room_count in field(s): room_rates[1] > room_count"),
+			wantFindings: 1,
+		},
+		{name: "implausibly large", count: 65535, wantFindings: 1},
+	}
+	for _, tc := range cases {
+		data, err := BookingAvailabilityData()
+		if err != nil {
+			t.Fatalf("error fetching BookingAvailabilityData: %q", err)
+		}
+		data.RespPb.RoomRates[1].RoomCount = tc.count
+		findings, got := ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb)
+		if diff := cmp.Diff(got, tc.want, equateErrorMessage); diff != "" {
+			t.Errorf("%s: unexpected error (diff -got +want): %s", tc.name, diff)
+		}
+		if len(findings) != tc.wantFindings {
+			t.Errorf("%s: got %d finding(s), want %d: %v", tc.name, len(findings), tc.wantFindings, findings)
+		}
+	}
+}
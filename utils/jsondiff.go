@@ -0,0 +1,112 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+)
+
+// MismatchPrefix starts the message of every echo field mismatch. It is followed by a line diff of the json encoding
+// of the field, each line prefixed with "- " if only in the response, "+ " if only in the request, or two spaces.
+const MismatchPrefix = "did not match (-got +want)\n"
+
+// jsonLines returns the indented json encoding of v, a proto message or a plain value, split into lines.
+func jsonLines(v interface{}) ([]string, bool) {
+	if rv := reflect.ValueOf(v); !rv.IsValid() || (rv.Kind() == reflect.Ptr && rv.IsNil()) {
+		return []string{"null"}, true
+	}
+	var s string
+	if m, ok := v.(proto.Message); ok {
+		var err error
+		if s, err = (&jsonpb.Marshaler{OrigName: true, Indent: "  "}).MarshalToString(m); err != nil {
+			return nil, false
+		}
+	} else {
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return nil, false
+		}
+		s = string(b)
+	}
+	// jsonpb writes an empty message as "{", an empty line and "}".
+	var lines []string
+	for _, l := range strings.Split(s, "\n") {
+		if l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return lines, true
+}
+
+// jsonDiff returns a line diff of the json encodings of got and want, or "" if they cannot be encoded or their
+// encodings are equal.
+func jsonDiff(got, want interface{}) string {
+	a, ok := jsonLines(got)
+	if !ok {
+		return ""
+	}
+	b, ok := jsonLines(want)
+	if !ok {
+		return ""
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var lines []string
+	changed := false
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, "  "+a[i])
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, "- "+a[i])
+			changed = true
+			i++
+		default:
+			lines = append(lines, "+ "+b[j])
+			changed = true
+			j++
+		}
+	}
+	if !changed {
+		return ""
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestJSONDiff(t *testing.T) {
+	got := &pb.RoomRate{Code: "a", LineItems: []*pb.RoomRate_LineItem{{Price: &pb.Price{Amount: 100, Currency: "USD"}}}}
+	want := &pb.RoomRate{Code: "a", LineItems: []*pb.RoomRate_LineItem{{Price: &pb.Price{Amount: 120, Currency: "USD"}}}}
+
+	if diff := cmp.Diff(jsonDiff(got, want), `  {
+    "code": "a",
+    "line_items": [
+      {
+        "price": {
+-         "amount": 100,
++         "amount": 120,
+          "currency": "USD"
+        }
+      }
+    ]
+  }`); diff != "" {
+		t.Errorf("jsonDiff() did not match (-got +want)\n%s", diff)
+	}
+	if d := jsonDiff("123", "456"); d != "- \"123\"\n+ \"456\"" {
+		t.Errorf("jsonDiff() of strings = %q", d)
+	}
+	if d := jsonDiff(got, got); d != "" {
+		t.Errorf("jsonDiff() of equal messages = %q, want none", d)
+	}
+	if d := jsonDiff((*pb.Price)(nil), &pb.Price{}); d != "- null\n+ {\n+ }" {
+		t.Errorf("jsonDiff() of a missing message = %q", d)
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// CheckJSONKeys inspects the json encoding of m in body and returns a Warning finding for each key repeated within
+// an object, naming the field under prefix, e.g. room_rates[3]. A field sent under both its proto and json name,
+// e.g. hotel_id and hotelId, is repeated too. jsonpb keeps the last value without an error, while other parsers keep
+// the first, so a repeated key is read differently along the way. If order is set, the first key of each object that
+// is out of the order of the schema is returned as a Warning as well; field order carries no meaning in json, so
+// this is only useful for partners whose own clients parse responses in order. Bodies that are not json are left to
+// jsonpb.
+func CheckJSONKeys(prefix string, body []byte, m proto.Message, order bool) []Finding {
+	var findings []Finding
+	dec := json.NewDecoder(bytes.NewReader(body))
+	c := keyChecker{dec: dec, order: order, findings: &findings}
+	if tok, err := dec.Token(); err == nil && tok == json.Delim('{') {
+		c.object(prefix, reflect.TypeOf(m).Elem())
+	}
+	for _, f := range findings {
+		log.Println(f)
+	}
+	return findings
+}
+
+type keyChecker struct {
+	dec      *json.Decoder
+	order    bool
+	findings *[]Finding
+}
+
+// protoFields returns the index in struct type t, a message, of each field by proto and json name.
+func protoFields(t reflect.Type) map[string]int {
+	fields := map[string]int{}
+	for i := 0; t != nil && i < t.NumField(); i++ {
+		for _, opt := range strings.Split(t.Field(i).Tag.Get("protobuf"), ",") {
+			if strings.HasPrefix(opt, "name=") || strings.HasPrefix(opt, "json=") {
+				fields[opt[5:]] = i
+			}
+		}
+	}
+	return fields
+}
+
+func join(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + " > " + name
+}
+
+// object checks the keys of the json object whose opening brace was just read, encoding a message of struct type t,
+// or of unknown type if t is nil. It returns false once the body can no longer be decoded.
+func (c *keyChecker) object(prefix string, t reflect.Type) bool {
+	fields := protoFields(t)
+	seen := map[string]string{}
+	last, lastKey, outOfOrder := -1, "", false
+	for c.dec.More() {
+		tok, err := c.dec.Token()
+		if err != nil {
+			return false
+		}
+		key, _ := tok.(string)
+		id := "key " + key
+		var ft reflect.Type
+		if i, ok := fields[key]; ok {
+			id = fmt.Sprintf("field %d", i)
+			ft = t.Field(i).Type
+			if c.order && !outOfOrder && i < last {
+				outOfOrder = true
+				*c.findings = append(*c.findings, Finding{Warning, join(prefix, key), fmt.Sprintf("key %s comes after %s, out of the order of the schema", key, lastKey), CodeJSONKeyOrder})
+			}
+			if i > last {
+				last, lastKey = i, key
+			}
+		}
+		if prev, ok := seen[id]; ok {
+			msg := fmt.Sprintf("key %s is repeated, the last value is used but other json parsers may use the first", key)
+			if prev != key {
+				msg = fmt.Sprintf("field is sent as both %s and %s, the last value is used but other json parsers may use the first", prev, key)
+			}
+			*c.findings = append(*c.findings, Finding{Warning, join(prefix, key), msg, CodeJSONRepeatedKey})
+		}
+		seen[id] = key
+		if !c.value(join(prefix, key), ft) {
+			return false
+		}
+	}
+	_, err := c.dec.Token()
+	return err == nil
+}
+
+// value checks the next json value, of Go type t or of unknown type if t is nil.
+func (c *keyChecker) value(field string, t reflect.Type) bool {
+	tok, err := c.dec.Token()
+	if err != nil {
+		return false
+	}
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch tok {
+	case json.Delim('{'):
+		if t != nil && t.Kind() != reflect.Struct {
+			t = nil
+		}
+		return c.object(field, t)
+	case json.Delim('['):
+		var elem reflect.Type
+		if t != nil && t.Kind() == reflect.Slice {
+			elem = t.Elem()
+		}
+		for j := 0; c.dec.More(); j++ {
+			if !c.value(fmt.Sprintf("%s[%d]", field, j), elem) {
+				return false
+			}
+		}
+		_, err := c.dec.Token()
+		return err == nil
+	}
+	return true
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestCheckJSONKeys(t *testing.T) {
+	cases := []struct {
+		body  string
+		order bool
+		want  []Finding
+	}{
+		{body: `{"api_version": 1, "hotel_id": "h", "room_rates": [{"code": "a"}, {"code": "b"}]}`, order: true},
+		{body: `{"hotel_id": "h", "api_version": 1}`},
+		{
+			body:  `{"hotel_id": "h", "api_version": 1, "start_date": "2019-06-01"}`,
+			order: true,
+			want:  []Finding{{Warning, "api_version", "key api_version comes after hotel_id, out of the order of the schema", CodeJSONKeyOrder}},
+		},
+		{
+			body: `{"hotel_id": "h", "hotelId": "g", "room_rates": [{"code": "a", "extra": {"x": 1, "x": 2}, "code": "b"}]}`,
+			want: []Finding{
+				{Warning, "hotelId", "field is sent as both hotel_id and hotelId, the last value is used but other json parsers may use the first", CodeJSONRepeatedKey},
+				{Warning, "room_rates[0] > extra > x", "key x is repeated, the last value is used but other json parsers may use the first", CodeJSONRepeatedKey},
+				{Warning, "room_rates[0] > code", "key code is repeated, the last value is used but other json parsers may use the first", CodeJSONRepeatedKey},
+			},
+		},
+		{body: `not json`},
+	}
+	for _, tc := range cases {
+		got := CheckJSONKeys("", []byte(tc.body), &pb.BookingAvailabilityResponse{}, tc.order)
+		if diff := cmp.Diff(got, tc.want); diff != "" {
+			t.Errorf("CheckJSONKeys(%s, %v) findings did not match (-got +want)\n%s", tc.body, tc.order, diff)
+		}
+	}
+}
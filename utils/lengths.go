@@ -0,0 +1,114 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// MaxFieldLengths maps the trailing components of a field path, with indexes replaced by [], to the most characters
+// the field should hold. Longer values may be truncated for display, cutting off hotel names and addresses or losing
+// the end of policies. The longest matching suffix applies, e.g. room_types[] > name > text for room_types[2] >
+// name > text. The v1 schema sets no maximum lengths, so these are validator defaults rather than documented
+// limits, except for email, the 254 characters of an address under RFC 5321 section 4.5.3.1. Callers may change
+// them before validation starts.
+var MaxFieldLengths = map[string]int{
+	"hotel_details > name":           255,
+	"hotel_details > phone_number":   32,
+	"hotel_details > email":          254,
+	"hotel_details > homepage_url":   2048,
+	"address > address1":             100,
+	"address > address2":             100,
+	"address > address3":             100,
+	"address > city":                 100,
+	"address > province":             100,
+	"address > postal_code":          20,
+	"room_types[] > name > text":     255,
+	"rate_plans[] > name > text":     255,
+	"description > text":             2000,
+	"unstructured_policies[] > text": 1000,
+	"locator > id":                   64,
+	"hotel_locators[] > id":          64,
+	"error > message":                1000,
+}
+
+// CheckFieldLengths returns a Warning for every string field of m, named under prefix, that is longer than its limit
+// in MaxFieldLengths.
+func CheckFieldLengths(prefix string, m proto.Message) []Finding {
+	var findings []Finding
+	checkLengths(prefix, reflect.ValueOf(m), &findings)
+	return findings
+}
+
+// maxFieldLength returns the limit of the field at path, or 0 if it has none.
+func maxFieldLength(path string) int {
+	parts := strings.Split(ruleIndex.ReplaceAllString(path, "[]"), " > ")
+	for i := range parts {
+		if max, ok := MaxFieldLengths[strings.Join(parts[i:], " > ")]; ok {
+			return max
+		}
+	}
+	return 0
+}
+
+// checkLengths walks v, a pointer to a generated message struct, checking its string fields and those of the
+// messages it contains.
+func checkLengths(prefix string, v reflect.Value, findings *[]Finding) {
+	if v.IsNil() {
+		return
+	}
+	v = v.Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := ""
+		for _, opt := range strings.Split(t.Field(i).Tag.Get("protobuf"), ",") {
+			if strings.HasPrefix(opt, "name=") {
+				name = opt[5:]
+			}
+		}
+		if name == "" {
+			continue
+		}
+		field := name
+		if prefix != "" {
+			field = prefix + " > " + name
+		}
+		f := v.Field(i)
+		switch {
+		case f.Kind() == reflect.String:
+			if max := maxFieldLength(field); max > 0 {
+				if n := utf8.RuneCountInString(f.String()); n > max {
+					finding := Finding{Warning, field, fmt.Sprintf("%d characters is longer than the validator default of %d, the value may be truncated for display", n, max), CodeFieldLength}
+					log.Println(finding)
+					*findings = append(*findings, finding)
+				}
+			}
+		case f.Kind() == reflect.Ptr && f.Type().Elem().Kind() == reflect.Struct:
+			checkLengths(field, f, findings)
+		case f.Kind() == reflect.Slice && f.Type().Elem().Kind() == reflect.Ptr:
+			for j := 0; j < f.Len(); j++ {
+				checkLengths(fmt.Sprintf("%s[%d]", field, j), f.Index(j), findings)
+			}
+		}
+	}
+}
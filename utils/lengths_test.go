@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestCheckFieldLengths(t *testing.T) {
+	resp := &pb.BookingAvailabilityResponse{
+		HotelDetails: &pb.HotelDetails{
+			Name:    strings.Repeat("é", 256),
+			Address: &pb.Address{Address1: strings.Repeat("a", 100), PostalCode: strings.Repeat("1", 21)},
+		},
+		RoomTypes: []*pb.RoomType{
+			{Name: &pb.DisplayString{Text: "Double"}},
+			{Name: &pb.DisplayString{Text: strings.Repeat("a", 300)}, Description: &pb.DisplayString{Text: strings.Repeat("a", 300)}},
+		},
+	}
+	want := []Finding{
+		{Warning, "room_types[1] > name > text", "300 characters is longer than the validator default of 255, the value may be truncated for display", CodeFieldLength},
+		{Warning, "hotel_details > name", "256 characters is longer than the validator default of 255, the value may be truncated for display", CodeFieldLength},
+		{Warning, "hotel_details > address > postal_code", "21 characters is longer than the validator default of 20, the value may be truncated for display", CodeFieldLength},
+	}
+	if diff := cmp.Diff(CheckFieldLengths("", resp), want); diff != "" {
+		t.Errorf("CheckFieldLengths() did not match (-got +want)\n%s", diff)
+	}
+
+	rate := &pb.RoomRate{Code: strings.Repeat("a", 300)}
+	if got := CheckFieldLengths("room_rates[0]", rate); got != nil {
+		t.Errorf("CheckFieldLengths() of a field without a limit = %v, want none", got)
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// LocatorFormat is the regular expression every reservation locator id must match. Partners with a stricter format
+// can override it, e.g. from the -locator_format flag.
+var LocatorFormat = `^[A-Za-z0-9_-]{6,64}$`
+
+// locatorField is a reservation locator id and the path of its field.
+type locatorField struct {
+	field string
+	id    string
+}
+
+// validateLocators ensures the reservation locator ids match LocatorFormat and are not trivially guessable, such as
+// a single repeated character or a counting sequence. Each invalid locator is returned as an Error finding, up to
+// MaxFindings.
+func validateLocators(locators []locatorField) ([]Finding, error) {
+	var tests []formatTest
+	for _, l := range locators {
+		if l.id != "" {
+			tests = append(tests, formatTest{l.field, l.id, LocatorFormat})
+		}
+	}
+	if findings, err := validateFormat(tests, CodeLocatorFormat); err != nil {
+		return findings, err
+	}
+
+	var errorFields []string
+	var findings []Finding
+	for _, l := range locators {
+		if trivialLocator(l.id) {
+			if !limitReached(len(errorFields)) {
+				f := Finding{Error, l.field, fmt.Sprintf("value %s is a trivial locator", l.id), CodeLocatorTrivial}
+				log.Println(f)
+				findings = append(findings, f)
+			}
+			errorFields = append(errorFields, l.field)
+		}
+	}
+	if len(errorFields) > 0 {
+		return findings, fmt.Errorf("trivial reservation locator(s) in field(s): %s", joinFields(errorFields))
+	}
+	return nil, nil
+}
+
+// trivialLocator reports whether id, ignoring separators, is one repeated character or an ascending or descending
+// run such as 123456 or abcdef.
+func trivialLocator(id string) bool {
+	s := strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(id))
+	if len(s) < 2 {
+		return s != ""
+	}
+	step := int(s[1]) - int(s[0])
+	if step < -1 || step > 1 {
+		return false
+	}
+	for i := 2; i < len(s); i++ {
+		if int(s[i])-int(s[i-1]) != step {
+			return false
+		}
+	}
+	return true
+}
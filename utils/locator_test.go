@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidateBookingSubmitLocators(t *testing.T) {
+	cases := []struct {
+		name         string
+		locator      string
+		hotelLocator string
+		format       string
+		want         error
+		wantCodes    []string
+	}{
+		{
+			name:         "sample locators",
+			locator:      "googleapi-e7fafbb0a132fb519d0e1b82b23dc794",
+			hotelLocator: "GB123-02ae0db95944feac57e4ee56be661975",
+		},
+		{
+			name:      "too short",
+			locator:   "A1",
+			want:      fmt.Errorf("error validating format for field(s): reservation > locator > id"),
+			wantCodes: []string{CodeLocatorFormat},
+		},
+		{
+			name:         "invalid charset",
+			locator:      "googleapi-e7fafbb0",
+			hotelLocator: "GB123/02ae 0db9",
+			want:         fmt.Errorf("error validating format for field(s): reservation > hotel_locators[0] > id"),
+			wantCodes:    []string{CodeLocatorFormat},
+		},
+		{
+			name:         "trivial",
+			locator:      "000000",
+			hotelLocator: "123-456",
+			want:         fmt.Errorf("trivial reservation locator(s) in field(s): reservation > locator > id, reservation > hotel_locators[0] > id"),
+			wantCodes:    []string{CodeLocatorTrivial, CodeLocatorTrivial},
+		},
+		{
+			name:      "custom format",
+			locator:   "googleapi-e7fafbb0a132fb519d0e1b82b23dc794",
+			format:    `^[A-Z]{2}\d{6}$`,
+			want:      fmt.Errorf("error validating format for field(s): reservation > locator > id"),
+			wantCodes: []string{CodeLocatorFormat},
+		},
+	}
+	defaultFormat := LocatorFormat
+	defer func() { LocatorFormat = defaultFormat }()
+	for _, tc := range cases {
+		data, err := BookingSubmitData()
+		if err != nil {
+			t.Fatalf("error fetching BookingSubmitData: %q", err)
+		}
+		data.RespPb.Reservation.Locator.Id = tc.locator
+		data.RespPb.Reservation.HotelLocators[0].Id = tc.hotelLocator
+		LocatorFormat = defaultFormat
+		if tc.format != "" {
+			LocatorFormat = tc.format
+		}
+		findings, got := ValidateBookingSubmitResponse(data.ReqPb, data.RespPb)
+		if diff := cmp.Diff(got, tc.want, equateErrorMessage); diff != "" {
+			t.Errorf("%s: unexpected error (diff -got +want): %s", tc.name, diff)
+		}
+		var codes []string
+		for _, f := range findings {
+			codes = append(codes, f.Code)
+		}
+		if diff := cmp.Diff(codes, tc.wantCodes); diff != "" {
+			t.Errorf("%s: unexpected finding codes (diff -got +want): %s", tc.name, diff)
+		}
+	}
+}
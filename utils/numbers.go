@@ -0,0 +1,165 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+)
+
+// CheckNumberEncoding inspects the json encoding of m in body and returns a finding for each numeric field encoded
+// as a string or in exponent notation, naming the field under prefix, e.g. room_rates[3]. Quoted numbers and floats
+// in exponent notation are accepted by jsonpb and returned as Warnings. Integers with an exponent or a fraction, and
+// strings that are not finite numbers, are returned as Errors along with an error listing their fields, since jsonpb
+// rejects them with a message that does not name the field. Bodies that are not json objects are left to jsonpb.
+func CheckNumberEncoding(prefix string, body []byte, m proto.Message) ([]Finding, error) {
+	var findings []Finding
+	checkNumbers(prefix, body, reflect.TypeOf(m).Elem(), &findings)
+
+	var errorFields []string
+	for _, f := range findings {
+		log.Println(f)
+		if f.Severity != Warning {
+			errorFields = append(errorFields, f.Field)
+		}
+	}
+	if len(errorFields) > 0 {
+		return findings, fmt.Errorf("invalid number encoding in field(s): %s", joinFields(errorFields))
+	}
+	return findings, nil
+}
+
+// checkNumbers walks the json object raw, encoding a message of struct type t, in the order of the message fields.
+func checkNumbers(prefix string, raw []byte, t reflect.Type, findings *[]Finding) {
+	var obj map[string]json.RawMessage
+	if json.Unmarshal(raw, &obj) != nil {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("protobuf")
+		if tag == "" || strings.Contains(tag, ",enum=") {
+			continue
+		}
+		name, value := "", json.RawMessage(nil)
+		for _, opt := range strings.Split(tag, ",") {
+			if strings.HasPrefix(opt, "name=") || strings.HasPrefix(opt, "json=") {
+				if v, ok := obj[opt[5:]]; ok {
+					name, value = opt[5:], v
+				}
+			}
+		}
+		if value == nil {
+			continue
+		}
+		field := name
+		if prefix != "" {
+			field = prefix + " > " + name
+		}
+
+		ft := sf.Type
+		if ft.Kind() != reflect.Slice {
+			checkValue(field, value, ft, findings)
+			continue
+		}
+		var elems []json.RawMessage
+		if json.Unmarshal(value, &elems) != nil {
+			continue
+		}
+		for j, e := range elems {
+			checkValue(fmt.Sprintf("%s[%d]", field, j), e, ft.Elem(), findings)
+		}
+	}
+}
+
+func checkValue(field string, raw json.RawMessage, t reflect.Type, findings *[]Finding) {
+	switch t.Kind() {
+	case reflect.Ptr:
+		if t.Elem().Kind() == reflect.Struct {
+			checkNumbers(field, raw, t.Elem(), findings)
+		}
+	case reflect.Int32, reflect.Int64, reflect.Uint32, reflect.Uint64:
+		checkNumber(field, raw, true, findings)
+	case reflect.Float32, reflect.Float64:
+		checkNumber(field, raw, false, findings)
+	}
+}
+
+// checkNumber adds a finding if raw, the value of a numeric field, is not a plain JSON number.
+func checkNumber(field string, raw json.RawMessage, integer bool, findings *[]Finding) {
+	s := strings.TrimSpace(string(raw))
+	if s == "null" {
+		return
+	}
+	report := func(severity Severity, code, format string, args ...interface{}) {
+		*findings = append(*findings, Finding{severity, field, fmt.Sprintf(format, args...), code})
+	}
+	kind := "number"
+	if integer {
+		kind = "integer"
+	}
+
+	if strings.HasPrefix(s, `"`) {
+		var text string
+		if json.Unmarshal(raw, &text) != nil {
+			return
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		switch {
+		case err != nil || math.IsNaN(v) || math.IsInf(v, 0):
+			report(Error, CodeNumberNotFinite, "value %s is not a finite %s; encode it as a JSON %s, e.g. %s", s, kind, kind, example(integer))
+		case integer && (v != math.Trunc(v) || strings.ContainsAny(text, ".eE")):
+			report(Error, CodeNumberNotInteger, "value %s is not a plain integer; encode it as a JSON integer, e.g. %s", s, example(integer))
+		default:
+			report(Warning, CodeNumberAsString, "%s is encoded as the string %s; encode it as a JSON number, e.g. %s", kind, s, plain(v))
+		}
+		return
+	}
+
+	if !strings.ContainsAny(s, ".eE") {
+		return
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	switch {
+	case err != nil:
+		return
+	case integer && v == math.Trunc(v):
+		report(Error, CodeIntegerWithFraction, "integer is encoded as %s, which fails to parse; encode it without a fraction or exponent, e.g. %s", s, plain(v))
+	case integer:
+		report(Error, CodeIntegerFractional, "value %s is not an integer; encode it as a JSON integer, e.g. %s", s, example(integer))
+	case strings.ContainsAny(s, "eE"):
+		report(Warning, CodeNumberExponent, "number is encoded in exponent notation as %s; encode it in plain decimal notation, e.g. %s", s, plain(v))
+	}
+}
+
+func plain(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func example(integer bool) string {
+	if integer {
+		return "2"
+	}
+	return "123.45"
+}
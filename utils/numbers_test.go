@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestCheckNumberEncoding(t *testing.T) {
+	cases := []struct {
+		body    string
+		want    []Finding
+		wantErr bool
+	}{
+		{body: `{"api_version": 1, "party": {"adults": 2, "children": [7]}, "room_rates": [{"total_price_at_booking": {"amount": 123.45}}]}`},
+		{
+			body: `{"api_version": "1", "room_rates": [{}, {"line_items": [{"price": {"amount": 1.2345e2}}]}]}`,
+			want: []Finding{
+				{Warning, "api_version", `integer is encoded as the string "1"; encode it as a JSON number, e.g. 1`, CodeNumberAsString},
+				{Warning, "room_rates[1] > line_items[0] > price > amount", "number is encoded in exponent notation as 1.2345e2; encode it in plain decimal notation, e.g. 123.45", CodeNumberExponent},
+			},
+		},
+		{
+			body: `{"party": {"adults": 2e0, "children": ["7", 7.5]}, "room_rates": [{"totalPriceAtBooking": {"amount": "$12.00"}}]}`,
+			want: []Finding{
+				{Error, "party > adults", "integer is encoded as 2e0, which fails to parse; encode it without a fraction or exponent, e.g. 2", CodeIntegerWithFraction},
+				{Warning, "party > children[0]", `integer is encoded as the string "7"; encode it as a JSON number, e.g. 7`, CodeNumberAsString},
+				{Error, "party > children[1]", "value 7.5 is not an integer; encode it as a JSON integer, e.g. 2", CodeIntegerFractional},
+				{Error, "room_rates[0] > totalPriceAtBooking > amount", `value "$12.00" is not a finite number; encode it as a JSON number, e.g. 123.45`, CodeNumberNotFinite},
+			},
+			wantErr: true,
+		},
+		{body: `not json`},
+	}
+	for _, tc := range cases {
+		got, err := CheckNumberEncoding("", []byte(tc.body), &pb.BookingAvailabilityResponse{})
+		if (err != nil) != tc.wantErr {
+			t.Errorf("CheckNumberEncoding(%s) returned error %v, want error %v", tc.body, err, tc.wantErr)
+		}
+		if diff := cmp.Diff(got, tc.want); diff != "" {
+			t.Errorf("CheckNumberEncoding(%s) findings did not match (-got +want)\n%s", tc.body, diff)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+const (
+	// minCardDigits is the length of the shortest card number, shorter card_numbers such as the ---PAN---
+	// placeholder of the sample request are not searched for in responses.
+	minCardDigits = 12
+	// maxRevealedDigits is the number of digits a masked card number may reveal.
+	maxRevealedDigits = 4
+)
+
+// maskedCardNumber matches a card number of which some digits are masked, e.g. XXXXXXXXXXXX1234 or **** **** 1234.
+var maskedCardNumber = regexp.MustCompile(`(?:[\dXx*•#][ -]?){12,19}`)
+
+// LintPayment checks the payment of a BookingSubmitRequest: a PAYMENT_CARD guarantee must carry exactly one of
+// payment_card_parameters or payment_token, and the card must have a known card_type and must not have expired at
+// now. Problems are returned as warnings, as they are in the request rather than in the partner's response, but
+// explain why a partner rejects the booking.
+func LintPayment(req *pb.BookingSubmitRequest, now time.Time) []Finding {
+	p := req.GetPayment()
+	if p == nil {
+		return nil
+	}
+	var findings []Finding
+	add := func(field, msg, code string) {
+		f := Finding{Warning, field, msg, code}
+		log.Println(f)
+		findings = append(findings, f)
+	}
+
+	c := p.GetPaymentCardParameters()
+	methods := 0
+	if c != nil {
+		methods++
+	}
+	if p.GetPaymentToken() != "" {
+		methods++
+	}
+	switch {
+	case p.GetType() == pb.GuaranteeType_PAYMENT_CARD && methods == 0:
+		add("payment", "type is PAYMENT_CARD but neither payment_card_parameters nor payment_token is set", CodePaymentMissing)
+	case methods > 1:
+		add("payment", "both payment_card_parameters and payment_token are set; set exactly one payment method", CodePaymentBoth)
+	case p.GetType() != pb.GuaranteeType_PAYMENT_CARD && methods > 0:
+		add("payment > type", fmt.Sprintf("type is %v but a payment method is set", p.GetType()), CodePaymentUnexpected)
+	}
+	if c == nil {
+		return findings
+	}
+
+	if _, ok := pb.CardType_name[int32(c.GetCardType())]; !ok {
+		add("payment > payment_card_parameters > card_type", fmt.Sprintf("unknown card type %d", c.GetCardType()), CodePaymentCardType)
+	}
+	month, merr := strconv.Atoi(c.GetExpirationMonth())
+	if merr != nil || len(c.GetExpirationMonth()) != 2 || month < 1 || month > 12 {
+		add("payment > payment_card_parameters > expiration_month", fmt.Sprintf("expiration month %q is not formatted MM", c.GetExpirationMonth()), CodePaymentMonth)
+	}
+	year, yerr := strconv.Atoi(c.GetExpirationYear())
+	if yerr != nil || len(c.GetExpirationYear()) != 4 {
+		add("payment > payment_card_parameters > expiration_year", fmt.Sprintf("expiration year %q is not formatted YYYY", c.GetExpirationYear()), CodePaymentYear)
+	}
+	if merr == nil && yerr == nil && month >= 1 && month <= 12 {
+		// Cards are valid through the last day of their expiration month.
+		if expiry := time.Date(year, time.Month(month)+1, 1, 0, 0, 0, 0, time.UTC); !now.Before(expiry) {
+			add("payment > payment_card_parameters", fmt.Sprintf("card expired in %s/%s", c.GetExpirationMonth(), c.GetExpirationYear()), CodePaymentExpired)
+		}
+	}
+	return findings
+}
+
+// digits returns the decimal digits of s.
+func digits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+// ValidateCardMasking ensures the raw body of a BookingSubmitResponse does not leak the card number of req: the full
+// number must not appear, with or without separators, and masked card numbers may only reveal the last four digits.
+func ValidateCardMasking(req *pb.BookingSubmitRequest, body string) ([]Finding, error) {
+	var findings []Finding
+	add := func(s Severity, msg, code string) {
+		f := Finding{s, "payment > payment_card_parameters > card_number", msg, code}
+		log.Println(f)
+		findings = append(findings, f)
+	}
+
+	pan := digits(req.GetPayment().GetPaymentCardParameters().GetCardNumber())
+	if len(pan) >= minCardDigits {
+		full := regexp.MustCompile(strings.Join(strings.Split(pan, ""), `[ -]?`))
+		if full.MatchString(body) {
+			add(Critical, "the response contains the full card number sent in the request", CodePaymentFullNumber)
+		}
+	}
+	for _, m := range maskedCardNumber.FindAllString(body, -1) {
+		d := digits(m)
+		if d == strings.NewReplacer(" ", "", "-", "").Replace(m) {
+			// Not masked at all, e.g. a long numeric id.
+			continue
+		}
+		m = strings.TrimRight(m, " -")
+		switch {
+		case len(d) > maxRevealedDigits:
+			add(Error, fmt.Sprintf("a masked card number in the response reveals %d digits; reveal at most the last %d", len(d), maxRevealedDigits), CodePaymentRevealsDigits)
+		case d != "" && len(pan) >= minCardDigits && (!strings.HasSuffix(m, d) || !strings.HasSuffix(pan, d)):
+			add(Error, fmt.Sprintf("a masked card number in the response reveals %d digit(s) that are not the last digits of the card number", len(d)), CodePaymentRevealsDigits)
+		}
+	}
+	if len(findings) > 0 {
+		return findings, fmt.Errorf("the response reveals the card number of the request")
+	}
+	return nil, nil
+}
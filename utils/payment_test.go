@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestLintPayment(t *testing.T) {
+	now := time.Date(2026, 5, 15, 0, 0, 0, 0, time.UTC)
+	card := func(month, year string) *pb.BookingSubmitRequest_Payment_PaymentCardParameters {
+		return &pb.BookingSubmitRequest_Payment_PaymentCardParameters{CardType: pb.CardType_VI, CardNumber: "4111111111111111", ExpirationMonth: month, ExpirationYear: year}
+	}
+	tests := []struct {
+		name    string
+		payment *pb.BookingSubmitRequest_Payment
+		want    []Finding
+	}{
+		{
+			name: "no payment",
+		},
+		{
+			name:    "valid card",
+			payment: &pb.BookingSubmitRequest_Payment{Type: pb.GuaranteeType_PAYMENT_CARD, PaymentCardParameters: card("05", "2026")},
+		},
+		{
+			name:    "token",
+			payment: &pb.BookingSubmitRequest_Payment{Type: pb.GuaranteeType_PAYMENT_CARD, PaymentToken: "token"},
+		},
+		{
+			name:    "no guarantee",
+			payment: &pb.BookingSubmitRequest_Payment{Type: pb.GuaranteeType_NO_GUARANTEE},
+		},
+		{
+			name:    "no payment method",
+			payment: &pb.BookingSubmitRequest_Payment{Type: pb.GuaranteeType_PAYMENT_CARD},
+			want:    []Finding{{Warning, "payment", "type is PAYMENT_CARD but neither payment_card_parameters nor payment_token is set", CodePaymentMissing}},
+		},
+		{
+			name:    "card and token",
+			payment: &pb.BookingSubmitRequest_Payment{Type: pb.GuaranteeType_PAYMENT_CARD, PaymentCardParameters: card("12", "2030"), PaymentToken: "token"},
+			want:    []Finding{{Warning, "payment", "both payment_card_parameters and payment_token are set; set exactly one payment method", CodePaymentBoth}},
+		},
+		{
+			name:    "method without guarantee",
+			payment: &pb.BookingSubmitRequest_Payment{Type: pb.GuaranteeType_NO_GUARANTEE, PaymentToken: "token"},
+			want:    []Finding{{Warning, "payment > type", "type is NO_GUARANTEE but a payment method is set", CodePaymentUnexpected}},
+		},
+		{
+			name:    "expired card",
+			payment: &pb.BookingSubmitRequest_Payment{Type: pb.GuaranteeType_PAYMENT_CARD, PaymentCardParameters: card("04", "2026")},
+			want:    []Finding{{Warning, "payment > payment_card_parameters", "card expired in 04/2026", CodePaymentExpired}},
+		},
+		{
+			name: "malformed card",
+			payment: &pb.BookingSubmitRequest_Payment{Type: pb.GuaranteeType_PAYMENT_CARD, PaymentCardParameters: &pb.BookingSubmitRequest_Payment_PaymentCardParameters{
+				CardType: pb.CardType(9), ExpirationMonth: "5", ExpirationYear: "26",
+			}},
+			want: []Finding{
+				{Warning, "payment > payment_card_parameters > card_type", "unknown card type 9", CodePaymentCardType},
+				{Warning, "payment > payment_card_parameters > expiration_month", "expiration month \"5\" is not formatted MM", CodePaymentMonth},
+				{Warning, "payment > payment_card_parameters > expiration_year", "expiration year \"26\" is not formatted YYYY", CodePaymentYear},
+				{Warning, "payment > payment_card_parameters", "card expired in 5/26", CodePaymentExpired},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(LintPayment(&pb.BookingSubmitRequest{Payment: tt.payment}, now), tt.want); diff != "" {
+				t.Errorf("LintPayment() did not match (-got +want)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateCardMasking(t *testing.T) {
+	req := &pb.BookingSubmitRequest{Payment: &pb.BookingSubmitRequest_Payment{
+		PaymentCardParameters: &pb.BookingSubmitRequest_Payment_PaymentCardParameters{CardNumber: "4111 1111 1111 1234"},
+	}}
+	tests := []struct {
+		name string
+		body string
+		want []Finding
+	}{
+		{
+			name: "no card number",
+			body: `{"reservation":{"locator":{"id":"123456789012345"}}}`,
+		},
+		{
+			name: "last four digits",
+			body: `{"reservation":{"locator":{"id":"ABC123"},"notes":"card XXXX-XXXX-XXXX-1234"}}`,
+		},
+		{
+			name: "full card number",
+			body: `{"reservation":{"notes":"card 4111-1111-1111-1234"}}`,
+			want: []Finding{{Critical, "payment > payment_card_parameters > card_number", "the response contains the full card number sent in the request", CodePaymentFullNumber}},
+		},
+		{
+			name: "first four digits",
+			body: `{"reservation":{"notes":"card 4111-XXXX-XXXX-XXXX"}}`,
+			want: []Finding{{Error, "payment > payment_card_parameters > card_number", "a masked card number in the response reveals 4 digit(s) that are not the last digits of the card number", CodePaymentRevealsDigits}},
+		},
+		{
+			name: "last four digits of another card",
+			body: `{"reservation":{"notes":"card XXXX-XXXX-XXXX-5678"}}`,
+			want: []Finding{{Error, "payment > payment_card_parameters > card_number", "a masked card number in the response reveals 4 digit(s) that are not the last digits of the card number", CodePaymentRevealsDigits}},
+		},
+		{
+			name: "first six and last four digits",
+			body: `{"reservation":{"notes":"card 411111******1234"}}`,
+			want: []Finding{{Error, "payment > payment_card_parameters > card_number", "a masked card number in the response reveals 10 digits; reveal at most the last 4", CodePaymentRevealsDigits}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateCardMasking(req, tt.body)
+			if diff := cmp.Diff(got, tt.want); diff != "" {
+				t.Errorf("ValidateCardMasking() did not match (-got +want)\n%s", diff)
+			}
+			if (err != nil) != (tt.want != nil) {
+				t.Errorf("ValidateCardMasking() returned error %v, want error %v", err, tt.want != nil)
+			}
+		})
+	}
+}
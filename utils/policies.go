@@ -0,0 +1,119 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"unicode/utf8"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// MaxPolicyTextLength is the longest unstructured policy text, in characters, accepted for display.
+const MaxPolicyTextLength = 1000
+
+// PolicyTimeFormat matches the ISO 8601 hh:mm or hh:mm+/-hh:mm format of check-in and check-out times.
+const PolicyTimeFormat = `^([01]\d|2[0-3]):[0-5]\d([+-]([01]\d|2[0-3]):[0-5]\d)?$`
+
+// validateHotelPolicies checks the property-level policies of resp when they are provided: check-in and check-out
+// times are well formed, max_child_age is a plausible age, amounts charged at booking, such as deposits, carry a
+// currency, and policy texts fit within MaxPolicyTextLength. Each invalid field is returned as an Error finding.
+func validateHotelPolicies(resp *pb.BookingAvailabilityResponse) ([]Finding, error) {
+	findings := propertyPolicyFindings(resp)
+	for i, r := range resp.GetRoomRates() {
+		findings = append(findings, chargesWithoutCurrency(i, r)...)
+	}
+	return findings, hotelPoliciesError(findings)
+}
+
+// propertyPolicyFindings returns an Error finding for each invalid field of the property-level policies of resp.
+func propertyPolicyFindings(resp *pb.BookingAvailabilityResponse) []Finding {
+	var findings []Finding
+	add := func(field, msg string) {
+		f := Finding{Error, field, msg, CodePropertyPolicy}
+		log.Println(f)
+		findings = append(findings, f)
+	}
+
+	p := resp.GetHotelDetails().GetPolicies()
+	policyTime := policyTimeFor(resp.GetApiVersion())
+	for _, t := range []struct{ field, value string }{
+		{"hotel_details > policies > check_in_time", p.GetCheckInTime()},
+		{"hotel_details > policies > check_out_time", p.GetCheckOutTime()},
+	} {
+		if t.value != "" && !policyTime.MatchString(t.value) {
+			add(t.field, fmt.Sprintf("%s did not match pattern %v", t.value, policyTime))
+		}
+	}
+	if age := p.GetMaxChildAge(); age < 0 || age >= 18 {
+		add("hotel_details > policies > max_child_age", fmt.Sprintf("%d is not an age between 0 and 17", age))
+	}
+
+	for _, t := range []struct {
+		prefix string
+		texts  []*pb.DisplayString
+	}{
+		{"hotel_details > policies > unstructured_policies", p.GetUnstructuredPolicies()},
+		{"policies > unstructured_policies", resp.GetPolicies().GetUnstructuredPolicies()},
+	} {
+		for i, d := range t.texts {
+			if n := utf8.RuneCountInString(d.GetText()); n > MaxPolicyTextLength {
+				add(fmt.Sprintf("%s[%d] > text", t.prefix, i), fmt.Sprintf("is %d characters long, more than %d", n, MaxPolicyTextLength))
+			}
+		}
+	}
+
+	return findings
+}
+
+// chargesWithoutCurrency returns an Error finding for each currency field missing from the amounts of r, the room
+// rate at index i, charged at booking.
+func chargesWithoutCurrency(i int, r *pb.RoomRate) []Finding {
+	charges := map[string]*pb.Price{fmt.Sprintf("room_rates[%d] > total_price_at_booking", i): r.GetTotalPriceAtBooking()}
+	for j, l := range r.GetLineItems() {
+		if !l.GetPaidAtCheckout() {
+			charges[fmt.Sprintf("room_rates[%d] > line_items[%d] > price", i, j)] = l.GetPrice()
+		}
+	}
+	var missing []string
+	for field, price := range charges {
+		if price.GetAmount() != 0 && price.GetCurrency() == "" {
+			missing = append(missing, field+" > currency")
+		}
+	}
+	sort.Strings(missing)
+	var findings []Finding
+	for _, field := range missing {
+		f := Finding{Error, field, "an amount charged at booking has no currency", CodeCurrencyMissing}
+		log.Println(f)
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+func hotelPoliciesError(findings []Finding) error {
+	if len(findings) > 0 {
+		errorFields := make([]string, len(findings))
+		for i, f := range findings {
+			errorFields[i] = f.Field
+		}
+		return fmt.Errorf("invalid property policies in field(s): %s", joinFields(errorFields))
+	}
+	return nil
+}
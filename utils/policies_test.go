@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestValidateHotelPolicies(t *testing.T) {
+	cases := []struct {
+		name   string
+		modify func(resp *pb.BookingAvailabilityResponse)
+		want   error
+	}{
+		{
+			name:   "sample response",
+			modify: func(resp *pb.BookingAvailabilityResponse) {},
+		},
+		{
+			name: "check-in time with offset",
+			modify: func(resp *pb.BookingAvailabilityResponse) {
+				resp.HotelDetails.Policies = &pb.HotelDetails_HotelPolicies{CheckInTime: "15:00+01:00", CheckOutTime: "11:00"}
+			},
+		},
+		{
+			name: "malformed check-out time",
+			modify: func(resp *pb.BookingAvailabilityResponse) {
+				resp.HotelDetails.Policies = &pb.HotelDetails_HotelPolicies{CheckOutTime: "11am"}
+			},
+			want: fmt.Errorf("invalid property policies in field(s): hotel_details > policies > check_out_time"),
+		},
+		{
+			name: "implausible max child age",
+			modify: func(resp *pb.BookingAvailabilityResponse) {
+				resp.HotelDetails.Policies = &pb.HotelDetails_HotelPolicies{MaxChildAge: 99}
+			},
+			want: fmt.Errorf("invalid property policies in field(s): hotel_details > policies > max_child_age"),
+		},
+		{
+			name: "policy text too long",
+			modify: func(resp *pb.BookingAvailabilityResponse) {
+				resp.HotelDetails.Policies = &pb.HotelDetails_HotelPolicies{
+					UnstructuredPolicies: []*pb.DisplayString{{Text: "No pets.", Language: "en"}, {Text: strings.Repeat("x", MaxPolicyTextLength+1), Language: "en"}},
+				}
+			},
+			want: fmt.Errorf("invalid property policies in field(s): hotel_details > policies > unstructured_policies[1] > text"),
+		},
+		{
+			name: "deposit without currency",
+			modify: func(resp *pb.BookingAvailabilityResponse) {
+				resp.RoomRates[1].TotalPriceAtBooking.Currency = ""
+				resp.RoomRates[1].LineItems[1].Price.Currency = ""
+			},
+			want: fmt.Errorf("invalid property policies in field(s): room_rates[1] > line_items[1] > price > currency, room_rates[1] > total_price_at_booking > currency"),
+		},
+	}
+	for _, tc := range cases {
+		data, err := BookingAvailabilityData()
+		if err != nil {
+			t.Fatalf("error fetching BookingAvailabilityData: %q", err)
+		}
+		tc.modify(data.RespPb)
+		findings, got := validateHotelPolicies(data.RespPb)
+		if diff := cmp.Diff(got, tc.want, equateErrorMessage); diff != "" {
+			t.Errorf("%s: unexpected error (diff -got +want): %s", tc.name, diff)
+		}
+		if (len(findings) > 0) != (tc.want != nil) {
+			t.Errorf("%s: got findings %v, want one per invalid field", tc.name, findings)
+		}
+		for _, f := range findings {
+			if f.Severity != Error || f.Code != CodePropertyPolicy && f.Code != CodeCurrencyMissing {
+				t.Errorf("%s: got finding %v, want an Error with code %s or %s", tc.name, f, CodePropertyPolicy, CodeCurrencyMissing)
+			}
+		}
+	}
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// currencyDecimals are the ISO 4217 minor units of the currencies with other than two decimals.
+var currencyDecimals = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0, "PYG": 0,
+	"RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// decimalsOf returns the number of decimals amounts in currency may have.
+func decimalsOf(currency string) int {
+	if d, ok := currencyDecimals[currency]; ok {
+		return d
+	}
+	return 2
+}
+
+// roundingMode is a way of rounding an amount to the decimals of its currency.
+type roundingMode int
+
+const (
+	halfUp roundingMode = iota
+	halfEven
+	down
+	up
+)
+
+// roundingModes are the rounding modes totals are matched against.
+var roundingModes = []roundingMode{halfUp, halfEven, down, up}
+
+func (m roundingMode) String() string {
+	return [...]string{"half up", "half even", "down", "up"}[m]
+}
+
+// amountText returns the decimal an amount was most likely sent as: the shortest one parsing to the same float32.
+func amountText(amount float32) string {
+	return strconv.FormatFloat(float64(amount), 'f', -1, 32)
+}
+
+// decimalPlaces returns the number of digits after the decimal point of s, as returned by amountText.
+func decimalPlaces(s string) int {
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return len(s) - i - 1
+	}
+	return 0
+}
+
+// exactAmount returns the amount of p as the exact decimal it was most likely sent as, or nil if it is not finite.
+func exactAmount(p *pb.Price) *big.Rat {
+	a := p.GetAmount()
+	if math.IsNaN(float64(a)) || math.IsInf(float64(a), 0) {
+		return nil
+	}
+	x, _ := new(big.Rat).SetString(amountText(a))
+	return x
+}
+
+// roundAt returns x rounded to d decimals with mode m, symmetrically around zero.
+func roundAt(x *big.Rat, d int, m roundingMode) *big.Rat {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(d)), nil)
+	q, r := new(big.Int).QuoRem(new(big.Int).Mul(x.Num(), scale), x.Denom(), new(big.Int))
+	if r.Sign() != 0 {
+		half := new(big.Int).Lsh(new(big.Int).Abs(r), 1).Cmp(x.Denom())
+		var away bool
+		switch m {
+		case halfUp:
+			away = half >= 0
+		case halfEven:
+			away = half > 0 || half == 0 && q.Bit(0) == 1
+		case up:
+			away = true
+		}
+		if away {
+			q.Add(q, big.NewInt(int64(r.Sign())))
+		}
+	}
+	return new(big.Rat).SetFrac(q, scale)
+}
+
+// decimalString formats x with the decimals of its currency, or more if x has more.
+func decimalString(x *big.Rat, d int) string {
+	for n := d; n < 20; n++ {
+		if roundAt(x, n, down).Cmp(x) == 0 {
+			return x.FloatString(n)
+		}
+	}
+	return x.FloatString(20)
+}
+
+// validateAmountPrecision checks the amounts of each room rate against the decimals of their currencies, and reports
+// totals that are the sum of their line items only when rounded inconsistently.
+func validateAmountPrecision(rates []*pb.RoomRate) ([]Finding, error) {
+	var findings []Finding
+	for i, r := range rates {
+		findings = append(findings, precisionFindings(i, r)...)
+	}
+	return findings, precisionError(findings)
+}
+
+// precisionFindings returns an Error finding for each amount of r, the room rate at index i, with more decimals than
+// its currency permits, naming how half up and half even rounding tell it apart, and a Warning for each total that
+// differs from the sum of its line items unless they are rounded another way than the total, naming that rounding.
+// Amounts without a currency are not checked.
+func precisionFindings(i int, r *pb.RoomRate) []Finding {
+	var findings []Finding
+	report := func(s Severity, field, msg, code string) {
+		f := Finding{s, field, msg, code}
+		log.Println(f)
+		findings = append(findings, f)
+	}
+	prefix := fmt.Sprintf("room_rates[%d] > ", i)
+	check := func(field string, p *pb.Price) {
+		x := exactAmount(p)
+		if x == nil || p.GetCurrency() == "" {
+			return
+		}
+		text, d := amountText(p.GetAmount()), decimalsOf(p.GetCurrency())
+		n := decimalPlaces(text)
+		if n <= d {
+			return
+		}
+		msg := fmt.Sprintf("amount %s has %d decimal(s), %s amounts have %d", text, n, p.GetCurrency(), d)
+		if hu, he := roundAt(x, d, halfUp), roundAt(x, d, halfEven); hu.Cmp(he) != 0 {
+			msg += fmt.Sprintf("; rounded half up it is %s but half even %s, so send the rounded amount", hu.FloatString(d), he.FloatString(d))
+		} else {
+			msg += fmt.Sprintf("; send the rounded amount %s", hu.FloatString(d))
+		}
+		report(Error, prefix+field, msg, CodePricePrecision)
+	}
+
+	check("total_price_at_booking", r.GetTotalPriceAtBooking())
+	check("total_price_at_checkout", r.GetTotalPriceAtCheckout())
+	var atBooking, atCheckout []*pb.Price
+	for j, l := range r.GetLineItems() {
+		check(fmt.Sprintf("line_items[%d] > price", j), l.GetPrice())
+		if l.GetPaidAtCheckout() {
+			atCheckout = append(atCheckout, l.GetPrice())
+		} else {
+			atBooking = append(atBooking, l.GetPrice())
+		}
+	}
+	for j, c := range r.GetCancellationRules() {
+		check(fmt.Sprintf("cancellation_rules[%d] > penalty", j), c.GetPenalty())
+	}
+
+	for _, t := range []struct {
+		field string
+		total *pb.Price
+		items []*pb.Price
+	}{
+		{"total_price_at_booking", r.GetTotalPriceAtBooking(), atBooking},
+		{"total_price_at_checkout", r.GetTotalPriceAtCheckout(), atCheckout},
+	} {
+		if msg := roundingMismatch(t.total, t.items); msg != "" {
+			report(Warning, prefix+t.field, msg, CodePriceRounding)
+		}
+	}
+	return findings
+}
+
+// roundingMismatch describes how total matches the sum of items, prices in its currency, only when they are rounded
+// another way than rounding their exact sum once half up or half even, or returns "" if it does not or need not.
+func roundingMismatch(total *pb.Price, items []*pb.Price) string {
+	want := exactAmount(total)
+	if want == nil || total.GetCurrency() == "" || len(items) == 0 {
+		return ""
+	}
+	d := decimalsOf(total.GetCurrency())
+	exact := new(big.Rat)
+	perItem := make([]*big.Rat, len(roundingModes))
+	for k := range perItem {
+		perItem[k] = new(big.Rat)
+	}
+	for _, p := range items {
+		x := exactAmount(p)
+		if x == nil || p.GetCurrency() != total.GetCurrency() {
+			return ""
+		}
+		exact.Add(exact, x)
+		for k, m := range roundingModes {
+			perItem[k].Add(perItem[k], roundAt(x, d, m))
+		}
+	}
+	if want.Cmp(exact) == 0 || want.Cmp(roundAt(exact, d, halfUp)) == 0 || want.Cmp(roundAt(exact, d, halfEven)) == 0 {
+		return ""
+	}
+	consistent := fmt.Sprintf("the exact sum of its line_items, %s, rounds half up to %s", decimalString(exact, d), roundAt(exact, d, halfUp).FloatString(d))
+	for k, m := range roundingModes {
+		if want.Cmp(perItem[k]) == 0 {
+			return fmt.Sprintf("amount %s matches its line_items only when each is rounded %s to %d decimal(s) before they are added; %s", amountText(total.GetAmount()), m, d, consistent)
+		}
+	}
+	for _, m := range []roundingMode{down, up} {
+		if want.Cmp(roundAt(exact, d, m)) == 0 {
+			return fmt.Sprintf("amount %s matches its line_items only when their sum is rounded %s to %d decimal(s); %s", amountText(total.GetAmount()), m, d, consistent)
+		}
+	}
+	return ""
+}
+
+func precisionError(findings []Finding) error {
+	var errorFields []string
+	for _, f := range findings {
+		if f.Severity != Warning {
+			errorFields = append(errorFields, f.Field)
+		}
+	}
+	if len(errorFields) == 0 {
+		return nil
+	}
+	return fmt.Errorf("amount(s) with more decimals than their currency has in field(s): %s", joinFields(errorFields))
+}
@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestRoundAt(t *testing.T) {
+	cases := []struct {
+		x    string
+		d    int
+		m    roundingMode
+		want string
+	}{
+		{"2.5", 0, halfUp, "3"},
+		{"2.5", 0, halfEven, "2"},
+		{"3.5", 0, halfEven, "4"},
+		{"-2.5", 0, halfUp, "-3"},
+		{"-2.5", 0, halfEven, "-2"},
+		{"1.239", 2, down, "1.23"},
+		{"-1.239", 2, down, "-1.23"},
+		{"1.231", 2, up, "1.24"},
+		{"1.235", 2, halfEven, "1.24"},
+		{"1.2", 2, up, "1.2"},
+	}
+	for _, tc := range cases {
+		x, _ := new(big.Rat).SetString(tc.x)
+		want, _ := new(big.Rat).SetString(tc.want)
+		if got := roundAt(x, tc.d, tc.m); got.Cmp(want) != 0 {
+			t.Errorf("roundAt(%s, %d, %v) = %s, want %s", tc.x, tc.d, tc.m, got.FloatString(tc.d), tc.want)
+		}
+	}
+}
+
+func TestValidateAmountPrecision(t *testing.T) {
+	setCurrency := func(r *pb.RoomRate, c string) {
+		r.TotalPriceAtBooking.Currency = c
+		r.TotalPriceAtCheckout.Currency = c
+		for _, l := range r.LineItems {
+			l.Price.Currency = c
+		}
+		for _, c2 := range r.CancellationRules {
+			c2.Penalty.Currency = c
+		}
+	}
+	cases := []struct {
+		name  string
+		alter func(*pb.RoomRate)
+		want  []string
+		err   error
+	}{
+		{
+			name:  "whole amounts",
+			alter: func(*pb.RoomRate) {},
+		},
+		{
+			name:  "three decimals in USD",
+			alter: func(r *pb.RoomRate) { r.TotalPriceAtCheckout.Amount = 537.125 },
+			want:  []string{"room_rates[1] > total_price_at_checkout amount 537.125 has 3 decimal(s), USD amounts have 2; rounded half up it is 537.13 but half even 537.12, so send the rounded amount"},
+			err:   fmt.Errorf("amount(s) with more decimals than their currency has in field(s): room_rates[1] > total_price_at_checkout"),
+		},
+		{
+			name: "decimals in JPY",
+			alter: func(r *pb.RoomRate) {
+				setCurrency(r, "JPY")
+				r.LineItems[2].Price.Amount = 384.5
+				r.TotalPriceAtCheckout.Amount = 537.5
+			},
+			want: []string{
+				"room_rates[1] > total_price_at_checkout amount 537.5 has 1 decimal(s), JPY amounts have 0; send the rounded amount 538",
+				"room_rates[1] > line_items[2] > price amount 384.5 has 1 decimal(s), JPY amounts have 0; rounded half up it is 385 but half even 384, so send the rounded amount",
+			},
+			err: fmt.Errorf("amount(s) with more decimals than their currency has in field(s): room_rates[1] > total_price_at_checkout, room_rates[1] > line_items[2] > price"),
+		},
+		{
+			name: "three decimals in KWD",
+			alter: func(r *pb.RoomRate) {
+				setCurrency(r, "KWD")
+				r.LineItems[2].Price.Amount = 384.125
+				r.TotalPriceAtCheckout.Amount = 537.125
+			},
+		},
+		{
+			name: "line items rounded before adding",
+			alter: func(r *pb.RoomRate) {
+				r.LineItems[2].Price.Amount = 384.004
+				r.LineItems[3].Price.Amount = 128.004
+			},
+			want: []string{
+				"room_rates[1] > line_items[2] > price amount 384.004 has 3 decimal(s), USD amounts have 2; send the rounded amount 384.00",
+				"room_rates[1] > line_items[3] > price amount 128.004 has 3 decimal(s), USD amounts have 2; send the rounded amount 128.00",
+				"room_rates[1] > total_price_at_checkout amount 537 matches its line_items only when each is rounded half up to 2 decimal(s) before they are added; the exact sum of its line_items, 537.008, rounds half up to 537.01",
+			},
+			err: fmt.Errorf("amount(s) with more decimals than their currency has in field(s): room_rates[1] > line_items[2] > price, room_rates[1] > line_items[3] > price"),
+		},
+		{
+			name: "sum rounded down",
+			alter: func(r *pb.RoomRate) {
+				r.LineItems[2].Price.Amount = 384.009
+				r.LineItems[3].Price.Amount = 128.009
+				r.TotalPriceAtCheckout.Amount = 537.01
+			},
+			want: []string{
+				"room_rates[1] > line_items[2] > price amount 384.009 has 3 decimal(s), USD amounts have 2; send the rounded amount 384.01",
+				"room_rates[1] > line_items[3] > price amount 128.009 has 3 decimal(s), USD amounts have 2; send the rounded amount 128.01",
+				"room_rates[1] > total_price_at_checkout amount 537.01 matches its line_items only when their sum is rounded down to 2 decimal(s); the exact sum of its line_items, 537.018, rounds half up to 537.02",
+			},
+			err: fmt.Errorf("amount(s) with more decimals than their currency has in field(s): room_rates[1] > line_items[2] > price, room_rates[1] > line_items[3] > price"),
+		},
+	}
+	for _, tc := range cases {
+		data, err := BookingAvailabilityData()
+		if err != nil {
+			t.Fatalf("error fetching BookingAvailabilityData: %q", err)
+		}
+		tc.alter(data.RespPb.RoomRates[1])
+		findings, got := validateAmountPrecision(data.RespPb.GetRoomRates())
+		if diff := cmp.Diff(got, tc.err, equateErrorMessage); diff != "" {
+			t.Errorf("%s: unexpected error (diff -got +want): %s", tc.name, diff)
+		}
+		var messages []string
+		for _, f := range findings {
+			messages = append(messages, f.Field+" "+f.Message)
+		}
+		if diff := cmp.Diff(messages, tc.want); diff != "" {
+			t.Errorf("%s: unexpected findings (diff -got +want): %s", tc.name, diff)
+		}
+		for _, f := range findings {
+			if strings.Contains(f.Message, "matches its line_items") != (f.Code == CodePriceRounding) {
+				t.Errorf("%s: finding %v has code %s", tc.name, f, f.Code)
+			}
+		}
+	}
+}
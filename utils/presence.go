@@ -0,0 +1,164 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"log"
+	"sync"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// isTax and isFee classify line item types; UNKNOWN_TAXES_AND_FEES is both.
+var (
+	isTax = map[pb.RoomRate_LineItem_LineItemType]bool{
+		pb.RoomRate_LineItem_UNKNOWN_TAXES_AND_FEES: true,
+		pb.RoomRate_LineItem_UNKNOWN_TAXES:          true,
+		pb.RoomRate_LineItem_TAX_MUNICIPAL:          true,
+		pb.RoomRate_LineItem_TAX_VAT:                true,
+		pb.RoomRate_LineItem_TAX_OTHER:              true,
+	}
+	isFee = map[pb.RoomRate_LineItem_LineItemType]bool{
+		pb.RoomRate_LineItem_UNKNOWN_TAXES_AND_FEES: true,
+		pb.RoomRate_LineItem_UNKNOWN_FEES:           true,
+		pb.RoomRate_LineItem_FEE_BOOKING:            true,
+		pb.RoomRate_LineItem_FEE_HOTEL:              true,
+		pb.RoomRate_LineItem_FEE_RESORT:             true,
+		pb.RoomRate_LineItem_FEE_TRANSFER:           true,
+		pb.RoomRate_LineItem_FEE_OTHER:              true,
+	}
+)
+
+// hasLineItem reports whether r has a line item of one of types.
+func hasLineItem(r *pb.RoomRate, types map[pb.RoomRate_LineItem_LineItemType]bool) bool {
+	for _, l := range r.GetLineItems() {
+		if types[l.GetType()] {
+			return true
+		}
+	}
+	return false
+}
+
+// presenceFields are the optional fields of a room rate counted by PresenceTally, in the order they are reported. The
+// cancellation_policy of the rate plan of a room rate, if any, counts as its cancellation_rules.
+var presenceFields = []struct {
+	name    string
+	present func(*pb.RoomRate, *pb.RatePlan) bool
+}{
+	{"maximum_allowed_occupancy", func(r *pb.RoomRate, _ *pb.RatePlan) bool { return r.GetMaximumAllowedOccupancy() != nil }},
+	{"total_price_at_checkout", func(r *pb.RoomRate, _ *pb.RatePlan) bool { return r.GetTotalPriceAtCheckout() != nil }},
+	{"line_items", func(r *pb.RoomRate, _ *pb.RatePlan) bool { return len(r.GetLineItems()) > 0 }},
+	{"line_items (taxes)", func(r *pb.RoomRate, _ *pb.RatePlan) bool { return hasLineItem(r, isTax) }},
+	{"line_items (fees)", func(r *pb.RoomRate, _ *pb.RatePlan) bool { return hasLineItem(r, isFee) }},
+	{"cancellation_rules", func(r *pb.RoomRate, p *pb.RatePlan) bool {
+		return len(r.GetCancellationRules()) > 0 || p.GetCancellationPolicy() != nil
+	}},
+	{"unstructured_policy", func(r *pb.RoomRate, _ *pb.RatePlan) bool { return r.GetUnstructuredPolicy().GetText() != "" }},
+	{"partner_data", func(r *pb.RoomRate, _ *pb.RatePlan) bool { return len(r.GetPartnerData()) > 0 }},
+	{"room_count", func(r *pb.RoomRate, _ *pb.RatePlan) bool { return r.GetRoomCount() > 0 }},
+}
+
+// FieldPresence is the number of room rates in which an optional field is set, and their share of all room rates.
+// Partial is set if the field is set in some room rates only, often the sign of a feature implemented for some rate
+// plans or suppliers but not others.
+type FieldPresence struct {
+	Field    string  `json:"field"`
+	Present  int     `json:"present"`
+	Fraction float64 `json:"fraction"`
+	Partial  bool    `json:"partial,omitempty"`
+}
+
+// PresenceReport summarizes which optional fields are set across the room rates of the availability responses of a
+// run.
+type PresenceReport struct {
+	Responses int             `json:"responses"`
+	RoomRates int             `json:"room_rates"`
+	Fields    []FieldPresence `json:"fields"`
+}
+
+// PresenceTally counts the optional fields set in the room rates of availability responses. The zero value is ready
+// to use, and a PresenceTally may be used by concurrent requests.
+type PresenceTally struct {
+	mu        sync.Mutex
+	responses int
+	rates     int
+	present   []int
+}
+
+// AddResponse records an availability response, whose room rates are added with AddRoomRate.
+func (t *PresenceTally) AddResponse() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.responses++
+}
+
+// RatePlansByCode indexes plans, the rate plans of an availability response, by code for AddRoomRate.
+func RatePlansByCode(plans []*pb.RatePlan) map[string]*pb.RatePlan {
+	byCode := make(map[string]*pb.RatePlan, len(plans))
+	for _, p := range plans {
+		byCode[p.GetCode()] = p
+	}
+	return byCode
+}
+
+// AddRoomRate records the optional fields set in r, or in the rate plan of r in ratePlans, as returned by
+// RatePlansByCode.
+func (t *PresenceTally) AddRoomRate(r *pb.RoomRate, ratePlans map[string]*pb.RatePlan) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.present == nil {
+		t.present = make([]int, len(presenceFields))
+	}
+	t.rates++
+	plan := ratePlans[r.GetRatePlanCode()]
+	for i, f := range presenceFields {
+		if f.present(r, plan) {
+			t.present[i]++
+		}
+	}
+}
+
+// Report returns the presence of every optional field, or nil if no room rate was recorded.
+func (t *PresenceTally) Report() *PresenceReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.rates == 0 {
+		return nil
+	}
+	r := &PresenceReport{Responses: t.responses, RoomRates: t.rates}
+	for i, f := range presenceFields {
+		n := t.present[i]
+		r.Fields = append(r.Fields, FieldPresence{Field: f.name, Present: n, Fraction: float64(n) / float64(t.rates), Partial: n > 0 && n < t.rates})
+	}
+	return r
+}
+
+// LogPresenceReport logs the share of room rates setting each optional field of r, marking fields set in some room
+// rates only.
+func LogPresenceReport(r *PresenceReport) {
+	if r == nil {
+		return
+	}
+	log.Printf("Room rate field presence across %d room_rates of %d availability response(s):\n", r.RoomRates, r.Responses)
+	for _, f := range r.Fields {
+		partial := ""
+		if f.Partial {
+			partial = " (partial)"
+		}
+		log.Printf("  %-26s %5d/%d %4.0f%%%s\n", f.Field, f.Present, r.RoomRates, 100*f.Fraction, partial)
+	}
+}
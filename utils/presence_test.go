@@ -0,0 +1,53 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestPresenceTally(t *testing.T) {
+	var tally PresenceTally
+	if tally.Report() != nil {
+		t.Error("Report() of an empty tally is not nil")
+	}
+	tally.AddResponse()
+	plans := RatePlansByCode([]*pb.RatePlan{
+		{Code: "flexible", CancellationPolicy: &pb.CancellationPolicy{Summary: pb.CancellationPolicy_FREE_CANCELLATION}},
+		{Code: "basic"},
+	})
+	tally.AddRoomRate(&pb.RoomRate{
+		LineItems:         []*pb.RoomRate_LineItem{{Type: pb.RoomRate_LineItem_TAX_VAT}},
+		CancellationRules: []*pb.RoomRate_CancellationRule{{}},
+		RoomCount:         2,
+	}, plans)
+	tally.AddRoomRate(&pb.RoomRate{
+		LineItems: []*pb.RoomRate_LineItem{{Type: pb.RoomRate_LineItem_UNKNOWN_TAXES_AND_FEES}},
+		RoomCount: 1,
+	}, plans)
+	tally.AddRoomRate(&pb.RoomRate{RatePlanCode: "flexible"}, plans)
+	tally.AddRoomRate(&pb.RoomRate{Code: "basic"}, plans)
+
+	got := tally.Report()
+	if got.Responses != 1 || got.RoomRates != 4 {
+		t.Errorf("Report() counted %d responses and %d room rates, want 1 and 4", got.Responses, got.RoomRates)
+	}
+	present := map[string]FieldPresence{}
+	for _, f := range got.Fields {
+		present[f.Field] = f
+	}
+	want := map[string]FieldPresence{
+		"line_items (taxes)": {Field: "line_items (taxes)", Present: 2, Fraction: 0.5, Partial: true},
+		"line_items (fees)":  {Field: "line_items (fees)", Present: 1, Fraction: 0.25, Partial: true},
+		"cancellation_rules": {Field: "cancellation_rules", Present: 2, Fraction: 0.5, Partial: true},
+		"partner_data":       {Field: "partner_data"},
+		"room_count":         {Field: "room_count", Present: 2, Fraction: 0.5, Partial: true},
+	}
+	for field, w := range want {
+		if diff := cmp.Diff(present[field], w); diff != "" {
+			t.Errorf("Report() presence of %s differs (-got +want):\n%s", field, diff)
+		}
+	}
+}
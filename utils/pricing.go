@@ -0,0 +1,94 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"log"
+	"math"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// minorUnitsPerMajor is the ratio between totals and line items that indicates one of them was encoded in minor
+// units, e.g. cents, while the other was in major units. ISO 4217 currencies with three decimals are rare enough in
+// partner feeds not to be checked.
+const minorUnitsPerMajor = 100
+
+// scaledBy reports whether a is b multiplied by factor, within 1%.
+func scaledBy(a, b, factor float64) bool {
+	return b != 0 && math.Abs(a/b-factor) < factor/100
+}
+
+// validatePriceUnits cross-checks the totals of each room rate against the sum of its line items paid at booking
+// and at checkout, and reports totals that differ from that sum by a factor of 100 as mixed major and minor unit
+// encodings.
+func validatePriceUnits(apiVersion int32, rates []*pb.RoomRate) ([]Finding, error) {
+	var findings []Finding
+	for i, r := range rates {
+		findings = append(findings, priceUnitFindings(apiVersion, i, r)...)
+	}
+	return findings, priceUnitsError(findings)
+}
+
+// priceUnitFindings returns an Error finding for each total of r, the room rate at index i, encoded in other units
+// than its line items.
+func priceUnitFindings(apiVersion int32, i int, r *pb.RoomRate) []Finding {
+	var findings []Finding
+	var atBooking, atCheckout float64
+	for _, l := range r.GetLineItems() {
+		if l.GetPaidAtCheckout() {
+			atCheckout += float64(l.GetPrice().GetAmount())
+		} else {
+			atBooking += float64(l.GetPrice().GetAmount())
+		}
+	}
+	for _, t := range []struct {
+		field string
+		total *pb.Price
+		sum   float64
+	}{
+		{fmt.Sprintf("room_rates[%d] > total_price_at_booking", i), r.GetTotalPriceAtBooking(), atBooking},
+		{fmt.Sprintf("room_rates[%d] > total_price_at_checkout", i), r.GetTotalPriceAtCheckout(), atCheckout},
+	} {
+		total := float64(t.total.GetAmount())
+		var relation string
+		switch {
+		case scaledBy(total, t.sum, minorUnitsPerMajor):
+			relation = "100 times"
+		case scaledBy(t.sum, total, minorUnitsPerMajor):
+			relation = "1/100 of"
+		default:
+			continue
+		}
+		f := Finding{Error, t.field, fmt.Sprintf("amount %v is %s the sum of its line_items (%v); api_version %d expects every amount in major units of the currency, e.g. 123.45 rather than 12345", total, relation, t.sum, apiVersion), CodePriceMinorUnits}
+		log.Println(f)
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+func priceUnitsError(findings []Finding) error {
+	if len(findings) == 0 {
+		return nil
+	}
+	errorFields := make([]string, len(findings))
+	for i, f := range findings {
+		errorFields[i] = f.Field
+	}
+	return fmt.Errorf("mixed major and minor unit price encoding in field(s): %s", joinFields(errorFields))
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidatePriceUnits(t *testing.T) {
+	cases := []struct {
+		name     string
+		checkout float32
+		booking  float32
+		want     error
+	}{
+		{
+			name:     "consistent major units",
+			checkout: 537,
+			booking:  25,
+		},
+		{
+			name:     "total in minor units",
+			checkout: 53700,
+			booking:  25,
+			want:     fmt.Errorf("mixed major and minor unit price encoding in field(s): room_rates[1] > total_price_at_checkout"),
+		},
+		{
+			name:     "line items in minor units",
+			checkout: 537,
+			booking:  0.25,
+			want:     fmt.Errorf("mixed major and minor unit price encoding in field(s): room_rates[1] > total_price_at_booking"),
+		},
+		{
+			name:     "unrelated mismatch",
+			checkout: 540,
+			booking:  25,
+		},
+	}
+	for _, tc := range cases {
+		data, err := BookingAvailabilityData()
+		if err != nil {
+			t.Fatalf("error fetching BookingAvailabilityData: %q", err)
+		}
+		data.RespPb.RoomRates[1].TotalPriceAtCheckout.Amount = tc.checkout
+		data.RespPb.RoomRates[1].TotalPriceAtBooking.Amount = tc.booking
+		findings, got := validatePriceUnits(data.RespPb.GetApiVersion(), data.RespPb.GetRoomRates())
+		if diff := cmp.Diff(got, tc.want, equateErrorMessage); diff != "" {
+			t.Errorf("%s: unexpected error (diff -got +want): %s", tc.name, diff)
+		}
+		if tc.want != nil && len(findings) != 1 {
+			t.Errorf("%s: got %d finding(s), want 1", tc.name, len(findings))
+		}
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"regexp"
+	"sort"
+	"sync"
+)
+
+var (
+	ruleIndex    = regexp.MustCompile(`\[\d+\]`)
+	ruleDuration = regexp.MustCompile(`\b\d+(\.\d+)?(ns|µs|ms|s|m|h)\b`)
+)
+
+// RuleCount is the number of requests of a batch that broke a rule, i.e. that had a finding for a field, or failed
+// with an error, once indexes such as room_rates[3] are replaced by room_rates[].
+type RuleCount struct {
+	Rule     string   `json:"rule"`
+	Code     string   `json:"code,omitempty"`
+	Severity Severity `json:"severity"`
+	Count    int      `json:"count"`
+}
+
+// RuleTally aggregates the findings and errors of the requests of a batch, such as a sweep or soak test, per rule,
+// so systemic issues stand out from those of single requests. The zero value is ready to use, and a RuleTally may be
+// used by concurrent requests.
+type RuleTally struct {
+	mu       sync.Mutex
+	requests int
+	notRun   int
+	counts   map[RuleCount]int
+}
+
+// Add records the outcome of a request. An error is counted as a rule of its own unless findings has an Error or
+// Critical finding explaining it. Add on a nil RuleTally does nothing, so batches can make the tally optional.
+func (t *RuleTally) Add(findings []Finding, err error) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.counts == nil {
+		t.counts = map[RuleCount]int{}
+	}
+	t.requests++
+	broken := map[RuleCount]bool{}
+	explained := false
+	for _, f := range findings {
+		broken[RuleCount{Rule: ruleIndex.ReplaceAllString(f.Field, "[]"), Code: f.Code, Severity: f.Severity}] = true
+		explained = explained || f.Severity != Warning
+	}
+	if err != nil && !explained {
+		rule := ruleDuration.ReplaceAllString(ruleIndex.ReplaceAllString(err.Error(), "[]"), "N")
+		broken[RuleCount{Rule: rule, Severity: Error}] = true
+	}
+	for r := range broken {
+		t.counts[r]++
+	}
+}
+
+// Requests returns the number of requests recorded.
+func (t *RuleTally) Requests() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.requests
+}
+
+// Skip records a request of the batch that was not sent, e.g. after a circuit breaker stopped the batch. Skipped
+// requests do not count in Requests. Skip on a nil RuleTally does nothing.
+func (t *RuleTally) Skip() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.notRun++
+}
+
+// NotRun returns the number of requests recorded with Skip.
+func (t *RuleTally) NotRun() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.notRun
+}
+
+// Rules returns the count of every rule broken by at least one request, most frequent first.
+func (t *RuleTally) Rules() []RuleCount {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rules := make([]RuleCount, 0, len(t.counts))
+	for r, n := range t.counts {
+		r.Count = n
+		rules = append(rules, r)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Count != rules[j].Count {
+			return rules[i].Count > rules[j].Count
+		}
+		if rules[i].Severity != rules[j].Severity {
+			return rules[i].Severity > rules[j].Severity
+		}
+		return rules[i].Rule < rules[j].Rule
+	})
+	return rules
+}
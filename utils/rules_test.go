@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRuleTally(t *testing.T) {
+	tally := &RuleTally{}
+	tally.Add([]Finding{
+		{Severity: Error, Field: "room_rates[0] > line_items", Message: "missing"},
+		{Severity: Error, Field: "room_rates[1] > line_items", Message: "missing"},
+		{Severity: Warning, Field: "rate_plans[0]", Message: "short window"},
+	}, errors.New("Validation error: 2 room rates are invalid"))
+	tally.Add([]Finding{{Severity: Error, Field: "room_rates[4] > line_items", Message: "missing"}}, errors.New("Validation error"))
+	tally.Add(nil, errors.New("request timed out after 1.5s"))
+	tally.Add(nil, errors.New("request timed out after 2s"))
+	tally.Add(nil, nil)
+
+	if got, want := tally.Requests(), 5; got != want {
+		t.Errorf("Requests() = %d, want %d", got, want)
+	}
+	want := []RuleCount{
+		{Rule: "request timed out after N", Severity: Error, Count: 2},
+		{Rule: "room_rates[] > line_items", Severity: Error, Count: 2},
+		{Rule: "rate_plans[]", Severity: Warning, Count: 1},
+	}
+	if diff := cmp.Diff(tally.Rules(), want); diff != "" {
+		t.Errorf("Rules() did not match (-got +want)\n%s", diff)
+	}
+}
+
+func TestRuleTallyNil(t *testing.T) {
+	var tally *RuleTally
+	tally.Add([]Finding{{Severity: Error, Field: "hotel_id"}}, errors.New("Validation error"))
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"crypto/rand"
+	"fmt"
+	mrand "math/rand"
+	"sync"
+	"time"
+)
+
+// random is the generator seeded by SetSeed, nil until it is called.
+var random struct {
+	sync.Mutex
+	seed int64
+	rand *mrand.Rand
+}
+
+// SetSeed seeds the generators of randomized requests and faults, so that a run can be reproduced exactly by passing
+// the same seed again. A zero seed is replaced by one derived from the clock. It returns the seed in use, which should
+// be logged and reported.
+func SetSeed(seed int64) int64 {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	random.Lock()
+	defer random.Unlock()
+	random.seed = seed
+	random.rand = mrand.New(mrand.NewSource(seed))
+	return seed
+}
+
+// Seed returns the seed set by SetSeed, or 0 if it was not called.
+func Seed() int64 {
+	random.Lock()
+	defer random.Unlock()
+	return random.seed
+}
+
+// NewUUID returns an RFC 4122 version 4 UUID drawn from the generator seeded by SetSeed, or from crypto/rand if it
+// was not called.
+func NewUUID() (string, error) {
+	b := make([]byte, 16)
+	random.Lock()
+	r := random.rand
+	if r != nil {
+		r.Read(b)
+	}
+	random.Unlock()
+	if r == nil {
+		if _, err := rand.Read(b); err != nil {
+			return "", fmt.Errorf("unable to generate uuid: %v", err)
+		}
+	}
+	return formatUUID(b), nil
+}
+
+// formatUUID sets the version and variant bits of the 16 random bytes b and formats them as a UUID.
+func formatUUID(b []byte) string {
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
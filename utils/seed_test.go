@@ -0,0 +1,34 @@
+package utils
+
+import "testing"
+
+func TestSetSeed(t *testing.T) {
+	uuids := func() []string {
+		var ids []string
+		for i := 0; i < 3; i++ {
+			id, err := NewUUID()
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, id)
+		}
+		return ids
+	}
+	if got := SetSeed(42); got != 42 || Seed() != 42 {
+		t.Fatalf("SetSeed(42) = %d, Seed() = %d, want 42", got, Seed())
+	}
+	first := uuids()
+	SetSeed(42)
+	second := uuids()
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("NewUUID() #%d after SetSeed(42) = %s, then %s, want the same", i, first[i], second[i])
+		}
+	}
+	if first[0] == first[1] {
+		t.Errorf("NewUUID() returned %s twice", first[0])
+	}
+	if SetSeed(0) == 0 {
+		t.Error("SetSeed(0) = 0, want a seed derived from the clock")
+	}
+}
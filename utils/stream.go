@@ -0,0 +1,164 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// indexedRate is a room rate along with its index in room_rates, under which its fields are reported.
+type indexedRate struct {
+	index int
+	rate  *pb.RoomRate
+}
+
+func indexRates(rates []*pb.RoomRate) []indexedRate {
+	indexed := make([]indexedRate, len(rates))
+	for i, r := range rates {
+		indexed[i] = indexedRate{i, r}
+	}
+	return indexed
+}
+
+// AvailabilityValidator applies the rules of ValidateBookingAvailabilityResponse to a response whose room rates are
+// added one at a time, e.g. while they are decoded from the response body. Only what the rules spanning the whole
+// response need is retained, such as the distinct codes referenced and the municipal tax line items, so the room
+// rates themselves can be discarded once added.
+type AvailabilityValidator struct {
+	req        *pb.BookingAvailabilityRequest
+	apiVersion int32
+	n          int
+	missing    []string
+	currency   []Finding
+	roomTypes  []rateCode
+	ratePlans  []rateCode
+	seen       map[string]bool
+	retained   []indexedRate
+	prices     []Finding
+	precision  []Finding
+	counts     []Finding
+}
+
+// NewAvailabilityValidator returns an AvailabilityValidator of a response to req. The apiVersion is used by the
+// messages of room rate findings, and is normally known from the first field of the response.
+func NewAvailabilityValidator(req *pb.BookingAvailabilityRequest, apiVersion int32) *AvailabilityValidator {
+	return &AvailabilityValidator{req: req, apiVersion: apiVersion, seen: map[string]bool{}}
+}
+
+// SetAPIVersion updates the api_version used by the findings of room rates added afterwards.
+func (v *AvailabilityValidator) SetAPIVersion(apiVersion int32) {
+	v.apiVersion = apiVersion
+}
+
+// RoomRates returns the number of room rates added.
+func (v *AvailabilityValidator) RoomRates() int {
+	return v.n
+}
+
+// AddRoomRate validates the next room rate of the response.
+func (v *AvailabilityValidator) AddRoomRate(r *pb.RoomRate) {
+	i := v.n
+	v.n++
+	v.missing = appendMissing(v.missing, roomRateRequired(i, r))
+	v.currency = append(v.currency, chargesWithoutCurrency(i, r)...)
+	if k := "room_type_code\x00" + r.GetRoomTypeCode(); !v.seen[k] {
+		v.seen[k] = true
+		v.roomTypes = append(v.roomTypes, rateCode{i, r.GetRoomTypeCode()})
+	}
+	if k := "rate_plan_code\x00" + r.GetRatePlanCode(); !v.seen[k] {
+		v.seen[k] = true
+		v.ratePlans = append(v.ratePlans, rateCode{i, r.GetRatePlanCode()})
+	}
+	v.prices = append(v.prices, priceUnitFindings(v.apiVersion, i, r)...)
+	v.precision = append(v.precision, precisionFindings(i, r)...)
+	v.counts = append(v.counts, roomCountFindings(i, r)...)
+
+	// Keep the parts validated against hotel_details, which usually follows room_rates.
+	var slim *pb.RoomRate
+	for _, l := range r.GetLineItems() {
+		if l.GetType() == pb.RoomRate_LineItem_TAX_MUNICIPAL {
+			if slim == nil {
+				slim = &pb.RoomRate{}
+			}
+			slim.LineItems = append(slim.LineItems, l)
+		}
+	}
+	if len(r.GetCancellationRules()) > 0 {
+		if slim == nil {
+			slim = &pb.RoomRate{}
+		}
+		slim.CancellationRules = r.GetCancellationRules()
+	}
+	v.retained = append(v.retained, indexedRate{i, slim})
+}
+
+// Validate checks resp, which must not contain room_rates, along with the room rates added, and returns the same
+// findings and error as ValidateBookingAvailabilityResponse of the complete response. Custom availability assertions
+// need the room rates the validator discards, so Validate fails while any are loaded.
+func (v *AvailabilityValidator) Validate(resp *pb.BookingAvailabilityResponse) ([]Finding, error) {
+	if len(resp.GetRoomRates()) > 0 {
+		return nil, fmt.Errorf("response passed to Validate contains %d room_rates, add them with AddRoomRate", len(resp.GetRoomRates()))
+	}
+	if CustomAssertions.HasAvailability() {
+		return nil, fmt.Errorf("custom availability assertions cannot be evaluated against a streamed response")
+	}
+	roomTypeCodes, ratePlanCodes, findings, err := validateAvailabilityHeader(v.req, resp)
+	if err != nil {
+		return findings, err
+	}
+	if policies := append(propertyPolicyFindings(resp), v.currency...); len(policies) > 0 {
+		return policies, hotelPoliciesError(policies)
+	}
+	findings, err = validateCancellationDeadlines(resp, v.retained)
+	if err != nil {
+		return findings, err
+	}
+	amenityFindings, err := validateAmenities(resp)
+	findings = append(findings, amenityFindings...)
+	if err != nil {
+		return findings, err
+	}
+	if missing, err := missingError(v.missing); err != nil {
+		return append(findings, missing...), err
+	}
+	if undefined, err := checkRateCodes("room_type_code", roomTypeCodes, v.roomTypes); err != nil {
+		return append(findings, undefined...), err
+	}
+	if undefined, err := checkRateCodes("rate_plan_code", ratePlanCodes, v.ratePlans); err != nil {
+		return append(findings, undefined...), err
+	}
+	taxFindings, err := validateOccupancyTaxLineItems(resp.GetHotelDetails().GetAddress().GetCountry(), v.retained)
+	findings = append(findings, taxFindings...)
+	if err != nil {
+		return findings, err
+	}
+	findings = append(findings, v.prices...)
+	if err := priceUnitsError(v.prices); err != nil {
+		return findings, err
+	}
+	findings = append(findings, v.precision...)
+	if err := precisionError(v.precision); err != nil {
+		return findings, err
+	}
+	findings = append(findings, v.counts...)
+	if err := roomCountsError(v.counts); err != nil {
+		return findings, err
+	}
+	return findings, nil
+}
@@ -0,0 +1,58 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/google/go-cmp/cmp"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestAvailabilityValidator(t *testing.T) {
+	data, err := BookingAvailabilityData()
+	if err != nil {
+		t.Fatalf("error fetching BookingAvailabilityData: %q", err)
+	}
+	cases := []struct {
+		name  string
+		alter func(*pb.BookingAvailabilityResponse)
+	}{
+		{"valid", func(*pb.BookingAvailabilityResponse) {}},
+		{"missing code", func(r *pb.BookingAvailabilityResponse) { r.RoomRates[1].Code = "" }},
+		{"undefined room type", func(r *pb.BookingAvailabilityResponse) { r.RoomRates[1].RoomTypeCode = "undefined" }},
+		{"missing currency", func(r *pb.BookingAvailabilityResponse) {
+			r.RoomRates[0].LineItems[0].PaidAtCheckout = false
+			r.RoomRates[0].LineItems[0].Price.Currency = ""
+		}},
+		{"minor units", func(r *pb.BookingAvailabilityResponse) { r.RoomRates[0].TotalPriceAtCheckout.Amount *= 100 }},
+		{"decimals", func(r *pb.BookingAvailabilityResponse) { r.RoomRates[1].LineItems[2].Price.Amount = 384.004 }},
+		{"occupancy tax", func(r *pb.BookingAvailabilityResponse) { r.HotelDetails.Address.Country = "FR" }},
+		{"late deadline", func(r *pb.BookingAvailabilityResponse) {
+			r.RoomRates[1].CancellationRules = []*pb.RoomRate_CancellationRule{{Deadline: "2019-04-06T00:00:00Z"}}
+		}},
+		{"amenity conflict", func(r *pb.BookingAvailabilityResponse) {
+			r.RatePlans[0].BasicAmenities = &pb.BasicAmenities{FreeBreakfast: true}
+			r.RatePlans[0].Description = &pb.DisplayString{Text: "Room only", Language: "en"}
+		}},
+		{"echo mismatch", func(r *pb.BookingAvailabilityResponse) { r.HotelId = "456" }},
+	}
+	for _, tc := range cases {
+		resp := proto.Clone(data.RespPb).(*pb.BookingAvailabilityResponse)
+		tc.alter(resp)
+		wantFindings, wantErr := ValidateBookingAvailabilityResponse(data.ReqPb, resp)
+
+		v := NewAvailabilityValidator(data.ReqPb, resp.GetApiVersion())
+		for _, r := range resp.RoomRates {
+			v.AddRoomRate(r)
+		}
+		resp.RoomRates = nil
+		gotFindings, gotErr := v.Validate(resp)
+		if diff := cmp.Diff(gotErr, wantErr, equateErrorMessage); diff != "" {
+			t.Errorf("%s: Validate() error did not match ValidateBookingAvailabilityResponse (-got +want)\n%s", tc.name, diff)
+		}
+		if diff := cmp.Diff(gotFindings, wantFindings); diff != "" {
+			t.Errorf("%s: Validate() findings did not match ValidateBookingAvailabilityResponse (-got +want)\n%s", tc.name, diff)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+// OccupancyTaxCountries are the ISO 3166-1 countries in which city or tourist taxes are levied on most stays and
+// must be disclosed as TAX_MUNICIPAL line items.
+var OccupancyTaxCountries = map[string]bool{
+	"AT": true, "BE": true, "CH": true, "CZ": true, "DE": true, "ES": true, "FR": true, "GR": true,
+	"HR": true, "HU": true, "IT": true, "NL": true, "PT": true,
+}
+
+// validateOccupancyTaxes checks the municipal tax disclosure of every room rate for hotels in OccupancyTaxCountries.
+// The v1 line item has no jurisdiction field, so TAX_MUNICIPAL items must name their jurisdiction in the
+// description. Room rates without a municipal tax, and municipal taxes charged at booking rather than collected by
+// the property, are returned as warnings.
+func validateOccupancyTaxes(resp *pb.BookingAvailabilityResponse) ([]Finding, error) {
+	return validateOccupancyTaxLineItems(resp.GetHotelDetails().GetAddress().GetCountry(), indexRates(resp.GetRoomRates()))
+}
+
+// validateOccupancyTaxLineItems behaves like validateOccupancyTaxes for the room rates of a hotel in country.
+func validateOccupancyTaxLineItems(country string, rates []indexedRate) ([]Finding, error) {
+	if !OccupancyTaxCountries[country] {
+		return nil, nil
+	}
+
+	var findings []Finding
+	var errorFields []string
+	for _, r := range rates {
+		i := r.index
+		disclosed := false
+		for j, l := range r.rate.GetLineItems() {
+			if l.GetType() != pb.RoomRate_LineItem_TAX_MUNICIPAL {
+				continue
+			}
+			disclosed = true
+			field := fmt.Sprintf("room_rates[%d] > line_items[%d]", i, j)
+			if strings.TrimSpace(l.GetDescription().GetText()) == "" {
+				errorFields = append(errorFields, field+" > description")
+				log.Println(fmt.Errorf("Field %s is a TAX_MUNICIPAL line item without a jurisdiction label", field))
+			}
+			if !l.GetPaidAtCheckout() {
+				f := Finding{Warning, field + " > paid_at_checkout", "municipal tax is charged at booking; in " + country + " it is usually collected by the property at checkout", CodeTaxAtBooking}
+				log.Println(f)
+				findings = append(findings, f)
+			}
+		}
+		if !disclosed {
+			f := Finding{Warning, fmt.Sprintf("room_rates[%d] > line_items", i), "no TAX_MUNICIPAL line item, but hotels in " + country + " usually levy an occupancy tax", CodeTaxMissing}
+			log.Println(f)
+			findings = append(findings, f)
+		}
+	}
+
+	if len(errorFields) > 0 {
+		return findings, fmt.Errorf("occupancy tax line item(s) missing jurisdiction: %s", joinFields(errorFields))
+	}
+	return findings, nil
+}
@@ -0,0 +1,63 @@
+package utils
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	pb "github.com/google/hotel-booking-api-validator/v1"
+)
+
+func TestValidateOccupancyTaxes(t *testing.T) {
+	cases := []struct {
+		name         string
+		country      string
+		lineItem     *pb.RoomRate_LineItem
+		want         error
+		wantWarnings int
+	}{
+		{
+			name:    "no occupancy tax market",
+			country: "US",
+		},
+		{
+			name:     "labelled city tax collected at checkout",
+			country:  "IT",
+			lineItem: &pb.RoomRate_LineItem{Type: pb.RoomRate_LineItem_TAX_MUNICIPAL, PaidAtCheckout: true, Description: &pb.DisplayString{Text: "Rome city tax", Language: "en"}},
+			// The other room rates have no municipal tax.
+			wantWarnings: 2,
+		},
+		{
+			name:         "city tax charged at booking",
+			country:      "IT",
+			lineItem:     &pb.RoomRate_LineItem{Type: pb.RoomRate_LineItem_TAX_MUNICIPAL, Description: &pb.DisplayString{Text: "Rome city tax", Language: "en"}},
+			wantWarnings: 3,
+		},
+		{
+			name:         "unlabelled city tax",
+			country:      "FR",
+			lineItem:     &pb.RoomRate_LineItem{Type: pb.RoomRate_LineItem_TAX_MUNICIPAL, PaidAtCheckout: true},
+			want:         fmt.Errorf("occupancy tax line item(s) missing jurisdiction: room_rates[0] > line_items[3] > description"),
+			wantWarnings: 2,
+		},
+	}
+	for _, tc := range cases {
+		data, err := BookingAvailabilityData()
+		if err != nil {
+			t.Fatalf("error fetching BookingAvailabilityData: %q", err)
+		}
+		data.RespPb.HotelDetails.Address.Country = tc.country
+		if tc.lineItem != nil {
+			tc.lineItem.Price = &pb.Price{Amount: 4, Currency: "EUR"}
+			data.RespPb.RoomRates[0].LineItems = append(data.RespPb.RoomRates[0].LineItems, tc.lineItem)
+		}
+		findings, got := validateOccupancyTaxes(data.RespPb)
+		if diff := cmp.Diff(got, tc.want, equateErrorMessage); diff != "" {
+			t.Errorf("%s: unexpected error (diff -got +want): %s", tc.name, diff)
+		}
+		if len(findings) != tc.wantWarnings {
+			t.Errorf("%s: got %d warning(s), want %d: %v", tc.name, len(findings), tc.wantWarnings, findings)
+		}
+	}
+}
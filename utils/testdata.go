@@ -23,6 +23,7 @@ import (
 
 	
 	jsonpb "github.com/golang/protobuf/jsonpb"
+	"github.com/google/hotel-booking-api-validator/data"
 	pb "github.com/google/hotel-booking-api-validator/v1"
 )
 
@@ -61,8 +62,9 @@ type BookingSubmitDataStruct struct {
 	RespPb *pb.BookingSubmitResponse
 }
 
+// readTestDataFile returns the sample filename embedded in the binary.
 func readTestDataFile(filename string) (string, error) {
-	f, err := ioutil.ReadFile(absPath(filename))
+	f, err := data.Samples.ReadFile(filename)
 	if err != nil {
 		return "", err
 	}
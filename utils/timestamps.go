@@ -0,0 +1,64 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// timestampLayout is a layout accepted for timestamps other than RFC 3339, along with how it deviates from it.
+type timestampLayout struct {
+	layout    string
+	deviation string
+}
+
+// lenientTimestampLayouts are the ISO 8601 variants partners commonly send instead of RFC 3339. Timestamps without
+// an offset are not accepted, as the instant they refer to is ambiguous.
+var lenientTimestampLayouts = []timestampLayout{
+	{"2006-01-02T15:04:05Z0700", "an offset without a colon"},
+	{"2006-01-02 15:04:05Z07:00", "a space instead of T"},
+	{"2006-01-02 15:04:05Z0700", "a space instead of T and an offset without a colon"},
+}
+
+// parseTimestamp parses value as an RFC 3339 timestamp, or one of lenientTimestampLayouts. If it is not RFC 3339,
+// the returned string describes how it deviates.
+func parseTimestamp(value string) (time.Time, string, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, "", nil
+	}
+	upper := strings.ToUpper(value)
+	var deviations []string
+	if upper != value {
+		deviations = append(deviations, "lowercase t or z")
+	}
+	if t, err := time.Parse(time.RFC3339, upper); err == nil {
+		return t, strings.Join(deviations, ", "), nil
+	}
+	for _, l := range lenientTimestampLayouts {
+		if t, err := time.Parse(l.layout, upper); err == nil {
+			return t, strings.Join(append(deviations, l.deviation), ", "), nil
+		}
+	}
+	return time.Time{}, "", fmt.Errorf("%q is not an RFC 3339 timestamp", value)
+}
+
+// nonCanonicalTimestamp returns the Warning finding of field, whose value was parsed with the given deviation.
+func nonCanonicalTimestamp(field, value, deviation string) Finding {
+	return Finding{Warning, field, fmt.Sprintf("timestamp %s uses %s, send RFC 3339 timestamps such as 2019-04-03T15:00:00+02:00 instead", value, deviation), CodeTimestampFormat}
+}
@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	want := time.Date(2019, 4, 3, 13, 0, 0, 0, time.UTC)
+	cases := []struct {
+		value         string
+		wantDeviation string
+		wantErr       bool
+	}{
+		{value: "2019-04-03T15:00:00+02:00"},
+		{value: "2019-04-03T13:00:00Z"},
+		{value: "2019-04-03T13:00:00.000Z"},
+		{value: "2019-04-03T15:00:00+0200", wantDeviation: "an offset without a colon"},
+		{value: "2019-04-03 15:00:00+02:00", wantDeviation: "a space instead of T"},
+		{value: "2019-04-03 13:00:00Z", wantDeviation: "a space instead of T"},
+		{value: "2019-04-03 15:00:00+0200", wantDeviation: "a space instead of T and an offset without a colon"},
+		{value: "2019-04-03t13:00:00z", wantDeviation: "lowercase t or z"},
+		{value: "2019-04-03T15:00:00", wantErr: true},
+		{value: "03/04/2019 15:00", wantErr: true},
+	}
+	for _, tc := range cases {
+		got, deviation, err := parseTimestamp(tc.value)
+		if (err != nil) != tc.wantErr {
+			t.Errorf("parseTimestamp(%q) returned error %v, want error %v", tc.value, err, tc.wantErr)
+			continue
+		}
+		if tc.wantErr {
+			continue
+		}
+		if !got.Equal(want) || deviation != tc.wantDeviation {
+			t.Errorf("parseTimestamp(%q) = %v, %q, want %v, %q", tc.value, got, deviation, want, tc.wantDeviation)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2019 Google Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+https://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"log"
+	"unicode/utf8"
+)
+
+// MaxTransactionIDLength is the longest transaction_id, in characters, accepted in a response. Google generates
+// transaction ids far shorter than this, so a longer one was invented or mangled by the partner.
+var MaxTransactionIDLength = 128
+
+// validateTransactionID ensures the transaction_id of a response is not longer than MaxTransactionIDLength.
+func validateTransactionID(id string) ([]Finding, error) {
+	if n := utf8.RuneCountInString(id); n > MaxTransactionIDLength {
+		f := Finding{Error, "transaction_id", fmt.Sprintf("is %d characters long, more than %d", n, MaxTransactionIDLength), CodeTransactionIDLength}
+		log.Println(f)
+		return []Finding{f}, fmt.Errorf("transaction_id longer than %d characters", MaxTransactionIDLength)
+	}
+	return nil, nil
+}
@@ -0,0 +1,38 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidateAvailabilityTransactionID(t *testing.T) {
+	cases := []struct {
+		name     string
+		reqID    string
+		id       string
+		want     error
+		wantCode string
+	}{
+		{name: "echoed", reqID: "tx-1", id: "tx-1"},
+		{name: "invented", reqID: "tx-1", id: "partner-42", want: fmt.Errorf("echo field(s) did not match request: transaction_id"), wantCode: CodeEchoMismatch},
+		{name: "too long", reqID: "tx-1", id: strings.Repeat("x", 129), want: fmt.Errorf("transaction_id longer than 128 characters"), wantCode: CodeTransactionIDLength},
+	}
+	for _, tc := range cases {
+		data, err := BookingAvailabilityData()
+		if err != nil {
+			t.Fatalf("error fetching BookingAvailabilityData: %q", err)
+		}
+		data.ReqPb.TransactionId = tc.reqID
+		data.RespPb.TransactionId = tc.id
+		findings, got := ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb)
+		if diff := cmp.Diff(got, tc.want, equateErrorMessage); diff != "" {
+			t.Errorf("%s: unexpected error (diff -got +want): %s", tc.name, diff)
+		}
+		if tc.wantCode != "" && (len(findings) != 1 || findings[0].Code != tc.wantCode) {
+			t.Errorf("%s: got findings %v, want one with code %s", tc.name, findings, tc.wantCode)
+		}
+	}
+}
@@ -18,9 +18,13 @@ limitations under the License.
 package utils
 
 import (
+	"bytes"
+	"crypto/rand"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
+	"os"
 	"path"
 	"strings"
 
@@ -28,31 +32,69 @@ import (
 	"github.com/golang/protobuf/proto"
 )
 
+// StdinPath is the request path that reads the request from standard input.
+const StdinPath = "-"
+
 var reader = ioutil.ReadFile
 
+var stdin io.Reader = os.Stdin
+
 // LogFlow is a convenience function for logging common flows..
 func LogFlow(f string, status string) {
 	log.Println(strings.Join([]string{"\n##########\n", status, f, "Flow", "\n##########"}, " "))
 }
 
+// NewRunID returns a random RFC 4122 version 4 UUID identifying a single validation run. Unlike NewUUID, it is never
+// drawn from the seed of SetSeed, so reproduced runs remain distinct.
+func NewRunID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("unable to generate run id: %v", err)
+	}
+	return formatUUID(b), nil
+}
+
 // LoadRequest loads the request file and returns it's parsed version in pb.
+// If fp is StdinPath the request is read from standard input and its format is detected from the content.
 func LoadRequest(fp string, pbReq proto.Message) error {
+	if fp == StdinPath {
+		return LoadRequestFromReader(stdin, pbReq)
+	}
+	ext := path.Ext(fp)
+	if ext != ".json" && ext != ".pb3" {
+		return fmt.Errorf("unexpected extension for file %q, expected .json or .pb3", fp)
+	}
 	content, err := reader(fp)
-
 	if err != nil {
 		return fmt.Errorf("unable to read input file: %v", err)
 	}
-	if path.Ext(fp) == ".json" {
+	return parseRequest(content, ext == ".json", pbReq)
+}
+
+// LoadRequestFromReader reads a json or pb3 request from r into pbReq. See LoadRequestFromBytes.
+func LoadRequestFromReader(r io.Reader, pbReq proto.Message) error {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("unable to read input: %v", err)
+	}
+	return LoadRequestFromBytes(content, pbReq)
+}
+
+// LoadRequestFromBytes parses a json or pb3 request held in memory into pbReq, so callers do not have to write it to
+// a file first. Content starting with '{' is parsed as json, anything else as pb3.
+func LoadRequestFromBytes(content []byte, pbReq proto.Message) error {
+	return parseRequest(content, bytes.HasPrefix(bytes.TrimSpace(content), []byte("{")), pbReq)
+}
+
+func parseRequest(content []byte, isJSON bool, pbReq proto.Message) error {
+	if isJSON {
 		if err := jsonpb.UnmarshalString(string(content), pbReq); err != nil {
 			return fmt.Errorf("unable to parse request as json: %v", err)
 		}
 		return nil
 	}
-	if path.Ext(fp) == ".pb3" {
-		if err := proto.UnmarshalText(string(content), pbReq); err != nil {
-			return fmt.Errorf("unable to parse request as pb3: %v", err)
-		}
-		return nil
+	if err := proto.UnmarshalText(string(content), pbReq); err != nil {
+		return fmt.Errorf("unable to parse request as pb3: %v", err)
 	}
-	return fmt.Errorf("unexpected extension for file %q, expected .json or .pb3", fp)
+	return nil
 }
@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"io"
 	"io/ioutil"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/golang/protobuf/jsonpb"
@@ -93,3 +95,60 @@ func TestLoadRequests(t *testing.T) {
 		}
 	}
 }
+
+func TestNewRunID(t *testing.T) {
+	uuidV4 := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	first, err := NewRunID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := NewRunID()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !uuidV4.MatchString(first) {
+		t.Errorf("NewRunID() = %q, want a version 4 UUID", first)
+	}
+	if first == second {
+		t.Errorf("NewRunID() returned %q twice", first)
+	}
+}
+
+func TestLoadRequestFromStdin(t *testing.T) {
+	data, err := BookingAvailabilityData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(r io.Reader) { stdin = r }(stdin)
+	for _, content := range []string{data.Req, proto.MarshalTextString(data.ReqPb)} {
+		stdin = strings.NewReader(content)
+		got := &pb.BookingAvailabilityRequest{}
+		if err := LoadRequest(StdinPath, got); err != nil {
+			t.Errorf("LoadRequest(%s) returned an error: %v", StdinPath, err)
+			continue
+		}
+		if !proto.Equal(got, data.ReqPb) {
+			t.Errorf("Failed, got [%v] want [%v]", got, data.ReqPb)
+		}
+	}
+}
+
+func TestLoadRequestFromBytes(t *testing.T) {
+	data, err := BookingSubmitData()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, content := range []string{data.Req, "\n  " + data.Req, proto.MarshalTextString(data.ReqPb)} {
+		got := &pb.BookingSubmitRequest{}
+		if err := LoadRequestFromBytes([]byte(content), got); err != nil {
+			t.Errorf("LoadRequestFromBytes() returned an error: %v", err)
+			continue
+		}
+		if !proto.Equal(got, data.ReqPb) {
+			t.Errorf("Failed, got [%v] want [%v]", got, data.ReqPb)
+		}
+	}
+	if err := LoadRequestFromBytes([]byte("{not json"), &pb.BookingSubmitRequest{}); err == nil {
+		t.Error("LoadRequestFromBytes() of invalid json returned nil error")
+	}
+}
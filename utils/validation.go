@@ -17,6 +17,7 @@ limitations under the License.
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"reflect"
@@ -38,6 +39,81 @@ const ISO3166 = `^A[^ABCHJKNPVY]|B[^CKPUX]|C[^BEJPQST]|D[EJKMOZ]|E[CEGHRST]|F[IJ
 // DateFormat provides the regular expression for validating a date in YYYY-MM-DD format
 const DateFormat = `^([12]\d{3}-(0[1-9]|1[0-2])-(0[1-9]|[12]\d|3[01]))$`
 
+// RuleSetVersion identifies the validation checks applied to responses. It is incremented whenever a check is added,
+// removed or made stricter, so reports made with different rule sets are not compared as equals.
+const RuleSetVersion = "2"
+
+// MaxFindings caps the number of invalid fields listed in each validation error, and logged by each check, so
+// responses with hundreds of invalid room rates do not flood the output. Zero or less means no limit.
+var MaxFindings = 100
+
+// limitReached reports whether n invalid fields already reach MaxFindings.
+func limitReached(n int) bool {
+	return MaxFindings > 0 && n >= MaxFindings
+}
+
+// joinFields joins invalid fields for an error message, eliding those beyond MaxFindings.
+func joinFields(fields []string) string {
+	if !limitReached(len(fields)) || len(fields) == MaxFindings {
+		return strings.Join(fields, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(fields[:MaxFindings], ", "), len(fields)-MaxFindings)
+}
+
+// Severity classifies how a Finding affects the validation result.
+type Severity int
+
+const (
+	// Warning findings are reported but do not fail validation.
+	Warning Severity = iota
+	// Error findings fail validation.
+	Error
+	// Critical findings fail validation and indicate a defect with real-world impact, such as a duplicate booking.
+	Critical
+)
+
+func (s Severity) String() string {
+	switch s {
+	case Error:
+		return "Error"
+	case Critical:
+		return "Critical"
+	}
+	return "Warning"
+}
+
+// MarshalText encodes the severity by name in reports.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a severity encoded by MarshalText.
+func (s *Severity) UnmarshalText(b []byte) error {
+	for _, v := range []Severity{Warning, Error, Critical} {
+		if v.String() == string(b) {
+			*s = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown severity %q", b)
+}
+
+// Finding describes a single issue found while validating a response. Code identifies the check that found it, see
+// Rules.
+type Finding struct {
+	Severity Severity `json:"severity"`
+	Field    string   `json:"field"`
+	Message  string   `json:"message"`
+	Code     string   `json:"code,omitempty"`
+}
+
+func (f Finding) String() string {
+	if f.Code == "" {
+		return fmt.Sprintf("%v: %s %s", f.Severity, f.Field, f.Message)
+	}
+	return fmt.Sprintf("%v %s: %s %s", f.Severity, f.Code, f.Field, f.Message)
+}
+
 type validationTest struct {
 	field string
 	want  interface{}
@@ -55,62 +131,107 @@ type formatTest struct {
 	pattern string
 }
 
-// compareFields will ensure each validationTest got and want proto values are equal
-func compareFields(v []validationTest) error {
-	var errorFields []string
+// compareFields will ensure each validationTest got and want proto values are equal. Each mismatch is returned as an
+// Error finding carrying the diff of the json encoding of the values, see MismatchPrefix. A mismatch only in the letter
+// case or whitespace of strings, typically from a partner normalizing ids, has its own code and error, so the partner
+// knows to echo the values byte for byte rather than look for a wrong value.
+func compareFields(v []validationTest) ([]Finding, error) {
+	var errorFields, normalizedFields []string
+	var findings []Finding
 
 	for _, vv := range v {
 		if diff := cmp.Diff(vv.got, vv.want, cmp.Comparer(proto.Equal)); diff != "" {
-			errorFields = append(errorFields, vv.field)
-			log.Println(fmt.Errorf("%s did not match (-got +want)\n%s", vv.field, diff))
+			if d := jsonDiff(vv.got, vv.want); d != "" {
+				diff = d
+			}
+			code := CodeEchoMismatch
+			if n := echoNormalization(vv.got, vv.want); n != "" {
+				code = CodeEchoNormalized
+				normalizedFields = append(normalizedFields, fmt.Sprintf("%s (%s)", vv.field, n))
+			} else if z := echoZeroChildren(vv.got, vv.want); z != "" {
+				code = CodeEchoZeroChildren
+				errorFields = append(errorFields, fmt.Sprintf("%s (%s)", vv.field, z))
+			} else {
+				errorFields = append(errorFields, vv.field)
+			}
+			findings = append(findings, Finding{Error, vv.field, MismatchPrefix + diff, code})
+			log.Println(fmt.Errorf("%s %s%s", vv.field, MismatchPrefix, diff))
 		}
 	}
 
+	var errs []string
 	if len(errorFields) > 0 {
-		return fmt.Errorf("echo field(s) did not match request: %v", strings.Join(errorFields, ","))
+		errs = append(errs, fmt.Sprintf("echo field(s) did not match request: %v", strings.Join(errorFields, ",")))
+	}
+	if len(normalizedFields) > 0 {
+		errs = append(errs, fmt.Sprintf("echo field(s) differ from request only in letter case or whitespace, echo them exactly as sent: %v", strings.Join(normalizedFields, ",")))
+	}
+	if len(errs) > 0 {
+		return findings, errors.New(strings.Join(errs, "; "))
 	}
 
-	return nil
+	return nil, nil
 }
 
 // checkRequired will ensure each requiredTest value is not equal to the unsetValue
-func checkRequired(r []requiredTest) error {
-	var errorFields []string
+func checkRequired(r []requiredTest) ([]Finding, error) {
+	return missingError(appendMissing(nil, r))
+}
 
-	for _, rr := range r {
-		if reflect.ValueOf(rr.got).IsZero() {
-			errorFields = append(errorFields, rr.field)
-			log.Println(fmt.Errorf("Required field %s was not set", rr.field))
+// missingError returns an Error finding for each of the missing required fields, up to MaxFindings, and their error.
+func missingError(missing []string) ([]Finding, error) {
+	if len(missing) == 0 {
+		return nil, nil
+	}
+	var findings []Finding
+	for i, field := range missing {
+		if limitReached(i) {
+			break
 		}
+		findings = append(findings, Finding{Error, field, "required field is not set", CodeRequiredMissing})
 	}
+	return findings, fmt.Errorf("required field(s) missing: %v", joinFields(missing))
+}
 
-	if len(errorFields) > 0 {
-		return fmt.Errorf("required field(s) missing: %v", strings.Join(errorFields, ", "))
+// appendMissing appends the fields of r that are not set to missing, logging them until MaxFindings is reached.
+func appendMissing(missing []string, r []requiredTest) []string {
+	for _, rr := range r {
+		if reflect.ValueOf(rr.got).IsZero() {
+			if !limitReached(len(missing)) {
+				log.Println(fmt.Errorf("Required field %s was not set", rr.field))
+			}
+			missing = append(missing, rr.field)
+		}
 	}
-
-	return nil
+	return missing
 }
 
-// validateFormat will ensure each formatTest value matches given pattern
-func validateFormat(f []formatTest) error {
+// validateFormat will ensure each formatTest value matches given pattern. Each mismatch is returned as an Error
+// finding with code, up to MaxFindings.
+func validateFormat(f []formatTest, code string) ([]Finding, error) {
 	var errorFields []string
+	var findings []Finding
 
 	for _, ff := range f {
 		matched, err := regexp.Match(ff.pattern, []byte(ff.value))
 		if err != nil {
-			return err
+			return nil, err
 		}
 		if !matched {
+			if !limitReached(len(errorFields)) {
+				finding := Finding{Error, ff.field, fmt.Sprintf("value %s did not match pattern %v", ff.value, ff.pattern), code}
+				log.Println(finding)
+				findings = append(findings, finding)
+			}
 			errorFields = append(errorFields, ff.field)
-			log.Println(fmt.Errorf("Field %s value %s did not match pattern %v", ff.field, ff.value, ff.pattern))
 		}
 	}
 
 	if len(errorFields) > 0 {
-		return fmt.Errorf("error validating format for field(s): %s", strings.Join(errorFields, ", "))
+		return findings, fmt.Errorf("error validating format for field(s): %s", joinFields(errorFields))
 	}
 
-	return nil
+	return nil, nil
 }
 
 // valuePresent will check if value v is present in slice s
@@ -124,9 +245,91 @@ func valuePresent(v string, s []string) bool {
 }
 
 // ValidateBookingAvailabilityResponse ensures the availability search criteria matches the echoed response.
-func ValidateBookingAvailabilityResponse(req *pb.BookingAvailabilityRequest, resp *pb.BookingAvailabilityResponse) error {
+func ValidateBookingAvailabilityResponse(req *pb.BookingAvailabilityRequest, resp *pb.BookingAvailabilityResponse) ([]Finding, error) {
+	roomTypeCodes, ratePlanCodes, findings, err := validateAvailabilityHeader(req, resp)
+	if err != nil {
+		return findings, err
+	}
+
+	// Validate the property-level policies
+	if policyFindings, err := validateHotelPolicies(resp); err != nil {
+		return policyFindings, err
+	}
+
+	// Validate cancellation deadlines against the stay dates
+	findings, err = validateCancellationPolicies(resp)
+	if err != nil {
+		return findings, err
+	}
+
+	// Validate the amenities of room types and rate plans
+	amenityFindings, err := validateAmenities(resp)
+	findings = append(findings, amenityFindings...)
+	if err != nil {
+		return findings, err
+	}
+
+	// Validate every Room Rate & ensure room_type_codes and rate_plan_codes exist in response
+	var rt []requiredTest
+	var roomTypeRefs, ratePlanRefs []rateCode
+	for i, r := range resp.GetRoomRates() {
+		rt = append(rt, roomRateRequired(i, r)...)
+		roomTypeRefs = append(roomTypeRefs, rateCode{i, r.GetRoomTypeCode()})
+		ratePlanRefs = append(ratePlanRefs, rateCode{i, r.GetRatePlanCode()})
+	}
+	if missing, err := checkRequired(rt); err != nil {
+		return append(findings, missing...), err
+	}
+	if undefined, err := checkRateCodes("room_type_code", roomTypeCodes, roomTypeRefs); err != nil {
+		return append(findings, undefined...), err
+	}
+	if undefined, err := checkRateCodes("rate_plan_code", ratePlanCodes, ratePlanRefs); err != nil {
+		return append(findings, undefined...), err
+	}
+
+	// Ensure occupancy taxes are disclosed where they are levied
+	taxFindings, err := validateOccupancyTaxes(resp)
+	findings = append(findings, taxFindings...)
+	if err != nil {
+		return findings, err
+	}
+
+	// Ensure totals and line items use the same price encoding
+	priceFindings, err := validatePriceUnits(resp.GetApiVersion(), resp.GetRoomRates())
+	findings = append(findings, priceFindings...)
+	if err != nil {
+		return findings, err
+	}
+
+	// Ensure amounts have the decimals of their currency
+	precisionFindings, err := validateAmountPrecision(resp.GetRoomRates())
+	findings = append(findings, precisionFindings...)
+	if err != nil {
+		return findings, err
+	}
+
+	// Ensure the rooms left are plausible
+	countFindings, err := validateRoomCounts(resp.GetRoomRates())
+	findings = append(findings, countFindings...)
+	if err != nil {
+		return findings, err
+	}
+
+	// Evaluate the custom assertions
+	assertFindings, err := CustomAssertions.checkAvailability(req, resp)
+	findings = append(findings, assertFindings...)
+	if err != nil {
+		return findings, err
+	}
+
+	return findings, nil
+}
+
+// validateAvailabilityHeader checks the fields of resp other than room_rates, and returns the codes of its room
+// types and rate plans.
+func validateAvailabilityHeader(req *pb.BookingAvailabilityRequest, resp *pb.BookingAvailabilityResponse) ([]string, []string, []Finding, error) {
 	// Validate the required fields are present and not set to the default value
-	if err := checkRequired([]requiredTest{
+	if findings, err := checkRequired([]requiredTest{
 		{"api_version", resp.GetApiVersion()},
 		{"transaction_id", resp.GetTransactionId()},
 		{"hotel_id", resp.GetHotelId()},
@@ -136,90 +339,124 @@ func ValidateBookingAvailabilityResponse(req *pb.BookingAvailabilityRequest, res
 		{"hotel_details > address > city", resp.GetHotelDetails().GetAddress().GetCity()},
 		{"hotel_details > address > province", resp.GetHotelDetails().GetAddress().GetProvince()},
 	}); err != nil {
-		return err
+		return nil, nil, findings, err
 	}
-	// Ensure certain fields match expected format
-	if err := validateFormat([]formatTest{
-		{"start_date", resp.GetStartDate(), DateFormat},
-		{"end_date", resp.GetEndDate(), DateFormat},
-		{"hotel_details > address > country", resp.GetHotelDetails().GetAddress().GetCountry(), ISO3166},
-	}); err != nil {
-		return err
+	// Ensure certain fields match the expected format of the API version
+	f := FormatsFor(resp.GetApiVersion())
+	if findings, err := validateFormat([]formatTest{
+		{"start_date", resp.GetStartDate(), f.Date},
+		{"end_date", resp.GetEndDate(), f.Date},
+		{"hotel_details > address > country", resp.GetHotelDetails().GetAddress().GetCountry(), f.Country},
+	}, CodeFormatInvalid); err != nil {
+		return nil, nil, findings, err
+	}
+	if findings, err := validateTransactionID(resp.GetTransactionId()); err != nil {
+		return nil, nil, findings, err
 	}
 	// Ensure response echo fields match request values
-	if err := compareFields([]validationTest{
+	if findings, err := compareFields([]validationTest{
+		{"transaction_id", req.GetTransactionId(), resp.GetTransactionId()},
 		{"hotel_id", req.GetHotelId(), resp.GetHotelId()},
 		{"start_date", req.GetStartDate(), resp.GetStartDate()},
 		{"end_date", req.GetEndDate(), resp.GetEndDate()},
 		{"party", req.GetParty(), resp.GetParty()},
 	}); err != nil {
-		return err
+		return nil, nil, findings, err
 	}
 
 	roomTypeCodes := make([]string, len(resp.GetRoomTypes()))
 	ratePlanCodes := make([]string, len(resp.GetRatePlans()))
 
-	// Validate each Room Type
+	// Validate every Room Type, reporting all invalid elements
+	var rt []requiredTest
 	for i, r := range resp.GetRoomTypes() {
 		roomTypeCodes[i] = r.GetCode()
-		err := checkRequired([]requiredTest{
-			{fmt.Sprintf("room_types[%d] > code", i), r.GetCode()},
-			{fmt.Sprintf("room_types[%d] > name", i), r.GetName().String()},
-		})
-		if err != nil {
-			return err
-		}
+		rt = append(rt,
+			requiredTest{fmt.Sprintf("room_types[%d] > code", i), r.GetCode()},
+			requiredTest{fmt.Sprintf("room_types[%d] > name", i), r.GetName().String()},
+		)
+	}
+	if findings, err := checkRequired(rt); err != nil {
+		return nil, nil, findings, err
 	}
 
-	// Validate each Rate Plan
+	// Validate every Rate Plan
+	rt = nil
 	for i, r := range resp.GetRatePlans() {
 		ratePlanCodes[i] = r.GetCode()
-		err := checkRequired([]requiredTest{
-			{fmt.Sprintf("rate_plans[%d] > code", i), r.GetCode()},
-			{fmt.Sprintf("rate_plans[%d] > name", i), r.GetName().String()},
-			{fmt.Sprintf("rate_plans[%d] > cancellation_policy", i), r.GetCancellationPolicy()},
-		})
-		if err != nil {
-			return err
-		}
+		rt = append(rt,
+			requiredTest{fmt.Sprintf("rate_plans[%d] > code", i), r.GetCode()},
+			requiredTest{fmt.Sprintf("rate_plans[%d] > name", i), r.GetName().String()},
+			requiredTest{fmt.Sprintf("rate_plans[%d] > cancellation_policy", i), r.GetCancellationPolicy()},
+		)
+	}
+	if findings, err := checkRequired(rt); err != nil {
+		return nil, nil, findings, err
 	}
+	return roomTypeCodes, ratePlanCodes, nil, nil
+}
 
-	// Validate each Room Rate & ensure room_type_codes and rate_plan_codes exist in response
-	for i, r := range resp.GetRoomRates() {
-		rt := make([]requiredTest, len(r.GetLineItems()))
-		for j, l := range r.GetLineItems() {
-			// Ensure price is not zero or unset
-			rt[j] = requiredTest{fmt.Sprintf("room_rates[%d] > line_items[%d] > price", i, j), l.GetPrice().GetAmount()}
-		}
-		rt = append(rt, requiredTest{fmt.Sprintf("room_rates[%d] > code", i), r.GetCode()})
-		if err := checkRequired(rt); err != nil {
-			return err
-		}
-		if !valuePresent(r.GetRoomTypeCode(), roomTypeCodes) {
-			return fmt.Errorf("room_rates > room_type_code %v not present in room_types > code", r.GetRoomTypeCode())
-		}
-		if !valuePresent(r.GetRatePlanCode(), ratePlanCodes) {
-			return fmt.Errorf("room_rates > rate_plan_code %v not present in rate_plans > code", r.GetRatePlanCode())
-		}
+// roomRateRequired returns the required fields of r, the room rate at index i.
+func roomRateRequired(i int, r *pb.RoomRate) []requiredTest {
+	var rt []requiredTest
+	for j, l := range r.GetLineItems() {
+		// Ensure price is not zero or unset
+		rt = append(rt, requiredTest{fmt.Sprintf("room_rates[%d] > line_items[%d] > price", i, j), l.GetPrice().GetAmount()})
 	}
+	return append(rt, requiredTest{fmt.Sprintf("room_rates[%d] > code", i), r.GetCode()})
+}
 
-	return nil
+// rateCode is a room_type_code or rate_plan_code referenced by the room rate at index.
+type rateCode struct {
+	index int
+	code  string
+}
+
+// checkRateCodes ensures every code referenced by room_rates under field, e.g. room_type_code, is one of defined.
+// The first room rate referencing each undefined code is returned as an Error finding, up to MaxFindings.
+func checkRateCodes(field string, defined []string, refs []rateCode) ([]Finding, error) {
+	var missing []string
+	var findings []Finding
+	definedIn := strings.TrimSuffix(field, "_code") + "s > code"
+	for _, r := range refs {
+		if !valuePresent(r.code, defined) && !valuePresent(r.code, missing) {
+			if !limitReached(len(missing)) {
+				f := Finding{Error, fmt.Sprintf("room_rates[%d] > %s", r.index, field), fmt.Sprintf("%s is not defined in %s", r.code, definedIn), CodeRateCodeUndefined}
+				log.Println(f)
+				findings = append(findings, f)
+			}
+			missing = append(missing, r.code)
+		}
+	}
+	if len(missing) > 0 {
+		return findings, fmt.Errorf("room_rates > %s %v not present in %s", field, joinFields(missing), definedIn)
+	}
+	return nil, nil
 }
 
 // ValidateBookingSubmitResponse checks for required fields, formats, and matching echo responses.
-func ValidateBookingSubmitResponse(req *pb.BookingSubmitRequest, resp *pb.BookingSubmitResponse) error {
+func ValidateBookingSubmitResponse(req *pb.BookingSubmitRequest, resp *pb.BookingSubmitResponse) ([]Finding, error) {
 	// Validate required fields are present and not set to the default value
-	if err := checkRequired([]requiredTest{
+	if findings, err := checkRequired([]requiredTest{
 		{"api_version", resp.GetApiVersion()},
 		{"transaction_id", resp.GetTransactionId()},
 		{"status", resp.GetStatus().String()},
 		{"reservation > locator > id", resp.GetReservation().GetLocator().GetId()},
 	}); err != nil {
-		return err
+		return findings, err
+	}
+
+	// Ensure the locators are well formed
+	locators := []locatorField{{"reservation > locator > id", resp.GetReservation().GetLocator().GetId()}}
+	for i, l := range resp.GetReservation().GetHotelLocators() {
+		locators = append(locators, locatorField{fmt.Sprintf("reservation > hotel_locators[%d] > id", i), l.GetId()})
+	}
+	if findings, err := validateLocators(locators); err != nil {
+		return findings, err
 	}
 
 	// Ensure echo response fields match request values
-	if err := compareFields([]validationTest{
+	if findings, err := compareFields([]validationTest{
 		{"hotel_id", req.GetHotelId(), resp.GetReservation().GetHotelId()},
 		{"start_date", req.GetStartDate(), resp.GetReservation().GetStartDate()},
 		{"end_date", req.GetEndDate(), resp.GetReservation().GetEndDate()},
@@ -227,8 +464,9 @@ func ValidateBookingSubmitResponse(req *pb.BookingSubmitRequest, resp *pb.Bookin
 		{"traveler", req.GetTraveler(), resp.GetReservation().GetTraveler()},
 		{"room_rate", req.GetRoomRate(), resp.GetReservation().GetRoomRate()},
 	}); err != nil {
-		return err
+		return findings, err
 	}
 
-	return nil
+	// Evaluate the custom assertions
+	return CustomAssertions.checkSubmit(req, resp)
 }
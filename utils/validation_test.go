@@ -19,7 +19,7 @@ func TestValidateBookingAvailabilityResponse(t *testing.T) {
 	if err != nil {
 		t.Fatalf("error fetching BookingAvailabilityData: %q", err)
 	}
-	got := ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb)
+	_, got := ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb)
 	if got != nil {
 		t.Errorf("Expected successful validation, got error %q", got)
 	}
@@ -30,7 +30,7 @@ func TestValidateBookingSubmitResponse(t *testing.T) {
 	if err != nil {
 		t.Fatalf("error fetching BookingSubmitData: %q", err)
 	}
-	got := ValidateBookingSubmitResponse(data.ReqPb, data.RespPb)
+	_, got := ValidateBookingSubmitResponse(data.ReqPb, data.RespPb)
 	if got != nil {
 		t.Errorf("Expected successful validation, got error %q", got)
 	}
@@ -43,7 +43,7 @@ func TestValidateBookingSubmitResponseError(t *testing.T) {
 	}
 	data.RespPb.Reservation.HotelId = "xxx"
 	want := fmt.Errorf("echo field(s) did not match request: hotel_id")
-	got := ValidateBookingSubmitResponse(data.ReqPb, data.RespPb)
+	_, got := ValidateBookingSubmitResponse(data.ReqPb, data.RespPb)
 	if diff := cmp.Diff(got, want, equateErrorMessage); diff != "" {
 		t.Errorf("failed to catch different value in echo field (diff -got +want): %s", diff)
 	}
@@ -58,7 +58,7 @@ func TestValidateBookingSubmitResponseMissing(t *testing.T) {
 	data.RespPb.TransactionId = ""
 	data.RespPb.Reservation.Locator.Id = ""
 	want := fmt.Errorf("required field(s) missing: api_version, transaction_id, reservation > locator > id")
-	got := ValidateBookingSubmitResponse(data.ReqPb, data.RespPb)
+	_, got := ValidateBookingSubmitResponse(data.ReqPb, data.RespPb)
 	if diff := cmp.Diff(got, want, equateErrorMessage); diff != "" {
 		t.Errorf("failed to catch missing required fields (diff -got +want): %s", diff)
 	}
@@ -74,7 +74,7 @@ func TestValidateBookingAvailabilityResponseMissing(t *testing.T) {
 	data.RespPb.Party.Adults = 0
 	data.RespPb.HotelDetails.Address.Address1 = ""
 	want := fmt.Errorf("required field(s) missing: api_version, party > adults, hotel_details > address > address1")
-	got := ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb)
+	_, got := ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb)
 	if diff := cmp.Diff(got, want, equateErrorMessage); diff != "" {
 		t.Errorf("failed to catch missing required fields (diff -got +want): %s", diff)
 	}
@@ -89,10 +89,13 @@ func TestValidateBookingAvailabilityResponseFormat(t *testing.T) {
 	data.ReqPb.StartDate = "20010401"
 	data.RespPb.StartDate = "20010401"
 	want := fmt.Errorf("error validating format for field(s): start_date")
-	got := ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb)
+	findings, got := ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb)
 	if diff := cmp.Diff(got, want, equateErrorMessage); diff != "" {
 		t.Errorf("failed to catch invalid date format (diff -got +want): %s", diff)
 	}
+	if len(findings) != 1 || findings[0].Field != "start_date" || findings[0].Code != CodeFormatInvalid {
+		t.Errorf("got findings %v, want one %s finding for start_date", findings, CodeFormatInvalid)
+	}
 }
 
 func TestValidateBookingAvailabilityResponseArrayValidation(t *testing.T) {
@@ -103,12 +106,36 @@ func TestValidateBookingAvailabilityResponseArrayValidation(t *testing.T) {
 	// missing room_types > code
 	data.RespPb.RoomTypes[1].Code = ""
 	want := fmt.Errorf("required field(s) missing: room_types[1] > code")
-	got := ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb)
+	_, got := ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb)
 	if diff := cmp.Diff(got, want, equateErrorMessage); diff != "" {
 		t.Errorf("failed to catch missing room_type > code (diff -got +want): %s", diff)
 	}
 }
 
+func TestValidateBookingAvailabilityResponseExhaustiveArrays(t *testing.T) {
+	data, err := BookingAvailabilityData()
+	if err != nil {
+		t.Fatalf("error fetching BookingAvailabilityData: %q", err)
+	}
+	// every invalid room rate is reported, not only the first
+	for _, r := range data.RespPb.RoomRates {
+		r.Code = ""
+	}
+	want := fmt.Errorf("required field(s) missing: room_rates[0] > code, room_rates[1] > code, room_rates[2] > code")
+	_, got := ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb)
+	if diff := cmp.Diff(got, want, equateErrorMessage); diff != "" {
+		t.Errorf("failed to report all missing room_rates > code (diff -got +want): %s", diff)
+	}
+
+	defer func(n int) { MaxFindings = n }(MaxFindings)
+	MaxFindings = 2
+	want = fmt.Errorf("required field(s) missing: room_rates[0] > code, room_rates[1] > code and 1 more")
+	_, got = ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb)
+	if diff := cmp.Diff(got, want, equateErrorMessage); diff != "" {
+		t.Errorf("failed to cap missing room_rates > code at MaxFindings (diff -got +want): %s", diff)
+	}
+}
+
 func TestValidateBookingAvailabilityResponseArrayStructValidation(t *testing.T) {
 	data, err := BookingAvailabilityData()
 	if err != nil {
@@ -117,7 +144,7 @@ func TestValidateBookingAvailabilityResponseArrayStructValidation(t *testing.T)
 	// missing rate_plans > cancellation_policy
 	data.RespPb.RatePlans[0].CancellationPolicy = nil
 	want := fmt.Errorf("required field(s) missing: rate_plans[0] > cancellation_policy")
-	got := ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb)
+	_, got := ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb)
 	if diff := cmp.Diff(got, want, equateErrorMessage); diff != "" {
 		t.Errorf("failed to catch missing rate_plans > cancellation_policy (diff -got +want): %s", diff)
 	}
@@ -131,7 +158,7 @@ func TestValidateBookingAvailabilityResponseLineItem(t *testing.T) {
 	// room_rates > line_items > price > amount set to 0
 	data.RespPb.RoomRates[0].LineItems[0].Price.Amount = 0
 	want := fmt.Errorf("required field(s) missing: room_rates[0] > line_items[0] > price")
-	got := ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb)
+	_, got := ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb)
 	if diff := cmp.Diff(got, want, equateErrorMessage); diff != "" {
 		t.Errorf("failed to catch price > amount set to 0 (diff -got +want): %s", diff)
 	}
@@ -139,7 +166,7 @@ func TestValidateBookingAvailabilityResponseLineItem(t *testing.T) {
 	// missing room_rates > line_items > price
 	data.RespPb.RoomRates[0].LineItems[0].Price = nil
 	want = fmt.Errorf("required field(s) missing: room_rates[0] > line_items[0] > price")
-	got = ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb)
+	_, got = ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb)
 	if diff := cmp.Diff(got, want, equateErrorMessage); diff != "" {
 		t.Errorf("failed to catch missing room_rates > line_items > price (diff -got +want): %s", diff)
 	}
@@ -153,7 +180,7 @@ func TestValidateBookingAvailabilityResponseInvalidRoomTypeCode(t *testing.T) {
 	// room_rates > room_type_code that does not match any value in room_types > code
 	data.RespPb.RoomRates[0].RoomTypeCode = "XXX"
 	want := fmt.Errorf("room_rates > room_type_code XXX not present in room_types > code")
-	got := ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb)
+	_, got := ValidateBookingAvailabilityResponse(data.ReqPb, data.RespPb)
 	if diff := cmp.Diff(got, want, equateErrorMessage); diff != "" {
 		t.Errorf("failed to catch invalid room_type_code (diff -got +want): %s", diff)
 	}